@@ -37,7 +37,10 @@ func (d DeadNationClient) BookInDeadNation(ctx context.Context, request entities
 	}
 
 	if resp.StatusCode() != http.StatusOK {
-		return fmt.Errorf("failed to book ticket: unexpected status code %d", resp.StatusCode())
+		return classifyStatusCode(
+			resp.HTTPResponse,
+			fmt.Errorf("failed to book ticket: unexpected status code %d", resp.StatusCode()),
+		)
 	}
 
 	return nil
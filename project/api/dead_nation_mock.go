@@ -20,3 +20,10 @@ func (c *DeadNationMock) BookInDeadNation(ctx context.Context, request entities.
 
 	return nil
 }
+
+func (c *DeadNationMock) Bookings() []entities.DeadNationBooking {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return append([]entities.DeadNationBooking(nil), c.DeadNationBookings...)
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"tickets/message/retry"
+	"time"
+)
+
+// classifyStatusCode marks err according to the status code of the response, so handlers don't retry requests
+// that can't succeed: 4xx are permanent (except for the ones below), 5xx are transient.
+//
+// 401 and 403 are transient, as they come from our credentials or the configuration of the API, not the request:
+// the messages should wait until it's fixed, instead of all of them going to the dead letter topic.
+// 409 is transient as well, the conflicting request may be still in progress. The clients expecting it
+// (like the files API for already uploaded files) handle it before.
+func classifyStatusCode(resp *http.Response, err error) error {
+	if resp == nil {
+		return retry.Transient(err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return retry.RateLimited(err, parseRetryAfter(resp.Header.Get("Retry-After")))
+	case resp.StatusCode == http.StatusRequestTimeout,
+		resp.StatusCode == http.StatusUnauthorized,
+		resp.StatusCode == http.StatusForbidden,
+		resp.StatusCode == http.StatusConflict:
+		return retry.Transient(err)
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		return retry.Permanent(err)
+	default:
+		return retry.Transient(err)
+	}
+}
+
+// parseRetryAfter supports both forms of the Retry-After header: delay in seconds and HTTP date.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		if delay := time.Until(date); delay > 0 {
+			return delay
+		}
+	}
+
+	return 0
+}
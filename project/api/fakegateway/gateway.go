@@ -0,0 +1,328 @@
+// Package fakegateway contains a local stand-in of the gateway with the external APIs used by the service
+// (spreadsheets, receipts, files and dead nation). Unlike the mocks from the api package,
+// it's called by the real HTTP clients, so their status code handling is tested as well.
+package fakegateway
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ThreeDotsLabs/go-event-driven/common/clients/dead_nation"
+	"github.com/ThreeDotsLabs/go-event-driven/common/clients/receipts"
+	"github.com/ThreeDotsLabs/go-event-driven/common/clients/spreadsheets"
+	"github.com/labstack/echo/v4"
+)
+
+type Request struct {
+	Method     string
+	Path       string
+	Header     http.Header
+	Body       []byte
+	StatusCode int
+}
+
+// Failure makes the requests matching Method and PathPrefix fail.
+// Empty Method or PathPrefix match all requests.
+type Failure struct {
+	Method     string
+	PathPrefix string
+
+	// StatusCode is returned instead of calling the API, when it's 0 the API is called after Latency.
+	StatusCode int
+	// Latency is added before the response.
+	Latency time.Duration
+	// Times is the number of requests affected by the failure, 0 means all of them until ClearFailures is called.
+	Times int
+}
+
+func (f Failure) matches(r *http.Request) bool {
+	if f.Method != "" && f.Method != r.Method {
+		return false
+	}
+
+	return strings.HasPrefix(r.URL.Path, f.PathPrefix)
+}
+
+type Gateway struct {
+	server *httptest.Server
+
+	lock     sync.Mutex
+	requests []Request
+	failures []*Failure
+
+	sheets             map[string][][]string
+	receipts           map[string]receipts.Receipt
+	files              map[string]string
+	deadNationBookings []dead_nation.PostTicketBookingRequest
+}
+
+func New() *Gateway {
+	g := &Gateway{
+		sheets:   make(map[string][][]string),
+		receipts: make(map[string]receipts.Receipt),
+		files:    make(map[string]string),
+	}
+
+	e := echo.New()
+	e.HideBanner = true
+	e.Use(g.recordRequest, g.injectFailures)
+
+	e.POST("/spreadsheets-api/sheets/:sheet/rows", g.postSheetRows)
+	e.GET("/spreadsheets-api/sheets/:sheet/rows", g.getSheetRows)
+	e.PUT("/receipts-api/receipts", g.putReceipts)
+	e.PUT("/files-api/files/:id/content", g.putFileContent)
+	e.GET("/files-api/files/:id/content", g.getFileContent)
+	e.POST("/dead-nation-api/ticket/booking", g.postTicketBooking)
+
+	g.server = httptest.NewServer(e)
+
+	return g
+}
+
+// URL is the gateway address to pass to clients.NewClients.
+func (g *Gateway) URL() string {
+	return g.server.URL
+}
+
+func (g *Gateway) Close() {
+	g.server.Close()
+}
+
+func (g *Gateway) InjectFailure(failure Failure) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	g.failures = append(g.failures, &failure)
+}
+
+func (g *Gateway) ClearFailures() {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	g.failures = nil
+}
+
+// Requests returns all requests received by the gateway, in the order they were received.
+func (g *Gateway) Requests() []Request {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	return append([]Request(nil), g.requests...)
+}
+
+func (g *Gateway) SheetRows(sheet string) [][]string {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	return append([][]string(nil), g.sheets[sheet]...)
+}
+
+func (g *Gateway) Receipts() []receipts.Receipt {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	result := make([]receipts.Receipt, 0, len(g.receipts))
+	for _, receipt := range g.receipts {
+		result = append(result, receipt)
+	}
+
+	return result
+}
+
+func (g *Gateway) File(fileID string) (string, bool) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	content, ok := g.files[fileID]
+	return content, ok
+}
+
+func (g *Gateway) DeadNationBookings() []dead_nation.PostTicketBookingRequest {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	return append([]dead_nation.PostTicketBookingRequest(nil), g.deadNationBookings...)
+}
+
+func (g *Gateway) recordRequest(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		body, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			return err
+		}
+		c.Request().Body = io.NopCloser(bytes.NewReader(body))
+
+		err = next(c)
+		if err != nil {
+			c.Error(err)
+		}
+
+		g.lock.Lock()
+		defer g.lock.Unlock()
+
+		g.requests = append(g.requests, Request{
+			Method:     c.Request().Method,
+			Path:       c.Request().URL.Path,
+			Header:     c.Request().Header.Clone(),
+			Body:       body,
+			StatusCode: c.Response().Status,
+		})
+
+		return nil
+	}
+}
+
+func (g *Gateway) injectFailures(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		failure, ok := g.takeFailure(c.Request())
+		if !ok {
+			return next(c)
+		}
+
+		if failure.Latency > 0 {
+			select {
+			case <-c.Request().Context().Done():
+				return c.Request().Context().Err()
+			case <-time.After(failure.Latency):
+			}
+		}
+
+		if failure.StatusCode != 0 {
+			return c.JSON(failure.StatusCode, map[string]string{
+				"error": fmt.Sprintf("injected failure: %d", failure.StatusCode),
+			})
+		}
+
+		return next(c)
+	}
+}
+
+func (g *Gateway) takeFailure(r *http.Request) (Failure, bool) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	for i, failure := range g.failures {
+		if !failure.matches(r) {
+			continue
+		}
+
+		if failure.Times > 0 {
+			failure.Times--
+			if failure.Times == 0 {
+				g.failures = append(g.failures[:i], g.failures[i+1:]...)
+			}
+		}
+
+		return *failure, true
+	}
+
+	return Failure{}, false
+}
+
+func (g *Gateway) postSheetRows(c echo.Context) error {
+	var request spreadsheets.PostSheetsSheetRowsJSONBody
+	if err := c.Bind(&request); err != nil {
+		return err
+	}
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	sheet := c.Param("sheet")
+	g.sheets[sheet] = append(g.sheets[sheet], request.Columns)
+
+	return c.NoContent(http.StatusOK)
+}
+
+func (g *Gateway) getSheetRows(c echo.Context) error {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	rows := append([]spreadsheets.SpreadsheetRow{}, g.sheets[c.Param("sheet")]...)
+
+	return c.JSON(http.StatusOK, spreadsheets.SpreadsheetRows{Rows: rows})
+}
+
+func (g *Gateway) putReceipts(c echo.Context) error {
+	var request receipts.CreateReceipt
+	if err := c.Bind(&request); err != nil {
+		return err
+	}
+
+	if request.TicketId == "" {
+		return c.JSON(http.StatusBadRequest, receipts.ErrorResponse{Error: "missing ticket_id"})
+	}
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	idempotencyKey := request.TicketId
+	if request.IdempotencyKey != nil {
+		idempotencyKey = *request.IdempotencyKey
+	}
+
+	if receipt, ok := g.receipts[idempotencyKey]; ok {
+		return c.JSON(http.StatusOK, receipt)
+	}
+
+	receipt := receipts.Receipt{
+		IdempotencyKey: request.IdempotencyKey,
+		IssuedAt:       time.Now().UTC(),
+		Number:         fmt.Sprintf("RCPT-%d", len(g.receipts)+1),
+		Price:          request.Price,
+		TicketId:       request.TicketId,
+	}
+	g.receipts[idempotencyKey] = receipt
+
+	return c.JSON(http.StatusCreated, receipt)
+}
+
+func (g *Gateway) putFileContent(c echo.Context) error {
+	content, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return err
+	}
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	fileID := c.Param("id")
+	if _, ok := g.files[fileID]; ok {
+		return c.NoContent(http.StatusConflict)
+	}
+	g.files[fileID] = string(content)
+
+	return c.NoContent(http.StatusCreated)
+}
+
+func (g *Gateway) getFileContent(c echo.Context) error {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	content, ok := g.files[c.Param("id")]
+	if !ok {
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	return c.String(http.StatusOK, content)
+}
+
+func (g *Gateway) postTicketBooking(c echo.Context) error {
+	var request dead_nation.PostTicketBookingRequest
+	if err := c.Bind(&request); err != nil {
+		return err
+	}
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	g.deadNationBookings = append(g.deadNationBookings, request)
+
+	return c.JSON(http.StatusOK, dead_nation.PostTicketBookingResp{BookingId: request.BookingId})
+}
@@ -34,7 +34,10 @@ func (c FileAPIClient) UploadFile(ctx context.Context, fileID string, fileConten
 		return nil
 	}
 	if resp.StatusCode() != http.StatusCreated {
-		return fmt.Errorf("unexpected status code while uploading file %s: %d", fileID, resp.StatusCode())
+		return classifyStatusCode(
+			resp.HTTPResponse,
+			fmt.Errorf("unexpected status code while uploading file %s: %d", fileID, resp.StatusCode()),
+		)
 	}
 
 	return nil
@@ -50,7 +53,10 @@ func (c FileAPIClient) DownloadFile(ctx context.Context, fileID string) (string,
 		return "", nil
 	}
 	if resp.StatusCode() != http.StatusOK {
-		return "", fmt.Errorf("unexpected status code while getting file %s: %d", fileID, resp.StatusCode())
+		return "", classifyStatusCode(
+			resp.HTTPResponse,
+			fmt.Errorf("unexpected status code while getting file %s: %d", fileID, resp.StatusCode()),
+		)
 	}
 
 	return string(resp.Body), nil
@@ -50,6 +50,9 @@ func (c ReceiptsServiceClient) IssueReceipt(ctx context.Context, request entitie
 			IssuedAt:      resp.JSON201.IssuedAt,
 		}, nil
 	default:
-		return entities.IssueReceiptResponse{}, fmt.Errorf("unexpected status code for POST receipts-api/receipts: %d", resp.StatusCode())
+		return entities.IssueReceiptResponse{}, classifyStatusCode(
+			resp.HTTPResponse,
+			fmt.Errorf("unexpected status code for POST receipts-api/receipts: %d", resp.StatusCode()),
+		)
 	}
 }
@@ -24,3 +24,16 @@ func (r *ReceiptsServiceMock) IssueReceipt(ctx context.Context, request entities
 		IssuedAt:      time.Now(),
 	}, nil
 }
+
+// Issued returns the requests of the issued receipts.
+func (r *ReceiptsServiceMock) Issued() []entities.IssueReceiptRequest {
+	r.mock.Lock()
+	defer r.mock.Unlock()
+
+	issued := make([]entities.IssueReceiptRequest, 0, len(r.IssuedReceipts))
+	for _, request := range r.IssuedReceipts {
+		issued = append(issued, request)
+	}
+
+	return issued
+}
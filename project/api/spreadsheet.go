@@ -31,8 +31,27 @@ func (c SpreadsheetsAPIClient) AppendRow(ctx context.Context, spreadsheetName st
 	}
 
 	if resp.StatusCode() != http.StatusOK {
-		return fmt.Errorf("failed to post row: unexpected status code %d", resp.StatusCode())
+		return classifyStatusCode(
+			resp.HTTPResponse,
+			fmt.Errorf("failed to post row: unexpected status code %d", resp.StatusCode()),
+		)
 	}
 
 	return nil
 }
+
+func (c SpreadsheetsAPIClient) GetRows(ctx context.Context, spreadsheetName string) ([][]string, error) {
+	resp, err := c.clients.Spreadsheets.GetSheetsSheetRowsWithResponse(ctx, spreadsheetName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows: %w", err)
+	}
+
+	if resp.StatusCode() != http.StatusOK || resp.JSON200 == nil {
+		return nil, classifyStatusCode(
+			resp.HTTPResponse,
+			fmt.Errorf("failed to get rows: unexpected status code %d", resp.StatusCode()),
+		)
+	}
+
+	return resp.JSON200.Rows, nil
+}
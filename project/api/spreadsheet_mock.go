@@ -21,3 +21,10 @@ func (c *SpreadsheetsAPIMock) AppendRow(ctx context.Context, spreadsheetName str
 	c.Rows[spreadsheetName] = append(c.Rows[spreadsheetName], row)
 	return nil
 }
+
+func (c *SpreadsheetsAPIMock) GetRows(ctx context.Context, spreadsheetName string) ([][]string, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return append([][]string(nil), c.Rows[spreadsheetName]...), nil
+}
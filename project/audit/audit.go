@@ -0,0 +1,157 @@
+// Package audit records who changed what with the API. Every mutating request gets an Entry in its context:
+// the repositories save its record in the transaction of the change (see Take), so the record is saved
+// if and only if the change is.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"sync"
+	"tickets/entities"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Entry is the record of a request, it's filled in while the request is handled.
+type Entry struct {
+	lock     sync.Mutex
+	record   entities.AuditRecord
+	recorded bool
+}
+
+func NewEntry(actor, action, correlationID, summary string) *Entry {
+	return &Entry{
+		record: entities.AuditRecord{
+			ID:            uuid.New(),
+			OccurredAt:    time.Now().UTC(),
+			Actor:         actor,
+			Action:        action,
+			TargetIDs:     []string{},
+			CorrelationID: correlationID,
+			Summary:       summary,
+		},
+	}
+}
+
+// AddTargets adds the IDs of the changed shows, bookings, tickets (or keys) to the record, skipping the duplicates.
+func (e *Entry) AddTargets(ids ...string) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	for _, id := range ids {
+		if id != "" && !slices.Contains(e.record.TargetIDs, id) {
+			e.record.TargetIDs = append(e.record.TargetIDs, id)
+		}
+	}
+}
+
+// Take returns the record to be saved, and marks it as recorded. It returns false if it's already recorded.
+func (e *Entry) Take() (entities.AuditRecord, bool) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if e.recorded {
+		return entities.AuditRecord{}, false
+	}
+	e.recorded = true
+
+	record := e.record
+	record.TargetIDs = slices.Clone(e.record.TargetIDs)
+
+	return record, true
+}
+
+type contextKey struct{}
+
+func WithEntry(ctx context.Context, entry *Entry) context.Context {
+	return context.WithValue(ctx, contextKey{}, entry)
+}
+
+// FromContext returns the entry of the request, or nil outside of the audited requests (like in the message handlers).
+func FromContext(ctx context.Context) *Entry {
+	entry, _ := ctx.Value(contextKey{}).(*Entry)
+	return entry
+}
+
+// AddTargets adds the IDs to the entry from the context, if there is one.
+func AddTargets(ctx context.Context, ids ...string) {
+	if entry := FromContext(ctx); entry != nil {
+		entry.AddTargets(ids...)
+	}
+}
+
+// Take returns the record of the entry from the context to be saved with the change.
+// It returns false if there is no entry, or its record is already taken.
+func Take(ctx context.Context) (entities.AuditRecord, bool) {
+	entry := FromContext(ctx)
+	if entry == nil {
+		return entities.AuditRecord{}, false
+	}
+
+	return entry.Take()
+}
+
+// maxSummaryLength is the maximum length of the summary (in characters), the longer summaries are truncated.
+const maxSummaryLength = 1000
+
+const redacted = "[redacted]"
+
+// sensitiveFields are the fields of the customers' data and the credentials, they are not kept in the audit log.
+var sensitiveFields = map[string]bool{
+	"customer_email": true,
+	"email":          true,
+	"secret":         true,
+	"key":            true,
+}
+
+// Sensitive returns true if the field (or the path parameter) has the customers' data or credentials,
+// so its value must not be recorded.
+func Sensitive(field string) bool {
+	return sensitiveFields[field]
+}
+
+// Summarize returns the JSON body of the request with the emails and the secrets redacted.
+// It returns an empty summary if the body is empty or it's not JSON.
+func Summarize(body []byte) string {
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return ""
+	}
+
+	summary, err := json.Marshal(redact(value))
+	if err != nil {
+		return ""
+	}
+
+	return truncate(string(summary))
+}
+
+func redact(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for field, fieldValue := range v {
+			if Sensitive(field) {
+				v[field] = redacted
+			} else {
+				v[field] = redact(fieldValue)
+			}
+		}
+	case []any:
+		for i := range v {
+			v[i] = redact(v[i])
+		}
+	}
+
+	return value
+}
+
+func truncate(summary string) string {
+	if utf8.RuneCountInString(summary) <= maxSummaryLength {
+		return summary
+	}
+
+	return string([]rune(summary)[:maxSummaryLength]) + "…"
+}
@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"tickets/entities"
+	"tickets/tenant"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	apiKeyPrefix       = "tk_"
+	apiKeyPrefixLength = 10
+)
+
+var ErrInvalidAPIKey = errors.New("invalid API key")
+
+// NewAPIKey generates a new key for the role in the tenant. The key is returned only once,
+// the returned APIKey has only its hash, which is what's stored.
+func NewAPIKey(name string, role entities.Role, tenantID string) (entities.APIKey, string, error) {
+	if strings.TrimSpace(name) == "" {
+		return entities.APIKey{}, "", fmt.Errorf("%w: name is required", ErrInvalidAPIKey)
+	}
+	if !role.Valid() {
+		return entities.APIKey{}, "", fmt.Errorf("%w: unknown role %s", ErrInvalidAPIKey, role)
+	}
+	if err := tenant.Validate(tenantID); err != nil {
+		return entities.APIKey{}, "", fmt.Errorf("%w: %w", ErrInvalidAPIKey, err)
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return entities.APIKey{}, "", fmt.Errorf("could not generate API key: %w", err)
+	}
+
+	key := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	return entities.APIKey{
+		ID:        uuid.New(),
+		Name:      name,
+		Role:      role,
+		TenantID:  tenantID,
+		Prefix:    key[:apiKeyPrefixLength],
+		Hash:      HashAPIKey(key),
+		CreatedAt: time.Now().UTC(),
+	}, key, nil
+}
+
+// HashAPIKey returns the stored hash of the key. The keys are random, so a fast hash is enough
+// (and the keys can be looked up by their hashes).
+func HashAPIKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,226 @@
+// Package auth authenticates the callers of the HTTP API and checks their roles.
+// Services (like the gateway) call the API with API keys, operators with JWT bearer tokens
+// signed by the identity provider.
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"tickets/db"
+	"tickets/entities"
+	"tickets/tenant"
+	"time"
+
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// APIKeyHeader is the header of the API keys, operators send JWTs in the Authorization header.
+	APIKeyHeader = "X-API-Key"
+
+	principalContextKey = "auth_principal"
+)
+
+type Config struct {
+	// JWTPublicKey verifies the operators' tokens (signed with RS256). Bearer tokens are rejected when it's nil.
+	JWTPublicKey *rsa.PublicKey
+	// JWTIssuer is the required "iss" claim of the tokens, it's not checked when empty.
+	JWTIssuer string
+
+	// GatewaySecrets verify the signatures of the gateway callbacks. The current secret goes first,
+	// the previous ones are kept until the gateway signs with the new one.
+	GatewaySecrets []string
+	// SignatureTolerance is the maximum age of the signature timestamp, 5 minutes by default.
+	SignatureTolerance time.Duration
+}
+
+// ConfigFromEnv reads the config from the JWT_PUBLIC_KEY (PEM), JWT_ISSUER, GATEWAY_SECRETS (separated by commas)
+// and GATEWAY_SIGNATURE_TOLERANCE environment variables.
+func ConfigFromEnv() (Config, error) {
+	config := Config{
+		JWTIssuer: os.Getenv("JWT_ISSUER"),
+	}
+
+	for _, secret := range strings.Split(os.Getenv("GATEWAY_SECRETS"), ",") {
+		if secret = strings.TrimSpace(secret); secret != "" {
+			config.GatewaySecrets = append(config.GatewaySecrets, secret)
+		}
+	}
+
+	if tolerance := os.Getenv("GATEWAY_SIGNATURE_TOLERANCE"); tolerance != "" {
+		var err error
+		config.SignatureTolerance, err = time.ParseDuration(tolerance)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid GATEWAY_SIGNATURE_TOLERANCE: %w", err)
+		}
+	}
+
+	if publicKey := os.Getenv("JWT_PUBLIC_KEY"); publicKey != "" {
+		var err error
+		config.JWTPublicKey, err = jwt.ParseRSAPublicKeyFromPEM([]byte(publicKey))
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid JWT_PUBLIC_KEY: %w", err)
+		}
+	}
+
+	return config, nil
+}
+
+type APIKeysRepository interface {
+	GetByHash(ctx context.Context, hash string) (entities.APIKey, error)
+}
+
+// Principal is the authenticated caller.
+type Principal struct {
+	// Subject is the ID of the API key, or the operator from the "sub" claim.
+	Subject string
+	Roles   []entities.Role
+	// TenantID is the tenant the caller acts for, the tenant of the API key, or the operator's "tenant_id" claim.
+	TenantID string
+}
+
+// HasAnyRole returns true if the principal has one of the roles. Admins have all roles.
+func (p Principal) HasAnyRole(roles ...entities.Role) bool {
+	for _, role := range p.Roles {
+		if role == entities.RoleAdmin || slices.Contains(roles, role) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type operatorClaims struct {
+	jwt.StandardClaims
+	Roles []entities.Role `json:"roles"`
+	// TenantID is optional, the operators without it act for the default tenant.
+	TenantID string `json:"tenant_id"`
+}
+
+type Authenticator struct {
+	apiKeys APIKeysRepository
+	config  Config
+}
+
+func NewAuthenticator(apiKeys APIKeysRepository, config Config) Authenticator {
+	if apiKeys == nil {
+		panic("missing apiKeys")
+	}
+
+	return Authenticator{
+		apiKeys: apiKeys,
+		config:  config,
+	}
+}
+
+// Require returns the middleware allowing only the callers with one of the roles (or admins).
+// Callers without credentials get 401, and callers without the role get 403.
+// The request context gets the tenant of the caller, see tenant.FromContext.
+func (a Authenticator) Require(roles ...entities.Role) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			principal, err := a.authenticate(c.Request())
+			if err != nil {
+				log.FromContext(c.Request().Context()).WithError(err).Info("Authentication failed")
+
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, "Bearer")
+				return echo.NewHTTPError(http.StatusUnauthorized, "invalid or missing credentials")
+			}
+
+			if !principal.HasAnyRole(roles...) {
+				return echo.NewHTTPError(http.StatusForbidden, "missing required role")
+			}
+
+			c.Set(principalContextKey, principal)
+			c.SetRequest(c.Request().WithContext(tenant.WithID(c.Request().Context(), principal.TenantID)))
+
+			return next(c)
+		}
+	}
+}
+
+// PrincipalFromContext returns the caller authenticated by the Require middleware.
+func PrincipalFromContext(c echo.Context) (Principal, bool) {
+	principal, ok := c.Get(principalContextKey).(Principal)
+	return principal, ok
+}
+
+func (a Authenticator) authenticate(r *http.Request) (Principal, error) {
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		return a.authenticateAPIKey(r.Context(), key)
+	}
+
+	authorization := r.Header.Get(echo.HeaderAuthorization)
+	if token, ok := strings.CutPrefix(authorization, "Bearer "); ok {
+		return a.authenticateJWT(token)
+	}
+
+	return Principal{}, errors.New("missing credentials")
+}
+
+func (a Authenticator) authenticateAPIKey(ctx context.Context, key string) (Principal, error) {
+	apiKey, err := a.apiKeys.GetByHash(ctx, HashAPIKey(key))
+	if errors.Is(err, db.ErrNotFound) {
+		return Principal{}, errors.New("unknown or revoked API key")
+	}
+	if err != nil {
+		return Principal{}, fmt.Errorf("could not get API key: %w", err)
+	}
+
+	return Principal{
+		Subject:  apiKey.ID.String(),
+		Roles:    []entities.Role{apiKey.Role},
+		TenantID: apiKey.TenantID,
+	}, nil
+}
+
+func (a Authenticator) authenticateJWT(token string) (Principal, error) {
+	if a.config.JWTPublicKey == nil {
+		return Principal{}, errors.New("bearer tokens are not configured")
+	}
+
+	var claims operatorClaims
+	_, err := jwt.ParseWithClaims(token, &claims, func(token *jwt.Token) (any, error) {
+		if token.Method != jwt.SigningMethodRS256 {
+			return nil, fmt.Errorf("unexpected signing method %s", token.Header["alg"])
+		}
+
+		return a.config.JWTPublicKey, nil
+	})
+	if err != nil {
+		return Principal{}, fmt.Errorf("invalid token: %w", err)
+	}
+
+	// the standard claims validation accepts tokens without the expiration time
+	if claims.ExpiresAt == 0 {
+		return Principal{}, errors.New("token without expiration time")
+	}
+	if a.config.JWTIssuer != "" && claims.Issuer != a.config.JWTIssuer {
+		return Principal{}, fmt.Errorf("unexpected token issuer %s", claims.Issuer)
+	}
+	if claims.Subject == "" {
+		return Principal{}, errors.New("token without subject")
+	}
+
+	tenantID := tenant.Default
+	if claims.TenantID != "" {
+		if err := tenant.Validate(claims.TenantID); err != nil {
+			return Principal{}, err
+		}
+		tenantID = claims.TenantID
+	}
+
+	return Principal{
+		Subject:  claims.Subject,
+		Roles:    claims.Roles,
+		TenantID: tenantID,
+	}, nil
+}
@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"tickets/webhook"
+	"time"
+
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var signatureVerifications = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "auth",
+	Subsystem: "signature",
+	Name:      "verifications_total",
+	Help:      "Number of verified signatures of the gateway callbacks, by result.",
+}, []string{"result"})
+
+// defaultSignatureTolerance is how old (or how far in the future) the signature timestamp can be.
+const defaultSignatureTolerance = 5 * time.Minute
+
+// maxSignedBodySize limits the body read for the verification, the callbacks are much smaller.
+const maxSignedBodySize = 1 << 20
+
+// VerifySignature returns the middleware verifying the gateway's signature of the callback.
+// The gateway signs the requests like the webhooks are signed (see webhook.Sign), with the
+// Webhook-Timestamp and Webhook-Signature headers. The signature header can have more signatures
+// separated by commas, and every configured secret is tried, so the secrets can be rotated
+// without downtime. Requests with timestamps outside of the tolerance are rejected, so captured
+// requests can't be replayed later. All callbacks are rejected when no secrets are configured.
+func (a Authenticator) VerifySignature() echo.MiddlewareFunc {
+	tolerance := a.config.SignatureTolerance
+	if tolerance == 0 {
+		tolerance = defaultSignatureTolerance
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			result := a.verifySignature(c.Request(), tolerance)
+			signatureVerifications.WithLabelValues(result).Inc()
+
+			if result != "valid" {
+				log.FromContext(c.Request().Context()).WithField("result", result).Info("Rejected callback signature")
+				return echo.NewHTTPError(http.StatusUnauthorized, "invalid signature")
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// verifySignature returns the result of the verification, used as the metric label.
+// The request body is replaced with a copy, so the handler can read it again.
+func (a Authenticator) verifySignature(r *http.Request, tolerance time.Duration) string {
+	if len(a.config.GatewaySecrets) == 0 {
+		return "not_configured"
+	}
+
+	timestamp := r.Header.Get(webhook.HeaderTimestamp)
+	signatures := r.Header.Get(webhook.HeaderSignature)
+	if timestamp == "" || signatures == "" {
+		return "missing"
+	}
+
+	unixTime, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return "invalid"
+	}
+
+	age := time.Since(time.Unix(unixTime, 0))
+	if age > tolerance || age < -tolerance {
+		return "expired"
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxSignedBodySize+1))
+	if err != nil {
+		return "invalid"
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	if len(body) > maxSignedBodySize {
+		return "invalid"
+	}
+
+	for _, signature := range strings.Split(signatures, ",") {
+		for _, secret := range a.config.GatewaySecrets {
+			if webhook.VerifySignature(secret, timestamp, body, strings.TrimSpace(signature)) {
+				return "valid"
+			}
+		}
+	}
+
+	return "invalid"
+}
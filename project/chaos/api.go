@@ -0,0 +1,105 @@
+package chaos
+
+import (
+	"context"
+	"tickets/entities"
+	"tickets/message/event"
+	"tickets/sheetsync"
+)
+
+// The wrappers below inject faults into the calls of the external APIs.
+// Duplicated calls and lost responses are only survived by idempotent APIs (or idempotent handlers calling them).
+
+type SpreadsheetsAPI struct {
+	chaos *Chaos
+	api   sheetsync.SpreadsheetsAPI
+}
+
+func NewSpreadsheetsAPI(chaos *Chaos, api sheetsync.SpreadsheetsAPI) SpreadsheetsAPI {
+	if api == nil {
+		panic("missing api")
+	}
+
+	return SpreadsheetsAPI{chaos: chaos, api: api}
+}
+
+func (s SpreadsheetsAPI) AppendRow(ctx context.Context, sheetName string, row []string) error {
+	return s.chaos.call(ctx, func() error {
+		return s.api.AppendRow(ctx, sheetName, row)
+	})
+}
+
+func (s SpreadsheetsAPI) GetRows(ctx context.Context, sheetName string) ([][]string, error) {
+	var rows [][]string
+
+	err := s.chaos.call(ctx, func() error {
+		var err error
+		rows, err = s.api.GetRows(ctx, sheetName)
+		return err
+	})
+
+	return rows, err
+}
+
+type ReceiptsService struct {
+	chaos *Chaos
+	api   event.ReceiptsService
+}
+
+func NewReceiptsService(chaos *Chaos, api event.ReceiptsService) ReceiptsService {
+	if api == nil {
+		panic("missing api")
+	}
+
+	return ReceiptsService{chaos: chaos, api: api}
+}
+
+func (r ReceiptsService) IssueReceipt(ctx context.Context, request entities.IssueReceiptRequest) (entities.IssueReceiptResponse, error) {
+	var resp entities.IssueReceiptResponse
+
+	err := r.chaos.call(ctx, func() error {
+		var err error
+		resp, err = r.api.IssueReceipt(ctx, request)
+		return err
+	})
+
+	return resp, err
+}
+
+type FileAPI struct {
+	chaos *Chaos
+	api   event.FileAPI
+}
+
+func NewFileAPI(chaos *Chaos, api event.FileAPI) FileAPI {
+	if api == nil {
+		panic("missing api")
+	}
+
+	return FileAPI{chaos: chaos, api: api}
+}
+
+func (f FileAPI) UploadFile(ctx context.Context, fileID string, fileContent string) error {
+	return f.chaos.call(ctx, func() error {
+		return f.api.UploadFile(ctx, fileID, fileContent)
+	})
+}
+
+type DeadNationAPI struct {
+	chaos *Chaos
+	api   event.DeadNationAPI
+}
+
+func NewDeadNationAPI(chaos *Chaos, api event.DeadNationAPI) DeadNationAPI {
+	if api == nil {
+		panic("missing api")
+	}
+
+	return DeadNationAPI{chaos: chaos, api: api}
+}
+
+func (d DeadNationAPI) BookInDeadNation(ctx context.Context, request entities.DeadNationBooking) error {
+	return d.chaos.call(ctx, func() error {
+		return d.api.BookInDeadNation(ctx, request)
+	})
+}
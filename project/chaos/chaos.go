@@ -0,0 +1,209 @@
+// Package chaos injects faults into message handling, publishing and outgoing API calls.
+// It's meant for checking that at-least-once delivery and idempotency hold under failures,
+// and should never be enabled in production.
+package chaos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var ErrInjected = errors.New("chaos: injected failure")
+
+// Config describes which faults are injected. Rates are probabilities between 0 and 1.
+// The zero value disables chaos.
+type Config struct {
+	// Seed makes the injected faults reproducible: the same seed gives the same sequence of decisions.
+	Seed int64
+
+	// FailureRate fails the handler or the API call before it's executed.
+	FailureRate float64
+	// LostAckRate fails the handler or the API call after it was executed, as if the response got lost.
+	LostAckRate float64
+	// DelayRate delays the handler, the API call or the publish by up to MaxDelay.
+	DelayRate float64
+	MaxDelay  time.Duration
+	// DuplicateRate handles, calls or publishes twice.
+	DuplicateRate float64
+	// ReorderRate holds back a published message for up to MaxDelay, so messages published later overtake it.
+	ReorderRate float64
+}
+
+func (c Config) Enabled() bool {
+	return c.FailureRate > 0 || c.LostAckRate > 0 || c.DelayRate > 0 || c.DuplicateRate > 0 || c.ReorderRate > 0
+}
+
+func (c Config) Validate() error {
+	rates := map[string]float64{
+		"FailureRate":   c.FailureRate,
+		"LostAckRate":   c.LostAckRate,
+		"DelayRate":     c.DelayRate,
+		"DuplicateRate": c.DuplicateRate,
+		"ReorderRate":   c.ReorderRate,
+	}
+	for name, rate := range rates {
+		if rate < 0 || rate > 1 {
+			return fmt.Errorf("chaos %s must be between 0 and 1, got %v", name, rate)
+		}
+	}
+
+	if c.MaxDelay < 0 {
+		return fmt.Errorf("chaos MaxDelay must not be negative, got %s", c.MaxDelay)
+	}
+	if (c.DelayRate > 0 || c.ReorderRate > 0) && c.MaxDelay == 0 {
+		return errors.New("chaos MaxDelay is required when DelayRate or ReorderRate is set")
+	}
+
+	return nil
+}
+
+// ConfigFromEnv reads the config from the CHAOS_* environment variables.
+// When none of them is set, chaos is disabled.
+func ConfigFromEnv() (Config, error) {
+	var config Config
+	var err error
+
+	if config.Seed, err = int64FromEnv("CHAOS_SEED"); err != nil {
+		return Config{}, err
+	}
+	if config.FailureRate, err = floatFromEnv("CHAOS_FAILURE_RATE"); err != nil {
+		return Config{}, err
+	}
+	if config.LostAckRate, err = floatFromEnv("CHAOS_LOST_ACK_RATE"); err != nil {
+		return Config{}, err
+	}
+	if config.DelayRate, err = floatFromEnv("CHAOS_DELAY_RATE"); err != nil {
+		return Config{}, err
+	}
+	if config.DuplicateRate, err = floatFromEnv("CHAOS_DUPLICATE_RATE"); err != nil {
+		return Config{}, err
+	}
+	if config.ReorderRate, err = floatFromEnv("CHAOS_REORDER_RATE"); err != nil {
+		return Config{}, err
+	}
+
+	if maxDelay := os.Getenv("CHAOS_MAX_DELAY"); maxDelay != "" {
+		config.MaxDelay, err = time.ParseDuration(maxDelay)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid CHAOS_MAX_DELAY: %w", err)
+		}
+	}
+
+	return config, config.Validate()
+}
+
+func int64FromEnv(key string) (int64, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return 0, nil
+	}
+
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+
+	return parsed, nil
+}
+
+func floatFromEnv(key string) (float64, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return 0, nil
+	}
+
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+
+	return parsed, nil
+}
+
+// Chaos decides which faults to inject. It's safe for concurrent use.
+// A nil *Chaos doesn't inject anything, so it can be passed around when chaos is disabled.
+type Chaos struct {
+	config Config
+
+	lock sync.Mutex
+	rand *rand.Rand
+}
+
+func New(config Config) (*Chaos, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &Chaos{
+		config: config,
+		rand:   rand.New(rand.NewSource(config.Seed)),
+	}, nil
+}
+
+func (c *Chaos) roll(rate float64) bool {
+	if c == nil || rate <= 0 {
+		return false
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.rand.Float64() < rate
+}
+
+func (c *Chaos) randomDelay() time.Duration {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return time.Duration(c.rand.Int63n(int64(c.config.MaxDelay) + 1))
+}
+
+func (c *Chaos) maybeDelay(ctx context.Context) error {
+	if !c.roll(c.config.DelayRate) {
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(c.randomDelay()):
+		return nil
+	}
+}
+
+// call executes fn with the configured faults: delays, failures before and after the call, and duplicated calls.
+func (c *Chaos) call(ctx context.Context, fn func() error) error {
+	if c == nil {
+		return fn()
+	}
+
+	if err := c.maybeDelay(ctx); err != nil {
+		return err
+	}
+
+	if c.roll(c.config.FailureRate) {
+		return ErrInjected
+	}
+
+	if err := fn(); err != nil {
+		return err
+	}
+
+	if c.roll(c.config.DuplicateRate) {
+		if err := fn(); err != nil {
+			return err
+		}
+	}
+
+	if c.roll(c.config.LostAckRate) {
+		return ErrInjected
+	}
+
+	return nil
+}
@@ -0,0 +1,157 @@
+package chaos_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"tickets/chaos"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	assert.NoError(t, chaos.Config{}.Validate())
+	assert.NoError(t, chaos.Config{FailureRate: 0.5, DelayRate: 0.1, MaxDelay: time.Millisecond}.Validate())
+
+	assert.Error(t, chaos.Config{FailureRate: 1.5}.Validate())
+	assert.Error(t, chaos.Config{DuplicateRate: -0.1}.Validate())
+	assert.Error(t, chaos.Config{DelayRate: 0.1}.Validate())
+	assert.Error(t, chaos.Config{ReorderRate: 0.1}.Validate())
+}
+
+func TestChaos_same_seed_injects_same_faults(t *testing.T) {
+	config := chaos.Config{
+		Seed:          42,
+		FailureRate:   0.3,
+		LostAckRate:   0.3,
+		DuplicateRate: 0.3,
+	}
+
+	run := func() []int {
+		c, err := chaos.New(config)
+		require.NoError(t, err)
+
+		var calls []int
+		for i := 0; i < 50; i++ {
+			api := &spreadsheetsAPIStub{}
+			_ = chaos.NewSpreadsheetsAPI(c, api).AppendRow(context.Background(), "sheet", nil)
+			calls = append(calls, api.calls)
+		}
+
+		return calls
+	}
+
+	first := run()
+	assert.Equal(t, first, run())
+	assert.Contains(t, first, 0, "expected some calls to fail before reaching the API")
+	assert.Contains(t, first, 2, "expected some calls to be duplicated")
+}
+
+func TestChaos_nil_injects_nothing(t *testing.T) {
+	var c *chaos.Chaos
+
+	api := &spreadsheetsAPIStub{}
+	require.NoError(t, chaos.NewSpreadsheetsAPI(c, api).AppendRow(context.Background(), "sheet", nil))
+	assert.Equal(t, 1, api.calls)
+
+	handled := 0
+	_, err := c.Middleware(func(msg *message.Message) ([]*message.Message, error) {
+		handled++
+		return nil, nil
+	})(message.NewMessage(watermill.NewUUID(), nil))
+	require.NoError(t, err)
+	assert.Equal(t, 1, handled)
+}
+
+func TestChaos_Middleware_fails_with_injected_error(t *testing.T) {
+	c, err := chaos.New(chaos.Config{FailureRate: 1})
+	require.NoError(t, err)
+
+	handled := 0
+	_, err = c.Middleware(func(msg *message.Message) ([]*message.Message, error) {
+		handled++
+		return nil, nil
+	})(message.NewMessage(watermill.NewUUID(), nil))
+
+	assert.True(t, errors.Is(err, chaos.ErrInjected))
+	assert.Equal(t, 0, handled)
+}
+
+func TestPublisher_held_back_messages_are_published(t *testing.T) {
+	c, err := chaos.New(chaos.Config{ReorderRate: 1, MaxDelay: time.Millisecond * 10})
+	require.NoError(t, err)
+
+	pubSub := &publisherStub{}
+	publisher := chaos.NewPublisher(c, pubSub, nil)
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, publisher.Publish("topic", message.NewMessage(watermill.NewUUID(), nil)))
+	}
+
+	require.NoError(t, publisher.Close())
+	assert.Equal(t, 10, pubSub.published)
+}
+
+func TestPublisher_Close_gives_up_on_held_back_messages(t *testing.T) {
+	c, err := chaos.New(chaos.Config{ReorderRate: 1, MaxDelay: time.Millisecond * 10})
+	require.NoError(t, err)
+
+	pubSub := &publisherStub{err: errors.New("unavailable")}
+	publisher := chaos.NewPublisher(c, pubSub, nil)
+
+	require.NoError(t, publisher.Publish("topic", message.NewMessage(watermill.NewUUID(), nil)))
+
+	closed := make(chan error)
+	go func() {
+		closed <- publisher.Close()
+	}()
+
+	select {
+	case err := <-closed:
+		require.NoError(t, err)
+	case <-time.After(time.Second * 5):
+		t.Fatal("Close didn't return")
+	}
+	assert.Equal(t, 0, pubSub.published)
+}
+
+type spreadsheetsAPIStub struct {
+	calls int
+}
+
+func (s *spreadsheetsAPIStub) AppendRow(ctx context.Context, sheetName string, row []string) error {
+	s.calls++
+	return nil
+}
+
+func (s *spreadsheetsAPIStub) GetRows(ctx context.Context, sheetName string) ([][]string, error) {
+	s.calls++
+	return nil, nil
+}
+
+type publisherStub struct {
+	lock      sync.Mutex
+	published int
+	err       error
+}
+
+func (p *publisherStub) Publish(topic string, messages ...*message.Message) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.err != nil {
+		return p.err
+	}
+
+	p.published += len(messages)
+	return nil
+}
+
+func (p *publisherStub) Close() error {
+	return nil
+}
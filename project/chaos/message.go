@@ -0,0 +1,159 @@
+package chaos
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+// Middleware is a Watermill router middleware failing, delaying and duplicating message handling.
+// It should be added after the retry middleware, so the injected failures are retried.
+func (c *Chaos) Middleware(h message.HandlerFunc) message.HandlerFunc {
+	if c == nil {
+		return h
+	}
+
+	return func(msg *message.Message) ([]*message.Message, error) {
+		var produced []*message.Message
+
+		err := c.call(msg.Context(), func() error {
+			// the duplicate gets a copy, as handlers may modify the message
+			handledMsg := msg.Copy()
+			handledMsg.SetContext(msg.Context())
+
+			msgs, err := h(handledMsg)
+			if err != nil {
+				return err
+			}
+
+			produced = append(produced, msgs...)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return produced, nil
+	}
+}
+
+// Publisher delays, duplicates and reorders published messages.
+type Publisher struct {
+	chaos     *Chaos
+	publisher message.Publisher
+	logger    watermill.LoggerAdapter
+
+	heldBack  sync.WaitGroup
+	closing   chan struct{}
+	closeOnce sync.Once
+}
+
+// closeTimeout is how long Close waits for the held back messages.
+const closeTimeout = time.Second * 10
+
+func NewPublisher(chaos *Chaos, publisher message.Publisher, logger watermill.LoggerAdapter) *Publisher {
+	if publisher == nil {
+		panic("missing publisher")
+	}
+	if logger == nil {
+		logger = watermill.NopLogger{}
+	}
+
+	return &Publisher{
+		chaos:     chaos,
+		publisher: publisher,
+		logger:    logger,
+		closing:   make(chan struct{}),
+	}
+}
+
+func (p *Publisher) Publish(topic string, messages ...*message.Message) error {
+	if p.chaos == nil {
+		return p.publisher.Publish(topic, messages...)
+	}
+
+	for _, msg := range messages {
+		if p.chaos.roll(p.chaos.config.ReorderRate) {
+			p.holdBack(topic, msg)
+			continue
+		}
+
+		err := p.chaos.call(msg.Context(), func() error {
+			return p.publisher.Publish(topic, msg.Copy())
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// holdBack publishes the message in the background after a random delay.
+// A failed publish is retried until the message's context is done or the publisher is closed.
+func (p *Publisher) holdBack(topic string, msg *message.Message) {
+	delay := p.chaos.randomDelay()
+
+	p.heldBack.Add(1)
+	go func() {
+		defer p.heldBack.Done()
+
+		logFields := watermill.LogFields{
+			"message_uuid": msg.UUID,
+			"topic":        topic,
+		}
+
+		for {
+			select {
+			case <-time.After(delay):
+			case <-p.closing:
+			case <-msg.Context().Done():
+			}
+
+			err := p.publisher.Publish(topic, msg)
+			if err == nil {
+				return
+			}
+
+			select {
+			case <-p.closing:
+				p.logger.Error("Failed to publish held back message, giving up", err, logFields)
+				return
+			case <-msg.Context().Done():
+				p.logger.Error("Failed to publish held back message, giving up", err, logFields)
+				return
+			default:
+			}
+
+			p.logger.Error("Failed to publish held back message, retrying", err, logFields)
+
+			delay = min(delay*2+time.Millisecond*100, time.Second)
+		}
+	}()
+}
+
+// Close publishes the held back messages right away, waits up to closeTimeout for them,
+// and closes the decorated publisher.
+func (p *Publisher) Close() error {
+	p.closeOnce.Do(func() {
+		close(p.closing)
+	})
+
+	published := make(chan struct{})
+	go func() {
+		p.heldBack.Wait()
+		close(published)
+	}()
+
+	var err error
+	select {
+	case <-published:
+	case <-time.After(closeTimeout):
+		err = errors.New("timed out waiting for held back messages")
+	}
+
+	return errors.Join(err, p.publisher.Close())
+}
@@ -0,0 +1,123 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"tickets/entities"
+	"tickets/tenant"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type APIKeysRepository struct {
+	db *sqlx.DB
+}
+
+func NewAPIKeysRepository(db *sqlx.DB) APIKeysRepository {
+	if db == nil {
+		panic("db is nil")
+	}
+
+	return APIKeysRepository{db: db}
+}
+
+const apiKeyColumns = `
+	id,
+	name,
+	role,
+	tenant_id,
+	prefix,
+	key_hash,
+	created_at,
+	revoked_at`
+
+func (a APIKeysRepository) Add(ctx context.Context, apiKey entities.APIKey) error {
+	err := inTenantTx(ctx, a.db, nil, func(tx *sqlx.Tx, tenantID string) error {
+		_, err := tx.NamedExecContext(
+			ctx,
+			`
+			INSERT INTO
+				api_keys (id, name, role, tenant_id, prefix, key_hash, created_at, revoked_at)
+			VALUES
+				(:id, :name, :role, :tenant_id, :prefix, :key_hash, :created_at, :revoked_at)`,
+			apiKey,
+		)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, tx, tenantID)
+	})
+	if err != nil {
+		return fmt.Errorf("could not save API key: %w", err)
+	}
+
+	return nil
+}
+
+// GetByHash returns the key with the hash unless it's revoked, in all tenants, as the key tells the tenant.
+func (a APIKeysRepository) GetByHash(ctx context.Context, hash string) (entities.APIKey, error) {
+	var apiKey entities.APIKey
+
+	err := a.db.GetContext(
+		ctx,
+		&apiKey,
+		`SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = $1 AND revoked_at IS NULL`,
+		hash,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entities.APIKey{}, ErrNotFound
+	}
+	if err != nil {
+		return entities.APIKey{}, fmt.Errorf("could not get API key: %w", err)
+	}
+
+	return apiKey, nil
+}
+
+// List returns the API keys of the tenant from the context (including the revoked ones), from the newest.
+func (a APIKeysRepository) List(ctx context.Context) ([]entities.APIKey, error) {
+	apiKeys := []entities.APIKey{}
+
+	err := a.db.SelectContext(
+		ctx,
+		&apiKeys,
+		`SELECT `+apiKeyColumns+` FROM api_keys WHERE tenant_id = $1 ORDER BY created_at DESC`,
+		tenant.FromContext(ctx),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not list API keys: %w", err)
+	}
+
+	return apiKeys, nil
+}
+
+// Revoke revokes the API key of the tenant from the context, revoking an already revoked key does nothing.
+func (a APIKeysRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	return inTenantTx(ctx, a.db, nil, func(tx *sqlx.Tx, tenantID string) error {
+		res, err := tx.ExecContext(
+			ctx,
+			`UPDATE api_keys SET revoked_at = COALESCE(revoked_at, $2) WHERE id = $1 AND tenant_id = $3`,
+			id,
+			time.Now().UTC(),
+			tenantID,
+		)
+		if err != nil {
+			return fmt.Errorf("could not revoke API key: %w", err)
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("could not get affected rows: %w", err)
+		}
+		if affected == 0 {
+			return fmt.Errorf("API key %s: %w", id, ErrNotFound)
+		}
+
+		return recordAudit(ctx, tx, tenantID)
+	})
+}
@@ -0,0 +1,153 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"tickets/audit"
+	"tickets/entities"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// AuditRepository reads the audit log, the audit_records table is append-only.
+type AuditRepository struct {
+	db *sqlx.DB
+}
+
+func NewAuditRepository(db *sqlx.DB) AuditRepository {
+	if db == nil {
+		panic("db is nil")
+	}
+
+	return AuditRepository{db: db}
+}
+
+type auditRecord struct {
+	ID            uuid.UUID      `db:"id"`
+	OccurredAt    time.Time      `db:"occurred_at"`
+	TenantID      string         `db:"tenant_id"`
+	Actor         string         `db:"actor"`
+	Action        string         `db:"action"`
+	TargetIDs     pq.StringArray `db:"target_ids"`
+	CorrelationID string         `db:"correlation_id"`
+	Summary       string         `db:"summary"`
+}
+
+func (a auditRecord) toEntity() entities.AuditRecord {
+	return entities.AuditRecord{
+		ID:            a.ID,
+		OccurredAt:    a.OccurredAt,
+		TenantID:      a.TenantID,
+		Actor:         a.Actor,
+		Action:        a.Action,
+		TargetIDs:     a.TargetIDs,
+		CorrelationID: a.CorrelationID,
+		Summary:       a.Summary,
+	}
+}
+
+// Find returns the records matching the filter, newest first.
+func (a AuditRepository) Find(ctx context.Context, filter entities.AuditRecordsFilter) ([]entities.AuditRecord, error) {
+	var rows []auditRecord
+
+	err := inTenantTx(ctx, a.db, &sql.TxOptions{ReadOnly: true}, func(tx *sqlx.Tx, tenantID string) error {
+		query, args := auditRecordsQuery(tenantID, filter)
+		return tx.SelectContext(ctx, &rows, query, args...)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not find audit records: %w", err)
+	}
+
+	records := make([]entities.AuditRecord, 0, len(rows))
+	for _, row := range rows {
+		records = append(records, row.toEntity())
+	}
+
+	return records, nil
+}
+
+func auditRecordsQuery(tenantID string, filter entities.AuditRecordsFilter) (string, []any) {
+	var conditions []string
+	var args []any
+
+	addArg := func(arg any) string {
+		args = append(args, arg)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	conditions = append(conditions, "tenant_id = "+addArg(tenantID))
+
+	if filter.Actor != "" {
+		conditions = append(conditions, "actor = "+addArg(filter.Actor))
+	}
+	if filter.Action != "" {
+		conditions = append(conditions, "action = "+addArg(filter.Action))
+	}
+	if filter.TargetID != "" {
+		// the containment is checked with the GIN index of the target IDs
+		conditions = append(conditions, "target_ids @> "+addArg(pq.StringArray{filter.TargetID}))
+	}
+	if !filter.From.IsZero() {
+		conditions = append(conditions, "occurred_at >= "+addArg(filter.From))
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, "occurred_at < "+addArg(filter.To))
+	}
+
+	query := `
+		SELECT
+			id, occurred_at, tenant_id, actor, action, target_ids, correlation_id, summary
+		FROM
+			audit_records
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY occurred_at DESC, id`
+
+	if filter.Limit > 0 {
+		query += " LIMIT " + addArg(filter.Limit)
+	}
+
+	return query, args
+}
+
+// recordAudit saves the audit record of the request from the context (see audit.Take), if there's one.
+func recordAudit(ctx context.Context, tx *sqlx.Tx, tenantID string) error {
+	record, ok := audit.Take(ctx)
+	if !ok {
+		return nil
+	}
+	record.TenantID = tenantID
+
+	if err := insertAuditRecord(ctx, tx, record); err != nil {
+		return fmt.Errorf("could not save audit record: %w", err)
+	}
+
+	return nil
+}
+
+func insertAuditRecord(ctx context.Context, tx *sqlx.Tx, record entities.AuditRecord) error {
+	_, err := tx.NamedExecContext(
+		ctx,
+		`
+		INSERT INTO
+			audit_records (id, occurred_at, tenant_id, actor, action, target_ids, correlation_id, summary)
+		VALUES
+			(:id, :occurred_at, :tenant_id, :actor, :action, :target_ids, :correlation_id, :summary)
+		ON CONFLICT DO NOTHING`,
+		auditRecord{
+			ID:            record.ID,
+			OccurredAt:    record.OccurredAt,
+			TenantID:      record.TenantID,
+			Actor:         record.Actor,
+			Action:        record.Action,
+			TargetIDs:     record.TargetIDs,
+			CorrelationID: record.CorrelationID,
+			Summary:       record.Summary,
+		},
+	)
+	return err
+}
@@ -0,0 +1,65 @@
+//go:build integration
+
+package db_test
+
+import (
+	"context"
+	"testing"
+	"tickets/audit"
+	ticketsDb "tickets/db"
+	"tickets/entities"
+	"tickets/tenant"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditRepository(t *testing.T) {
+	sqlxDb := GetDb()
+
+	err := ticketsDb.InitializeDatabaseSchema(sqlxDb)
+	require.NoError(t, err)
+
+	showsRepo := ticketsDb.NewShowsRepository(sqlxDb)
+	auditRepo := ticketsDb.NewAuditRepository(sqlxDb)
+
+	// every test run has its own tenant, so the records of the previous runs are not found
+	ctx := tenant.WithID(context.Background(), "audit-"+uuid.NewString()[:8])
+
+	show := entities.Show{
+		ID:              uuid.New(),
+		DeadNationID:    uuid.New(),
+		NumberOfTickets: 10,
+		StartTime:       time.Now().Add(time.Hour).UTC(),
+		Title:           "Audited show",
+		Venue:           "Audited venue",
+	}
+
+	entry := audit.NewEntry("operator@example.com", "PostShows", uuid.NewString(), `{"title":"Audited show"}`)
+	entry.AddTargets(show.ID.String())
+
+	err = showsRepo.Add(audit.WithEntry(ctx, entry), show)
+	require.NoError(t, err)
+
+	_, pending := entry.Take()
+	assert.False(t, pending, "the record should be saved with the show")
+
+	records, err := auditRepo.Find(ctx, entities.AuditRecordsFilter{TargetID: show.ID.String()})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "PostShows", records[0].Action)
+	assert.Equal(t, "operator@example.com", records[0].Actor)
+	assert.Equal(t, []string{show.ID.String()}, records[0].TargetIDs)
+	recordID := records[0].ID
+
+	records, err = auditRepo.Find(context.Background(), entities.AuditRecordsFilter{TargetID: show.ID.String()})
+	require.NoError(t, err)
+	assert.Empty(t, records, "the records of other tenants should not be found")
+
+	_, err = sqlxDb.Exec(`DELETE FROM audit_records WHERE id = $1`, recordID)
+	assert.Error(t, err, "the audit records should be append-only")
+	_, err = sqlxDb.Exec(`UPDATE audit_records SET actor = 'someone-else' WHERE id = $1`, recordID)
+	assert.Error(t, err, "the audit records should be append-only")
+}
@@ -9,27 +9,32 @@ import (
 	"tickets/message/event"
 	"tickets/message/outbox"
 
+	"github.com/ThreeDotsLabs/watermill/components/cqrs"
 	"github.com/jmoiron/sqlx"
 )
 
 type BookingsRepository struct {
-	db *sqlx.DB
+	db             *sqlx.DB
+	eventMarshaler cqrs.CommandEventMarshaler
 }
 
-func NewBookingsRepository(db *sqlx.DB) BookingsRepository {
+func NewBookingsRepository(db *sqlx.DB, eventMarshaler cqrs.CommandEventMarshaler) BookingsRepository {
 	if db == nil {
 		panic("db is nil")
 	}
+	if eventMarshaler == nil {
+		panic("eventMarshaler is nil")
+	}
 	
-	return BookingsRepository{db: db}
+	return BookingsRepository{db: db, eventMarshaler: eventMarshaler}
 }
 
 func (b BookingsRepository) Add(ctx context.Context, booking entities.Booking) (err error) {
-	tx, err := b.db.BeginTxx(ctx, &sql.TxOptions{
+	tx, tenantID, err := beginTenantTx(ctx, b.db, &sql.TxOptions{
 		Isolation: sql.LevelSerializable,
 	})
 	if err != nil {
-		return fmt.Errorf("could not begin transaction: %w", err)
+		return err
 	}
 
 	defer func() {
@@ -51,10 +56,14 @@ func (b BookingsRepository) Add(ctx context.Context, booking entities.Booking) (
 			FROM
 				shows
 			WHERE
-				id = $1
+				id = $1 AND tenant_id = $2
 		`,
 		booking.ShowID,
+		tenantID,
 	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("could not get available seats: show %s: %w", booking.ShowID, ErrNotFound)
+	}
 	if err != nil {
 		return fmt.Errorf("could not get available seats: %w", err)
 	}
@@ -71,9 +80,10 @@ func (b BookingsRepository) Add(ctx context.Context, booking entities.Booking) (
 			FROM
 				bookings
 			WHERE
-				show_id = $1
+				show_id = $1 AND tenant_id = $2
 	`,
-	booking.ShowID)
+	booking.ShowID,
+	tenantID)
 	if err != nil {
 		return fmt.Errorf("could not get already booked seats: %w", err)
 	}
@@ -81,14 +91,16 @@ func (b BookingsRepository) Add(ctx context.Context, booking entities.Booking) (
 	if availableSeats - alreadyBookedSeats < booking.NumberOfTickets {
 		return ErrExceedingTicketLimit
 	}
+
+	booking.TenantID = tenantID
 		
 	_, err = tx.NamedExecContext(
 		ctx,
 		`
 		INSERT INTO 
-			bookings (id, show_id, number_of_tickets, customer_email)
+			bookings (id, show_id, number_of_tickets, customer_email, tenant_id)
 		VALUES
-			(:id, :show_id, :number_of_tickets, :customer_email)
+			(:id, :show_id, :number_of_tickets, :customer_email, :tenant_id)
 		ON CONFLICT DO NOTHING`,
 		booking,
 	)
@@ -101,11 +113,12 @@ func (b BookingsRepository) Add(ctx context.Context, booking entities.Booking) (
 		return fmt.Errorf("could not create SQL publisher: %w", err)
 	}
 
-	bus, err := event.NewEventBus(outboxPublisher)
+	bus, err := event.NewEventBus(outboxPublisher, b.eventMarshaler)
 	if err != nil {
 		return fmt.Errorf("could not create event bus: %w", err)
 	}
 	err = bus.Publish(ctx, entities.BookingMade{
+		Header: entities.NewEventHeader().WithTenant(tenantID),
 		BookingID: booking.ID,
 		NumberOfTickets: booking.NumberOfTickets,
 		CustomerEmail: booking.CustomerEmail,
@@ -115,5 +128,71 @@ func (b BookingsRepository) Add(ctx context.Context, booking entities.Booking) (
 		return fmt.Errorf("could not publish event: %w", err)
 	}
 
+	return recordAudit(ctx, tx, tenantID)
+}
+
+// PublishTicketStatusChanges publishes the ticket confirmations and cancellations with their versions through the outbox.
+func (b BookingsRepository) PublishTicketStatusChanges(ctx context.Context, events []any) error {
+	err := inTenantTx(ctx, b.db, nil, func(tx *sqlx.Tx, tenantID string) error {
+		outboxPublisher, err := outbox.NewPublisherForDb(ctx, tx)
+		if err != nil {
+			return fmt.Errorf("could not create SQL publisher: %w", err)
+		}
+
+		bus, err := event.NewEventBus(outboxPublisher, b.eventMarshaler)
+		if err != nil {
+			return fmt.Errorf("could not create event bus: %w", err)
+		}
+
+		for _, e := range events {
+			switch e := e.(type) {
+			case entities.TicketBookingConfirmed:
+				e.Version, err = nextTicketVersion(ctx, tx, tenantID, e.TicketID)
+				if err == nil {
+					err = bus.Publish(ctx, e)
+				}
+			case entities.TicketBookingCanceled:
+				e.Version, err = nextTicketVersion(ctx, tx, tenantID, e.TicketID)
+				if err == nil {
+					err = bus.Publish(ctx, e)
+				}
+			default:
+				err = fmt.Errorf("unsupported event %T", e)
+			}
+			if err != nil {
+				return fmt.Errorf("could not publish event: %w", err)
+			}
+		}
+
+		return recordAudit(ctx, tx, tenantID)
+	})
+	if err != nil {
+		return fmt.Errorf("could not publish ticket status changes: %w", err)
+	}
+
 	return nil
 }
+
+// nextTicketVersion returns the next version of the ticket, continuing from the version stored with it.
+func nextTicketVersion(ctx context.Context, tx *sqlx.Tx, tenantID string, ticketID string) (int64, error) {
+	var version int64
+
+	err := tx.GetContext(
+		ctx,
+		&version,
+		`
+		INSERT INTO
+			ticket_versions (tenant_id, ticket_id, version)
+		VALUES
+			($1, $2, COALESCE((SELECT version FROM tickets WHERE ticket_id = $2 AND tenant_id = $1), 0) + 1)
+		ON CONFLICT (tenant_id, ticket_id) DO UPDATE SET version = ticket_versions.version + 1
+		RETURNING version`,
+		tenantID,
+		ticketID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("could not get next version of ticket %s: %w", ticketID, err)
+	}
+
+	return version, nil
+}
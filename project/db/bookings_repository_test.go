@@ -1,3 +1,5 @@
+//go:build integration
+
 package db_test
 
 import (
@@ -5,8 +7,11 @@ import (
 	"net/http"
 	"sync"
 	"testing"
+	"tickets/audit"
 	ticketsDb "tickets/db"
 	"tickets/entities"
+	"tickets/message/event"
+	"tickets/tenant"
 	"time"
 
 	"github.com/google/uuid"
@@ -22,7 +27,7 @@ func TestBookingsRepository_AddBooking_seats_limit(t *testing.T) {
 	err := ticketsDb.InitializeDatabaseSchema(db)
 	require.NoError(t, err)
 
-	bookingsRepo := ticketsDb.NewBookingsRepository(db)
+	bookingsRepo := ticketsDb.NewBookingsRepository(db, event.NewMarshaler(event.FormatJSON))
 	showsRepo := ticketsDb.NewShowsRepository(db)
 
 	t.Run("overbooking", func(t *testing.T) {
@@ -129,3 +134,82 @@ func requireNotEnoughSeatsError(t *testing.T, err error) {
 	require.Equal(t, "not enough seats available", echoErr.Message)
 }
 
+
+func TestBookingsRepository_PublishTicketStatusChanges(t *testing.T) {
+	db := GetDb()
+
+	err := ticketsDb.InitializeDatabaseSchema(db)
+	require.NoError(t, err)
+
+	bookingsRepo := ticketsDb.NewBookingsRepository(db, event.NewMarshaler(event.FormatJSON))
+	ticketsRepo := ticketsDb.NewTicketsRepository(db)
+	auditRepo := ticketsDb.NewAuditRepository(db)
+
+	ctx := tenant.WithID(context.Background(), "tickets-"+uuid.NewString()[:8])
+
+	ticketID := uuid.NewString()
+	confirmed := entities.TicketBookingConfirmed{
+		Header:        entities.NewEventHeader(),
+		TicketID:      ticketID,
+		CustomerEmail: "customer@example.com",
+		Price:         entities.Money{Amount: "10.00", Currency: "EUR"},
+	}
+	canceled := entities.TicketBookingCanceled{
+		Header:   entities.NewEventHeader(),
+		TicketID: ticketID,
+	}
+
+	entry := audit.NewEntry("gateway", "PostTicketsStatus", uuid.NewString(), "")
+	entry.AddTargets(ticketID)
+
+	err = bookingsRepo.PublishTicketStatusChanges(audit.WithEntry(ctx, entry), []any{confirmed, canceled})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 2}, publishedVersions(t, ticketID))
+
+	records, err := auditRepo.Find(ctx, entities.AuditRecordsFilter{TargetID: ticketID})
+	require.NoError(t, err)
+	require.Len(t, records, 1, "the record should be saved with the events")
+
+	// the versions of the tickets stored before they were counted continue from the publish time of their last event
+	storedTicketID := uuid.NewString()
+	err = ticketsRepo.Add(ctx, entities.Ticket{
+		TicketID:      storedTicketID,
+		Price:         entities.Money{Amount: "10.00", Currency: "EUR"},
+		CustomerEmail: "customer@example.com",
+		Version:       1700000000000000000,
+	})
+	require.NoError(t, err)
+
+	confirmed.TicketID = storedTicketID
+	err = bookingsRepo.PublishTicketStatusChanges(ctx, []any{confirmed})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1700000000000000001}, publishedVersions(t, storedTicketID))
+
+	// nothing is published when one of the events can't be
+	failedTicketID := uuid.NewString()
+	confirmed.TicketID = failedTicketID
+	err = bookingsRepo.PublishTicketStatusChanges(ctx, []any{confirmed, entities.TicketPrinted{TicketID: failedTicketID}})
+	require.Error(t, err)
+	assert.Empty(t, publishedVersions(t, failedTicketID))
+}
+
+// publishedVersions returns the versions of the ticket's events in the outbox, in the order they were published.
+func publishedVersions(t *testing.T, ticketID string) []int64 {
+	var versions []int64
+	err := GetDb().Select(
+		&versions,
+		`
+		SELECT
+			(convert_from(decode(payload->>'payload', 'base64'), 'UTF8')::jsonb->>'version')::bigint
+		FROM
+			watermill_events_to_forward
+		WHERE
+			payload->'metadata'->>'ordering_key' = $1
+		ORDER BY
+			"offset"`,
+		ticketID,
+	)
+	require.NoError(t, err)
+
+	return versions
+}
@@ -0,0 +1,309 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"tickets/audit"
+	"tickets/entities"
+	"tickets/message/event"
+	"tickets/message/outbox"
+
+	"github.com/ThreeDotsLabs/watermill/components/cqrs"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// CustomersRepository exports and erases the data of the customers, found by their emails.
+type CustomersRepository struct {
+	db             *sqlx.DB
+	eventMarshaler cqrs.CommandEventMarshaler
+}
+
+func NewCustomersRepository(db *sqlx.DB, eventMarshaler cqrs.CommandEventMarshaler) CustomersRepository {
+	if db == nil {
+		panic("db is nil")
+	}
+	if eventMarshaler == nil {
+		panic("eventMarshaler is nil")
+	}
+
+	return CustomersRepository{db: db, eventMarshaler: eventMarshaler}
+}
+
+// GetData returns the tickets, the bookings, the sheet rows and the webhook messages of the customer.
+func (c CustomersRepository) GetData(ctx context.Context, email string) (entities.CustomerData, error) {
+	data := entities.CustomerData{
+		Email:           email,
+		Tickets:         []entities.CustomerTicket{},
+		Bookings:        []entities.Booking{},
+		SheetRows:       []entities.SheetRow{},
+		WebhookMessages: []entities.CustomerWebhookMessage{},
+	}
+
+	emailJSON, err := json.Marshal(email)
+	if err != nil {
+		return entities.CustomerData{}, fmt.Errorf("could not marshal email: %w", err)
+	}
+
+	err = inTenantTx(ctx, c.db, &sql.TxOptions{ReadOnly: true}, func(tx *sqlx.Tx, tenantID string) error {
+		var tickets []entities.Ticket
+		err := tx.SelectContext(
+			ctx,
+			&tickets,
+			`SELECT `+ticketColumns+` FROM tickets WHERE customer_email = $1 AND tenant_id = $2 ORDER BY ticket_id`,
+			email,
+			tenantID,
+		)
+		if err != nil {
+			return fmt.Errorf("could not get tickets: %w", err)
+		}
+
+		ticketIDs := make(pq.StringArray, 0, len(tickets))
+		for _, ticket := range tickets {
+			ticketIDs = append(ticketIDs, ticket.TicketID)
+		}
+
+		var history []entities.TicketStatusChange
+		err = tx.SelectContext(
+			ctx,
+			&history,
+			`
+				SELECT
+					ticket_id, status, changed_at
+				FROM
+					ticket_status_history
+				WHERE
+					ticket_id = ANY($1::uuid[]) AND tenant_id = $2
+				ORDER BY
+					id
+			`,
+			ticketIDs,
+			tenantID,
+		)
+		if err != nil {
+			return fmt.Errorf("could not get ticket status history: %w", err)
+		}
+
+		for _, ticket := range tickets {
+			customerTicket := entities.CustomerTicket{Ticket: ticket, StatusHistory: []entities.TicketStatusChange{}}
+			for _, change := range history {
+				if change.TicketID == ticket.TicketID {
+					customerTicket.StatusHistory = append(customerTicket.StatusHistory, change)
+				}
+			}
+
+			data.Tickets = append(data.Tickets, customerTicket)
+		}
+
+		err = tx.SelectContext(
+			ctx,
+			&data.Bookings,
+			`
+				SELECT
+					id, show_id, number_of_tickets, customer_email, tenant_id
+				FROM
+					bookings
+				WHERE
+					customer_email = $1 AND tenant_id = $2
+				ORDER BY
+					id
+			`,
+			email,
+			tenantID,
+		)
+		if err != nil {
+			return fmt.Errorf("could not get bookings: %w", err)
+		}
+
+		var sheetRows []dbSheetRow
+		err = tx.SelectContext(
+			ctx,
+			&sheetRows,
+			`
+				SELECT
+					`+sheetRowColumns+`
+				FROM
+					sheet_rows
+				WHERE
+					ticket_id = ANY($1::uuid[]) AND tenant_id = $2
+				ORDER BY
+					added_at
+			`,
+			ticketIDs,
+			tenantID,
+		)
+		if err != nil {
+			return fmt.Errorf("could not get sheet rows: %w", err)
+		}
+
+		for _, row := range sheetRows {
+			data.SheetRows = append(data.SheetRows, row.toEntity())
+		}
+
+		// the webhook_messages have no tenant, they belong to the tenant of their subscription
+		err = tx.SelectContext(
+			ctx,
+			&data.WebhookMessages,
+			`
+				SELECT
+					m.subscription_id, m.event_id, m.event_type, m.body, m.completed_at
+				FROM
+					webhook_messages m
+				JOIN
+					webhook_subscriptions s ON s.id = m.subscription_id
+				WHERE
+					position(convert_to($1, 'UTF8') IN m.body) > 0 AND s.tenant_id = $2
+				ORDER BY
+					m.event_id, m.subscription_id
+			`,
+			string(emailJSON),
+			tenantID,
+		)
+		if err != nil {
+			return fmt.Errorf("could not get webhook messages: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return entities.CustomerData{}, fmt.Errorf("could not get customer data: %w", err)
+	}
+
+	return data, nil
+}
+
+// Erase replaces the customer's email with the pseudonym, and publishes CustomerDataErased if any data was erased.
+func (c CustomersRepository) Erase(ctx context.Context, email string, pseudonym string) (entities.CustomerErasure, error) {
+	erasure := entities.CustomerErasure{
+		Pseudonym:  pseudonym,
+		TicketIDs:  []string{},
+		BookingIDs: []string{},
+	}
+
+	// the webhook messages have the email as a JSON string, in the body marshaled by the dispatcher
+	emailJSON, err := json.Marshal(email)
+	if err != nil {
+		return entities.CustomerErasure{}, fmt.Errorf("could not marshal email: %w", err)
+	}
+	pseudonymJSON, err := json.Marshal(pseudonym)
+	if err != nil {
+		return entities.CustomerErasure{}, fmt.Errorf("could not marshal pseudonym: %w", err)
+	}
+
+	err = inTenantTx(ctx, c.db, nil, func(tx *sqlx.Tx, tenantID string) error {
+		err := tx.SelectContext(
+			ctx,
+			&erasure.TicketIDs,
+			`UPDATE tickets SET customer_email = $1 WHERE customer_email = $2 AND tenant_id = $3 RETURNING ticket_id`,
+			pseudonym,
+			email,
+			tenantID,
+		)
+		if err != nil {
+			return fmt.Errorf("could not erase tickets: %w", err)
+		}
+
+		err = tx.SelectContext(
+			ctx,
+			&erasure.BookingIDs,
+			`UPDATE bookings SET customer_email = $1 WHERE customer_email = $2 AND tenant_id = $3 RETURNING id`,
+			pseudonym,
+			email,
+			tenantID,
+		)
+		if err != nil {
+			return fmt.Errorf("could not erase bookings: %w", err)
+		}
+
+		res, err := tx.ExecContext(
+			ctx,
+			`
+				UPDATE
+					sheet_rows
+				SET
+					columns = array_replace(columns, $1, $2)
+				WHERE
+					ticket_id = ANY($3::uuid[]) AND $1 = ANY(columns) AND tenant_id = $4
+			`,
+			email,
+			pseudonym,
+			pq.StringArray(erasure.TicketIDs),
+			tenantID,
+		)
+		if err != nil {
+			return fmt.Errorf("could not erase sheet rows: %w", err)
+		}
+
+		sheetRows, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("could not get erased sheet rows: %w", err)
+		}
+		erasure.SheetRows = int(sheetRows)
+
+		res, err = tx.ExecContext(
+			ctx,
+			`
+				UPDATE
+					webhook_messages
+				SET
+					body = convert_to(replace(convert_from(body, 'UTF8'), $1, $2), 'UTF8')
+				WHERE
+					position(convert_to($1, 'UTF8') IN body) > 0
+					AND subscription_id IN (SELECT id FROM webhook_subscriptions WHERE tenant_id = $3)
+			`,
+			string(emailJSON),
+			string(pseudonymJSON),
+			tenantID,
+		)
+		if err != nil {
+			return fmt.Errorf("could not erase webhook messages: %w", err)
+		}
+
+		webhookMessages, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("could not get erased webhook messages: %w", err)
+		}
+		erasure.WebhookMessages = int(webhookMessages)
+
+		if !erasure.Erased() {
+			return recordAudit(ctx, tx, tenantID)
+		}
+
+		// the BookingMade events are ordered by the booking ID
+		if _, err := outbox.RemoveForwarded(ctx, tx, erasure.BookingIDs); err != nil {
+			return err
+		}
+
+		outboxPublisher, err := outbox.NewPublisherForDb(ctx, tx)
+		if err != nil {
+			return fmt.Errorf("could not create SQL publisher: %w", err)
+		}
+
+		bus, err := event.NewEventBus(outboxPublisher, c.eventMarshaler)
+		if err != nil {
+			return fmt.Errorf("could not create event bus: %w", err)
+		}
+
+		err = bus.Publish(ctx, entities.CustomerDataErased{
+			Header:     entities.NewEventHeader().WithTenant(tenantID),
+			TicketIDs:  erasure.TicketIDs,
+			BookingIDs: erasure.BookingIDs,
+			Pseudonym:  pseudonym,
+		})
+		if err != nil {
+			return fmt.Errorf("could not publish event: %w", err)
+		}
+
+		audit.AddTargets(ctx, erasure.TicketIDs...)
+		audit.AddTargets(ctx, erasure.BookingIDs...)
+
+		return recordAudit(ctx, tx, tenantID)
+	})
+	if err != nil {
+		return entities.CustomerErasure{}, fmt.Errorf("could not erase customer data: %w", err)
+	}
+
+	return erasure, nil
+}
@@ -0,0 +1,133 @@
+//go:build integration
+
+package db_test
+
+import (
+	"context"
+	"testing"
+	ticketsDb "tickets/db"
+	"tickets/entities"
+	"tickets/message/event"
+	"tickets/webhook"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomersRepository(t *testing.T) {
+	ctx := context.Background()
+	db := GetDb()
+
+	err := ticketsDb.InitializeDatabaseSchema(db)
+	require.NoError(t, err)
+
+	eventMarshaler := event.NewMarshaler(event.FormatJSON)
+	showsRepo := ticketsDb.NewShowsRepository(db)
+	bookingsRepo := ticketsDb.NewBookingsRepository(db, eventMarshaler)
+	ticketsRepo := ticketsDb.NewTicketsRepository(db)
+	sheetRowsRepo := ticketsDb.NewSheetRowsRepository(db)
+	webhooksRepo := ticketsDb.NewWebhooksRepository(db)
+	customersRepo := ticketsDb.NewCustomersRepository(db, eventMarshaler)
+
+	// every test run has its own customer
+	email := uuid.NewString() + "@example.com"
+
+	show := entities.Show{
+		ID:              uuid.New(),
+		DeadNationID:    uuid.New(),
+		NumberOfTickets: 10,
+		StartTime:       time.Now().Add(time.Hour),
+		Title:           "Example title",
+		Venue:           "Example venue",
+	}
+	require.NoError(t, showsRepo.Add(ctx, show))
+
+	booking := entities.Booking{
+		ID:              uuid.New(),
+		ShowID:          show.ID,
+		NumberOfTickets: 1,
+		CustomerEmail:   email,
+	}
+	require.NoError(t, bookingsRepo.Add(ctx, booking))
+
+	ticket := entities.Ticket{
+		TicketID:      uuid.NewString(),
+		Price:         entities.Money{Amount: "50.30", Currency: "GBP"},
+		CustomerEmail: email,
+		BookingID:     booking.ID.String(),
+	}
+	require.NoError(t, ticketsRepo.Add(ctx, ticket))
+
+	_, err = sheetRowsRepo.Add(ctx, entities.SheetRow{
+		SheetName:      "tickets-to-print",
+		TicketID:       ticket.TicketID,
+		IdempotencyKey: uuid.NewString(),
+		Columns:        []string{ticket.TicketID, email, "50.30", "GBP"},
+	})
+	require.NoError(t, err)
+
+	subscription, err := webhook.NewSubscription("https://example.com", []string{"BookingMade"})
+	require.NoError(t, err)
+	require.NoError(t, webhooksRepo.Add(ctx, subscription))
+
+	err = webhook.NewDispatcher(webhooksRepo, nil, webhook.Config{}).Dispatch(
+		ctx,
+		uuid.NewString(),
+		"BookingMade",
+		[]byte(`{"booking_id":"`+booking.ID.String()+`","customer_email":"`+email+`"}`),
+	)
+	require.NoError(t, err)
+
+	data, err := customersRepo.GetData(ctx, email)
+	require.NoError(t, err)
+	require.Len(t, data.Tickets, 1)
+	assert.Equal(t, ticket.TicketID, data.Tickets[0].TicketID)
+	assert.Len(t, data.Tickets[0].StatusHistory, 1)
+	require.Len(t, data.Bookings, 1)
+	assert.Equal(t, booking.ID, data.Bookings[0].ID)
+	require.Len(t, data.SheetRows, 1)
+	assert.Contains(t, data.SheetRows[0].Columns, email)
+	require.Len(t, data.WebhookMessages, 1)
+	assert.Equal(t, subscription.ID, data.WebhookMessages[0].SubscriptionID)
+	assert.Contains(t, string(data.WebhookMessages[0].Body), email)
+
+	pseudonym := entities.NewCustomerPseudonym()
+	erasure, err := customersRepo.Erase(ctx, email, pseudonym)
+	require.NoError(t, err)
+	assert.Equal(t, []string{ticket.TicketID}, erasure.TicketIDs)
+	assert.Equal(t, []string{booking.ID.String()}, erasure.BookingIDs)
+	assert.Equal(t, 1, erasure.SheetRows)
+	assert.Equal(t, 1, erasure.WebhookMessages)
+
+	data, err = customersRepo.GetData(ctx, email)
+	require.NoError(t, err)
+	assert.Empty(t, data.Tickets)
+	assert.Empty(t, data.Bookings)
+	assert.Empty(t, data.SheetRows)
+	assert.Empty(t, data.WebhookMessages)
+
+	data, err = customersRepo.GetData(ctx, pseudonym)
+	require.NoError(t, err)
+	require.Len(t, data.Tickets, 1)
+	require.Len(t, data.SheetRows, 1)
+	assert.Equal(t, []string{ticket.TicketID, pseudonym, "50.30", "GBP"}, data.SheetRows[0].Columns)
+	assert.Nil(t, data.SheetRows[0].ErasedAt)
+	require.Len(t, data.WebhookMessages, 1)
+	assert.NotContains(t, string(data.WebhookMessages[0].Body), email)
+
+	// the sheet rows are marked as erased by the CustomerDataErased handler
+	err = sheetRowsRepo.MarkErased(ctx, erasure.TicketIDs, erasure.Pseudonym)
+	require.NoError(t, err)
+
+	data, err = customersRepo.GetData(ctx, pseudonym)
+	require.NoError(t, err)
+	require.Len(t, data.SheetRows, 1)
+	assert.NotNil(t, data.SheetRows[0].ErasedAt)
+	assert.Equal(t, pseudonym, data.SheetRows[0].ErasedPseudonym)
+
+	erasure, err = customersRepo.Erase(ctx, email, entities.NewCustomerPseudonym())
+	require.NoError(t, err)
+	assert.False(t, erasure.Erased(), "the erased data should not be found again")
+}
@@ -3,5 +3,6 @@ package db
 import "errors"
 
 var (
-	ErrExceedingTicketLimit       = errors.New("exceeding ticket limit")
+	ErrExceedingTicketLimit = errors.New("exceeding ticket limit")
+	ErrNotFound             = errors.New("not found")
 )
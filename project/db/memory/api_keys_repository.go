@@ -0,0 +1,93 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"tickets/db"
+	"tickets/entities"
+	"tickets/tenant"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type APIKeysRepository struct {
+	db *Database
+}
+
+func NewAPIKeysRepository(db *Database) APIKeysRepository {
+	if db == nil {
+		panic("db is nil")
+	}
+
+	return APIKeysRepository{db: db}
+}
+
+func (a APIKeysRepository) Add(ctx context.Context, apiKey entities.APIKey) error {
+	a.db.lock.Lock()
+	defer a.db.lock.Unlock()
+
+	for _, existing := range a.db.apiKeys {
+		if existing.ID == apiKey.ID || existing.Hash == apiKey.Hash {
+			return fmt.Errorf("could not save API key: duplicated API key %s", apiKey.ID)
+		}
+	}
+
+	a.db.apiKeys = append(a.db.apiKeys, apiKey)
+	a.db.recordAudit(ctx, apiKey.TenantID)
+
+	return nil
+}
+
+func (a APIKeysRepository) GetByHash(ctx context.Context, hash string) (entities.APIKey, error) {
+	a.db.lock.RLock()
+	defer a.db.lock.RUnlock()
+
+	for _, apiKey := range a.db.apiKeys {
+		if apiKey.Hash == hash && apiKey.RevokedAt == nil {
+			return apiKey, nil
+		}
+	}
+
+	return entities.APIKey{}, db.ErrNotFound
+}
+
+func (a APIKeysRepository) List(ctx context.Context) ([]entities.APIKey, error) {
+	a.db.lock.RLock()
+	defer a.db.lock.RUnlock()
+
+	tenantID := tenant.FromContext(ctx)
+
+	apiKeys := []entities.APIKey{}
+	for _, apiKey := range a.db.apiKeys {
+		if apiKey.TenantID == tenantID {
+			apiKeys = append(apiKeys, apiKey)
+		}
+	}
+	slices.SortStableFunc(apiKeys, func(a, b entities.APIKey) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+
+	return apiKeys, nil
+}
+
+func (a APIKeysRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	a.db.lock.Lock()
+	defer a.db.lock.Unlock()
+
+	tenantID := tenant.FromContext(ctx)
+
+	for i, apiKey := range a.db.apiKeys {
+		if apiKey.ID == id && apiKey.TenantID == tenantID {
+			if apiKey.RevokedAt == nil {
+				now := time.Now().UTC()
+				a.db.apiKeys[i].RevokedAt = &now
+			}
+			a.db.recordAudit(ctx, tenantID)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("API key %s: %w", id, db.ErrNotFound)
+}
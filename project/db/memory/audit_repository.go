@@ -0,0 +1,62 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"tickets/entities"
+	"tickets/tenant"
+)
+
+type AuditRepository struct {
+	db *Database
+}
+
+func NewAuditRepository(db *Database) AuditRepository {
+	if db == nil {
+		panic("db is nil")
+	}
+
+	return AuditRepository{db: db}
+}
+
+// Find returns the records matching the filter, newest first.
+func (a AuditRepository) Find(ctx context.Context, filter entities.AuditRecordsFilter) ([]entities.AuditRecord, error) {
+	a.db.lock.RLock()
+	defer a.db.lock.RUnlock()
+
+	tenantID := tenant.FromContext(ctx)
+
+	records := []entities.AuditRecord{}
+	for _, record := range a.db.auditRecords {
+		if record.TenantID != tenantID {
+			continue
+		}
+		if filter.Actor != "" && record.Actor != filter.Actor {
+			continue
+		}
+		if filter.Action != "" && record.Action != filter.Action {
+			continue
+		}
+		if filter.TargetID != "" && !slices.Contains(record.TargetIDs, filter.TargetID) {
+			continue
+		}
+		if !filter.From.IsZero() && record.OccurredAt.Before(filter.From) {
+			continue
+		}
+		if !filter.To.IsZero() && !record.OccurredAt.Before(filter.To) {
+			continue
+		}
+
+		records = append(records, record)
+	}
+
+	slices.SortStableFunc(records, func(a, b entities.AuditRecord) int {
+		return b.OccurredAt.Compare(a.OccurredAt)
+	})
+
+	if filter.Limit > 0 {
+		records = records[:min(filter.Limit, len(records))]
+	}
+
+	return records, nil
+}
@@ -0,0 +1,120 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"tickets/db"
+	"tickets/entities"
+	"tickets/message/event"
+	"tickets/message/outbox"
+	"tickets/tenant"
+
+	"github.com/ThreeDotsLabs/watermill/components/cqrs"
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+type BookingsRepository struct {
+	db              *Database
+	outboxPublisher message.Publisher
+	eventMarshaler  cqrs.CommandEventMarshaler
+}
+
+// NewBookingsRepository creates a repository publishing BookingMade events to the outboxPublisher.
+func NewBookingsRepository(db *Database, outboxPublisher message.Publisher, eventMarshaler cqrs.CommandEventMarshaler) BookingsRepository {
+	if db == nil {
+		panic("db is nil")
+	}
+	if outboxPublisher == nil {
+		panic("outboxPublisher is nil")
+	}
+	if eventMarshaler == nil {
+		panic("eventMarshaler is nil")
+	}
+
+	return BookingsRepository{
+		db:              db,
+		outboxPublisher: outbox.NewPublisher(outboxPublisher),
+		eventMarshaler:  eventMarshaler,
+	}
+}
+
+func (b BookingsRepository) Add(ctx context.Context, booking entities.Booking) error {
+	b.db.lock.Lock()
+	defer b.db.lock.Unlock()
+
+	booking.TenantID = tenant.FromContext(ctx)
+
+	i := b.db.findShow(booking.TenantID, booking.ShowID)
+	if i == -1 {
+		return fmt.Errorf("could not get available seats: show %s: %w", booking.ShowID, db.ErrNotFound)
+	}
+	availableSeats := b.db.shows[i].NumberOfTickets
+
+	alreadyBookedSeats := 0
+	for _, existing := range b.db.bookings {
+		if existing.ID == booking.ID {
+			return nil
+		}
+		if existing.ShowID == booking.ShowID && existing.TenantID == booking.TenantID {
+			alreadyBookedSeats += existing.NumberOfTickets
+		}
+	}
+
+	if availableSeats-alreadyBookedSeats < booking.NumberOfTickets {
+		return db.ErrExceedingTicketLimit
+	}
+
+	bus, err := event.NewEventBus(b.outboxPublisher, b.eventMarshaler)
+	if err != nil {
+		return fmt.Errorf("could not create event bus: %w", err)
+	}
+
+	// the booking is saved only when the event was published, as it would be when the transaction is committed
+	err = bus.Publish(ctx, entities.BookingMade{
+		Header:          entities.NewEventHeader().WithTenant(booking.TenantID),
+		BookingID:       booking.ID,
+		NumberOfTickets: booking.NumberOfTickets,
+		CustomerEmail:   booking.CustomerEmail,
+		ShowId:          booking.ShowID,
+	})
+	if err != nil {
+		return fmt.Errorf("could not publish event: %w", err)
+	}
+
+	b.db.bookings = append(b.db.bookings, booking)
+	b.db.recordAudit(ctx, booking.TenantID)
+
+	return nil
+}
+
+func (b BookingsRepository) PublishTicketStatusChanges(ctx context.Context, events []any) error {
+	b.db.lock.Lock()
+	defer b.db.lock.Unlock()
+
+	tenantID := tenant.FromContext(ctx)
+
+	bus, err := event.NewEventBus(b.outboxPublisher, b.eventMarshaler)
+	if err != nil {
+		return fmt.Errorf("could not create event bus: %w", err)
+	}
+
+	for _, e := range events {
+		switch e := e.(type) {
+		case entities.TicketBookingConfirmed:
+			e.Version = b.db.nextTicketVersion(tenantID, e.TicketID)
+			err = bus.Publish(ctx, e)
+		case entities.TicketBookingCanceled:
+			e.Version = b.db.nextTicketVersion(tenantID, e.TicketID)
+			err = bus.Publish(ctx, e)
+		default:
+			err = fmt.Errorf("unsupported event %T", e)
+		}
+		if err != nil {
+			return fmt.Errorf("could not publish event: %w", err)
+		}
+	}
+
+	b.db.recordAudit(ctx, tenantID)
+
+	return nil
+}
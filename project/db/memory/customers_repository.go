@@ -0,0 +1,204 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"tickets/audit"
+	"tickets/entities"
+	"tickets/message/event"
+	"tickets/message/outbox"
+	"tickets/tenant"
+
+	"github.com/ThreeDotsLabs/watermill/components/cqrs"
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+type CustomersRepository struct {
+	db              *Database
+	outboxPublisher message.Publisher
+	eventMarshaler  cqrs.CommandEventMarshaler
+}
+
+// NewCustomersRepository creates a repository publishing CustomerDataErased events to the outboxPublisher.
+func NewCustomersRepository(db *Database, outboxPublisher message.Publisher, eventMarshaler cqrs.CommandEventMarshaler) CustomersRepository {
+	if db == nil {
+		panic("db is nil")
+	}
+	if outboxPublisher == nil {
+		panic("outboxPublisher is nil")
+	}
+	if eventMarshaler == nil {
+		panic("eventMarshaler is nil")
+	}
+
+	return CustomersRepository{
+		db:              db,
+		outboxPublisher: outbox.NewPublisher(outboxPublisher),
+		eventMarshaler:  eventMarshaler,
+	}
+}
+
+func (c CustomersRepository) GetData(ctx context.Context, email string) (entities.CustomerData, error) {
+	c.db.lock.RLock()
+	defer c.db.lock.RUnlock()
+
+	tenantID := tenant.FromContext(ctx)
+
+	data := entities.CustomerData{
+		Email:           email,
+		Tickets:         []entities.CustomerTicket{},
+		Bookings:        []entities.Booking{},
+		SheetRows:       []entities.SheetRow{},
+		WebhookMessages: []entities.CustomerWebhookMessage{},
+	}
+
+	emailJSON, err := json.Marshal(email)
+	if err != nil {
+		return entities.CustomerData{}, fmt.Errorf("could not marshal email: %w", err)
+	}
+
+	var ticketIDs []string
+	for _, ticket := range c.db.tickets {
+		if ticket.TenantID != tenantID || ticket.CustomerEmail != email {
+			continue
+		}
+		ticketIDs = append(ticketIDs, ticket.TicketID)
+
+		customerTicket := entities.CustomerTicket{Ticket: ticket, StatusHistory: []entities.TicketStatusChange{}}
+		for _, change := range c.db.ticketStatusHistory {
+			if change.TicketID == ticket.TicketID {
+				customerTicket.StatusHistory = append(customerTicket.StatusHistory, change)
+			}
+		}
+
+		data.Tickets = append(data.Tickets, customerTicket)
+	}
+
+	for _, booking := range c.db.bookings {
+		if booking.TenantID == tenantID && booking.CustomerEmail == email {
+			data.Bookings = append(data.Bookings, booking)
+		}
+	}
+
+	for _, row := range c.db.sheetRows {
+		if row.TenantID == tenantID && slices.Contains(ticketIDs, row.TicketID) {
+			row.Columns = slices.Clone(row.Columns)
+			data.SheetRows = append(data.SheetRows, row)
+		}
+	}
+
+	for _, message := range c.db.webhookMessages {
+		if message.Subscription.TenantID == tenantID && bytes.Contains(message.Body, emailJSON) {
+			data.WebhookMessages = append(data.WebhookMessages, entities.CustomerWebhookMessage{
+				SubscriptionID: message.Subscription.ID,
+				EventID:        message.EventID,
+				EventType:      message.EventType,
+				Body:           slices.Clone(message.Body),
+				CompletedAt:    message.CompletedAt,
+			})
+		}
+	}
+
+	return data, nil
+}
+
+func (c CustomersRepository) Erase(ctx context.Context, email string, pseudonym string) (entities.CustomerErasure, error) {
+	c.db.lock.Lock()
+	defer c.db.lock.Unlock()
+
+	tenantID := tenant.FromContext(ctx)
+
+	erasure := entities.CustomerErasure{
+		Pseudonym:  pseudonym,
+		TicketIDs:  []string{},
+		BookingIDs: []string{},
+	}
+
+	emailJSON, err := json.Marshal(email)
+	if err != nil {
+		return entities.CustomerErasure{}, fmt.Errorf("could not marshal email: %w", err)
+	}
+	pseudonymJSON, err := json.Marshal(pseudonym)
+	if err != nil {
+		return entities.CustomerErasure{}, fmt.Errorf("could not marshal pseudonym: %w", err)
+	}
+
+	for _, ticket := range c.db.tickets {
+		if ticket.TenantID == tenantID && ticket.CustomerEmail == email {
+			erasure.TicketIDs = append(erasure.TicketIDs, ticket.TicketID)
+		}
+	}
+	for _, booking := range c.db.bookings {
+		if booking.TenantID == tenantID && booking.CustomerEmail == email {
+			erasure.BookingIDs = append(erasure.BookingIDs, booking.ID.String())
+		}
+	}
+	for _, row := range c.db.sheetRows {
+		if row.TenantID == tenantID && slices.Contains(erasure.TicketIDs, row.TicketID) && slices.Contains(row.Columns, email) {
+			erasure.SheetRows++
+		}
+	}
+	for _, message := range c.db.webhookMessages {
+		if message.Subscription.TenantID == tenantID && bytes.Contains(message.Body, emailJSON) {
+			erasure.WebhookMessages++
+		}
+	}
+
+	if erasure.Erased() {
+		bus, err := event.NewEventBus(c.outboxPublisher, c.eventMarshaler)
+		if err != nil {
+			return entities.CustomerErasure{}, fmt.Errorf("could not create event bus: %w", err)
+		}
+
+		// the data is changed only when the event was published, as it would be when the transaction is committed
+		err = bus.Publish(ctx, entities.CustomerDataErased{
+			Header:     entities.NewEventHeader().WithTenant(tenantID),
+			TicketIDs:  erasure.TicketIDs,
+			BookingIDs: erasure.BookingIDs,
+			Pseudonym:  pseudonym,
+		})
+		if err != nil {
+			return entities.CustomerErasure{}, fmt.Errorf("could not publish event: %w", err)
+		}
+	}
+
+	for i, ticket := range c.db.tickets {
+		if ticket.TenantID == tenantID && ticket.CustomerEmail == email {
+			c.db.tickets[i].CustomerEmail = pseudonym
+		}
+	}
+	for i, booking := range c.db.bookings {
+		if booking.TenantID == tenantID && booking.CustomerEmail == email {
+			c.db.bookings[i].CustomerEmail = pseudonym
+		}
+	}
+	for i, row := range c.db.sheetRows {
+		if row.TenantID != tenantID || !slices.Contains(erasure.TicketIDs, row.TicketID) {
+			continue
+		}
+
+		// the columns are copied, the rows returned before keep theirs
+		columns := slices.Clone(row.Columns)
+		for j, column := range columns {
+			if column == email {
+				columns[j] = pseudonym
+			}
+		}
+		c.db.sheetRows[i].Columns = columns
+	}
+	for i, message := range c.db.webhookMessages {
+		if message.Subscription.TenantID == tenantID {
+			// the body is replaced, not changed in place, as it's shared by the messages of one event
+			c.db.webhookMessages[i].Body = bytes.ReplaceAll(message.Body, emailJSON, pseudonymJSON)
+		}
+	}
+
+	audit.AddTargets(ctx, erasure.TicketIDs...)
+	audit.AddTargets(ctx, erasure.BookingIDs...)
+	c.db.recordAudit(ctx, tenantID)
+
+	return erasure, nil
+}
@@ -0,0 +1,108 @@
+// Package memory contains in-memory implementations of the repositories from the db package, for tests and local runs.
+package memory
+
+import (
+	"context"
+	"sync"
+	"tickets/audit"
+	"tickets/entities"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Database struct {
+	lock sync.RWMutex
+
+	tickets             []entities.Ticket
+	ticketStatusHistory []entities.TicketStatusChange
+	ticketVersions      map[ticketKey]int64
+	shows               []entities.Show
+	bookings            []entities.Booking
+	webhooks            []entities.WebhookSubscription
+	webhookMessages     []entities.WebhookMessage
+	webhookDeliveries   []entities.WebhookDelivery
+	sheetRows           []entities.SheetRow
+	sheetRowClaims      map[int]time.Time // the claims of the sheetRows by their index
+	ticketSales         []entities.TicketSale
+	bookedShows         map[bookingKey]uuid.UUID
+	apiKeys             []entities.APIKey
+	auditRecords        []entities.AuditRecord
+	messageHandlers     map[string]entities.MessageHandlerState
+}
+
+func NewDatabase() *Database {
+	return &Database{
+		ticketVersions:  make(map[ticketKey]int64),
+		bookedShows:     make(map[bookingKey]uuid.UUID),
+		sheetRowClaims:  make(map[int]time.Time),
+		messageHandlers: make(map[string]entities.MessageHandlerState),
+	}
+}
+
+type ticketKey struct {
+	TenantID string
+	TicketID string
+}
+
+type bookingKey struct {
+	TenantID  string
+	BookingID string
+}
+
+func (d *Database) findTicket(tenantID string, ticketID string) int {
+	for i, ticket := range d.tickets {
+		if ticket.TicketID == ticketID && ticket.TenantID == tenantID {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// nextTicketVersion must be called with the lock held.
+func (d *Database) nextTicketVersion(tenantID string, ticketID string) int64 {
+	key := ticketKey{TenantID: tenantID, TicketID: ticketID}
+
+	version, ok := d.ticketVersions[key]
+	if !ok {
+		if i := d.findTicket(tenantID, ticketID); i != -1 {
+			version = d.tickets[i].Version
+		}
+	}
+	version++
+	d.ticketVersions[key] = version
+
+	return version
+}
+
+func (d *Database) findShow(tenantID string, showID uuid.UUID) int {
+	for i, show := range d.shows {
+		if show.ID == showID && show.TenantID == tenantID {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (d *Database) findWebhook(subscriptionID uuid.UUID) int {
+	for i, subscription := range d.webhooks {
+		if subscription.ID == subscriptionID {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// recordAudit must be called with the lock held, once the change can't fail.
+func (d *Database) recordAudit(ctx context.Context, tenantID string) {
+	record, ok := audit.Take(ctx)
+	if !ok {
+		return
+	}
+	record.TenantID = tenantID
+
+	d.auditRecords = append(d.auditRecords, record)
+}
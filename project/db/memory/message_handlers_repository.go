@@ -0,0 +1,62 @@
+package memory
+
+import (
+	"context"
+	"tickets/entities"
+	"tickets/tenant"
+	"time"
+)
+
+type MessageHandlersRepository struct {
+	db *Database
+}
+
+func NewMessageHandlersRepository(db *Database) MessageHandlersRepository {
+	if db == nil {
+		panic("db is nil")
+	}
+
+	return MessageHandlersRepository{db: db}
+}
+
+func (m MessageHandlersRepository) SetPaused(ctx context.Context, name string, paused bool) error {
+	m.db.lock.Lock()
+	defer m.db.lock.Unlock()
+
+	state := m.db.messageHandlers[name]
+	state.Name = name
+	state.Paused = paused
+	m.db.messageHandlers[name] = state
+	m.db.recordAudit(ctx, tenant.FromContext(ctx))
+
+	return nil
+}
+
+func (m MessageHandlersRepository) RecordError(ctx context.Context, name string, message string, occurredAt time.Time) error {
+	m.db.lock.Lock()
+	defer m.db.lock.Unlock()
+
+	state := m.db.messageHandlers[name]
+	if state.LastErrorAt != nil && !state.LastErrorAt.Before(occurredAt) {
+		return nil
+	}
+
+	state.Name = name
+	state.LastError = message
+	state.LastErrorAt = &occurredAt
+	m.db.messageHandlers[name] = state
+
+	return nil
+}
+
+func (m MessageHandlersRepository) GetStates(ctx context.Context) ([]entities.MessageHandlerState, error) {
+	m.db.lock.RLock()
+	defer m.db.lock.RUnlock()
+
+	var states []entities.MessageHandlerState
+	for _, state := range m.db.messageHandlers {
+		states = append(states, state)
+	}
+
+	return states, nil
+}
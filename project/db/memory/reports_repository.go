@@ -0,0 +1,210 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"slices"
+	"tickets/entities"
+	"tickets/tenant"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ReportsRepository struct {
+	db *Database
+}
+
+func NewReportsRepository(db *Database) ReportsRepository {
+	if db == nil {
+		panic("db is nil")
+	}
+
+	return ReportsRepository{db: db}
+}
+
+func (r ReportsRepository) SaveTicketSale(ctx context.Context, sale entities.TicketSale) error {
+	// the amount is validated like the NUMERIC column does it
+	if sale.Price.Amount != "" {
+		if _, ok := new(big.Rat).SetString(sale.Price.Amount); !ok {
+			return fmt.Errorf("could not save ticket sale: invalid price amount %q", sale.Price.Amount)
+		}
+	}
+
+	r.db.lock.Lock()
+	defer r.db.lock.Unlock()
+
+	tenantID := tenant.FromContext(ctx)
+
+	for i, stored := range r.db.ticketSales {
+		if stored.TicketID == sale.TicketID && stored.TenantID == tenantID {
+			r.db.ticketSales[i] = stored.Merge(sale)
+			return nil
+		}
+	}
+
+	r.db.ticketSales = append(r.db.ticketSales, entities.TicketSale{TicketID: sale.TicketID, TenantID: tenantID}.Merge(sale))
+
+	return nil
+}
+
+func (r ReportsRepository) AddBookedShow(ctx context.Context, bookingID string, showID uuid.UUID) error {
+	r.db.lock.Lock()
+	defer r.db.lock.Unlock()
+
+	key := bookingKey{TenantID: tenant.FromContext(ctx), BookingID: bookingID}
+	if _, ok := r.db.bookedShows[key]; !ok {
+		r.db.bookedShows[key] = showID
+	}
+
+	return nil
+}
+
+func (r ReportsRepository) GetShowReport(ctx context.Context, showID uuid.UUID) (entities.ShowSalesReport, error) {
+	r.db.lock.RLock()
+	defer r.db.lock.RUnlock()
+
+	figures := r.salesFigures(tenant.FromContext(ctx), entities.SalesReportFilter{ShowID: &showID})
+
+	var all []entities.SalesFigures
+	for _, day := range sortedKeys(figures) {
+		all = append(all, figures[day]...)
+	}
+
+	return entities.ShowSalesReport{
+		ShowID:      showID,
+		SalesReport: entities.NewSalesReport(mergeCurrencies(all)),
+	}, nil
+}
+
+func (r ReportsRepository) GetDailyReports(ctx context.Context, filter entities.SalesReportFilter) ([]entities.DailySalesReport, error) {
+	r.db.lock.RLock()
+	defer r.db.lock.RUnlock()
+
+	figures := r.salesFigures(tenant.FromContext(ctx), filter)
+
+	reports := []entities.DailySalesReport{}
+	for _, day := range sortedKeys(figures) {
+		reports = append(reports, entities.DailySalesReport{
+			Date:        day,
+			SalesReport: entities.NewSalesReport(mergeCurrencies(figures[day])),
+		})
+	}
+
+	return reports, nil
+}
+
+// salesFigures returns the figures of every confirmation, cancellation and refund of the tenant matching the filter, by day.
+func (r ReportsRepository) salesFigures(tenantID string, filter entities.SalesReportFilter) map[string][]entities.SalesFigures {
+	from, to := "", ""
+	if !filter.From.IsZero() {
+		from = entities.SaleDay(filter.From)
+	}
+	if !filter.To.IsZero() {
+		to = entities.SaleDay(filter.To)
+	}
+
+	figures := make(map[string][]entities.SalesFigures)
+
+	add := func(at *time.Time, f entities.SalesFigures) {
+		if at == nil {
+			return
+		}
+
+		day := entities.SaleDay(*at)
+		if (from != "" && day < from) || (to != "" && day > to) {
+			return
+		}
+
+		figures[day] = append(figures[day], f)
+	}
+
+	for _, sale := range r.db.ticketSales {
+		if sale.TenantID != tenantID {
+			continue
+		}
+		if filter.ShowID != nil {
+			showID, ok := r.db.bookedShows[bookingKey{TenantID: tenantID, BookingID: sale.BookingID}]
+			if !ok || showID != *filter.ShowID {
+				continue
+			}
+		}
+
+		amount := sale.Price.Amount
+		if amount == "" {
+			amount = "0"
+		}
+		currency := sale.Price.Currency
+
+		add(sale.ConfirmedAt, entities.SalesFigures{Currency: currency, TicketsSold: 1, Gross: amount, Net: amount})
+		if sale.Canceled() {
+			net := "0"
+			if sale.ConfirmedAt != nil {
+				net = "-" + amount
+			}
+			add(sale.CanceledAt, entities.SalesFigures{Currency: currency, TicketsCanceled: 1, Gross: "0", Net: net})
+		}
+		add(sale.RefundedAt, entities.SalesFigures{Currency: currency, TicketsRefunded: 1, Gross: "0", Net: "0"})
+	}
+
+	return figures
+}
+
+// mergeCurrencies sums up the figures of each currency, amounts are formatted like NUMERIC(12, 2).
+func mergeCurrencies(figures []entities.SalesFigures) []entities.SalesFigures {
+	type sums struct {
+		figures    entities.SalesFigures
+		gross, net *big.Rat
+	}
+
+	var currencies []string
+	byCurrency := make(map[string]*sums)
+
+	for _, f := range figures {
+		s, ok := byCurrency[f.Currency]
+		if !ok {
+			s = &sums{figures: entities.SalesFigures{Currency: f.Currency}, gross: new(big.Rat), net: new(big.Rat)}
+			byCurrency[f.Currency] = s
+			currencies = append(currencies, f.Currency)
+		}
+
+		s.figures.TicketsSold += f.TicketsSold
+		s.figures.TicketsCanceled += f.TicketsCanceled
+		s.figures.TicketsRefunded += f.TicketsRefunded
+		s.gross.Add(s.gross, parseAmount(f.Gross))
+		s.net.Add(s.net, parseAmount(f.Net))
+	}
+
+	slices.Sort(currencies)
+
+	merged := make([]entities.SalesFigures, 0, len(currencies))
+	for _, currency := range currencies {
+		s := byCurrency[currency]
+		s.figures.Gross = s.gross.FloatString(2)
+		s.figures.Net = s.net.FloatString(2)
+		merged = append(merged, s.figures)
+	}
+
+	return merged
+}
+
+// parseAmount parses the amounts validated by SaveTicketSale.
+func parseAmount(amount string) *big.Rat {
+	r, ok := new(big.Rat).SetString(amount)
+	if !ok {
+		return new(big.Rat)
+	}
+
+	return r
+}
+
+func sortedKeys(figures map[string][]entities.SalesFigures) []string {
+	keys := make([]string, 0, len(figures))
+	for key := range figures {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	return keys
+}
@@ -0,0 +1,167 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"tickets/entities"
+	"tickets/tenant"
+	"time"
+)
+
+type SheetRowsRepository struct {
+	db *Database
+}
+
+func NewSheetRowsRepository(db *Database) SheetRowsRepository {
+	if db == nil {
+		panic("db is nil")
+	}
+
+	return SheetRowsRepository{db: db}
+}
+
+func (s SheetRowsRepository) Add(ctx context.Context, row entities.SheetRow) (bool, error) {
+	s.db.lock.Lock()
+	defer s.db.lock.Unlock()
+
+	row.TenantID = tenant.FromContext(ctx)
+	if slices.ContainsFunc(s.db.sheetRows, row.SameRow) {
+		return false, nil
+	}
+
+	row.Columns = slices.Clone(row.Columns)
+	row.AddedAt = time.Now().UTC()
+	row.AppendedAt = nil
+	s.db.sheetRows = append(s.db.sheetRows, row)
+
+	return true, nil
+}
+
+func (s SheetRowsRepository) ClaimPending(ctx context.Context, limit int, claimFor time.Duration) ([]entities.SheetRow, error) {
+	s.db.lock.Lock()
+	defer s.db.lock.Unlock()
+
+	now := time.Now().UTC()
+
+	// the rows are kept in the order they were added
+	var claimed []entities.SheetRow
+	for i, row := range s.db.sheetRows {
+		if len(claimed) == limit {
+			break
+		}
+		if row.AppendedAt != nil || row.RejectedAt != nil || s.db.sheetRowClaims[i].After(now) {
+			continue
+		}
+
+		s.db.sheetRowClaims[i] = now.Add(claimFor)
+
+		row.Columns = slices.Clone(row.Columns)
+		claimed = append(claimed, row)
+	}
+
+	return claimed, nil
+}
+
+func (s SheetRowsRepository) MarkAppended(ctx context.Context, row entities.SheetRow) error {
+	s.db.lock.Lock()
+	defer s.db.lock.Unlock()
+
+	now := time.Now().UTC()
+	for i := range s.db.sheetRows {
+		if s.db.sheetRows[i].SameRow(row) {
+			s.db.sheetRows[i].AppendedAt = &now
+			delete(s.db.sheetRowClaims, i)
+		}
+	}
+
+	return nil
+}
+
+func (s SheetRowsRepository) MarkRejected(ctx context.Context, row entities.SheetRow, rejection string) error {
+	s.db.lock.Lock()
+	defer s.db.lock.Unlock()
+
+	now := time.Now().UTC()
+	for i := range s.db.sheetRows {
+		if s.db.sheetRows[i].SameRow(row) {
+			s.db.sheetRows[i].RejectedAt = &now
+			s.db.sheetRows[i].Rejection = rejection
+			delete(s.db.sheetRowClaims, i)
+		}
+	}
+
+	return nil
+}
+
+func (s SheetRowsRepository) ReleaseClaimed(ctx context.Context, rows []entities.SheetRow) error {
+	s.db.lock.Lock()
+	defer s.db.lock.Unlock()
+
+	for i := range s.db.sheetRows {
+		if slices.ContainsFunc(rows, s.db.sheetRows[i].SameRow) {
+			delete(s.db.sheetRowClaims, i)
+		}
+	}
+
+	return nil
+}
+
+func (s SheetRowsRepository) MarkErased(ctx context.Context, ticketIDs []string, pseudonym string) error {
+	s.db.lock.Lock()
+	defer s.db.lock.Unlock()
+
+	tenantID := tenant.FromContext(ctx)
+
+	now := time.Now().UTC()
+	for i, row := range s.db.sheetRows {
+		if row.TenantID != tenantID || !slices.Contains(ticketIDs, row.TicketID) {
+			continue
+		}
+		if row.ErasedAt == nil {
+			s.db.sheetRows[i].ErasedAt = &now
+		}
+		s.db.sheetRows[i].ErasedPseudonym = pseudonym
+	}
+
+	return nil
+}
+
+func (s SheetRowsRepository) GetAppended(ctx context.Context, sheetName string) ([]entities.SheetRow, error) {
+	s.db.lock.RLock()
+	defer s.db.lock.RUnlock()
+
+	tenantID := tenant.FromContext(ctx)
+
+	var appended []entities.SheetRow
+	for _, row := range s.db.sheetRows {
+		if row.TenantID == tenantID && row.SheetName == sheetName && row.AppendedAt != nil {
+			appended = append(appended, row)
+		}
+	}
+
+	slices.SortStableFunc(appended, func(a, b entities.SheetRow) int {
+		return a.AppendedAt.Compare(*b.AppendedAt)
+	})
+
+	return appended, nil
+}
+
+func (s SheetRowsRepository) GetRejected(ctx context.Context, sheetName string) ([]entities.SheetRow, error) {
+	s.db.lock.RLock()
+	defer s.db.lock.RUnlock()
+
+	tenantID := tenant.FromContext(ctx)
+
+	var rejected []entities.SheetRow
+	for _, row := range s.db.sheetRows {
+		if row.TenantID == tenantID && row.SheetName == sheetName && row.RejectedAt != nil {
+			rejected = append(rejected, row)
+		}
+	}
+
+	slices.SortStableFunc(rejected, func(a, b entities.SheetRow) int {
+		return a.RejectedAt.Compare(*b.RejectedAt)
+	})
+
+	return rejected, nil
+}
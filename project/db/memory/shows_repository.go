@@ -0,0 +1,53 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"tickets/db"
+	"tickets/entities"
+	"tickets/tenant"
+
+	"github.com/google/uuid"
+)
+
+type ShowsRepository struct {
+	db *Database
+}
+
+func NewShowsRepository(db *Database) ShowsRepository {
+	if db == nil {
+		panic("db is nil")
+	}
+
+	return ShowsRepository{db: db}
+}
+
+func (s ShowsRepository) Add(ctx context.Context, show entities.Show) error {
+	s.db.lock.Lock()
+	defer s.db.lock.Unlock()
+
+	show.TenantID = tenant.FromContext(ctx)
+
+	for _, existing := range s.db.shows {
+		if existing.ID == show.ID || (existing.TenantID == show.TenantID && existing.DeadNationID == show.DeadNationID) {
+			return nil
+		}
+	}
+
+	s.db.shows = append(s.db.shows, show)
+	s.db.recordAudit(ctx, show.TenantID)
+
+	return nil
+}
+
+func (s ShowsRepository) GetOne(ctx context.Context, showId uuid.UUID) (entities.Show, error) {
+	s.db.lock.RLock()
+	defer s.db.lock.RUnlock()
+
+	i := s.db.findShow(tenant.FromContext(ctx), showId)
+	if i == -1 {
+		return entities.Show{}, fmt.Errorf("show %s: %w", showId, db.ErrNotFound)
+	}
+
+	return s.db.shows[i], nil
+}
@@ -0,0 +1,202 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"tickets/db"
+	"tickets/entities"
+	"tickets/tenant"
+	"time"
+)
+
+type TicketsRepository struct {
+	db *Database
+}
+
+func NewTicketsRepository(db *Database) TicketsRepository {
+	if db == nil {
+		panic("db is nil")
+	}
+
+	return TicketsRepository{db: db}
+}
+
+func (t TicketsRepository) Add(ctx context.Context, ticket entities.Ticket) error {
+	ticket.Status = entities.TicketStatusConfirmed
+
+	return t.update(ctx, ticket.TicketID, func(stored entities.Ticket) (entities.Ticket, bool, error) {
+		return stored.ApplyVersioned(ticket, time.Now().UTC())
+	})
+}
+
+func (t TicketsRepository) Remove(ctx context.Context, ticket entities.Ticket) error {
+	ticket.Status = entities.TicketStatusCanceled
+
+	return t.update(ctx, ticket.TicketID, func(stored entities.Ticket) (entities.Ticket, bool, error) {
+		return stored.ApplyVersioned(ticket, time.Now().UTC())
+	})
+}
+
+func (t TicketsRepository) UpdateStatus(ctx context.Context, ticketID string, status entities.TicketStatus) error {
+	return t.update(ctx, ticketID, func(stored entities.Ticket) (entities.Ticket, bool, error) {
+		if stored.Status == "" {
+			return stored, false, fmt.Errorf("ticket %s: %w", ticketID, db.ErrNotFound)
+		}
+
+		return stored.ChangeStatus(status, time.Now().UTC())
+	})
+}
+
+func (t TicketsRepository) update(ctx context.Context, ticketID string, updateFn func(stored entities.Ticket) (entities.Ticket, bool, error)) error {
+	t.db.lock.Lock()
+	defer t.db.lock.Unlock()
+
+	tenantID := tenant.FromContext(ctx)
+
+	var stored entities.Ticket
+	i := t.db.findTicket(tenantID, ticketID)
+	if i != -1 {
+		stored = t.db.tickets[i]
+	}
+
+	updated, changed, err := updateFn(stored)
+	if err != nil || !changed {
+		return err
+	}
+	updated.TenantID = tenantID
+
+	if i != -1 {
+		t.db.tickets[i] = updated
+	} else {
+		t.db.tickets = append(t.db.tickets, updated)
+	}
+
+	if updated.Status != stored.Status {
+		t.db.ticketStatusHistory = append(t.db.ticketStatusHistory, entities.TicketStatusChange{
+			TicketID:  ticketID,
+			Status:    updated.Status,
+			ChangedAt: updated.UpdatedAt,
+		})
+	}
+
+	t.db.recordAudit(ctx, tenantID)
+
+	return nil
+}
+
+func (t TicketsRepository) GetOne(ctx context.Context, ticketID string) (entities.Ticket, error) {
+	t.db.lock.RLock()
+	defer t.db.lock.RUnlock()
+
+	i := t.db.findTicket(tenant.FromContext(ctx), ticketID)
+	if i == -1 {
+		return entities.Ticket{}, fmt.Errorf("ticket %s: %w", ticketID, db.ErrNotFound)
+	}
+
+	return t.db.tickets[i], nil
+}
+
+func (t TicketsRepository) GetStatusHistory(ctx context.Context, ticketID string) ([]entities.TicketStatusChange, error) {
+	t.db.lock.RLock()
+	defer t.db.lock.RUnlock()
+
+	history := []entities.TicketStatusChange{}
+	// the history is kept for the stored tickets only, so it's of the tenant if the ticket is
+	if t.db.findTicket(tenant.FromContext(ctx), ticketID) == -1 {
+		return history, nil
+	}
+
+	for _, change := range t.db.ticketStatusHistory {
+		if change.TicketID == ticketID {
+			history = append(history, change)
+		}
+	}
+
+	return history, nil
+}
+
+func (t TicketsRepository) Find(ctx context.Context, filter entities.TicketsFilter) ([]entities.Ticket, error) {
+	t.db.lock.RLock()
+	defer t.db.lock.RUnlock()
+
+	return t.find(tenant.FromContext(ctx), filter), nil
+}
+
+// Export calls fn for every ticket matching the filter, in order.
+func (t TicketsRepository) Export(ctx context.Context, filter entities.TicketsFilter, fn func(entities.Ticket) error) error {
+	t.db.lock.RLock()
+	tickets := t.find(tenant.FromContext(ctx), filter)
+	t.db.lock.RUnlock()
+
+	for _, ticket := range tickets {
+		if err := fn(ticket); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (t TicketsRepository) find(tenantID string, filter entities.TicketsFilter) []entities.Ticket {
+	tickets := []entities.Ticket{}
+	for _, ticket := range t.db.tickets {
+		if ticket.TenantID != tenantID {
+			continue
+		}
+		if filter.Status != "" && ticket.Status != filter.Status {
+			continue
+		}
+		if filter.Status == "" && ticket.DeletedAt != nil {
+			continue
+		}
+		if filter.CustomerEmail != "" && ticket.CustomerEmail != filter.CustomerEmail {
+			continue
+		}
+		if filter.BookingID != "" && ticket.BookingID != filter.BookingID {
+			continue
+		}
+		if !filter.UpdatedAfter.IsZero() && !ticket.UpdatedAt.After(filter.UpdatedAfter) {
+			continue
+		}
+		if !filter.UpdatedBefore.IsZero() && !ticket.UpdatedAt.Before(filter.UpdatedBefore) {
+			continue
+		}
+		if filter.After != nil && compareTickets(ticket.Cursor(), *filter.After, filter.Sort) <= 0 {
+			continue
+		}
+
+		tickets = append(tickets, ticket)
+	}
+
+	slices.SortFunc(tickets, func(a, b entities.Ticket) int {
+		return compareTickets(a.Cursor(), b.Cursor(), filter.Sort)
+	})
+
+	if filter.Limit > 0 {
+		tickets = tickets[:min(filter.Limit, len(tickets))]
+	}
+
+	return tickets
+}
+
+// compareTickets compares tickets in the order of the sort, a negative result means a is before b.
+func compareTickets(a, b entities.TicketsCursor, sort entities.TicketsSort) int {
+	var result int
+	switch sort.Field {
+	case entities.TicketsSortByUpdatedAt:
+		result = a.UpdatedAt.Compare(b.UpdatedAt)
+	case entities.TicketsSortByCustomerEmail:
+		result = strings.Compare(a.CustomerEmail, b.CustomerEmail)
+	}
+	if result == 0 {
+		result = strings.Compare(a.TicketID, b.TicketID)
+	}
+
+	if sort.Descending {
+		return -result
+	}
+
+	return result
+}
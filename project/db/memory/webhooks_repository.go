@@ -0,0 +1,205 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"tickets/db"
+	"tickets/entities"
+	"tickets/tenant"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type WebhooksRepository struct {
+	db *Database
+}
+
+func NewWebhooksRepository(db *Database) WebhooksRepository {
+	if db == nil {
+		panic("db is nil")
+	}
+
+	return WebhooksRepository{db: db}
+}
+
+func (w WebhooksRepository) Add(ctx context.Context, subscription entities.WebhookSubscription) error {
+	w.db.lock.Lock()
+	defer w.db.lock.Unlock()
+
+	if w.db.findWebhook(subscription.ID) != -1 {
+		return nil
+	}
+
+	subscription.TenantID = tenant.FromContext(ctx)
+	subscription.EventTypes = slices.Clone(subscription.EventTypes)
+	w.db.webhooks = append(w.db.webhooks, subscription)
+	w.db.recordAudit(ctx, subscription.TenantID)
+
+	return nil
+}
+
+func (w WebhooksRepository) GetOne(ctx context.Context, subscriptionID uuid.UUID) (entities.WebhookSubscription, error) {
+	w.db.lock.RLock()
+	defer w.db.lock.RUnlock()
+
+	i := w.db.findWebhook(subscriptionID)
+	if i == -1 || w.db.webhooks[i].TenantID != tenant.FromContext(ctx) {
+		return entities.WebhookSubscription{}, db.ErrNotFound
+	}
+
+	return w.db.webhooks[i], nil
+}
+
+func (w WebhooksRepository) FindActiveForEventType(ctx context.Context, eventType string) ([]entities.WebhookSubscription, error) {
+	w.db.lock.RLock()
+	defer w.db.lock.RUnlock()
+
+	tenantID := tenant.FromContext(ctx)
+
+	var subscriptions []entities.WebhookSubscription
+	for _, subscription := range w.db.webhooks {
+		if subscription.TenantID == tenantID && !subscription.Disabled && slices.Contains(subscription.EventTypes, eventType) {
+			subscriptions = append(subscriptions, subscription)
+		}
+	}
+
+	return subscriptions, nil
+}
+
+func (w WebhooksRepository) AddMessages(ctx context.Context, messages []entities.WebhookMessage) error {
+	w.db.lock.Lock()
+	defer w.db.lock.Unlock()
+
+	for _, message := range messages {
+		if w.findMessage(message.Subscription.ID, message.EventID) != -1 {
+			continue
+		}
+		w.db.webhookMessages = append(w.db.webhookMessages, message)
+	}
+
+	return nil
+}
+
+func (w WebhooksRepository) ClaimDueMessages(ctx context.Context, limit int, claimFor time.Duration) ([]entities.WebhookMessage, error) {
+	w.db.lock.Lock()
+	defer w.db.lock.Unlock()
+
+	now := time.Now().UTC()
+
+	var due []int
+	for i, message := range w.db.webhookMessages {
+		if message.CompletedAt != nil || message.NextAttemptAt.After(now) {
+			continue
+		}
+		subscription := w.db.findWebhook(message.Subscription.ID)
+		if subscription == -1 || w.db.webhooks[subscription].Disabled {
+			continue
+		}
+		due = append(due, i)
+	}
+
+	sort.SliceStable(due, func(i, j int) bool {
+		return w.db.webhookMessages[due[i]].NextAttemptAt.Before(w.db.webhookMessages[due[j]].NextAttemptAt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	messages := make([]entities.WebhookMessage, 0, len(due))
+	for _, i := range due {
+		w.db.webhookMessages[i].NextAttemptAt = now.Add(claimFor)
+
+		message := w.db.webhookMessages[i]
+		message.Subscription = w.db.webhooks[w.db.findWebhook(message.Subscription.ID)]
+		messages = append(messages, message)
+	}
+
+	return messages, nil
+}
+
+func (w WebhooksRepository) UpdateMessage(ctx context.Context, message entities.WebhookMessage) error {
+	w.db.lock.Lock()
+	defer w.db.lock.Unlock()
+
+	i := w.findMessage(message.Subscription.ID, message.EventID)
+	if i == -1 {
+		return db.ErrNotFound
+	}
+
+	w.db.webhookMessages[i].Attempts = message.Attempts
+	w.db.webhookMessages[i].NextAttemptAt = message.NextAttemptAt
+	w.db.webhookMessages[i].CompletedAt = message.CompletedAt
+
+	return nil
+}
+
+func (w WebhooksRepository) findMessage(subscriptionID uuid.UUID, eventID string) int {
+	for i, message := range w.db.webhookMessages {
+		if message.Subscription.ID == subscriptionID && message.EventID == eventID {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (w WebhooksRepository) AddDelivery(ctx context.Context, delivery entities.WebhookDelivery) error {
+	w.db.lock.Lock()
+	defer w.db.lock.Unlock()
+
+	w.db.webhookDeliveries = append(w.db.webhookDeliveries, delivery)
+
+	return nil
+}
+
+func (w WebhooksRepository) GetDeliveries(ctx context.Context, subscriptionID uuid.UUID) ([]entities.WebhookDelivery, error) {
+	w.db.lock.RLock()
+	defer w.db.lock.RUnlock()
+
+	if i := w.db.findWebhook(subscriptionID); i == -1 || w.db.webhooks[i].TenantID != tenant.FromContext(ctx) {
+		return nil, nil
+	}
+
+	var deliveries []entities.WebhookDelivery
+	for _, delivery := range w.db.webhookDeliveries {
+		if delivery.SubscriptionID == subscriptionID {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+
+	sort.SliceStable(deliveries, func(i, j int) bool {
+		return deliveries[i].DeliveredAt.After(deliveries[j].DeliveredAt)
+	})
+
+	return deliveries, nil
+}
+
+func (w WebhooksRepository) MarkDeliverySucceeded(ctx context.Context, subscriptionID uuid.UUID) error {
+	w.db.lock.Lock()
+	defer w.db.lock.Unlock()
+
+	if i := w.db.findWebhook(subscriptionID); i != -1 {
+		w.db.webhooks[i].ConsecutiveFailures = 0
+	}
+
+	return nil
+}
+
+func (w WebhooksRepository) MarkDeliveryFailed(ctx context.Context, subscriptionID uuid.UUID, disableAfter int) (bool, error) {
+	w.db.lock.Lock()
+	defer w.db.lock.Unlock()
+
+	i := w.db.findWebhook(subscriptionID)
+	if i == -1 {
+		return false, db.ErrNotFound
+	}
+
+	w.db.webhooks[i].ConsecutiveFailures++
+	if w.db.webhooks[i].ConsecutiveFailures >= disableAfter {
+		w.db.webhooks[i].Disabled = true
+	}
+
+	return w.db.webhooks[i].Disabled, nil
+}
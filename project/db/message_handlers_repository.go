@@ -0,0 +1,86 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"tickets/entities"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// MessageHandlersRepository keeps the state of the message handlers, so every instance of the service sees it.
+type MessageHandlersRepository struct {
+	db *sqlx.DB
+}
+
+func NewMessageHandlersRepository(db *sqlx.DB) MessageHandlersRepository {
+	if db == nil {
+		panic("db is nil")
+	}
+
+	return MessageHandlersRepository{db: db}
+}
+
+func (m MessageHandlersRepository) SetPaused(ctx context.Context, name string, paused bool) error {
+	err := inTenantTx(ctx, m.db, nil, func(tx *sqlx.Tx, tenantID string) error {
+		_, err := tx.ExecContext(
+			ctx,
+			`
+			INSERT INTO
+				message_handlers (name, paused)
+			VALUES
+				($1, $2)
+			ON CONFLICT (name) DO UPDATE SET paused = excluded.paused`,
+			name,
+			paused,
+		)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, tx, tenantID)
+	})
+	if err != nil {
+		return fmt.Errorf("could not set handler %s paused: %w", name, err)
+	}
+
+	return nil
+}
+
+// RecordError saves the error as the last error of the handler, unless a later one is saved already.
+func (m MessageHandlersRepository) RecordError(ctx context.Context, name string, message string, occurredAt time.Time) error {
+	_, err := m.db.ExecContext(
+		ctx,
+		`
+		INSERT INTO
+			message_handlers (name, last_error, last_error_at)
+		VALUES
+			($1, $2, $3)
+		ON CONFLICT (name) DO UPDATE SET
+			last_error = excluded.last_error,
+			last_error_at = excluded.last_error_at
+		WHERE
+			message_handlers.last_error_at IS NULL OR message_handlers.last_error_at < excluded.last_error_at`,
+		name,
+		message,
+		occurredAt,
+	)
+	if err != nil {
+		return fmt.Errorf("could not record error of handler %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// GetStates returns the states of the handlers that were paused or failed at least once.
+func (m MessageHandlersRepository) GetStates(ctx context.Context) ([]entities.MessageHandlerState, error) {
+	var states []entities.MessageHandlerState
+
+	err := m.db.SelectContext(ctx, &states, `SELECT name, paused, last_error, last_error_at FROM message_handlers`)
+	if err != nil {
+		return nil, fmt.Errorf("could not get handler states: %w", err)
+	}
+
+	return states, nil
+}
@@ -0,0 +1,60 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// OrderingLocks locks the ordering keys in all instances with advisory locks, every lock holds a connection.
+type OrderingLocks struct {
+	db *sqlx.DB
+}
+
+func NewOrderingLocks(db *sqlx.DB) OrderingLocks {
+	if db == nil {
+		panic("db is nil")
+	}
+
+	return OrderingLocks{db: db}
+}
+
+func (o OrderingLocks) Lock(ctx context.Context, key string) (func(), error) {
+	conn, err := o.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not get connection: %w", err)
+	}
+
+	_, err = conn.ExecContext(ctx, `SELECT pg_advisory_lock(hashtextextended($1, 0))`, key)
+	if err != nil {
+		// the lock may be taken just before the query is canceled, so the connection is not reused
+		return nil, errors.Join(err, discardConn(conn))
+	}
+
+	return func() {
+		// not the context of the message, so the key is unlocked when it's canceled as well
+		_, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock(hashtextextended($1, 0))`, key)
+		if err != nil {
+			// closing the session unlocks the key
+			_ = discardConn(conn)
+			return
+		}
+		_ = conn.Close()
+	}, nil
+}
+
+// discardConn closes the connection instead of returning it to the pool.
+func discardConn(conn *sql.Conn) error {
+	err := conn.Raw(func(any) error {
+		return driver.ErrBadConn
+	})
+	if errors.Is(err, driver.ErrBadConn) {
+		return nil
+	}
+
+	return err
+}
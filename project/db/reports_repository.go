@@ -0,0 +1,191 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"tickets/entities"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// ReportsRepository keeps the sales reporting read model: the ticket sales, and the shows of the bookings.
+type ReportsRepository struct {
+	db *sqlx.DB
+}
+
+func NewReportsRepository(db *sqlx.DB) ReportsRepository {
+	if db == nil {
+		panic("db is nil")
+	}
+
+	return ReportsRepository{db: db}
+}
+
+// SaveTicketSale merges the sale with the stored one (see entities.TicketSale.Merge).
+func (r ReportsRepository) SaveTicketSale(ctx context.Context, sale entities.TicketSale) error {
+	err := inTenantTx(ctx, r.db, nil, func(tx *sqlx.Tx, tenantID string) error {
+		sale.TenantID = tenantID
+
+		_, err := tx.NamedExecContext(
+			ctx,
+			`
+			INSERT INTO
+				report_ticket_sales (ticket_id, booking_id, price_amount, price_currency, confirmed_at, canceled_at, refunded_at, status, status_at, tenant_id)
+			VALUES
+				(:ticket_id, :booking_id, CAST(NULLIF(:price.amount, '') AS NUMERIC), NULLIF(:price.currency, ''), :confirmed_at, :canceled_at, :refunded_at, NULLIF(:status, ''), :status_at, :tenant_id)
+			ON CONFLICT (tenant_id, ticket_id) DO UPDATE SET
+				booking_id = CASE WHEN report_ticket_sales.booking_id = '' THEN EXCLUDED.booking_id ELSE report_ticket_sales.booking_id END,
+				price_amount = COALESCE(report_ticket_sales.price_amount, EXCLUDED.price_amount),
+				price_currency = COALESCE(report_ticket_sales.price_currency, EXCLUDED.price_currency),
+				confirmed_at = LEAST(report_ticket_sales.confirmed_at, EXCLUDED.confirmed_at),
+				canceled_at = LEAST(report_ticket_sales.canceled_at, EXCLUDED.canceled_at),
+				refunded_at = LEAST(report_ticket_sales.refunded_at, EXCLUDED.refunded_at),
+				status = CASE
+					WHEN report_ticket_sales.status_at IS NULL OR EXCLUDED.status_at > report_ticket_sales.status_at THEN COALESCE(EXCLUDED.status, report_ticket_sales.status)
+					ELSE report_ticket_sales.status
+				END,
+				status_at = GREATEST(report_ticket_sales.status_at, EXCLUDED.status_at)`,
+			sale,
+		)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("could not save ticket sale: %w", err)
+	}
+
+	return nil
+}
+
+// AddBookedShow records the show of the booking, so the tickets of the booking are reported for the show.
+func (r ReportsRepository) AddBookedShow(ctx context.Context, bookingID string, showID uuid.UUID) error {
+	err := inTenantTx(ctx, r.db, nil, func(tx *sqlx.Tx, tenantID string) error {
+		_, err := tx.ExecContext(
+			ctx,
+			`INSERT INTO report_bookings (booking_id, show_id, tenant_id) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`,
+			bookingID,
+			showID,
+			tenantID,
+		)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("could not add booked show: %w", err)
+	}
+
+	return nil
+}
+
+// GetShowReport returns the sales of the tickets of the show, without the tickets of the unknown bookings.
+func (r ReportsRepository) GetShowReport(ctx context.Context, showID uuid.UUID) (entities.ShowSalesReport, error) {
+	var figures []entities.SalesFigures
+
+	err := inTenantTx(ctx, r.db, &sql.TxOptions{ReadOnly: true}, func(tx *sqlx.Tx, tenantID string) error {
+		query, args := salesFiguresQuery(tenantID, entities.SalesReportFilter{ShowID: &showID}, false)
+		return tx.SelectContext(ctx, &figures, query, args...)
+	})
+	if err != nil {
+		return entities.ShowSalesReport{}, fmt.Errorf("could not get show sales: %w", err)
+	}
+
+	return entities.ShowSalesReport{
+		ShowID:      showID,
+		SalesReport: entities.NewSalesReport(figures),
+	}, nil
+}
+
+type dailySalesFigures struct {
+	Day string `db:"day"`
+	entities.SalesFigures
+}
+
+// GetDailyReports returns the sales of each day with any sales, from the oldest day.
+func (r ReportsRepository) GetDailyReports(ctx context.Context, filter entities.SalesReportFilter) ([]entities.DailySalesReport, error) {
+	var figures []dailySalesFigures
+
+	err := inTenantTx(ctx, r.db, &sql.TxOptions{ReadOnly: true}, func(tx *sqlx.Tx, tenantID string) error {
+		query, args := salesFiguresQuery(tenantID, filter, true)
+		return tx.SelectContext(ctx, &figures, query, args...)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not get daily sales: %w", err)
+	}
+
+	reports := []entities.DailySalesReport{}
+	for i := 0; i < len(figures); {
+		day := figures[i].Day
+
+		var dayFigures []entities.SalesFigures
+		for ; i < len(figures) && figures[i].Day == day; i++ {
+			dayFigures = append(dayFigures, figures[i].SalesFigures)
+		}
+
+		reports = append(reports, entities.DailySalesReport{
+			Date:        day,
+			SalesReport: entities.NewSalesReport(dayFigures),
+		})
+	}
+
+	return reports, nil
+}
+
+// salesEvents has a row for every confirmation, cancellation and refund of the sales of the $1 tenant.
+const salesEvents = `
+	SELECT booking_id, price_currency, (confirmed_at AT TIME ZONE 'UTC')::DATE AS day,
+		1 AS sold, 0 AS canceled, 0 AS refunded, COALESCE(price_amount, 0) AS gross, COALESCE(price_amount, 0) AS net
+	FROM report_ticket_sales WHERE confirmed_at IS NOT NULL AND tenant_id = $1
+	UNION ALL
+	SELECT booking_id, price_currency, (canceled_at AT TIME ZONE 'UTC')::DATE AS day,
+		0, 1, 0, 0, CASE WHEN confirmed_at IS NOT NULL THEN -COALESCE(price_amount, 0) ELSE 0 END
+	FROM report_ticket_sales WHERE canceled_at IS NOT NULL AND status = 'canceled' AND tenant_id = $1
+	UNION ALL
+	SELECT booking_id, price_currency, (refunded_at AT TIME ZONE 'UTC')::DATE AS day,
+		0, 0, 1, 0, 0
+	FROM report_ticket_sales WHERE refunded_at IS NOT NULL AND tenant_id = $1`
+
+// salesFiguresQuery builds the query of the tenant's sales figures per currency (and per day, if byDay is set).
+func salesFiguresQuery(tenantID string, filter entities.SalesReportFilter, byDay bool) (string, []any) {
+	var conditions []string
+	// the first argument is the tenant of the salesEvents
+	args := []any{tenantID}
+
+	addArg := func(arg any) string {
+		args = append(args, arg)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	from := `(` + salesEvents + `) AS e`
+	if filter.ShowID != nil {
+		from += ` JOIN report_bookings b ON b.booking_id = e.booking_id AND b.tenant_id = $1`
+		conditions = append(conditions, "b.show_id = "+addArg(*filter.ShowID))
+	}
+	if !filter.From.IsZero() {
+		conditions = append(conditions, "e.day >= "+addArg(entities.SaleDay(filter.From))+"::DATE")
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, "e.day <= "+addArg(entities.SaleDay(filter.To))+"::DATE")
+	}
+
+	columns := `
+		COALESCE(e.price_currency, '')::TEXT AS currency,
+		SUM(e.sold) AS tickets_sold,
+		SUM(e.canceled) AS tickets_canceled,
+		SUM(e.refunded) AS tickets_refunded,
+		SUM(e.gross)::NUMERIC(12, 2)::TEXT AS gross,
+		SUM(e.net)::NUMERIC(12, 2)::TEXT AS net`
+	groupBy := ` GROUP BY e.price_currency ORDER BY currency`
+
+	if byDay {
+		columns = `e.day::TEXT AS day,` + columns
+		groupBy = ` GROUP BY e.day, e.price_currency ORDER BY e.day, currency`
+	}
+
+	query := `SELECT ` + columns + ` FROM ` + from
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+
+	return query + groupBy, args
+}
@@ -0,0 +1,162 @@
+//go:build integration
+
+package db_test
+
+import (
+	"context"
+	"testing"
+	ticketsDb "tickets/db"
+	"tickets/entities"
+	"tickets/tenant"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportsRepository(t *testing.T) {
+	ctx := context.Background()
+	sqlxDb := GetDb()
+
+	err := ticketsDb.InitializeDatabaseSchema(sqlxDb)
+	require.NoError(t, err)
+
+	repo := ticketsDb.NewReportsRepository(sqlxDb)
+
+	showID := uuid.New()
+	bookingID := uuid.NewString()
+
+	monday := time.Date(2024, 3, 4, 20, 0, 0, 0, time.UTC)
+	tuesday := monday.Add(24 * time.Hour)
+
+	soldTicketID := uuid.NewString()
+	refundedTicketID := uuid.NewString()
+
+	sales := []entities.TicketSale{
+		// the refund and the cancellation are handled before the confirmation
+		{TicketID: refundedTicketID, RefundedAt: &tuesday},
+		{TicketID: refundedTicketID, Price: entities.Money{Amount: "20.00", Currency: "EUR"}, CanceledAt: &tuesday},
+		{TicketID: refundedTicketID, BookingID: bookingID, Price: entities.Money{Amount: "20.00", Currency: "EUR"}, ConfirmedAt: &monday},
+		{TicketID: soldTicketID, BookingID: bookingID, Price: entities.Money{Amount: "30.50", Currency: "EUR"}, ConfirmedAt: &monday},
+		// redelivered confirmation
+		{TicketID: soldTicketID, BookingID: bookingID, Price: entities.Money{Amount: "30.50", Currency: "EUR"}, ConfirmedAt: &tuesday},
+	}
+	for _, sale := range sales {
+		require.NoError(t, repo.SaveTicketSale(ctx, sale))
+	}
+
+	showReport, err := repo.GetShowReport(ctx, showID)
+	require.NoError(t, err)
+	assert.Zero(t, showReport.TicketsSold, "tickets of the booking shouldn't be counted before the show is known")
+
+	require.NoError(t, repo.AddBookedShow(ctx, bookingID, showID))
+
+	showReport, err = repo.GetShowReport(ctx, showID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.SalesReport{
+		TicketsSold:     2,
+		TicketsCanceled: 1,
+		TicketsRefunded: 1,
+		Revenue: []entities.Revenue{
+			{
+				Gross: entities.Money{Amount: "50.50", Currency: "EUR"},
+				Net:   entities.Money{Amount: "30.50", Currency: "EUR"},
+			},
+		},
+	}, showReport.SalesReport)
+
+	daily, err := repo.GetDailyReports(ctx, entities.SalesReportFilter{ShowID: &showID})
+	require.NoError(t, err)
+	assert.Equal(t, []entities.DailySalesReport{
+		{
+			Date: "2024-03-04",
+			SalesReport: entities.SalesReport{
+				TicketsSold: 2,
+				Revenue: []entities.Revenue{
+					{
+						Gross: entities.Money{Amount: "50.50", Currency: "EUR"},
+						Net:   entities.Money{Amount: "50.50", Currency: "EUR"},
+					},
+				},
+			},
+		},
+		{
+			Date: "2024-03-05",
+			SalesReport: entities.SalesReport{
+				TicketsCanceled: 1,
+				TicketsRefunded: 1,
+				Revenue: []entities.Revenue{
+					{
+						Gross: entities.Money{Amount: "0.00", Currency: "EUR"},
+						Net:   entities.Money{Amount: "-20.00", Currency: "EUR"},
+					},
+				},
+			},
+		},
+	}, daily)
+
+	daily, err = repo.GetDailyReports(ctx, entities.SalesReportFilter{ShowID: &showID, From: tuesday, To: tuesday})
+	require.NoError(t, err)
+	require.Len(t, daily, 1)
+	assert.Equal(t, "2024-03-05", daily[0].Date)
+
+	t.Run("status", func(t *testing.T) {
+		showID := uuid.New()
+		bookingID := uuid.NewString()
+		wednesday := tuesday.Add(24 * time.Hour)
+
+		reconfirmedTicketID := uuid.NewString()
+		unconfirmedTicketID := uuid.NewString()
+
+		price := entities.Money{Amount: "10.00", Currency: "EUR"}
+
+		sales := []entities.TicketSale{
+			{TicketID: reconfirmedTicketID, BookingID: bookingID, Price: price, ConfirmedAt: &monday, Status: entities.TicketStatusConfirmed, StatusAt: &monday},
+			// the confirmation after the cancellation is handled before it
+			{TicketID: reconfirmedTicketID, BookingID: bookingID, Price: price, ConfirmedAt: &wednesday, Status: entities.TicketStatusConfirmed, StatusAt: &wednesday},
+			{TicketID: reconfirmedTicketID, Price: price, CanceledAt: &tuesday, Status: entities.TicketStatusCanceled, StatusAt: &tuesday},
+			// the confirmation is not handled yet
+			{TicketID: unconfirmedTicketID, BookingID: bookingID, Price: price, CanceledAt: &tuesday, Status: entities.TicketStatusCanceled, StatusAt: &tuesday},
+		}
+		for _, sale := range sales {
+			require.NoError(t, repo.SaveTicketSale(ctx, sale))
+		}
+		require.NoError(t, repo.AddBookedShow(ctx, bookingID, showID))
+
+		showReport, err := repo.GetShowReport(ctx, showID)
+		require.NoError(t, err)
+		assert.Equal(t, entities.SalesReport{
+			TicketsSold:     1,
+			TicketsCanceled: 1,
+			Revenue: []entities.Revenue{
+				{
+					Gross: entities.Money{Amount: "10.00", Currency: "EUR"},
+					Net:   entities.Money{Amount: "10.00", Currency: "EUR"},
+				},
+			},
+		}, showReport.SalesReport, "the confirmed ticket should be sold, and the unconfirmed one should not lower the revenue")
+	})
+
+	t.Run("tenants", func(t *testing.T) {
+		acme := tenant.WithID(ctx, "acme-"+uuid.NewString()[:8])
+		other := tenant.WithID(ctx, "other-"+uuid.NewString()[:8])
+
+		// the same IDs in both tenants
+		showID := uuid.New()
+		bookingID := uuid.NewString()
+		ticketID := uuid.NewString()
+
+		for _, tenantCtx := range []context.Context{acme, other} {
+			sale := entities.TicketSale{TicketID: ticketID, BookingID: bookingID, Price: entities.Money{Amount: "10.00", Currency: "EUR"}, ConfirmedAt: &monday}
+			require.NoError(t, repo.SaveTicketSale(tenantCtx, sale))
+			require.NoError(t, repo.AddBookedShow(tenantCtx, bookingID, showID))
+		}
+
+		for _, tenantCtx := range []context.Context{acme, other} {
+			showReport, err := repo.GetShowReport(tenantCtx, showID)
+			require.NoError(t, err)
+			assert.Equal(t, 1, showReport.TicketsSold)
+		}
+	})
+}
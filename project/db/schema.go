@@ -1,8 +1,11 @@
 package db
 
 import (
+	"context"
 	"fmt"
+	"strings"
 	"tickets/message/outbox"
+	"tickets/tenant"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -32,11 +35,160 @@ func InitializeDatabaseSchema(db *sqlx.DB) error {
 			customer_email VARCHAR(255) NOT NULL,
 			FOREIGN KEY (show_id) REFERENCES shows(id)
 		);
+		CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+			id UUID PRIMARY KEY,
+			url TEXT NOT NULL,
+			event_types TEXT[] NOT NULL,
+			secret VARCHAR(255) NOT NULL,
+			disabled BOOLEAN NOT NULL DEFAULT FALSE,
+			consecutive_failures INTEGER NOT NULL DEFAULT 0,
+			created_at timestamptz NOT NULL
+		);
+		CREATE TABLE IF NOT EXISTS webhook_deliveries (
+			id UUID PRIMARY KEY,
+			subscription_id UUID NOT NULL,
+			event_id VARCHAR(255) NOT NULL,
+			event_type VARCHAR(255) NOT NULL,
+			attempt INTEGER NOT NULL,
+			status_code INTEGER NOT NULL,
+			error TEXT NOT NULL,
+			succeeded BOOLEAN NOT NULL,
+			delivered_at timestamptz NOT NULL,
+			FOREIGN KEY (subscription_id) REFERENCES webhook_subscriptions(id)
+		);
+		CREATE TABLE IF NOT EXISTS webhook_messages (
+			subscription_id UUID NOT NULL,
+			event_id VARCHAR(255) NOT NULL,
+			event_type VARCHAR(255) NOT NULL,
+			body BYTEA NOT NULL,
+			correlation_id VARCHAR(255) NOT NULL,
+			attempts INTEGER NOT NULL,
+			next_attempt_at timestamptz NOT NULL,
+			completed_at timestamptz,
+			PRIMARY KEY (subscription_id, event_id),
+			FOREIGN KEY (subscription_id) REFERENCES webhook_subscriptions(id)
+		);
+		CREATE INDEX IF NOT EXISTS webhook_messages_due_idx ON webhook_messages (next_attempt_at) WHERE completed_at IS NULL;
+		CREATE TABLE IF NOT EXISTS sheet_rows (
+			sheet_name VARCHAR(255) NOT NULL,
+			ticket_id UUID NOT NULL,
+			idempotency_key VARCHAR(255) NOT NULL,
+			added_at timestamptz NOT NULL,
+			PRIMARY KEY (sheet_name, ticket_id, idempotency_key)
+		);
+		ALTER TABLE sheet_rows ADD COLUMN IF NOT EXISTS columns TEXT[] NOT NULL DEFAULT '{}';
+		-- rows recorded before the sheet sync were appended right away
+		ALTER TABLE sheet_rows ADD COLUMN IF NOT EXISTS appended_at timestamptz DEFAULT NOW();
+		ALTER TABLE sheet_rows ALTER COLUMN appended_at DROP DEFAULT;
+		CREATE INDEX IF NOT EXISTS sheet_rows_pending_idx ON sheet_rows (added_at) WHERE appended_at IS NULL;
+		ALTER TABLE sheet_rows ADD COLUMN IF NOT EXISTS claimed_until timestamptz;
+		ALTER TABLE sheet_rows ADD COLUMN IF NOT EXISTS rejected_at timestamptz;
+		ALTER TABLE sheet_rows ADD COLUMN IF NOT EXISTS rejection TEXT NOT NULL DEFAULT '';
+		ALTER TABLE sheet_rows ADD COLUMN IF NOT EXISTS erased_at timestamptz;
+		ALTER TABLE sheet_rows ADD COLUMN IF NOT EXISTS erased_pseudonym VARCHAR(255) NOT NULL DEFAULT '';
+		ALTER TABLE tickets ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 0;
+		ALTER TABLE tickets ADD COLUMN IF NOT EXISTS booking_id VARCHAR(255) NOT NULL DEFAULT '';
+		ALTER TABLE tickets ADD COLUMN IF NOT EXISTS status VARCHAR(32) NOT NULL DEFAULT 'confirmed';
+		ALTER TABLE tickets ADD COLUMN IF NOT EXISTS updated_at timestamptz NOT NULL DEFAULT NOW();
+		ALTER TABLE tickets ADD COLUMN IF NOT EXISTS deleted_at timestamptz;
+		CREATE INDEX IF NOT EXISTS tickets_status_idx ON tickets (status);
+		CREATE INDEX IF NOT EXISTS tickets_customer_email_idx ON tickets (customer_email, ticket_id);
+		CREATE INDEX IF NOT EXISTS tickets_booking_id_idx ON tickets (booking_id);
+		CREATE INDEX IF NOT EXISTS tickets_updated_at_idx ON tickets (updated_at, ticket_id);
+		CREATE TABLE IF NOT EXISTS ticket_status_history (
+			id BIGSERIAL PRIMARY KEY,
+			ticket_id UUID NOT NULL,
+			status VARCHAR(32) NOT NULL,
+			changed_at timestamptz NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS ticket_status_history_ticket_id_idx ON ticket_status_history (ticket_id, id);
+		CREATE TABLE IF NOT EXISTS ticket_versions (
+			tenant_id VARCHAR(64) NOT NULL,
+			ticket_id UUID NOT NULL,
+			version BIGINT NOT NULL,
+			PRIMARY KEY (tenant_id, ticket_id)
+		);
+		CREATE TABLE IF NOT EXISTS report_ticket_sales (
+			ticket_id UUID PRIMARY KEY,
+			booking_id VARCHAR(255) NOT NULL,
+			price_amount NUMERIC(10, 2),
+			price_currency CHAR(3),
+			confirmed_at timestamptz,
+			canceled_at timestamptz,
+			refunded_at timestamptz
+		);
+		CREATE INDEX IF NOT EXISTS report_ticket_sales_booking_id_idx ON report_ticket_sales (booking_id);
+		ALTER TABLE report_ticket_sales ADD COLUMN IF NOT EXISTS status VARCHAR(32);
+		ALTER TABLE report_ticket_sales ADD COLUMN IF NOT EXISTS status_at timestamptz;
+		-- the sales saved before the status was tracked
+		UPDATE report_ticket_sales SET
+			status = CASE WHEN canceled_at IS NOT NULL THEN 'canceled' ELSE 'confirmed' END,
+			status_at = COALESCE(canceled_at, confirmed_at)
+		WHERE status IS NULL AND COALESCE(canceled_at, confirmed_at) IS NOT NULL;
+		CREATE TABLE IF NOT EXISTS report_bookings (
+			booking_id VARCHAR(255) PRIMARY KEY,
+			show_id UUID NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS report_bookings_show_id_idx ON report_bookings (show_id);
+		CREATE TABLE IF NOT EXISTS api_keys (
+			id UUID PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			role VARCHAR(32) NOT NULL,
+			prefix VARCHAR(32) NOT NULL,
+			key_hash CHAR(64) NOT NULL UNIQUE,
+			created_at timestamptz NOT NULL,
+			revoked_at timestamptz
+		);
+		-- the keys created before the tenants were introduced belong to the default tenant
+		ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+		CREATE TABLE IF NOT EXISTS message_handlers (
+			name VARCHAR(255) PRIMARY KEY,
+			paused BOOLEAN NOT NULL DEFAULT FALSE,
+			last_error TEXT NOT NULL DEFAULT '',
+			last_error_at timestamptz
+		);
+		CREATE TABLE IF NOT EXISTS audit_records (
+			id UUID PRIMARY KEY,
+			occurred_at timestamptz NOT NULL,
+			tenant_id VARCHAR(64) NOT NULL,
+			actor VARCHAR(255) NOT NULL,
+			action VARCHAR(64) NOT NULL,
+			target_ids TEXT[] NOT NULL,
+			correlation_id VARCHAR(255) NOT NULL,
+			summary TEXT NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS audit_records_occurred_at_idx ON audit_records (tenant_id, occurred_at);
+		CREATE INDEX IF NOT EXISTS audit_records_target_ids_idx ON audit_records USING GIN (target_ids);
+		-- the audit log is append-only, the records can't be changed or removed
+		CREATE OR REPLACE FUNCTION reject_audit_records_change() RETURNS trigger AS $$
+		BEGIN
+			RAISE EXCEPTION 'audit records are append-only';
+		END;
+		$$ LANGUAGE plpgsql;
+		DROP TRIGGER IF EXISTS audit_records_append_only ON audit_records;
+		CREATE TRIGGER audit_records_append_only
+			BEFORE UPDATE OR DELETE OR TRUNCATE ON audit_records
+			FOR EACH STATEMENT EXECUTE FUNCTION reject_audit_records_change();
 	`)
 	if err != nil {
 		return fmt.Errorf("could not initialize database schema: %w", err)
 	}
 
+	err = initializeTenantSchema(db)
+	if err != nil {
+		return err
+	}
+
+	err = migrateTenantKeys(db)
+	if err != nil {
+		return err
+	}
+
+	err = migrateSheetRows(db)
+	if err != nil {
+		return err
+	}
+
 	err = outbox.InitializeSchema(db.DB)
 	if err != nil {
 		return fmt.Errorf("could not initialize outbox schema: %w", err)
@@ -44,3 +196,77 @@ func InitializeDatabaseSchema(db *sqlx.DB) error {
 
 	return nil
 }
+
+// tenantPrimaryKeys are the primary keys of the tenantTables, which were unique in all tenants before the tenants were introduced.
+var tenantPrimaryKeys = []struct {
+	Table   string
+	Columns string
+}{
+	{"tickets", "tenant_id, ticket_id"},
+	{"report_ticket_sales", "tenant_id, ticket_id"},
+	{"report_bookings", "tenant_id, booking_id"},
+	{"sheet_rows", "tenant_id, sheet_name, ticket_id, idempotency_key"},
+}
+
+// migrateTenantKeys makes the keys of the tenantTables unique within the tenant, so the IDs of one tenant don't clash with another's.
+func migrateTenantKeys(db *sqlx.DB) error {
+	statements := []string{
+		`CREATE UNIQUE INDEX IF NOT EXISTS shows_tenant_dead_nation_id_idx ON shows (tenant_id, dead_nation_id);`,
+		`ALTER TABLE shows DROP CONSTRAINT IF EXISTS shows_dead_nation_id_key;`,
+	}
+	for _, key := range tenantPrimaryKeys {
+		statements = append(statements, fmt.Sprintf(`
+			DO $$
+			BEGIN
+				IF NOT EXISTS (
+					SELECT 1 FROM information_schema.key_column_usage
+					WHERE table_schema = current_schema() AND constraint_name = '%[1]s_pkey' AND column_name = 'tenant_id'
+				) THEN
+					ALTER TABLE %[1]s DROP CONSTRAINT %[1]s_pkey, ADD PRIMARY KEY (%[2]s);
+				END IF;
+			END $$;`,
+			key.Table,
+			key.Columns,
+		))
+	}
+
+	_, err := db.Exec(strings.Join(statements, "\n"))
+	if err != nil {
+		return fmt.Errorf("could not migrate tenant keys: %w", err)
+	}
+
+	return nil
+}
+
+// migrateSheetRows moves the rows of the sheets prefixed with the tenant (see tenant.NameFor) to the tenant of their ticket.
+func migrateSheetRows(db *sqlx.DB) error {
+	err := inAllTenantsTx(context.Background(), db, nil, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(
+			`
+			UPDATE
+				sheet_rows r
+			SET
+				tenant_id = t.tenant_id,
+				sheet_name = substr(r.sheet_name, length(t.tenant_id) + 2)
+			FROM
+				tickets t
+			WHERE
+				t.ticket_id = r.ticket_id
+				AND r.tenant_id = $1
+				AND t.tenant_id <> $1
+				AND starts_with(r.sheet_name, t.tenant_id || '-')`,
+			tenant.Default,
+		)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(`CREATE INDEX IF NOT EXISTS sheet_rows_tenant_sheet_idx ON sheet_rows (tenant_id, sheet_name)`)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("could not migrate sheet rows: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,319 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"tickets/entities"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type SheetRowsRepository struct {
+	db *sqlx.DB
+}
+
+func NewSheetRowsRepository(db *sqlx.DB) SheetRowsRepository {
+	if db == nil {
+		panic("db is nil")
+	}
+
+	return SheetRowsRepository{db: db}
+}
+
+// dbSheetRow is the row of the sheet_rows table, the columns are stored as an array.
+type dbSheetRow struct {
+	entities.SheetRow
+	Columns pq.StringArray `db:"columns"`
+}
+
+func (r dbSheetRow) toEntity() entities.SheetRow {
+	row := r.SheetRow
+	row.Columns = r.Columns
+
+	return row
+}
+
+const sheetRowColumns = `
+	tenant_id,
+	sheet_name,
+	ticket_id,
+	idempotency_key,
+	columns,
+	added_at,
+	appended_at,
+	rejected_at,
+	rejection,
+	erased_at,
+	erased_pseudonym`
+
+// Add records the row to append to the sheet, it returns false when it was already recorded.
+func (s SheetRowsRepository) Add(ctx context.Context, row entities.SheetRow) (bool, error) {
+	var added bool
+
+	err := inTenantTx(ctx, s.db, nil, func(tx *sqlx.Tx, tenantID string) error {
+		row.TenantID = tenantID
+
+		res, err := tx.NamedExecContext(
+			ctx,
+			`
+			INSERT INTO
+				sheet_rows (tenant_id, sheet_name, ticket_id, idempotency_key, columns, added_at)
+			VALUES
+				(:tenant_id, :sheet_name, :ticket_id, :idempotency_key, :columns, NOW())
+			ON CONFLICT DO NOTHING`,
+			dbSheetRow{SheetRow: row, Columns: row.Columns},
+		)
+		if err != nil {
+			return err
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("could not get affected rows: %w", err)
+		}
+
+		added = affected > 0
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("could not save sheet row: %w", err)
+	}
+
+	return added, nil
+}
+
+// ClaimPending claims the rows of all tenants with SKIP LOCKED, so replicas claim different rows.
+func (s SheetRowsRepository) ClaimPending(ctx context.Context, limit int, claimFor time.Duration) ([]entities.SheetRow, error) {
+	var rows []dbSheetRow
+
+	// the rows of all tenants are appended by the sheet sync
+	err := inAllTenantsTx(ctx, s.db, nil, func(tx *sqlx.Tx) error {
+		return tx.SelectContext(
+			ctx,
+			&rows,
+			`
+				WITH claimed AS (
+					UPDATE
+						sheet_rows
+					SET
+						claimed_until = NOW() + make_interval(secs => $2)
+					WHERE
+						(tenant_id, sheet_name, ticket_id, idempotency_key) IN (
+							SELECT
+								tenant_id, sheet_name, ticket_id, idempotency_key
+							FROM
+								sheet_rows
+							WHERE
+								appended_at IS NULL
+								AND rejected_at IS NULL
+								AND (claimed_until IS NULL OR claimed_until < NOW())
+							ORDER BY
+								added_at
+							LIMIT $1
+							FOR UPDATE SKIP LOCKED
+						)
+					RETURNING
+						`+sheetRowColumns+`
+				)
+				SELECT
+					*
+				FROM
+					claimed
+				ORDER BY
+					added_at
+			`,
+			limit,
+			claimFor.Seconds(),
+		)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not claim pending sheet rows: %w", err)
+	}
+
+	return toSheetRows(rows), nil
+}
+
+func (s SheetRowsRepository) MarkAppended(ctx context.Context, row entities.SheetRow) error {
+	err := inAllTenantsTx(ctx, s.db, nil, func(tx *sqlx.Tx) error {
+		_, err := tx.ExecContext(
+			ctx,
+			`
+			UPDATE
+				sheet_rows
+			SET
+				appended_at = NOW(), claimed_until = NULL
+			WHERE
+				tenant_id = $1 AND sheet_name = $2 AND ticket_id = $3 AND idempotency_key = $4`,
+			row.TenantID,
+			row.SheetName,
+			row.TicketID,
+			row.IdempotencyKey,
+		)
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("could not mark sheet row as appended: %w", err)
+	}
+
+	return nil
+}
+
+func (s SheetRowsRepository) MarkRejected(ctx context.Context, row entities.SheetRow, rejection string) error {
+	err := inAllTenantsTx(ctx, s.db, nil, func(tx *sqlx.Tx) error {
+		_, err := tx.ExecContext(
+			ctx,
+			`
+			UPDATE
+				sheet_rows
+			SET
+				rejected_at = NOW(), rejection = $5, claimed_until = NULL
+			WHERE
+				tenant_id = $1 AND sheet_name = $2 AND ticket_id = $3 AND idempotency_key = $4`,
+			row.TenantID,
+			row.SheetName,
+			row.TicketID,
+			row.IdempotencyKey,
+			rejection,
+		)
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("could not mark sheet row as rejected: %w", err)
+	}
+
+	return nil
+}
+
+func (s SheetRowsRepository) ReleaseClaimed(ctx context.Context, rows []entities.SheetRow) error {
+	var tenantIDs, sheetNames, ticketIDs, idempotencyKeys pq.StringArray
+	for _, row := range rows {
+		tenantIDs = append(tenantIDs, row.TenantID)
+		sheetNames = append(sheetNames, row.SheetName)
+		ticketIDs = append(ticketIDs, row.TicketID)
+		idempotencyKeys = append(idempotencyKeys, row.IdempotencyKey)
+	}
+
+	err := inAllTenantsTx(ctx, s.db, nil, func(tx *sqlx.Tx) error {
+		_, err := tx.ExecContext(
+			ctx,
+			`
+			UPDATE
+				sheet_rows
+			SET
+				claimed_until = NULL
+			WHERE
+				(tenant_id, sheet_name, ticket_id, idempotency_key) IN (
+					SELECT * FROM unnest($1::text[], $2::text[], $3::uuid[], $4::text[])
+				)`,
+			tenantIDs,
+			sheetNames,
+			ticketIDs,
+			idempotencyKeys,
+		)
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("could not release claimed sheet rows: %w", err)
+	}
+
+	return nil
+}
+
+// GetAppended returns the rows of the tenant from the context recorded as appended to the sheet.
+func (s SheetRowsRepository) GetAppended(ctx context.Context, sheetName string) ([]entities.SheetRow, error) {
+	var rows []dbSheetRow
+
+	err := inTenantTx(ctx, s.db, &sql.TxOptions{ReadOnly: true}, func(tx *sqlx.Tx, tenantID string) error {
+		return tx.SelectContext(
+			ctx,
+			&rows,
+			`
+				SELECT
+					`+sheetRowColumns+`
+				FROM
+					sheet_rows
+				WHERE
+					tenant_id = $1 AND sheet_name = $2 AND appended_at IS NOT NULL
+				ORDER BY
+					appended_at
+			`,
+			tenantID,
+			sheetName,
+		)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not get appended sheet rows: %w", err)
+	}
+
+	return toSheetRows(rows), nil
+}
+
+// GetRejected returns the rows of the tenant from the context the API rejected, so they are not appended to the sheet.
+func (s SheetRowsRepository) GetRejected(ctx context.Context, sheetName string) ([]entities.SheetRow, error) {
+	var rows []dbSheetRow
+
+	err := inTenantTx(ctx, s.db, &sql.TxOptions{ReadOnly: true}, func(tx *sqlx.Tx, tenantID string) error {
+		return tx.SelectContext(
+			ctx,
+			&rows,
+			`
+				SELECT
+					`+sheetRowColumns+`
+				FROM
+					sheet_rows
+				WHERE
+					tenant_id = $1 AND sheet_name = $2 AND rejected_at IS NOT NULL
+				ORDER BY
+					rejected_at
+			`,
+			tenantID,
+			sheetName,
+		)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not get rejected sheet rows: %w", err)
+	}
+
+	return toSheetRows(rows), nil
+}
+
+// MarkErased marks the rows of the tickets as erased, their columns were pseudonymised by CustomersRepository.Erase.
+func (s SheetRowsRepository) MarkErased(ctx context.Context, ticketIDs []string, pseudonym string) error {
+	err := inTenantTx(ctx, s.db, nil, func(tx *sqlx.Tx, tenantID string) error {
+		_, err := tx.ExecContext(
+			ctx,
+			`
+			UPDATE
+				sheet_rows
+			SET
+				erased_at = COALESCE(erased_at, NOW()), erased_pseudonym = $2
+			WHERE
+				ticket_id = ANY($1::uuid[]) AND tenant_id = $3`,
+			pq.StringArray(ticketIDs),
+			pseudonym,
+			tenantID,
+		)
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("could not mark sheet rows as erased: %w", err)
+	}
+
+	return nil
+}
+
+func toSheetRows(rows []dbSheetRow) []entities.SheetRow {
+	sheetRows := make([]entities.SheetRow, 0, len(rows))
+	for _, row := range rows {
+		sheetRows = append(sheetRows, row.toEntity())
+	}
+
+	return sheetRows
+}
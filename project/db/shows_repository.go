@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"tickets/entities"
 
@@ -22,16 +23,25 @@ func NewShowsRepository(db *sqlx.DB) ShowsRepository {
 }
 
 func (s ShowsRepository) Add(ctx context.Context, show entities.Show) error {
-	_, err := s.db.NamedExecContext(
-		ctx,
-		`
-		INSERT INTO
-			shows (id, dead_nation_id, number_of_tickets, start_time, title, venue)
-		VALUES
-			(:id, :dead_nation_id, :number_of_tickets, :start_time, :title, :venue)
-		ON CONFLICT DO NOTHING`,
-		show,
-	)
+	err := inTenantTx(ctx, s.db, nil, func(tx *sqlx.Tx, tenantID string) error {
+		show.TenantID = tenantID
+
+		_, err := tx.NamedExecContext(
+			ctx,
+			`
+			INSERT INTO
+				shows (id, dead_nation_id, number_of_tickets, start_time, title, venue, tenant_id)
+			VALUES
+				(:id, :dead_nation_id, :number_of_tickets, :start_time, :title, :venue, :tenant_id)
+			ON CONFLICT DO NOTHING`,
+			show,
+		)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, tx, tenantID)
+	})
 	if err != nil {
 		return fmt.Errorf("could not save show: %w", err)
 	}
@@ -42,24 +52,28 @@ func (s ShowsRepository) Add(ctx context.Context, show entities.Show) error {
 func (s ShowsRepository) GetOne(ctx context.Context, showId uuid.UUID) (entities.Show, error) {
 	var result entities.Show
 
-	err := s.db.GetContext(
-		ctx,
-		&result,
-		`
-			SELECT
-				id,
-				dead_nation_id,
-				number_of_tickets,
-				start_time,
-				title,
-				venue
-			FROM
-				shows
-			WHERE
-				id = $1
-		`,
-		showId,
-	)
+	err := inTenantTx(ctx, s.db, &sql.TxOptions{ReadOnly: true}, func(tx *sqlx.Tx, tenantID string) error {
+		return tx.GetContext(
+			ctx,
+			&result,
+			`
+				SELECT
+					id,
+					dead_nation_id,
+					number_of_tickets,
+					start_time,
+					title,
+					venue,
+					tenant_id
+				FROM
+					shows
+				WHERE
+					id = $1 AND tenant_id = $2
+			`,
+			showId,
+			tenantID,
+		)
+	})
 
 	if err != nil {
 		return entities.Show{}, err
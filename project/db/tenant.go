@@ -0,0 +1,123 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"tickets/tenant"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// tenantTables are isolated with row-level security, the queries filter by the tenant as well for the superusers.
+var tenantTables = []string{
+	"shows",
+	"bookings",
+	"tickets",
+	"ticket_status_history",
+	"ticket_versions",
+	"report_ticket_sales",
+	"report_bookings",
+	"audit_records",
+	"webhook_subscriptions",
+	"sheet_rows",
+}
+
+// tenantSetting is the setting with the tenant of the transaction, checked by the row-level security policies.
+const tenantSetting = "tickets.tenant_id"
+
+// allTenants is the tenant setting of the background jobs, it's not a valid tenant ID.
+const allTenants = "*"
+
+// initializeTenantSchema adds the tenant to the tenantTables, the existing rows belong to the default tenant.
+func initializeTenantSchema(db *sqlx.DB) error {
+	var statements []string
+	for _, table := range tenantTables {
+		statements = append(statements, fmt.Sprintf(`
+			ALTER TABLE %[1]s ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64) NOT NULL DEFAULT '%[2]s';
+			ALTER TABLE %[1]s ENABLE ROW LEVEL SECURITY;
+			ALTER TABLE %[1]s FORCE ROW LEVEL SECURITY;
+			DROP POLICY IF EXISTS tenant_isolation ON %[1]s;
+			CREATE POLICY tenant_isolation ON %[1]s USING (current_setting('%[3]s', true) IN (tenant_id, '%[4]s'));`,
+			table,
+			tenant.Default,
+			tenantSetting,
+			allTenants,
+		))
+	}
+
+	_, err := db.Exec(strings.Join(statements, "\n"))
+	if err != nil {
+		return fmt.Errorf("could not initialize tenant schema: %w", err)
+	}
+
+	return nil
+}
+
+// beginTenantTx begins the transaction of the tenant from the context, and returns it with the tenant.
+func beginTenantTx(ctx context.Context, db *sqlx.DB, opts *sql.TxOptions) (*sqlx.Tx, string, error) {
+	tenantID := tenant.FromContext(ctx)
+
+	tx, err := beginTxAs(ctx, db, opts, tenantID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return tx, tenantID, nil
+}
+
+// beginTxAs begins the transaction with the tenant setting, which may be allTenants.
+func beginTxAs(ctx context.Context, db *sqlx.DB, opts *sql.TxOptions, tenantID string) (*sqlx.Tx, error) {
+	tx, err := db.BeginTxx(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("could not begin transaction: %w", err)
+	}
+
+	// the setting is local, so it's reset when the transaction ends and the connection goes back to the pool
+	_, err = tx.ExecContext(ctx, `SELECT set_config($1, $2, true)`, tenantSetting, tenantID)
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("could not set tenant: %w", err), tx.Rollback())
+	}
+
+	return tx, nil
+}
+
+// inTenantTx runs fn in the transaction of the tenant from the context, and commits it if fn succeeds.
+func inTenantTx(ctx context.Context, db *sqlx.DB, opts *sql.TxOptions, fn func(tx *sqlx.Tx, tenantID string) error) error {
+	tx, tenantID, err := beginTenantTx(ctx, db, opts)
+	if err != nil {
+		return err
+	}
+
+	return commitIfSucceeds(tx, func() error {
+		return fn(tx, tenantID)
+	})
+}
+
+// inAllTenantsTx runs fn in a transaction seeing the rows of all tenants, for the background jobs.
+func inAllTenantsTx(ctx context.Context, db *sqlx.DB, opts *sql.TxOptions, fn func(tx *sqlx.Tx) error) error {
+	tx, err := beginTxAs(ctx, db, opts, allTenants)
+	if err != nil {
+		return err
+	}
+
+	return commitIfSucceeds(tx, func() error {
+		return fn(tx)
+	})
+}
+
+// commitIfSucceeds runs fn, and commits the transaction if it succeeds, or rolls it back if it fails.
+func commitIfSucceeds(tx *sqlx.Tx, fn func() error) (err error) {
+	defer func() {
+		if err != nil {
+			rollbackErr := tx.Rollback()
+			err = errors.Join(err, rollbackErr)
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	return fn()
+}
@@ -2,8 +2,12 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
 	"tickets/entities"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -20,17 +24,22 @@ func NewTicketsRepository(db *sqlx.DB) TicketsRepository {
 	return TicketsRepository{db: db}
 }
 
+const ticketColumns = `
+	ticket_id,
+	price_amount as "price.amount",
+	price_currency as "price.currency",
+	customer_email,
+	booking_id,
+	status,
+	updated_at,
+	deleted_at,
+	version`
+
+// Add stores the confirmed ticket, it returns entities.ErrStaleWrite if the stored version is newer.
 func (t TicketsRepository) Add(ctx context.Context, ticket entities.Ticket) error {
-	_, err := t.db.NamedExecContext(
-		ctx,
-		`
-		INSERT INTO 
-    		tickets (ticket_id, price_amount, price_currency, customer_email) 
-		VALUES 
-		    (:ticket_id, :price.amount, :price.currency, :customer_email)
-		ON CONFLICT DO NOTHING`,
-		ticket,
-	)
+	ticket.Status = entities.TicketStatusConfirmed
+
+	err := t.saveVersioned(ctx, ticket)
 	if err != nil {
 		return fmt.Errorf("could not save ticket: %w", err)
 	}
@@ -38,12 +47,11 @@ func (t TicketsRepository) Add(ctx context.Context, ticket entities.Ticket) erro
 	return nil
 }
 
-func (t TicketsRepository) Remove(ctx context.Context, ticketId string) error {
-	_, err := t.db.ExecContext(
-		ctx,
-		`DELETE FROM tickets WHERE ticket_id = $1`,
-		ticketId,
-	)
+// Remove soft-deletes the canceled ticket, it returns entities.ErrStaleWrite if the stored version is newer.
+func (t TicketsRepository) Remove(ctx context.Context, ticket entities.Ticket) error {
+	ticket.Status = entities.TicketStatusCanceled
+
+	err := t.saveVersioned(ctx, ticket)
 	if err != nil {
 		return fmt.Errorf("could not remove ticket: %w", err)
 	}
@@ -51,26 +59,299 @@ func (t TicketsRepository) Remove(ctx context.Context, ticketId string) error {
 	return nil
 }
 
-func (t TicketsRepository) GetAll(ctx context.Context) ([]entities.Ticket, error) {
-	var tickets []entities.Ticket
-
-	err := t.db.SelectContext(
-		ctx,
-		&tickets,
-		`
-			SELECT
-				ticket_id,
-				price_amount as "price.amount",
-				price_currency as "price.currency",
-				customer_email
-			FROM
+func (t TicketsRepository) saveVersioned(ctx context.Context, ticket entities.Ticket) error {
+	return t.update(ctx, ticket.TicketID, func(stored entities.Ticket) (entities.Ticket, bool, error) {
+		return stored.ApplyVersioned(ticket, time.Now().UTC())
+	})
+}
+
+// UpdateStatus changes the status of the ticket, it returns ErrNotFound or entities.ErrInvalidStatusTransition.
+func (t TicketsRepository) UpdateStatus(ctx context.Context, ticketID string, status entities.TicketStatus) error {
+	err := t.update(ctx, ticketID, func(stored entities.Ticket) (entities.Ticket, bool, error) {
+		if stored.Status == "" {
+			return stored, false, fmt.Errorf("ticket %s: %w", ticketID, ErrNotFound)
+		}
+
+		return stored.ChangeStatus(status, time.Now().UTC())
+	})
+	if err != nil {
+		return fmt.Errorf("could not update ticket status: %w", err)
+	}
+
+	return nil
+}
+
+// update saves the ticket returned by updateFn with its status history, updateFn gets the zero Ticket if it's not stored.
+func (t TicketsRepository) update(
+	ctx context.Context,
+	ticketID string,
+	updateFn func(stored entities.Ticket) (entities.Ticket, bool, error),
+) (err error) {
+	tx, tenantID, err := beginTenantTx(ctx, t.db, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			rollbackErr := tx.Rollback()
+			err = errors.Join(err, rollbackErr)
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	var stored entities.Ticket
+	err = tx.GetContext(ctx, &stored, `SELECT `+ticketColumns+` FROM tickets WHERE ticket_id = $1 AND tenant_id = $2 FOR UPDATE`, ticketID, tenantID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("could not get ticket: %w", err)
+	}
+	exists := err == nil
+
+	updated, changed, err := updateFn(stored)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return nil
+	}
+	updated.TenantID = tenantID
+
+	if exists {
+		_, err = tx.NamedExecContext(
+			ctx,
+			`
+			UPDATE
 				tickets
-		`,
-	)
+			SET
+				price_amount = :price.amount,
+				price_currency = :price.currency,
+				customer_email = :customer_email,
+				booking_id = :booking_id,
+				status = :status,
+				updated_at = :updated_at,
+				deleted_at = :deleted_at,
+				version = :version
+			WHERE
+				ticket_id = :ticket_id AND tenant_id = :tenant_id`,
+			updated,
+		)
+		if err != nil {
+			return fmt.Errorf("could not update ticket: %w", err)
+		}
+	} else {
+		// the price is not known when the cancellation is stored before the confirmation
+		if updated.Price.Amount == "" {
+			updated.Price.Amount = "0"
+		}
+
+		res, err := tx.NamedExecContext(
+			ctx,
+			`
+			INSERT INTO 
+				tickets (ticket_id, price_amount, price_currency, customer_email, booking_id, status, updated_at, deleted_at, version, tenant_id) 
+			VALUES 
+				(:ticket_id, :price.amount, :price.currency, :customer_email, :booking_id, :status, :updated_at, :deleted_at, :version, :tenant_id)
+			ON CONFLICT DO NOTHING`,
+			updated,
+		)
+		if err != nil {
+			return fmt.Errorf("could not insert ticket: %w", err)
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("could not get affected rows: %w", err)
+		}
+		if affected == 0 {
+			// the row can't be locked before it exists, the write will be retried
+			return fmt.Errorf("ticket %s was inserted concurrently", ticketID)
+		}
+	}
+
+	if updated.Status != stored.Status {
+		_, err = tx.ExecContext(
+			ctx,
+			`INSERT INTO ticket_status_history (ticket_id, status, changed_at, tenant_id) VALUES ($1, $2, $3, $4)`,
+			ticketID,
+			updated.Status,
+			updated.UpdatedAt,
+			tenantID,
+		)
+		if err != nil {
+			return fmt.Errorf("could not add status history: %w", err)
+		}
+	}
+
+	return recordAudit(ctx, tx, tenantID)
+}
+
+func (t TicketsRepository) GetOne(ctx context.Context, ticketID string) (entities.Ticket, error) {
+	var ticket entities.Ticket
+
+	err := inTenantTx(ctx, t.db, &sql.TxOptions{ReadOnly: true}, func(tx *sqlx.Tx, tenantID string) error {
+		return tx.GetContext(ctx, &ticket, `SELECT `+ticketColumns+` FROM tickets WHERE ticket_id = $1 AND tenant_id = $2`, ticketID, tenantID)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return entities.Ticket{}, fmt.Errorf("ticket %s: %w", ticketID, ErrNotFound)
+	}
+	if err != nil {
+		return entities.Ticket{}, fmt.Errorf("could not get ticket: %w", err)
+	}
+
+	return ticket, nil
+}
+
+// GetStatusHistory returns the status changes of the ticket, from the oldest.
+func (t TicketsRepository) GetStatusHistory(ctx context.Context, ticketID string) ([]entities.TicketStatusChange, error) {
+	history := []entities.TicketStatusChange{}
+
+	err := inTenantTx(ctx, t.db, &sql.TxOptions{ReadOnly: true}, func(tx *sqlx.Tx, tenantID string) error {
+		return tx.SelectContext(
+			ctx,
+			&history,
+			`
+				SELECT
+					ticket_id,
+					status,
+					changed_at
+				FROM
+					ticket_status_history
+				WHERE
+					ticket_id = $1 AND tenant_id = $2
+				ORDER BY
+					id
+			`,
+			ticketID,
+			tenantID,
+		)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not get ticket status history: %w", err)
+	}
 
+	return history, nil
+}
+
+// Find returns a page of the tickets matching the filter.
+func (t TicketsRepository) Find(ctx context.Context, filter entities.TicketsFilter) ([]entities.Ticket, error) {
+	tickets := []entities.Ticket{}
+
+	err := inTenantTx(ctx, t.db, &sql.TxOptions{ReadOnly: true}, func(tx *sqlx.Tx, tenantID string) error {
+		query, args := ticketsQuery(tenantID, filter)
+		return tx.SelectContext(ctx, &tickets, query, args...)
+	})
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("could not find tickets: %w", err)
 	}
 
 	return tickets, nil
 }
+
+// exportBatchSize is the number of rows fetched from the cursor at once.
+const exportBatchSize = 1000
+
+// Export calls fn for every ticket matching the filter, in order, reading them in batches from a cursor.
+func (t TicketsRepository) Export(ctx context.Context, filter entities.TicketsFilter, fn func(entities.Ticket) error) (err error) {
+	tx, tenantID, err := beginTenantTx(ctx, t.db, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return err
+	}
+
+	// the transaction only reads, it's rolled back to close the cursor
+	defer func() {
+		err = errors.Join(err, tx.Rollback())
+	}()
+
+	query, args := ticketsQuery(tenantID, filter)
+
+	_, err = tx.ExecContext(ctx, `DECLARE tickets_export NO SCROLL CURSOR FOR `+query, args...)
+	if err != nil {
+		return fmt.Errorf("could not declare cursor: %w", err)
+	}
+
+	for {
+		var batch []entities.Ticket
+		err = tx.SelectContext(ctx, &batch, fmt.Sprintf(`FETCH FORWARD %d FROM tickets_export`, exportBatchSize))
+		if err != nil {
+			return fmt.Errorf("could not fetch tickets: %w", err)
+		}
+
+		for _, ticket := range batch {
+			if err := fn(ticket); err != nil {
+				return err
+			}
+		}
+
+		if len(batch) < exportBatchSize {
+			return nil
+		}
+	}
+}
+
+// ticketsQuery builds the query of the tenant's tickets matching the filter, with the keyset pagination of the cursor.
+func ticketsQuery(tenantID string, filter entities.TicketsFilter) (string, []any) {
+	var conditions []string
+	var args []any
+
+	addArg := func(arg any) string {
+		args = append(args, arg)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	conditions = append(conditions, "tenant_id = "+addArg(tenantID))
+
+	if filter.Status != "" {
+		conditions = append(conditions, "status = "+addArg(filter.Status))
+	} else {
+		conditions = append(conditions, "deleted_at IS NULL")
+	}
+	if filter.CustomerEmail != "" {
+		conditions = append(conditions, "customer_email = "+addArg(filter.CustomerEmail))
+	}
+	if filter.BookingID != "" {
+		conditions = append(conditions, "booking_id = "+addArg(filter.BookingID))
+	}
+	if !filter.UpdatedAfter.IsZero() {
+		conditions = append(conditions, "updated_at > "+addArg(filter.UpdatedAfter))
+	}
+	if !filter.UpdatedBefore.IsZero() {
+		conditions = append(conditions, "updated_at < "+addArg(filter.UpdatedBefore))
+	}
+
+	// the sort field is one of the validated column names, so it's safe to use it in the query
+	sortField := filter.Sort.Field
+	if sortField == "" {
+		sortField = entities.TicketsSortByTicketID
+	}
+
+	direction, comparison := "ASC", ">"
+	if filter.Sort.Descending {
+		direction, comparison = "DESC", "<"
+	}
+
+	if filter.After != nil {
+		switch sortField {
+		case entities.TicketsSortByUpdatedAt:
+			conditions = append(conditions, fmt.Sprintf("(updated_at, ticket_id) %s (%s, %s)", comparison, addArg(filter.After.UpdatedAt), addArg(filter.After.TicketID)))
+		case entities.TicketsSortByCustomerEmail:
+			conditions = append(conditions, fmt.Sprintf("(customer_email, ticket_id) %s (%s, %s)", comparison, addArg(filter.After.CustomerEmail), addArg(filter.After.TicketID)))
+		default:
+			conditions = append(conditions, fmt.Sprintf("ticket_id %s %s", comparison, addArg(filter.After.TicketID)))
+		}
+	}
+
+	query := `SELECT ` + ticketColumns + ` FROM tickets WHERE ` + strings.Join(conditions, " AND ")
+
+	if sortField == entities.TicketsSortByTicketID {
+		query += fmt.Sprintf(" ORDER BY ticket_id %s", direction)
+	} else {
+		query += fmt.Sprintf(" ORDER BY %s %s, ticket_id %s", sortField, direction, direction)
+	}
+
+	if filter.Limit > 0 {
+		query += " LIMIT " + addArg(filter.Limit)
+	}
+
+	return query, args
+}
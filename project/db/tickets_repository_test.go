@@ -1,3 +1,5 @@
+//go:build integration
+
 package db_test
 
 import (
@@ -8,6 +10,7 @@ import (
 	"testing"
 	ticketsDb "tickets/db"
 	"tickets/entities"
+	"tickets/tenant"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
@@ -55,7 +58,7 @@ func TestTicketRepository_Add_Idempotency(t *testing.T) {
 		err := repo.Add(ctx, ticketToAdd)
 		require.NoError(t, err)
 
-		tickets, err := repo.GetAll(ctx)
+		tickets, err := repo.Find(ctx, entities.TicketsFilter{CustomerEmail: ticketToAdd.CustomerEmail})
 		require.NoError(t, err)
 
 		foundTickets := lo.Filter(tickets, func(t entities.Ticket, _ int) bool {
@@ -66,3 +69,231 @@ func TestTicketRepository_Add_Idempotency(t *testing.T) {
 	}
 
 }
+
+func TestTicketRepository_rejects_stale_writes(t *testing.T) {
+	ctx := context.Background()
+	sqlxDb := GetDb()
+
+	err := ticketsDb.InitializeDatabaseSchema(sqlxDb)
+	require.NoError(t, err)
+
+	repo := ticketsDb.NewTicketsRepository(sqlxDb)
+
+	ticket := entities.Ticket{
+		TicketID: uuid.NewString(),
+		Price: entities.Money{
+			Amount:   "50.30",
+			Currency: "GBP",
+		},
+		CustomerEmail: "customer@gm.com",
+		Version:       1,
+	}
+
+	canceled := ticket
+	canceled.Version = 2
+
+	// the cancellation is handled before the confirmation
+	err = repo.Remove(ctx, canceled)
+	require.NoError(t, err)
+
+	err = repo.Add(ctx, ticket)
+	require.ErrorIs(t, err, entities.ErrStaleWrite)
+
+	stored, err := repo.GetOne(ctx, ticket.TicketID)
+	require.NoError(t, err)
+	require.Equal(t, entities.TicketStatusCanceled, stored.Status, "canceled ticket came back")
+
+	confirmedAgain := ticket
+	confirmedAgain.Version = 3
+
+	err = repo.Add(ctx, confirmedAgain)
+	require.NoError(t, err)
+
+	stored, err = repo.GetOne(ctx, ticket.TicketID)
+	require.NoError(t, err)
+	require.Equal(t, entities.TicketStatusConfirmed, stored.Status)
+	require.Nil(t, stored.DeletedAt)
+}
+
+func TestTicketRepository_status_history(t *testing.T) {
+	ctx := context.Background()
+	sqlxDb := GetDb()
+
+	err := ticketsDb.InitializeDatabaseSchema(sqlxDb)
+	require.NoError(t, err)
+
+	repo := ticketsDb.NewTicketsRepository(sqlxDb)
+
+	ticket := entities.Ticket{
+		TicketID: uuid.NewString(),
+		Price: entities.Money{
+			Amount:   "50.30",
+			Currency: "GBP",
+		},
+		CustomerEmail: "customer@gm.com",
+		BookingID:     uuid.NewString(),
+		Version:       1,
+	}
+
+	err = repo.UpdateStatus(ctx, ticket.TicketID, entities.TicketStatusPrinted)
+	require.ErrorIs(t, err, ticketsDb.ErrNotFound)
+
+	require.NoError(t, repo.Add(ctx, ticket))
+	require.NoError(t, repo.UpdateStatus(ctx, ticket.TicketID, entities.TicketStatusPrinted))
+
+	err = repo.UpdateStatus(ctx, ticket.TicketID, entities.TicketStatusRefunded)
+	require.ErrorIs(t, err, entities.ErrInvalidStatusTransition)
+
+	canceled := ticket
+	canceled.Version = 2
+	require.NoError(t, repo.Remove(ctx, canceled))
+	require.NoError(t, repo.UpdateStatus(ctx, ticket.TicketID, entities.TicketStatusRefunded))
+
+	stored, err := repo.GetOne(ctx, ticket.TicketID)
+	require.NoError(t, err)
+	require.Equal(t, entities.TicketStatusRefunded, stored.Status)
+	require.Equal(t, ticket.BookingID, stored.BookingID)
+	require.NotNil(t, stored.DeletedAt)
+
+	history, err := repo.GetStatusHistory(ctx, ticket.TicketID)
+	require.NoError(t, err)
+	require.Equal(
+		t,
+		[]entities.TicketStatus{
+			entities.TicketStatusConfirmed,
+			entities.TicketStatusPrinted,
+			entities.TicketStatusCanceled,
+			entities.TicketStatusRefunded,
+		},
+		lo.Map(history, func(change entities.TicketStatusChange, _ int) entities.TicketStatus {
+			return change.Status
+		}),
+	)
+
+	found, err := repo.Find(ctx, entities.TicketsFilter{BookingID: ticket.BookingID})
+	require.NoError(t, err)
+	require.Empty(t, found, "deleted tickets are not active")
+
+	found, err = repo.Find(ctx, entities.TicketsFilter{BookingID: ticket.BookingID, Status: entities.TicketStatusRefunded})
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+}
+
+func TestTicketRepository_pages_and_export(t *testing.T) {
+	ctx := context.Background()
+	sqlxDb := GetDb()
+
+	err := ticketsDb.InitializeDatabaseSchema(sqlxDb)
+	require.NoError(t, err)
+
+	repo := ticketsDb.NewTicketsRepository(sqlxDb)
+
+	email := uuid.NewString() + "@example.com"
+	var ticketIDs []string
+	for i := 0; i < 5; i++ {
+		ticket := entities.Ticket{
+			TicketID: uuid.NewString(),
+			Price: entities.Money{
+				Amount:   "50.30",
+				Currency: "GBP",
+			},
+			CustomerEmail: email,
+			Version:       1,
+		}
+		require.NoError(t, repo.Add(ctx, ticket))
+
+		ticketIDs = append(ticketIDs, ticket.TicketID)
+	}
+
+	for _, sort := range []entities.TicketsSort{
+		{Field: entities.TicketsSortByTicketID},
+		{Field: entities.TicketsSortByUpdatedAt, Descending: true},
+	} {
+		filter := entities.TicketsFilter{CustomerEmail: email, Sort: sort, Limit: 2}
+
+		var paged []string
+		for {
+			page, err := repo.Find(ctx, filter)
+			require.NoError(t, err)
+
+			for _, ticket := range page {
+				paged = append(paged, ticket.TicketID)
+			}
+
+			if len(page) < filter.Limit {
+				break
+			}
+			cursor := page[len(page)-1].Cursor()
+			filter.After = &cursor
+		}
+
+		var exported []string
+		err = repo.Export(ctx, entities.TicketsFilter{CustomerEmail: email, Sort: sort}, func(ticket entities.Ticket) error {
+			exported = append(exported, ticket.TicketID)
+			return nil
+		})
+		require.NoError(t, err)
+
+		require.ElementsMatch(t, ticketIDs, paged)
+		require.Equal(t, exported, paged, "pages should have the order of the export")
+	}
+}
+
+func TestTicketRepository_tenants(t *testing.T) {
+	sqlxDb := GetDb()
+
+	err := ticketsDb.InitializeDatabaseSchema(sqlxDb)
+	require.NoError(t, err)
+
+	repo := ticketsDb.NewTicketsRepository(sqlxDb)
+
+	acme := tenant.WithID(context.Background(), "acme-"+uuid.NewString()[:8])
+	other := tenant.WithID(context.Background(), "other-"+uuid.NewString()[:8])
+
+	ticket := entities.Ticket{
+		TicketID: uuid.NewString(),
+		Price: entities.Money{
+			Amount:   "50.30",
+			Currency: "GBP",
+		},
+		CustomerEmail: "customer@gm.com",
+		BookingID:     uuid.NewString(),
+		Version:       1,
+	}
+	require.NoError(t, repo.Add(acme, ticket))
+
+	stored, err := repo.GetOne(acme, ticket.TicketID)
+	require.NoError(t, err)
+	require.Equal(t, ticket.BookingID, stored.BookingID)
+
+	_, err = repo.GetOne(other, ticket.TicketID)
+	require.ErrorIs(t, err, ticketsDb.ErrNotFound)
+
+	err = repo.UpdateStatus(other, ticket.TicketID, entities.TicketStatusPrinted)
+	require.ErrorIs(t, err, ticketsDb.ErrNotFound)
+
+	found, err := repo.Find(other, entities.TicketsFilter{BookingID: ticket.BookingID})
+	require.NoError(t, err)
+	require.Empty(t, found)
+
+	history, err := repo.GetStatusHistory(other, ticket.TicketID)
+	require.NoError(t, err)
+	require.Empty(t, history)
+
+	found, err = repo.Find(acme, entities.TicketsFilter{BookingID: ticket.BookingID})
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+
+	// the ticket IDs are unique within the tenant only
+	otherTicket := ticket
+	otherTicket.Price.Amount = "10.00"
+	require.NoError(t, repo.Add(other, otherTicket))
+
+	stored, err = repo.GetOne(other, ticket.TicketID)
+	require.NoError(t, err)
+	require.Equal(t, "10.00", stored.Price.Amount)
+
+	stored, err = repo.GetOne(acme, ticket.TicketID)
+	require.NoError(t, err)
+	require.Equal(t, "50.30", stored.Price.Amount)
+}
@@ -0,0 +1,413 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"tickets/entities"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type WebhooksRepository struct {
+	db *sqlx.DB
+}
+
+func NewWebhooksRepository(db *sqlx.DB) WebhooksRepository {
+	if db == nil {
+		panic("db is nil")
+	}
+
+	return WebhooksRepository{db: db}
+}
+
+type webhookSubscription struct {
+	ID                  uuid.UUID      `db:"id"`
+	TenantID            string         `db:"tenant_id"`
+	URL                 string         `db:"url"`
+	EventTypes          pq.StringArray `db:"event_types"`
+	Secret              string         `db:"secret"`
+	Disabled            bool           `db:"disabled"`
+	ConsecutiveFailures int            `db:"consecutive_failures"`
+	CreatedAt           time.Time      `db:"created_at"`
+}
+
+func (w webhookSubscription) toEntity() entities.WebhookSubscription {
+	return entities.WebhookSubscription{
+		ID:                  w.ID,
+		TenantID:            w.TenantID,
+		URL:                 w.URL,
+		EventTypes:          w.EventTypes,
+		Secret:              w.Secret,
+		Disabled:            w.Disabled,
+		ConsecutiveFailures: w.ConsecutiveFailures,
+		CreatedAt:           w.CreatedAt,
+	}
+}
+
+const webhookSubscriptionColumns = `
+	id,
+	tenant_id,
+	url,
+	event_types,
+	secret,
+	disabled,
+	consecutive_failures,
+	created_at`
+
+// Add saves the subscription of the tenant from the context.
+func (w WebhooksRepository) Add(ctx context.Context, subscription entities.WebhookSubscription) error {
+	err := inTenantTx(ctx, w.db, nil, func(tx *sqlx.Tx, tenantID string) error {
+		_, err := tx.NamedExecContext(
+			ctx,
+			`
+			INSERT INTO
+				webhook_subscriptions (id, tenant_id, url, event_types, secret, disabled, consecutive_failures, created_at)
+			VALUES
+				(:id, :tenant_id, :url, :event_types, :secret, :disabled, :consecutive_failures, :created_at)
+			ON CONFLICT DO NOTHING`,
+			webhookSubscription{
+				ID:                  subscription.ID,
+				TenantID:            tenantID,
+				URL:                 subscription.URL,
+				EventTypes:          subscription.EventTypes,
+				Secret:              subscription.Secret,
+				Disabled:            subscription.Disabled,
+				ConsecutiveFailures: subscription.ConsecutiveFailures,
+				CreatedAt:           subscription.CreatedAt,
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, tx, tenantID)
+	})
+	if err != nil {
+		return fmt.Errorf("could not save webhook subscription: %w", err)
+	}
+
+	return nil
+}
+
+func (w WebhooksRepository) GetOne(ctx context.Context, subscriptionID uuid.UUID) (entities.WebhookSubscription, error) {
+	var result webhookSubscription
+
+	err := inTenantTx(ctx, w.db, &sql.TxOptions{ReadOnly: true}, func(tx *sqlx.Tx, tenantID string) error {
+		return tx.GetContext(
+			ctx,
+			&result,
+			`
+				SELECT
+					`+webhookSubscriptionColumns+`
+				FROM
+					webhook_subscriptions
+				WHERE
+					id = $1 AND tenant_id = $2
+			`,
+			subscriptionID,
+			tenantID,
+		)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return entities.WebhookSubscription{}, ErrNotFound
+	}
+	if err != nil {
+		return entities.WebhookSubscription{}, fmt.Errorf("could not get webhook subscription: %w", err)
+	}
+
+	return result.toEntity(), nil
+}
+
+// FindActiveForEventType returns the active subscriptions of the tenant from the context.
+func (w WebhooksRepository) FindActiveForEventType(ctx context.Context, eventType string) ([]entities.WebhookSubscription, error) {
+	var rows []webhookSubscription
+
+	err := inTenantTx(ctx, w.db, &sql.TxOptions{ReadOnly: true}, func(tx *sqlx.Tx, tenantID string) error {
+		return tx.SelectContext(
+			ctx,
+			&rows,
+			`
+				SELECT
+					`+webhookSubscriptionColumns+`
+				FROM
+					webhook_subscriptions
+				WHERE
+					disabled = FALSE
+					AND $1 = ANY(event_types)
+					AND tenant_id = $2
+			`,
+			eventType,
+			tenantID,
+		)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not find webhook subscriptions: %w", err)
+	}
+
+	subscriptions := make([]entities.WebhookSubscription, 0, len(rows))
+	for _, row := range rows {
+		subscriptions = append(subscriptions, row.toEntity())
+	}
+
+	return subscriptions, nil
+}
+
+type webhookMessage struct {
+	SubscriptionID uuid.UUID  `db:"subscription_id"`
+	EventID        string     `db:"event_id"`
+	EventType      string     `db:"event_type"`
+	Body           []byte     `db:"body"`
+	CorrelationID  string     `db:"correlation_id"`
+	Attempts       int        `db:"attempts"`
+	NextAttemptAt  time.Time  `db:"next_attempt_at"`
+	CompletedAt    *time.Time `db:"completed_at"`
+}
+
+func newWebhookMessage(message entities.WebhookMessage) webhookMessage {
+	return webhookMessage{
+		SubscriptionID: message.Subscription.ID,
+		EventID:        message.EventID,
+		EventType:      message.EventType,
+		Body:           message.Body,
+		CorrelationID:  message.CorrelationID,
+		Attempts:       message.Attempts,
+		NextAttemptAt:  message.NextAttemptAt,
+		CompletedAt:    message.CompletedAt,
+	}
+}
+
+func (w WebhooksRepository) AddMessages(ctx context.Context, messages []entities.WebhookMessage) error {
+	rows := make([]webhookMessage, 0, len(messages))
+	for _, message := range messages {
+		rows = append(rows, newWebhookMessage(message))
+	}
+
+	_, err := w.db.NamedExecContext(
+		ctx,
+		`
+		INSERT INTO
+			webhook_messages (subscription_id, event_id, event_type, body, correlation_id, attempts, next_attempt_at, completed_at)
+		VALUES
+			(:subscription_id, :event_id, :event_type, :body, :correlation_id, :attempts, :next_attempt_at, :completed_at)
+		ON CONFLICT DO NOTHING`,
+		rows,
+	)
+	if err != nil {
+		return fmt.Errorf("could not save webhook messages: %w", err)
+	}
+
+	return nil
+}
+
+// ClaimDueMessages claims the messages in its own transaction, so they are not locked while they are delivered.
+func (w WebhooksRepository) ClaimDueMessages(ctx context.Context, limit int, claimFor time.Duration) ([]entities.WebhookMessage, error) {
+	var rows []struct {
+		webhookMessage
+		Subscription webhookSubscription `db:"subscription"`
+	}
+
+	// the messages of all tenants are delivered
+	err := inAllTenantsTx(ctx, w.db, nil, func(tx *sqlx.Tx) error {
+		return tx.SelectContext(
+			ctx,
+			&rows,
+			`
+				WITH claimed AS (
+					UPDATE
+						webhook_messages
+					SET
+						next_attempt_at = NOW() + make_interval(secs => $2)
+					WHERE
+						(subscription_id, event_id) IN (
+							SELECT
+								m.subscription_id, m.event_id
+							FROM
+								webhook_messages m
+								JOIN webhook_subscriptions s ON s.id = m.subscription_id
+							WHERE
+								m.completed_at IS NULL
+								AND m.next_attempt_at <= NOW()
+								AND s.disabled = FALSE
+							ORDER BY
+								m.next_attempt_at
+							LIMIT $1
+							FOR UPDATE OF m SKIP LOCKED
+						)
+					RETURNING
+						*
+				)
+				SELECT
+					c.subscription_id,
+					c.event_id,
+					c.event_type,
+					c.body,
+					c.correlation_id,
+					c.attempts,
+					c.next_attempt_at,
+					c.completed_at,
+					s.id AS "subscription.id",
+					s.tenant_id AS "subscription.tenant_id",
+					s.url AS "subscription.url",
+					s.event_types AS "subscription.event_types",
+					s.secret AS "subscription.secret",
+					s.disabled AS "subscription.disabled",
+					s.consecutive_failures AS "subscription.consecutive_failures",
+					s.created_at AS "subscription.created_at"
+				FROM
+					claimed c
+					JOIN webhook_subscriptions s ON s.id = c.subscription_id
+			`,
+			limit,
+			claimFor.Seconds(),
+		)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not claim webhook messages: %w", err)
+	}
+
+	messages := make([]entities.WebhookMessage, 0, len(rows))
+	for _, row := range rows {
+		messages = append(messages, entities.WebhookMessage{
+			Subscription:  row.Subscription.toEntity(),
+			EventID:       row.EventID,
+			EventType:     row.EventType,
+			Body:          row.Body,
+			CorrelationID: row.CorrelationID,
+			Attempts:      row.Attempts,
+			NextAttemptAt: row.NextAttemptAt,
+			CompletedAt:   row.CompletedAt,
+		})
+	}
+
+	return messages, nil
+}
+
+func (w WebhooksRepository) UpdateMessage(ctx context.Context, message entities.WebhookMessage) error {
+	_, err := w.db.NamedExecContext(
+		ctx,
+		`
+		UPDATE
+			webhook_messages
+		SET
+			attempts = :attempts,
+			next_attempt_at = :next_attempt_at,
+			completed_at = :completed_at
+		WHERE
+			subscription_id = :subscription_id AND event_id = :event_id`,
+		newWebhookMessage(message),
+	)
+	if err != nil {
+		return fmt.Errorf("could not update webhook message: %w", err)
+	}
+
+	return nil
+}
+
+func (w WebhooksRepository) AddDelivery(ctx context.Context, delivery entities.WebhookDelivery) error {
+	_, err := w.db.NamedExecContext(
+		ctx,
+		`
+		INSERT INTO
+			webhook_deliveries (id, subscription_id, event_id, event_type, attempt, status_code, error, succeeded, delivered_at)
+		VALUES
+			(:id, :subscription_id, :event_id, :event_type, :attempt, :status_code, :error, :succeeded, :delivered_at)
+		ON CONFLICT DO NOTHING`,
+		delivery,
+	)
+	if err != nil {
+		return fmt.Errorf("could not save webhook delivery: %w", err)
+	}
+
+	return nil
+}
+
+// GetDeliveries returns the deliveries of the subscription, if it belongs to the tenant from the context.
+func (w WebhooksRepository) GetDeliveries(ctx context.Context, subscriptionID uuid.UUID) ([]entities.WebhookDelivery, error) {
+	var deliveries []entities.WebhookDelivery
+
+	err := inTenantTx(ctx, w.db, &sql.TxOptions{ReadOnly: true}, func(tx *sqlx.Tx, tenantID string) error {
+		return tx.SelectContext(
+			ctx,
+			&deliveries,
+			`
+				SELECT
+					d.id,
+					d.subscription_id,
+					d.event_id,
+					d.event_type,
+					d.attempt,
+					d.status_code,
+					d.error,
+					d.succeeded,
+					d.delivered_at
+				FROM
+					webhook_deliveries d
+					JOIN webhook_subscriptions s ON s.id = d.subscription_id
+				WHERE
+					d.subscription_id = $1 AND s.tenant_id = $2
+				ORDER BY
+					d.delivered_at DESC
+			`,
+			subscriptionID,
+			tenantID,
+		)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not get webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+func (w WebhooksRepository) MarkDeliverySucceeded(ctx context.Context, subscriptionID uuid.UUID) error {
+	// the deliveries of all tenants are marked by the delivery job
+	err := inAllTenantsTx(ctx, w.db, nil, func(tx *sqlx.Tx) error {
+		_, err := tx.ExecContext(
+			ctx,
+			`UPDATE webhook_subscriptions SET consecutive_failures = 0 WHERE id = $1`,
+			subscriptionID,
+		)
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("could not reset webhook failures: %w", err)
+	}
+
+	return nil
+}
+
+// MarkDeliveryFailed disables the subscription after disableAfter consecutive failures, and returns whether it did.
+func (w WebhooksRepository) MarkDeliveryFailed(ctx context.Context, subscriptionID uuid.UUID, disableAfter int) (bool, error) {
+	var disabled bool
+
+	err := inAllTenantsTx(ctx, w.db, nil, func(tx *sqlx.Tx) error {
+		return tx.GetContext(
+			ctx,
+			&disabled,
+			`
+				UPDATE
+					webhook_subscriptions
+				SET
+					consecutive_failures = consecutive_failures + 1,
+					disabled = disabled OR consecutive_failures + 1 >= $2
+				WHERE
+					id = $1
+				RETURNING
+					disabled
+			`,
+			subscriptionID,
+			disableAfter,
+		)
+	})
+	if err != nil {
+		return false, fmt.Errorf("could not mark webhook failure: %w", err)
+	}
+
+	return disabled, nil
+}
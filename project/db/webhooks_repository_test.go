@@ -0,0 +1,133 @@
+//go:build integration
+
+package db_test
+
+import (
+	"context"
+	"testing"
+	ticketsDb "tickets/db"
+	"tickets/entities"
+	"tickets/tenant"
+	"tickets/webhook"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhooksRepository_messages(t *testing.T) {
+	ctx := context.Background()
+	db := GetDb()
+
+	err := ticketsDb.InitializeDatabaseSchema(db)
+	require.NoError(t, err)
+
+	webhooksRepo := ticketsDb.NewWebhooksRepository(db)
+
+	subscription, err := webhook.NewSubscription("https://example.com", []string{"BookingMade"})
+	require.NoError(t, err)
+	require.NoError(t, webhooksRepo.Add(ctx, subscription))
+
+	message := entities.WebhookMessage{
+		Subscription:  subscription,
+		EventID:       uuid.NewString(),
+		EventType:     "BookingMade",
+		Body:          []byte(`{}`),
+		NextAttemptAt: time.Now().Add(-time.Second),
+	}
+	require.NoError(t, webhooksRepo.AddMessages(ctx, []entities.WebhookMessage{message}))
+	// a redelivered event is recorded once
+	require.NoError(t, webhooksRepo.AddMessages(ctx, []entities.WebhookMessage{message}))
+
+	claimed := claimMessages(t, webhooksRepo, subscription.ID)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, subscription.URL, claimed[0].Subscription.URL)
+	assert.Equal(t, subscription.Secret, claimed[0].Subscription.Secret)
+	assert.Equal(t, []byte(`{}`), claimed[0].Body)
+
+	assert.Empty(t, claimMessages(t, webhooksRepo, subscription.ID), "claimed message should not be claimed again")
+
+	completedAt := time.Now()
+	claimed[0].Attempts = 1
+	claimed[0].NextAttemptAt = time.Now().Add(-time.Second)
+	claimed[0].CompletedAt = &completedAt
+	require.NoError(t, webhooksRepo.UpdateMessage(ctx, claimed[0]))
+
+	assert.Empty(t, claimMessages(t, webhooksRepo, subscription.ID), "completed message should not be claimed")
+}
+
+func TestWebhooksRepository_tenants(t *testing.T) {
+	db := GetDb()
+
+	err := ticketsDb.InitializeDatabaseSchema(db)
+	require.NoError(t, err)
+
+	webhooksRepo := ticketsDb.NewWebhooksRepository(db)
+
+	acme := tenant.WithID(context.Background(), "acme-"+uuid.NewString()[:8])
+	other := tenant.WithID(context.Background(), "other-"+uuid.NewString()[:8])
+
+	subscription, err := webhook.NewSubscription("https://example.com", []string{"BookingMade"})
+	require.NoError(t, err)
+	require.NoError(t, webhooksRepo.Add(acme, subscription))
+
+	_, err = webhooksRepo.GetOne(other, subscription.ID)
+	assert.ErrorIs(t, err, ticketsDb.ErrNotFound)
+
+	subscriptions, err := webhooksRepo.FindActiveForEventType(other, "BookingMade")
+	require.NoError(t, err)
+	assert.Empty(t, subscriptions)
+
+	subscriptions, err = webhooksRepo.FindActiveForEventType(acme, "BookingMade")
+	require.NoError(t, err)
+	require.Len(t, subscriptions, 1)
+	assert.Equal(t, subscription.ID, subscriptions[0].ID)
+
+	// the delivery job claims the messages of all tenants
+	require.NoError(t, webhooksRepo.AddMessages(acme, []entities.WebhookMessage{{
+		Subscription:  subscriptions[0],
+		EventID:       uuid.NewString(),
+		EventType:     "BookingMade",
+		Body:          []byte(`{}`),
+		NextAttemptAt: time.Now().Add(-time.Second),
+	}}))
+	claimed := claimMessages(t, webhooksRepo, subscription.ID)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, tenant.FromContext(acme), claimed[0].Subscription.TenantID)
+
+	require.NoError(t, webhooksRepo.AddDelivery(context.Background(), entities.WebhookDelivery{
+		ID:             uuid.New(),
+		SubscriptionID: subscription.ID,
+		EventID:        claimed[0].EventID,
+		EventType:      "BookingMade",
+		Attempt:        1,
+		Succeeded:      true,
+		DeliveredAt:    time.Now(),
+	}))
+
+	deliveries, err := webhooksRepo.GetDeliveries(other, subscription.ID)
+	require.NoError(t, err)
+	assert.Empty(t, deliveries)
+
+	deliveries, err = webhooksRepo.GetDeliveries(acme, subscription.ID)
+	require.NoError(t, err)
+	assert.Len(t, deliveries, 1)
+}
+
+// claimMessages claims the due messages of the subscription, other tests may leave messages of their subscriptions.
+func claimMessages(t *testing.T, repo ticketsDb.WebhooksRepository, subscriptionID uuid.UUID) []entities.WebhookMessage {
+	t.Helper()
+
+	claimed, err := repo.ClaimDueMessages(context.Background(), 1000, time.Minute)
+	require.NoError(t, err)
+
+	var messages []entities.WebhookMessage
+	for _, message := range claimed {
+		if message.Subscription.ID == subscriptionID {
+			messages = append(messages, message)
+		}
+	}
+
+	return messages
+}
@@ -0,0 +1,35 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditRecord is the record of a change made with the API: who did what, to which shows, bookings, tickets (or keys).
+type AuditRecord struct {
+	ID         uuid.UUID `json:"id"`
+	OccurredAt time.Time `json:"occurred_at"`
+	TenantID   string    `json:"-"`
+	// Actor is the subject of the caller: the ID of the API key, or the operator from the JWT.
+	Actor string `json:"actor"`
+	// Action is the operation of the API, like "PostBookTickets".
+	Action        string   `json:"action"`
+	TargetIDs     []string `json:"target_ids"`
+	CorrelationID string   `json:"correlation_id"`
+	// Summary is the request body, without the emails and secrets.
+	Summary string `json:"summary"`
+}
+
+// AuditRecordsFilter selects the audit records, the empty fields don't limit them.
+type AuditRecordsFilter struct {
+	Actor    string
+	Action   string
+	TargetID string
+	// From and To limit the time of the records, To is exclusive.
+	From time.Time
+	To   time.Time
+
+	// Limit is the maximum number of the (newest) records, zero means no limit.
+	Limit int
+}
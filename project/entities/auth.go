@@ -0,0 +1,53 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Role is the role of an API caller, the routes allow only some of the roles.
+type Role string
+
+const (
+	// RoleAdmin can call every route.
+	RoleAdmin Role = "admin"
+	// RolePromoter manages the shows and reads the sales reports.
+	RolePromoter Role = "promoter"
+	// RoleSupport looks up and books the tickets for customers.
+	RoleSupport Role = "support"
+	// RoleGateway sends the ticket status callbacks.
+	RoleGateway Role = "gateway"
+)
+
+var Roles = []Role{
+	RoleAdmin,
+	RolePromoter,
+	RoleSupport,
+	RoleGateway,
+}
+
+func (r Role) Valid() bool {
+	for _, role := range Roles {
+		if r == role {
+			return true
+		}
+	}
+
+	return false
+}
+
+// APIKey is a key of a service calling the API (like the gateway). The key itself is shown only
+// when it's created, only its hash is stored.
+type APIKey struct {
+	ID   uuid.UUID `json:"id" db:"id"`
+	Name string    `json:"name" db:"name"`
+	Role Role      `json:"role" db:"role"`
+	// TenantID is the tenant of the callers with the key.
+	TenantID string `json:"tenant_id" db:"tenant_id"`
+	// Prefix is the beginning of the key, so the keys can be told apart.
+	Prefix    string     `json:"prefix" db:"prefix"`
+	Hash      string     `json:"-" db:"key_hash"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+}
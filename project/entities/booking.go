@@ -9,6 +9,7 @@ type Booking struct {
 	ShowID          uuid.UUID `json:"show_id" db:"show_id"`
 	NumberOfTickets int       `json:"number_of_tickets" db:"number_of_tickets"`
 	CustomerEmail   string    `json:"customer_email" db:"customer_email"`
+	TenantID        string    `json:"-" db:"tenant_id"`
 }
 
 type DeadNationBooking struct {
@@ -0,0 +1,53 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CustomerData is everything stored about the customer, found by the customer's email.
+type CustomerData struct {
+	Email    string           `json:"email"`
+	Tickets  []CustomerTicket `json:"tickets"`
+	Bookings []Booking        `json:"bookings"`
+	// SheetRows are the rows of the customer's tickets, recorded to be appended to the sheets.
+	SheetRows []SheetRow `json:"sheet_rows"`
+	// WebhookMessages are the events with the customer's email, recorded to be sent to the webhooks.
+	WebhookMessages []CustomerWebhookMessage `json:"webhook_messages"`
+}
+
+type CustomerTicket struct {
+	Ticket
+	StatusHistory []TicketStatusChange `json:"status_history"`
+}
+
+type CustomerWebhookMessage struct {
+	SubscriptionID uuid.UUID       `json:"subscription_id" db:"subscription_id"`
+	EventID        string          `json:"event_id" db:"event_id"`
+	EventType      string          `json:"event_type" db:"event_type"`
+	Body           json.RawMessage `json:"body" db:"body"`
+	CompletedAt    *time.Time      `json:"completed_at,omitempty" db:"completed_at"`
+}
+
+// CustomerErasure is the result of erasing the customer's data, the IDs are of the pseudonymised tickets and bookings.
+type CustomerErasure struct {
+	Pseudonym  string   `json:"pseudonym"`
+	TicketIDs  []string `json:"ticket_ids"`
+	BookingIDs []string `json:"booking_ids"`
+	SheetRows  int      `json:"sheet_rows"`
+	// WebhookMessages is the number of the pseudonymised webhook messages.
+	WebhookMessages int `json:"webhook_messages"`
+}
+
+// Erased returns true if any data of the customer was found and erased.
+func (e CustomerErasure) Erased() bool {
+	return len(e.TicketIDs) > 0 || len(e.BookingIDs) > 0 || e.SheetRows > 0 || e.WebhookMessages > 0
+}
+
+// NewCustomerPseudonym returns the address replacing the email of an erased customer. It's random, so the data
+// can't be linked back to the customer, but all data of one erasure gets the same pseudonym.
+func NewCustomerPseudonym() string {
+	return "erased-" + uuid.NewString() + "@erased.invalid"
+}
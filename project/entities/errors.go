@@ -0,0 +1,10 @@
+package entities
+
+import "errors"
+
+var (
+	// ErrStaleWrite is returned when a write is based on an older version than the one already stored.
+	ErrStaleWrite = errors.New("stale write")
+	// ErrInvalidStatusTransition is returned when the status can't be changed from the current one.
+	ErrInvalidStatusTransition = errors.New("invalid status transition")
+)
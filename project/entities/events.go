@@ -10,6 +10,8 @@ type EventHeader struct {
 	ID          string    `json:"id"`
 	PublishedAt time.Time `json:"published_at"`
 	IdempotencyKey	string `json:"idempotency_key"`
+	// TenantID is the tenant the event belongs to, empty for the events published before the tenants were introduced.
+	TenantID string `json:"tenant_id,omitempty"`
 }
 
 func NewEventHeader() EventHeader {
@@ -28,12 +30,19 @@ func NewEventHeaderWithIdempotencyKey(idempotencyKey string) EventHeader {
 	}
 }
 
+// WithTenant returns the header of an event of the tenant.
+func (h EventHeader) WithTenant(tenantID string) EventHeader {
+	h.TenantID = tenantID
+	return h
+}
+
 type TicketBookingConfirmed struct {
 	Header        EventHeader `json:"header"`
 	TicketID      string      `json:"ticket_id"`
 	CustomerEmail string      `json:"customer_email"`
 	Price         Money       `json:"price"`
 	BookingID     string      `json:"booking_id"`
+	Version       int64       `json:"version,omitempty"`
 }
 
 type TicketBookingCanceled struct {
@@ -41,6 +50,7 @@ type TicketBookingCanceled struct {
 	TicketID      string      `json:"ticket_id"`
 	CustomerEmail string      `json:"customer_email"`
 	Price         Money       `json:"price"`
+	Version       int64       `json:"version,omitempty"`
 }
 
 type TicketRefunded struct {
@@ -61,3 +71,35 @@ type BookingMade struct {
 	CustomerEmail   string      `json:"customer_email"`
 	ShowId          uuid.UUID   `json:"show_id"`
 }
+
+// CustomerDataErased is published when the data of a customer is erased: the customer's email was replaced
+// with the Pseudonym in the tickets and bookings. The email isn't in the event, the handlers (and the partners)
+// find the data to clean up by the IDs. The events published before with the email are not changed,
+// they are removed from the streams by the trim policies (see broker.TrimPolicy).
+type CustomerDataErased struct {
+	Header     EventHeader `json:"header"`
+	TicketIDs  []string    `json:"ticket_ids"`
+	BookingIDs []string    `json:"booking_ids"`
+	Pseudonym  string      `json:"pseudonym"`
+}
+
+// OrderingKey returns the key of events that have to be handled in order, see event.NewEventBus.
+func (e TicketBookingConfirmed) OrderingKey() string {
+	return e.TicketID
+}
+
+func (e TicketBookingCanceled) OrderingKey() string {
+	return e.TicketID
+}
+
+func (e TicketRefunded) OrderingKey() string {
+	return e.TicketID
+}
+
+func (e TicketPrinted) OrderingKey() string {
+	return e.TicketID
+}
+
+func (e BookingMade) OrderingKey() string {
+	return e.BookingID.String()
+}
@@ -0,0 +1,12 @@
+package entities
+
+import "time"
+
+// MessageHandlerState is the state of a message handler shared by all instances of the service.
+type MessageHandlerState struct {
+	Name   string `db:"name"`
+	Paused bool   `db:"paused"`
+	// LastError is empty when the handler never failed.
+	LastError   string     `db:"last_error"`
+	LastErrorAt *time.Time `db:"last_error_at"`
+}
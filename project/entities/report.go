@@ -0,0 +1,154 @@
+package entities
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TicketSale is the reporting read model of a ticket, built from the ticket events.
+// The events can be handled in any order (and more than once), so every event only fills in its own fields.
+type TicketSale struct {
+	TicketID  string `db:"ticket_id"`
+	BookingID string `db:"booking_id"`
+	// Price is empty until the confirmation or the cancellation is handled, refunds don't carry it.
+	Price Money `db:"price"`
+
+	ConfirmedAt *time.Time `db:"confirmed_at"`
+	CanceledAt  *time.Time `db:"canceled_at"`
+	RefundedAt  *time.Time `db:"refunded_at"`
+
+	// Status is the status of the latest confirmation or cancellation (by the time of its event), StatusAt is its time.
+	// The cancellation is reported only while it's the latest one, so a ticket confirmed again is sold again.
+	Status   TicketStatus `db:"status"`
+	StatusAt *time.Time   `db:"status_at"`
+
+	TenantID string `db:"tenant_id"`
+}
+
+// Merge returns the sale with the fields missing in s taken from other, and the status of the latest event.
+// Times are merged to the earliest one, so a redelivered event doesn't move the sale to another day.
+func (s TicketSale) Merge(other TicketSale) TicketSale {
+	if s.BookingID == "" {
+		s.BookingID = other.BookingID
+	}
+	if s.Price.Amount == "" {
+		s.Price = other.Price
+	}
+
+	s.ConfirmedAt = earliest(s.ConfirmedAt, other.ConfirmedAt)
+	s.CanceledAt = earliest(s.CanceledAt, other.CanceledAt)
+	s.RefundedAt = earliest(s.RefundedAt, other.RefundedAt)
+
+	if other.StatusAt != nil && (s.StatusAt == nil || other.StatusAt.After(*s.StatusAt)) {
+		s.Status = other.Status
+		s.StatusAt = other.StatusAt
+	}
+
+	return s
+}
+
+// Canceled returns true if the latest event of the ticket was its cancellation.
+func (s TicketSale) Canceled() bool {
+	return s.Status == TicketStatusCanceled
+}
+
+func earliest(a, b *time.Time) *time.Time {
+	if a == nil || (b != nil && b.Before(*a)) {
+		return b
+	}
+
+	return a
+}
+
+// SaleDay returns the day (in UTC) the sale's event happened, which is the day it's reported in.
+func SaleDay(t time.Time) string {
+	return t.UTC().Format(time.DateOnly)
+}
+
+// SalesReportFilter selects the sales included in the daily report.
+type SalesReportFilter struct {
+	// ShowID limits the report to the tickets of the show, when set.
+	ShowID *uuid.UUID
+	// From and To are the first and the last day of the report (in UTC), they are not limited when zero.
+	From time.Time
+	To   time.Time
+}
+
+// SalesFigures are the sales of the tickets in one currency, in one show or one day.
+type SalesFigures struct {
+	// Currency is empty for refunds of tickets that have no price yet.
+	Currency        string `db:"currency"`
+	TicketsSold     int    `db:"tickets_sold"`
+	TicketsCanceled int    `db:"tickets_canceled"`
+	TicketsRefunded int    `db:"tickets_refunded"`
+	// Gross is the price of the sold tickets, Net is Gross without the price of the canceled tickets.
+	Gross string `db:"gross"`
+	Net   string `db:"net"`
+}
+
+// SalesReport counts the tickets sold, canceled and refunded, with the revenue per currency.
+// Tickets are counted on the day of each event, so a ticket sold on Monday and canceled on Tuesday
+// adds to Monday's gross revenue and lowers Tuesday's net revenue.
+type SalesReport struct {
+	TicketsSold     int       `json:"tickets_sold"`
+	TicketsCanceled int       `json:"tickets_canceled"`
+	TicketsRefunded int       `json:"tickets_refunded"`
+	Revenue         []Revenue `json:"revenue"`
+}
+
+// Revenue is the revenue in one currency. Canceled tickets are refunded, so their price
+// is not a part of the net revenue (whether the refund was already made or not).
+type Revenue struct {
+	Gross Money `json:"gross"`
+	Net   Money `json:"net"`
+}
+
+func NewSalesReport(figures []SalesFigures) SalesReport {
+	report := SalesReport{
+		Revenue: []Revenue{},
+	}
+
+	for _, f := range figures {
+		report.TicketsSold += f.TicketsSold
+		report.TicketsCanceled += f.TicketsCanceled
+		report.TicketsRefunded += f.TicketsRefunded
+
+		if f.Currency == "" {
+			continue
+		}
+
+		report.Revenue = append(report.Revenue, Revenue{
+			Gross: Money{Amount: f.Gross, Currency: f.Currency},
+			Net:   Money{Amount: f.Net, Currency: f.Currency},
+		})
+	}
+
+	sort.Slice(report.Revenue, func(i, j int) bool {
+		return report.Revenue[i].Gross.Currency < report.Revenue[j].Gross.Currency
+	})
+
+	return report
+}
+
+// Currencies returns the currencies the report has revenue in.
+func (r SalesReport) Currencies() []string {
+	currencies := make([]string, 0, len(r.Revenue))
+	for _, revenue := range r.Revenue {
+		currencies = append(currencies, revenue.Gross.Currency)
+	}
+
+	return currencies
+}
+
+type ShowSalesReport struct {
+	ShowID uuid.UUID `json:"show_id"`
+	SalesReport
+}
+
+type DailySalesReport struct {
+	// Date is the day of the report (in UTC), formatted as YYYY-MM-DD.
+	Date string `json:"date"`
+	SalesReport
+}
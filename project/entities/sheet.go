@@ -0,0 +1,59 @@
+package entities
+
+import "time"
+
+// The sheets the rows are appended to. Every tenant has its own sheets in the spreadsheets API, see tenant.NameFor.
+const (
+	SheetTicketsToPrint  = "tickets-to-print"
+	SheetTicketsToRefund = "tickets-to-refund"
+)
+
+// SheetRow is a row to append to a sheet for a ticket. It's recorded when the event is handled,
+// and appended to the sheet later, together with other pending rows.
+type SheetRow struct {
+	TenantID string `json:"-" db:"tenant_id"`
+	// SheetName is the name of the sheet without the tenant, like SheetTicketsToPrint.
+	SheetName      string     `json:"sheet_name" db:"sheet_name"`
+	TicketID       string     `json:"ticket_id" db:"ticket_id"`
+	IdempotencyKey string     `json:"-" db:"idempotency_key"`
+	Columns        []string   `json:"columns" db:"-"`
+	AddedAt        time.Time  `json:"added_at" db:"added_at"`
+	AppendedAt     *time.Time `json:"appended_at,omitempty" db:"appended_at"`
+	// RejectedAt is set when the API rejected the row with a permanent error, the row is not appended then.
+	RejectedAt *time.Time `json:"rejected_at,omitempty" db:"rejected_at"`
+	Rejection  string     `json:"rejection,omitempty" db:"rejection"`
+	// ErasedAt is set when the customer's data in the row was erased, the Columns have the ErasedPseudonym
+	// instead of the customer's email then. The row may have been appended to the sheet with the email before.
+	ErasedAt        *time.Time `json:"erased_at,omitempty" db:"erased_at"`
+	ErasedPseudonym string     `json:"-" db:"erased_pseudonym"`
+}
+
+// SameRow returns true if the rows are for the same tenant, sheet, ticket and idempotency key,
+// which means they are duplicates of each other.
+func (r SheetRow) SameRow(other SheetRow) bool {
+	return r.TenantID == other.TenantID && r.SheetName == other.SheetName && r.TicketID == other.TicketID &&
+		r.IdempotencyKey == other.IdempotencyKey
+}
+
+// SheetReconciliation compares the rows recorded as appended with the rows the sheet has.
+type SheetReconciliation struct {
+	SheetName string `json:"sheet_name"`
+	// Recorded is the number of rows recorded as appended to the sheet.
+	Recorded int `json:"recorded"`
+	// InSheet is the number of rows in the sheet.
+	InSheet int `json:"in_sheet"`
+	// Missing are the recorded rows that are not in the sheet.
+	Missing [][]string `json:"missing"`
+	// Unexpected are the rows in the sheet that were not recorded (including duplicates of recorded rows).
+	Unexpected [][]string `json:"unexpected"`
+	// Rejected are the rows the API rejected, they are not expected in the sheet.
+	Rejected [][]string `json:"rejected"`
+	// Erased are the recorded rows (with the pseudonyms) whose customer's data was erased, but which are still
+	// in the sheet with the customer's data. The spreadsheets API can't change rows, so they have to be removed
+	// in the sheet. The erased rows are not expected in the sheet.
+	Erased [][]string `json:"erased"`
+}
+
+func (r SheetReconciliation) Consistent() bool {
+	return len(r.Missing) == 0 && len(r.Unexpected) == 0
+}
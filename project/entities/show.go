@@ -13,4 +13,5 @@ type Show struct {
 	StartTime       time.Time `json:"start_time" db:"start_time"`
 	Title           string    `json:"title" db:"title"`
 	Venue           string    `json:"venue" db:"venue"`
+	TenantID        string    `json:"-" db:"tenant_id"`
 }
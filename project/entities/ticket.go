@@ -1,7 +1,208 @@
 package entities
 
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+type TicketStatus string
+
+const (
+	TicketStatusConfirmed TicketStatus = "confirmed"
+	TicketStatusCanceled  TicketStatus = "canceled"
+	TicketStatusRefunded  TicketStatus = "refunded"
+	TicketStatusPrinted   TicketStatus = "printed"
+	TicketStatusCheckedIn TicketStatus = "checked-in"
+)
+
+var TicketStatuses = []TicketStatus{
+	TicketStatusConfirmed,
+	TicketStatusCanceled,
+	TicketStatusRefunded,
+	TicketStatusPrinted,
+	TicketStatusCheckedIn,
+}
+
+// ticketStatusTransitions are the statuses each status can be changed from with TicketsRepository.UpdateStatus.
+// Confirmations and cancellations come from the ticket-status API and are ordered by the ticket version instead.
+var ticketStatusTransitions = map[TicketStatus][]TicketStatus{
+	TicketStatusPrinted:   {TicketStatusConfirmed},
+	TicketStatusCheckedIn: {TicketStatusConfirmed, TicketStatusPrinted},
+	TicketStatusRefunded:  {TicketStatusCanceled},
+}
+
+func (s TicketStatus) Valid() bool {
+	return slices.Contains(TicketStatuses, s)
+}
+
+// Deleted is true for the statuses of soft-deleted tickets: they are kept with their history, but they are not active.
+func (s TicketStatus) Deleted() bool {
+	return s == TicketStatusCanceled || s == TicketStatusRefunded
+}
+
+// CanChangeTo returns true if a ticket in this status can be changed to the next status with UpdateStatus.
+func (s TicketStatus) CanChangeTo(next TicketStatus) bool {
+	return slices.Contains(ticketStatusTransitions[next], s)
+}
+
 type Ticket struct {
-	TicketID      string `json:"ticket_id" db:"ticket_id"`
-	Price         Money  `json:"price" db:"price"`
-	CustomerEmail string `json:"customer_email" db:"customer_email"`
+	TicketID      string       `json:"ticket_id" db:"ticket_id"`
+	Price         Money        `json:"price" db:"price"`
+	CustomerEmail string       `json:"customer_email" db:"customer_email"`
+	BookingID     string       `json:"booking_id" db:"booking_id"`
+	Status        TicketStatus `json:"status" db:"status"`
+	UpdatedAt     time.Time    `json:"updated_at" db:"updated_at"`
+	DeletedAt     *time.Time   `json:"deleted_at,omitempty" db:"deleted_at"`
+	TenantID      string       `json:"-" db:"tenant_id"`
+
+	// Version is the version of the last confirmation or cancellation, writes with older versions are rejected.
+	Version int64 `json:"-" db:"version"`
+}
+
+type TicketStatusChange struct {
+	TicketID  string       `json:"-" db:"ticket_id"`
+	Status    TicketStatus `json:"status" db:"status"`
+	ChangedAt time.Time    `json:"changed_at" db:"changed_at"`
+}
+
+// TicketsFilter filters the tickets returned by TicketsRepository.Find, empty fields match all tickets.
+// Without Status, only active (not deleted) tickets are returned.
+type TicketsFilter struct {
+	Status        TicketStatus
+	CustomerEmail string
+	BookingID     string
+	UpdatedAfter  time.Time
+	UpdatedBefore time.Time
+
+	Sort TicketsSort
+	// After is the cursor of the last ticket of the previous page, the page starts after it.
+	After *TicketsCursor
+	// Limit is the size of the page, zero means no limit.
+	Limit int
+}
+
+type TicketsSortField string
+
+const (
+	TicketsSortByTicketID      TicketsSortField = "ticket_id"
+	TicketsSortByUpdatedAt     TicketsSortField = "updated_at"
+	TicketsSortByCustomerEmail TicketsSortField = "customer_email"
+)
+
+func (f TicketsSortField) Valid() bool {
+	return f == TicketsSortByTicketID || f == TicketsSortByUpdatedAt || f == TicketsSortByCustomerEmail
+}
+
+// TicketsSort orders tickets by the field, and then by the ticket ID, so the order is stable for the cursors.
+// The zero value sorts by the ticket ID.
+type TicketsSort struct {
+	Field      TicketsSortField
+	Descending bool
+}
+
+// ParseTicketsSort parses sorts like "updated_at" or "-updated_at" (descending).
+func ParseTicketsSort(s string) (TicketsSort, error) {
+	sort := TicketsSort{Field: TicketsSortByTicketID}
+	if s == "" {
+		return sort, nil
+	}
+
+	if strings.HasPrefix(s, "-") {
+		sort.Descending = true
+		s = s[1:]
+	}
+
+	sort.Field = TicketsSortField(s)
+	if !sort.Field.Valid() {
+		return TicketsSort{}, fmt.Errorf("invalid sort field: %s", s)
+	}
+
+	return sort, nil
+}
+
+// TicketsCursor points to a ticket in the sorted tickets, it has the values of all fields tickets can be sorted by.
+type TicketsCursor struct {
+	TicketID      string    `json:"ticket_id"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	CustomerEmail string    `json:"customer_email"`
+}
+
+func (t Ticket) Cursor() TicketsCursor {
+	return TicketsCursor{
+		TicketID:      t.TicketID,
+		UpdatedAt:     t.UpdatedAt,
+		CustomerEmail: t.CustomerEmail,
+	}
+}
+
+// ApplyVersioned returns the stored ticket t after the confirmation or cancellation from next.
+// Confirming a printed or checked-in ticket again keeps its status, and cancellation keeps the other data
+// (the cancellation doesn't need to have it).
+//
+// t is the zero Ticket when the ticket isn't stored yet.
+// It returns false when next was already applied, and ErrStaleWrite when next is older than t.
+func (t Ticket) ApplyVersioned(next Ticket, now time.Time) (Ticket, bool, error) {
+	if t.Status == "" {
+		created := next
+		created.UpdatedAt = now
+		created.setDeletedAt(now)
+
+		return created, true, nil
+	}
+
+	if t.Version > next.Version {
+		return t, false, fmt.Errorf("ticket %s has version %d, newer than %d: %w", t.TicketID, t.Version, next.Version, ErrStaleWrite)
+	}
+	if t.Version == next.Version {
+		return t, false, nil
+	}
+
+	updated := t
+	updated.Version = next.Version
+	updated.UpdatedAt = now
+
+	if next.Status == TicketStatusConfirmed {
+		updated.Price = next.Price
+		updated.CustomerEmail = next.CustomerEmail
+		if next.BookingID != "" {
+			updated.BookingID = next.BookingID
+		}
+		if t.Status.Deleted() {
+			updated.Status = TicketStatusConfirmed
+		}
+	} else {
+		updated.Status = next.Status
+	}
+
+	updated.setDeletedAt(now)
+
+	return updated, true, nil
+}
+
+// ChangeStatus returns the ticket after the status change, see CanChangeTo.
+// It returns false when the ticket already has the status.
+func (t Ticket) ChangeStatus(status TicketStatus, now time.Time) (Ticket, bool, error) {
+	if t.Status == status {
+		return t, false, nil
+	}
+	if !t.Status.CanChangeTo(status) {
+		return t, false, fmt.Errorf("ticket %s can't be changed from %s to %s: %w", t.TicketID, t.Status, status, ErrInvalidStatusTransition)
+	}
+
+	updated := t
+	updated.Status = status
+	updated.UpdatedAt = now
+	updated.setDeletedAt(now)
+
+	return updated, true, nil
+}
+
+func (t *Ticket) setDeletedAt(now time.Time) {
+	if !t.Status.Deleted() {
+		t.DeletedAt = nil
+	} else if t.DeletedAt == nil {
+		t.DeletedAt = &now
+	}
 }
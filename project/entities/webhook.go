@@ -0,0 +1,44 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type WebhookSubscription struct {
+	ID                  uuid.UUID `json:"id"`
+	TenantID            string    `json:"-"`
+	URL                 string    `json:"url"`
+	EventTypes          []string  `json:"event_types"`
+	Secret              string    `json:"-"`
+	Disabled            bool      `json:"disabled"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+type WebhookDelivery struct {
+	ID             uuid.UUID `json:"id" db:"id"`
+	SubscriptionID uuid.UUID `json:"subscription_id" db:"subscription_id"`
+	EventID        string    `json:"event_id" db:"event_id"`
+	EventType      string    `json:"event_type" db:"event_type"`
+	Attempt        int       `json:"attempt" db:"attempt"`
+	StatusCode     int       `json:"status_code" db:"status_code"`
+	Error          string    `json:"error,omitempty" db:"error"`
+	Succeeded      bool      `json:"succeeded" db:"succeeded"`
+	DeliveredAt    time.Time `json:"delivered_at" db:"delivered_at"`
+}
+
+// WebhookMessage is an event to deliver to one subscription. The events are recorded as one message per subscription,
+// which is delivered (and retried) on its own, outside the event handlers.
+type WebhookMessage struct {
+	Subscription  WebhookSubscription
+	EventID       string
+	EventType     string
+	Body          []byte
+	CorrelationID string
+	Attempts      int
+	NextAttemptAt time.Time
+	// CompletedAt is set once the message is delivered, or it failed too many times.
+	CompletedAt *time.Time
+}
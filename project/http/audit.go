@@ -0,0 +1,95 @@
+package http
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"tickets/audit"
+	"tickets/auth"
+	"tickets/entities"
+	"tickets/http/openapi"
+
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+	"github.com/labstack/echo/v4"
+)
+
+// newAuditMiddleware returns the middleware adding the audit entry of the action, saved by the repositories.
+func newAuditMiddleware() func(action string) echo.MiddlewareFunc {
+	return func(action string) echo.MiddlewareFunc {
+		return func(next echo.HandlerFunc) echo.HandlerFunc {
+			return func(c echo.Context) error {
+				req := c.Request()
+
+				// the size of the body is limited by the router (see maxBodySize)
+				body, err := io.ReadAll(req.Body)
+				if err != nil {
+					return fmt.Errorf("could not read request body: %w", err)
+				}
+				req.Body = io.NopCloser(bytes.NewReader(body))
+
+				var actor string
+				if principal, ok := auth.PrincipalFromContext(c); ok {
+					actor = principal.Subject
+				}
+
+				ctx := req.Context()
+				entry := audit.NewEntry(actor, action, log.CorrelationIDFromContext(ctx), audit.Summarize(body))
+				paramValues := c.ParamValues()
+				for i, name := range c.ParamNames() {
+					if !audit.Sensitive(name) {
+						entry.AddTargets(paramValues[i])
+					}
+				}
+
+				c.SetRequest(req.WithContext(audit.WithEntry(ctx, entry)))
+
+				if err := next(c); err != nil {
+					return err
+				}
+
+				if c.Response().Status < http.StatusBadRequest {
+					if _, ok := entry.Take(); ok {
+						log.FromContext(ctx).WithField("action", action).Error("Audit record was not saved with the change")
+					}
+				}
+
+				return nil
+			}
+		}
+	}
+}
+
+const defaultAuditRecordsLimit = 100
+
+// GetAuditRecords returns the audit records of the caller's tenant matching the filters from the query, newest first.
+func (h Handler) GetAuditRecords(c echo.Context, params openapi.GetAuditRecordsParams) error {
+	filter := entities.AuditRecordsFilter{
+		Limit: defaultAuditRecordsLimit,
+	}
+	if params.Actor != nil {
+		filter.Actor = *params.Actor
+	}
+	if params.Action != nil {
+		filter.Action = *params.Action
+	}
+	if params.TargetId != nil {
+		filter.TargetID = *params.TargetId
+	}
+	if params.From != nil {
+		filter.From = *params.From
+	}
+	if params.To != nil {
+		filter.To = *params.To
+	}
+	if params.Limit != nil {
+		filter.Limit = *params.Limit
+	}
+
+	records, err := h.auditRepository.Find(c.Request().Context(), filter)
+	if err != nil {
+		return fmt.Errorf("failed to find audit records: %w", err)
+	}
+
+	return c.JSON(http.StatusOK, records)
+}
@@ -3,25 +3,48 @@ package http
 import (
 	"context"
 	"tickets/entities"
+	"tickets/http/openapi"
+	"tickets/message"
 
-	"github.com/ThreeDotsLabs/watermill/components/cqrs"
 	"github.com/google/uuid"
 )
 
 type Handler struct {
 	spreadsheetsAPIClient SpreadsheetsAPI
-	eventBus              *cqrs.EventBus
 	ticketsRepository     TicketsRepository
 	showsRepository       ShowsRepository
 	bookingsRepository    BookingsRepository
+	webhooksRepository    WebhooksRepository
+	sheetsReconciler      SheetsReconciler
+	reportsRepository     ReportsRepository
+	apiKeysRepository     APIKeysRepository
+	messageHandlers       MessageHandlers
+	auditRepository       AuditRepository
+	customersRepository   CustomersRepository
 }
 
+var _ openapi.ServerInterface = Handler{}
+
 type SpreadsheetsAPI interface {
 	AppendRow(ctx context.Context, spreadsheetName string, row []string) error
 }
 
+type MessageHandlers interface {
+	List(ctx context.Context) ([]message.HandlerStatus, error)
+	Pause(ctx context.Context, name string) error
+	Resume(ctx context.Context, name string) error
+}
+
+type SheetsReconciler interface {
+	Reconcile(ctx context.Context, sheetName string) (entities.SheetReconciliation, error)
+}
+
 type TicketsRepository interface {
-	GetAll(ctx context.Context) ([]entities.Ticket, error)
+	GetOne(ctx context.Context, ticketID string) (entities.Ticket, error)
+	GetStatusHistory(ctx context.Context, ticketID string) ([]entities.TicketStatusChange, error)
+	Find(ctx context.Context, filter entities.TicketsFilter) ([]entities.Ticket, error)
+	Export(ctx context.Context, filter entities.TicketsFilter, fn func(entities.Ticket) error) error
+	UpdateStatus(ctx context.Context, ticketID string, status entities.TicketStatus) error
 }
 
 type ShowsRepository interface {
@@ -29,6 +52,34 @@ type ShowsRepository interface {
 	GetOne(ctx context.Context, showId uuid.UUID) (entities.Show, error)
 }
 
+type ReportsRepository interface {
+	GetShowReport(ctx context.Context, showID uuid.UUID) (entities.ShowSalesReport, error)
+	GetDailyReports(ctx context.Context, filter entities.SalesReportFilter) ([]entities.DailySalesReport, error)
+}
+
+type APIKeysRepository interface {
+	Add(ctx context.Context, apiKey entities.APIKey) error
+	List(ctx context.Context) ([]entities.APIKey, error)
+	Revoke(ctx context.Context, id uuid.UUID) error
+}
+
+type AuditRepository interface {
+	Find(ctx context.Context, filter entities.AuditRecordsFilter) ([]entities.AuditRecord, error)
+}
+
+type CustomersRepository interface {
+	GetData(ctx context.Context, email string) (entities.CustomerData, error)
+	Erase(ctx context.Context, email string, pseudonym string) (entities.CustomerErasure, error)
+}
+
 type BookingsRepository interface {
 	Add(ctx context.Context, booking entities.Booking) error
+	// PublishTicketStatusChanges publishes the TicketBookingConfirmed and TicketBookingCanceled events with the versions of the tickets.
+	PublishTicketStatusChanges(ctx context.Context, events []any) error
+}
+
+type WebhooksRepository interface {
+	Add(ctx context.Context, subscription entities.WebhookSubscription) error
+	GetOne(ctx context.Context, subscriptionID uuid.UUID) (entities.WebhookSubscription, error)
+	GetDeliveries(ctx context.Context, subscriptionID uuid.UUID) ([]entities.WebhookDelivery, error)
 }
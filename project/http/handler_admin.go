@@ -0,0 +1,71 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"tickets/http/openapi"
+	"tickets/message"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (h Handler) GetAdminHandlers(c echo.Context) error {
+	handlers, err := h.messageHandlers.List(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	response := make([]openapi.HandlerStatus, 0, len(handlers))
+	for _, handler := range handlers {
+		status := openapi.HandlerStatus{
+			Name:          handler.Name,
+			Topic:         handler.Topic,
+			ConsumerGroup: handler.ConsumerGroup,
+			Paused:        handler.Paused,
+		}
+
+		if pending := handler.PendingMessages; pending != nil {
+			status.PendingMessages = &pending.Count
+			if !pending.OldestPublishedAt.IsZero() {
+				age := time.Since(pending.OldestPublishedAt).Seconds()
+				status.OldestPendingAgeSeconds = &age
+			}
+		}
+
+		if lastErr := handler.LastError; lastErr != nil {
+			status.LastError = &openapi.HandlerError{
+				Message:    lastErr.Message,
+				OccurredAt: lastErr.OccurredAt,
+			}
+		}
+
+		response = append(response, status)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+func (h Handler) PostPauseHandler(c echo.Context, name openapi.HandlerName) error {
+	if err := h.messageHandlers.Pause(c.Request().Context(), name); err != nil {
+		return handlerNotFound(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h Handler) PostResumeHandler(c echo.Context, name openapi.HandlerName) error {
+	if err := h.messageHandlers.Resume(c.Request().Context(), name); err != nil {
+		return handlerNotFound(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func handlerNotFound(err error) error {
+	if errors.Is(err, message.ErrUnknownHandler) {
+		return echo.NewHTTPError(http.StatusNotFound, "handler not found")
+	}
+
+	return err
+}
@@ -0,0 +1,82 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"tickets/audit"
+	"tickets/auth"
+	"tickets/db"
+	"tickets/entities"
+	"tickets/http/openapi"
+	"tickets/tenant"
+
+	"github.com/labstack/echo/v4"
+)
+
+type apiKeyResponse struct {
+	entities.APIKey
+	Key string `json:"key"`
+}
+
+func validateCreateAPIKeyRequest(v *validator, r openapi.CreateAPIKeyRequest) {
+	v.text(r.Name, "name")
+
+	roles := make([]string, 0, len(entities.Roles))
+	for _, role := range entities.Roles {
+		roles = append(roles, string(role))
+	}
+	v.oneOf(string(r.Role), roles, "role")
+}
+
+func (h Handler) PostAPIKeys(c echo.Context) error {
+	request, err := bindAndValidate(c, validateCreateAPIKeyRequest)
+	if err != nil {
+		return err
+	}
+
+	// the admins create the keys of their tenant only
+	tenantID := tenant.FromContext(c.Request().Context())
+
+	apiKey, key, err := auth.NewAPIKey(request.Name, entities.Role(request.Role), tenantID)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidAPIKey) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+
+		return err
+	}
+
+	audit.AddTargets(c.Request().Context(), apiKey.ID.String())
+
+	if err = h.apiKeysRepository.Add(c.Request().Context(), apiKey); err != nil {
+		return err
+	}
+
+	// the key is returned only once, only its hash is stored
+	return c.JSON(http.StatusCreated, apiKeyResponse{
+		APIKey: apiKey,
+		Key:    key,
+	})
+}
+
+func (h Handler) GetAPIKeys(c echo.Context) error {
+	apiKeys, err := h.apiKeysRepository.List(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, apiKeys)
+}
+
+func (h Handler) DeleteAPIKey(c echo.Context, apiKeyID openapi.ID) error {
+	err := h.apiKeysRepository.Revoke(c.Request().Context(), apiKeyID)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "API key not found")
+		}
+
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -3,48 +3,50 @@ package http
 import (
 	"errors"
 	"net/http"
+	"tickets/audit"
 	"tickets/db"
 	"tickets/entities"
+	"tickets/http/openapi"
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
 
-type bookingRequest struct {
-	ShowID          uuid.UUID `json:"show_id"`
-	NumberOfTickets int       `json:"number_of_tickets"`
-	CustomerEmail   string    `json:"customer_email"`
-}
-
-type bookingsResponse struct {
-	BookingID uuid.UUID `json:"booking_id"`
+func validateBookTicketsRequest(v *validator, r openapi.BookTicketsRequest) {
+	v.check(r.ShowId != uuid.Nil, "show_id", "is required")
+	v.check(r.NumberOfTickets > 0, "number_of_tickets", "must be greater than 0")
+	v.email(r.CustomerEmail, "customer_email")
 }
 
 func (h Handler) PostBookTickets(c echo.Context) error {
-	var request bookingRequest
-	err := c.Bind(&request)
+	request, err := bindAndValidate(c, validateBookTicketsRequest)
 	if err != nil {
 		return err
 	}
 
-	if request.NumberOfTickets < 1 {
-		return echo.NewHTTPError(http.StatusBadRequest, "number of tickets must be greater than 0")
-	}
-
 	booking := entities.Booking{
 		ID:              uuid.New(),
-		ShowID:          request.ShowID,
+		ShowID:          request.ShowId,
 		NumberOfTickets: request.NumberOfTickets,
 		CustomerEmail:   request.CustomerEmail,
 	}
+	audit.AddTargets(c.Request().Context(), booking.ID.String(), booking.ShowID.String())
+
 	err = h.bookingsRepository.Add(c.Request().Context(), booking);
 	if err != nil {
 		if errors.Is(err, db.ErrExceedingTicketLimit) {
 			return echo.NewHTTPError(http.StatusBadRequest, "not enough seats available")
 		}
+		if errors.Is(err, db.ErrNotFound) {
+			return &ValidationError{
+				Status:  http.StatusUnprocessableEntity,
+				Message: "invalid request",
+				Fields:  []FieldError{{Field: "show_id", Message: "show not found"}},
+			}
+		}
 
 		return err
 	}
 
-	return c.JSON(http.StatusCreated, bookingsResponse{BookingID: booking.ID})
+	return c.JSON(http.StatusCreated, openapi.BookTicketsResponse{BookingId: booking.ID})
 }
@@ -0,0 +1,47 @@
+package http
+
+import (
+	"net/http"
+	"tickets/audit"
+	"tickets/entities"
+	"tickets/http/openapi"
+
+	"github.com/labstack/echo/v4"
+)
+
+func validateCustomerEmail(email string) error {
+	var v validator
+	v.email(email, "email")
+
+	return v.err()
+}
+
+func (h Handler) GetCustomerData(c echo.Context, email openapi.CustomerEmail) error {
+	if err := validateCustomerEmail(email); err != nil {
+		return err
+	}
+
+	data, err := h.customersRepository.GetData(c.Request().Context(), email)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, data)
+}
+
+// EraseCustomerData pseudonymises the customer's data, the audit record gets the pseudonym instead of the email.
+func (h Handler) EraseCustomerData(c echo.Context, email openapi.CustomerEmail) error {
+	if err := validateCustomerEmail(email); err != nil {
+		return err
+	}
+
+	pseudonym := entities.NewCustomerPseudonym()
+	audit.AddTargets(c.Request().Context(), pseudonym)
+
+	erasure, err := h.customersRepository.Erase(c.Request().Context(), email, pseudonym)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, erasure)
+}
@@ -0,0 +1,154 @@
+package http
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"tickets/db"
+	"tickets/entities"
+	"tickets/http/openapi"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetShowReport returns the sales of the show's tickets, as JSON or CSV (depending on the format query param).
+func (h Handler) GetShowReport(c echo.Context, showID openapi.ID, params openapi.GetShowReportParams) error {
+	var format string
+	if params.Format != nil {
+		format = string(*params.Format)
+	}
+
+	format, err := parseReportFormat(format)
+	if err != nil {
+		return err
+	}
+
+	_, err = h.showsRepository.GetOne(c.Request().Context(), showID)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "show not found")
+		}
+
+		return err
+	}
+
+	report, err := h.reportsRepository.GetShowReport(c.Request().Context(), showID)
+	if err != nil {
+		return fmt.Errorf("failed to get show report: %w", err)
+	}
+
+	if format == "csv" {
+		return writeSalesReportCSV(
+			c,
+			fmt.Sprintf("show-%s.csv", showID),
+			"show_id",
+			[]string{showID.String()},
+			[]entities.SalesReport{report.SalesReport},
+		)
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+// GetDailyReports returns the sales of each day as JSON or CSV, filtered by the from, to and show_id query params.
+func (h Handler) GetDailyReports(c echo.Context, params openapi.GetDailyReportsParams) error {
+	var format string
+	if params.Format != nil {
+		format = string(*params.Format)
+	}
+
+	format, err := parseReportFormat(format)
+	if err != nil {
+		return err
+	}
+
+	filter := entities.SalesReportFilter{
+		ShowID: params.ShowId,
+	}
+	if params.From != nil {
+		filter.From = params.From.Time
+	}
+	if params.To != nil {
+		filter.To = params.To.Time
+	}
+
+	reports, err := h.reportsRepository.GetDailyReports(c.Request().Context(), filter)
+	if err != nil {
+		return fmt.Errorf("failed to get daily reports: %w", err)
+	}
+
+	if format == "csv" {
+		dates := make([]string, 0, len(reports))
+		salesReports := make([]entities.SalesReport, 0, len(reports))
+		for _, report := range reports {
+			dates = append(dates, report.Date)
+			salesReports = append(salesReports, report.SalesReport)
+		}
+
+		return writeSalesReportCSV(c, "daily.csv", "date", dates, salesReports)
+	}
+
+	return c.JSON(http.StatusOK, reports)
+}
+
+func parseReportFormat(format string) (string, error) {
+	if format == "" {
+		format = "json"
+	}
+
+	if format != "json" && format != "csv" {
+		return "", echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("unsupported format: %s", format))
+	}
+
+	return format, nil
+}
+
+// writeSalesReportCSV writes a row for each report, with the gross and net columns of every currency, like gross_EUR.
+func writeSalesReportCSV(c echo.Context, fileName string, keyColumn string, keys []string, reports []entities.SalesReport) error {
+	var currencies []string
+	for _, report := range reports {
+		for _, currency := range report.Currencies() {
+			if !slices.Contains(currencies, currency) {
+				currencies = append(currencies, currency)
+			}
+		}
+	}
+	slices.Sort(currencies)
+
+	header := []string{keyColumn, "tickets_sold", "tickets_canceled", "tickets_refunded"}
+	for _, currency := range currencies {
+		header = append(header, "gross_"+currency, "net_"+currency)
+	}
+
+	rows := [][]string{header}
+	for i, report := range reports {
+		row := []string{
+			keys[i],
+			strconv.Itoa(report.TicketsSold),
+			strconv.Itoa(report.TicketsCanceled),
+			strconv.Itoa(report.TicketsRefunded),
+		}
+
+		for _, currency := range currencies {
+			gross, net := "0.00", "0.00"
+			for _, revenue := range report.Revenue {
+				if revenue.Gross.Currency == currency {
+					gross, net = revenue.Gross.Amount, revenue.Net.Amount
+				}
+			}
+			row = append(row, gross, net)
+		}
+
+		rows = append(rows, row)
+	}
+
+	resp := c.Response()
+	resp.Header().Set(echo.HeaderContentType, "text/csv")
+	resp.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, fileName))
+	resp.WriteHeader(http.StatusOK)
+
+	return csv.NewWriter(resp).WriteAll(rows)
+}
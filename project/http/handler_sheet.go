@@ -0,0 +1,21 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"tickets/http/openapi"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetSheetReconciliation compares the rows recorded as appended to the tenant's sheet with the rows in the sheet.
+func (h Handler) GetSheetReconciliation(c echo.Context, sheet openapi.GetSheetReconciliationParamsSheet) error {
+	ctx := c.Request().Context()
+
+	reconciliation, err := h.sheetsReconciler.Reconcile(ctx, string(sheet))
+	if err != nil {
+		return fmt.Errorf("failed to reconcile sheet: %w", err)
+	}
+
+	return c.JSON(http.StatusOK, reconciliation)
+}
@@ -2,43 +2,42 @@ package http
 
 import (
 	"net/http"
+	"tickets/audit"
 	"tickets/entities"
+	"tickets/http/openapi"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
 
-type showsRequest struct {
-	DeadNationID    uuid.UUID `json:"dead_nation_id" db:"dead_nation_id"`
-	NumberOfTickets int       `json:"number_of_tickets" db:"number_of_tickets"`
-	StartTime       time.Time `json:"start_time" db:"start_time"`
-	Title           string    `json:"title" db:"title"`
-	Venue           string    `json:"venue" db:"venue"`
-}
-
-type showsResponse struct {
-	ShowID uuid.UUID `json:"show_id"`
+func validateCreateShowRequest(v *validator, r openapi.CreateShowRequest) {
+	v.check(r.DeadNationId != uuid.Nil, "dead_nation_id", "is required")
+	v.check(r.NumberOfTickets > 0, "number_of_tickets", "must be greater than 0")
+	v.check(r.StartTime.After(time.Now()), "start_time", "must be in the future")
+	v.text(r.Title, "title")
+	v.text(r.Venue, "venue")
 }
 
 func (h Handler) PostShows(c echo.Context) error {
-	var request showsRequest
-	err := c.Bind(&request)
+	request, err := bindAndValidate(c, validateCreateShowRequest)
 	if err != nil {
 		return err
 	}
 
 	show := entities.Show{
 		ID:              uuid.New(),
-		DeadNationID:    request.DeadNationID,
+		DeadNationID:    request.DeadNationId,
 		NumberOfTickets: request.NumberOfTickets,
 		StartTime:       request.StartTime,
 		Title:           request.Title,
 		Venue:           request.Venue,
 	}
+	audit.AddTargets(c.Request().Context(), show.ID.String())
+
 	if err = h.showsRepository.Add(c.Request().Context(), show); err != nil {
 		return err
 	}
 
-	return c.JSON(http.StatusCreated, showsResponse{ShowID: show.ID})
+	return c.JSON(http.StatusCreated, openapi.CreateShowResponse{ShowId: show.ID})
 }
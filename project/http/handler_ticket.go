@@ -1,73 +1,256 @@
 package http
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"tickets/audit"
+	"tickets/db"
 	"tickets/entities"
+	"tickets/http/openapi"
+	"tickets/tenant"
 
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
 
-type ticketsStatusRequest struct {
-	Tickets []ticketStatusRequest `json:"tickets"`
+func validateTicketsStatusRequest(v *validator, r openapi.TicketsStatusRequest) {
+	for i, ticket := range ticketUpdates(r) {
+		field := fmt.Sprintf("tickets[%d].", i)
+		price, customerEmail, bookingID := ticketDetails(ticket)
+
+		v.check(ticket.TicketId != uuid.Nil, field+"ticket_id", "is required")
+
+		// canceled tickets can come without the details, but the confirmed ones need them
+		confirmed := ticket.Status == openapi.TicketStatusUpdateStatusConfirmed
+		if confirmed || price != (entities.Money{}) {
+			v.amount(price.Amount, field+"price.amount")
+			v.currency(price.Currency, field+"price.currency")
+		}
+		if confirmed || customerEmail != "" {
+			v.email(customerEmail, field+"customer_email")
+		}
+		if bookingID != "" {
+			_, err := uuid.Parse(bookingID)
+			v.check(err == nil, field+"booking_id", "must be a UUID")
+		}
+	}
 }
 
-type ticketStatusRequest struct {
-	TicketID      string         `json:"ticket_id"`
-	Status        string         `json:"status"`
-	Price         entities.Money `json:"price"`
-	CustomerEmail string         `json:"customer_email"`
-	BookingID     string         `json:"booking_id"`
+// ticketUpdates returns the updated tickets, the gateway sends null when there are none.
+func ticketUpdates(r openapi.TicketsStatusRequest) []openapi.TicketStatusUpdate {
+	if r.Tickets == nil {
+		return nil
+	}
+
+	return *r.Tickets
 }
 
-func (h Handler) PostTicketsStatus(c echo.Context) error {
-	var request ticketsStatusRequest
-	err := c.Bind(&request)
+// ticketDetails returns the optional details of the ticket, they are empty when the gateway doesn't send them.
+func ticketDetails(ticket openapi.TicketStatusUpdate) (price entities.Money, customerEmail string, bookingID string) {
+	if ticket.Price != nil {
+		price = entities.Money{Amount: ticket.Price.Amount, Currency: ticket.Price.Currency}
+	}
+	if ticket.CustomerEmail != nil {
+		customerEmail = *ticket.CustomerEmail
+	}
+	if ticket.BookingId != nil {
+		bookingID = *ticket.BookingId
+	}
+
+	return price, customerEmail, bookingID
+}
+
+func (h Handler) PostTicketsStatus(c echo.Context, params openapi.PostTicketsStatusParams) error {
+	request, err := bindAndValidate(c, validateTicketsStatusRequest)
 	if err != nil {
 		return err
 	}
 
-	idempotencyKey := c.Request().Header.Get("Idempotency-Key")
-	if idempotencyKey == "" {
-		return echo.NewHTTPError(http.StatusBadRequest, "Idempotency-Key header is required")
-	}
-
-	for _, ticket := range request.Tickets {
-		if ticket.Status == "confirmed" {
-			event := entities.TicketBookingConfirmed{
-				Header:        entities.NewEventHeaderWithIdempotencyKey(idempotencyKey + ticket.TicketID),
-				TicketID:      ticket.TicketID,
-				CustomerEmail: ticket.CustomerEmail,
-				Price:         ticket.Price,
-			}
-
-			if err := h.eventBus.Publish(c.Request().Context(), event); err != nil {
-				return fmt.Errorf("failed to publish TicketBookingConfirmed event: %w", err)
-			}
-		} else if ticket.Status == "canceled" {
-			event := entities.TicketBookingCanceled{
-				Header:        entities.NewEventHeaderWithIdempotencyKey(idempotencyKey + ticket.TicketID),
-				TicketID:      ticket.TicketID,
-				CustomerEmail: ticket.CustomerEmail,
-				Price:         ticket.Price,
-			}
-
-			if err := h.eventBus.Publish(c.Request().Context(), event); err != nil {
-				return fmt.Errorf("failed to publish TicketBookingCanceled event: %w", err)
-			}
+	ctx := c.Request().Context()
+	tenantID := tenant.FromContext(ctx)
+
+	var events []any
+	for _, ticket := range ticketUpdates(request) {
+		ticketID := ticket.TicketId.String()
+		price, customerEmail, bookingID := ticketDetails(ticket)
+		audit.AddTargets(ctx, ticketID, bookingID)
+
+		if ticket.Status == openapi.TicketStatusUpdateStatusConfirmed {
+			events = append(events, entities.TicketBookingConfirmed{
+				Header:        entities.NewEventHeaderWithIdempotencyKey(params.IdempotencyKey + ticketID).WithTenant(tenantID),
+				TicketID:      ticketID,
+				CustomerEmail: customerEmail,
+				Price:         price,
+				BookingID:     bookingID,
+			})
 		} else {
-			return fmt.Errorf("unknown ticket status: %s", ticket.Status)
+			events = append(events, entities.TicketBookingCanceled{
+				Header:        entities.NewEventHeaderWithIdempotencyKey(params.IdempotencyKey + ticketID).WithTenant(tenantID),
+				TicketID:      ticketID,
+				CustomerEmail: customerEmail,
+				Price:         price,
+			})
 		}
 	}
 
+	if err := h.bookingsRepository.PublishTicketStatusChanges(ctx, events); err != nil {
+		return fmt.Errorf("failed to publish ticket status changes: %w", err)
+	}
+
 	return c.NoContent(http.StatusOK)
 }
 
-func (h Handler) GetAllTickets(c echo.Context) error {
-	tickets, err := h.ticketsRepository.GetAll(c.Request().Context())
+const (
+	defaultTicketsLimit = 100
+	maxTicketsLimit     = 1000
+
+	// nextCursorHeader has the cursor of the next page, it's not set on the last page
+	nextCursorHeader = "X-Next-Cursor"
+)
+
+// GetTickets returns a page of the tickets matching the query, the next page has the cursor from X-Next-Cursor.
+func (h Handler) GetTickets(c echo.Context, params openapi.GetTicketsParams) error {
+	filter, err := newTicketsFilter(params)
+	if err != nil {
+		return err
+	}
+
+	filter.Limit = defaultTicketsLimit
+	if params.Limit != nil {
+		filter.Limit = *params.Limit
+		if filter.Limit < 1 || filter.Limit > maxTicketsLimit {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxTicketsLimit))
+		}
+	}
+
+	if params.Cursor != nil && *params.Cursor != "" {
+		after, err := decodeTicketsCursor(*params.Cursor)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid cursor")
+		}
+		filter.After = &after
+	}
+
+	tickets, err := h.ticketsRepository.Find(c.Request().Context(), filter)
 	if err != nil {
 		return fmt.Errorf("failed to find tickets: %w", err)
 	}
 
+	if len(tickets) == filter.Limit {
+		next, err := encodeTicketsCursor(tickets[len(tickets)-1].Cursor())
+		if err != nil {
+			return err
+		}
+		c.Response().Header().Set(nextCursorHeader, next)
+	}
+
 	return c.JSON(http.StatusOK, tickets)
 }
+
+// newTicketsFilter creates the filter from the query params, without the status only active tickets are returned.
+func newTicketsFilter(params openapi.GetTicketsParams) (entities.TicketsFilter, error) {
+	var filter entities.TicketsFilter
+
+	if params.Status != nil {
+		filter.Status = entities.TicketStatus(*params.Status)
+		if !filter.Status.Valid() {
+			return filter, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid status: %s", filter.Status))
+		}
+	}
+	if params.Email != nil {
+		filter.CustomerEmail = *params.Email
+	}
+	if params.BookingId != nil {
+		filter.BookingID = *params.BookingId
+	}
+	if params.UpdatedAfter != nil {
+		filter.UpdatedAfter = *params.UpdatedAfter
+	}
+	if params.UpdatedBefore != nil {
+		filter.UpdatedBefore = *params.UpdatedBefore
+	}
+
+	if params.Sort != nil {
+		var err error
+		filter.Sort, err = entities.ParseTicketsSort(*params.Sort)
+		if err != nil {
+			return filter, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+	}
+
+	return filter, nil
+}
+
+// The cursors are opaque for the clients, so the fields they contain can change.
+func encodeTicketsCursor(cursor entities.TicketsCursor) (string, error) {
+	payload, err := json.Marshal(cursor)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal cursor: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(payload), nil
+}
+
+func decodeTicketsCursor(s string) (entities.TicketsCursor, error) {
+	var cursor entities.TicketsCursor
+
+	payload, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor, err
+	}
+
+	err = json.Unmarshal(payload, &cursor)
+	if err == nil && cursor.TicketID == "" {
+		err = errors.New("missing ticket id")
+	}
+
+	return cursor, err
+}
+
+type ticketResponse struct {
+	entities.Ticket
+	StatusHistory []entities.TicketStatusChange `json:"status_history"`
+}
+
+// GetTicket returns the ticket with its status history, including canceled tickets.
+func (h Handler) GetTicket(c echo.Context, id openapi.ID) error {
+	ticketID := id.String()
+
+	ticket, err := h.ticketsRepository.GetOne(c.Request().Context(), ticketID)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "ticket not found")
+		}
+
+		return err
+	}
+
+	history, err := h.ticketsRepository.GetStatusHistory(c.Request().Context(), ticketID)
+	if err != nil {
+		return fmt.Errorf("failed to get ticket status history: %w", err)
+	}
+
+	return c.JSON(http.StatusOK, ticketResponse{
+		Ticket:        ticket,
+		StatusHistory: history,
+	})
+}
+
+func (h Handler) PostTicketCheckIn(c echo.Context, id openapi.ID) error {
+	err := h.ticketsRepository.UpdateStatus(c.Request().Context(), id.String(), entities.TicketStatusCheckedIn)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "ticket not found")
+		}
+		if errors.Is(err, entities.ErrInvalidStatusTransition) {
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		}
+
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
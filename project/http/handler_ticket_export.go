@@ -0,0 +1,130 @@
+package http
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"tickets/entities"
+	"tickets/http/openapi"
+	"time"
+
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+	"github.com/labstack/echo/v4"
+)
+
+// exportFlushInterval is the number of tickets written before the response is flushed to the client.
+const exportFlushInterval = 500
+
+var exportCSVHeader = []string{
+	"ticket_id",
+	"status",
+	"price_amount",
+	"price_currency",
+	"customer_email",
+	"booking_id",
+	"updated_at",
+	"deleted_at",
+}
+
+// GetTicketsExport streams the tickets matching the query as CSV or NDJSON, as they are read from the database.
+func (h Handler) GetTicketsExport(c echo.Context, params openapi.GetTicketsExportParams) error {
+	filter, err := newTicketsFilter(openapi.GetTicketsParams{
+		Status:        params.Status,
+		Email:         params.Email,
+		BookingId:     params.BookingId,
+		UpdatedAfter:  params.UpdatedAfter,
+		UpdatedBefore: params.UpdatedBefore,
+		Sort:          params.Sort,
+	})
+	if err != nil {
+		return err
+	}
+
+	format := "csv"
+	if params.Format != nil {
+		format = string(*params.Format)
+	}
+
+	if format != "csv" && format != "ndjson" {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("unsupported format: %s", format))
+	}
+
+	var writeTicket func(entities.Ticket) error
+	flush := func() error {
+		return nil
+	}
+
+	resp := c.Response()
+	resp.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="tickets.%s"`, format))
+
+	if format == "csv" {
+		resp.Header().Set(echo.HeaderContentType, "text/csv")
+		resp.WriteHeader(http.StatusOK)
+
+		w := csv.NewWriter(resp)
+		if err := w.Write(exportCSVHeader); err != nil {
+			return err
+		}
+
+		writeTicket = func(ticket entities.Ticket) error {
+			return w.Write(ticketCSVRow(ticket))
+		}
+		flush = func() error {
+			w.Flush()
+			return w.Error()
+		}
+	} else {
+		resp.Header().Set(echo.HeaderContentType, "application/x-ndjson")
+		resp.WriteHeader(http.StatusOK)
+
+		encoder := json.NewEncoder(resp)
+		writeTicket = func(ticket entities.Ticket) error {
+			return encoder.Encode(ticket)
+		}
+	}
+
+	written := 0
+	err = h.ticketsRepository.Export(c.Request().Context(), filter, func(ticket entities.Ticket) error {
+		if err := writeTicket(ticket); err != nil {
+			return err
+		}
+
+		written++
+		if written%exportFlushInterval == 0 {
+			if err := flush(); err != nil {
+				return err
+			}
+			resp.Flush()
+		}
+
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		// the status was already sent, so the client only sees the export cut short
+		log.FromContext(c.Request().Context()).WithError(err).Error("Failed to export tickets")
+	}
+
+	return nil
+}
+
+func ticketCSVRow(ticket entities.Ticket) []string {
+	deletedAt := ""
+	if ticket.DeletedAt != nil {
+		deletedAt = ticket.DeletedAt.Format(time.RFC3339Nano)
+	}
+
+	return []string{
+		ticket.TicketID,
+		string(ticket.Status),
+		ticket.Price.Amount,
+		ticket.Price.Currency,
+		ticket.CustomerEmail,
+		ticket.BookingID,
+		ticket.UpdatedAt.Format(time.RFC3339Nano),
+		deletedAt,
+	}
+}
@@ -0,0 +1,81 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"tickets/audit"
+	"tickets/db"
+	"tickets/http/openapi"
+	"tickets/webhook"
+
+	"github.com/labstack/echo/v4"
+)
+
+func validateCreateWebhookRequest(v *validator, r openapi.CreateWebhookRequest) {
+	if v.required(r.Url, "url") {
+		parsedURL, err := url.Parse(r.Url)
+		v.check(
+			err == nil && (parsedURL.Scheme == "http" || parsedURL.Scheme == "https") && parsedURL.Host != "",
+			"url",
+			"must be an absolute http(s) url",
+		)
+	}
+
+	v.check(len(r.EventTypes) > 0, "event_types", "at least one event type is required")
+	for i, eventType := range r.EventTypes {
+		v.oneOf(string(eventType), webhook.EventTypes, fmt.Sprintf("event_types[%d]", i))
+	}
+}
+
+func (h Handler) PostWebhooks(c echo.Context) error {
+	request, err := bindAndValidate(c, validateCreateWebhookRequest)
+	if err != nil {
+		return err
+	}
+
+	eventTypes := make([]string, 0, len(request.EventTypes))
+	for _, eventType := range request.EventTypes {
+		eventTypes = append(eventTypes, string(eventType))
+	}
+
+	subscription, err := webhook.NewSubscription(request.Url, eventTypes)
+	if err != nil {
+		if errors.Is(err, webhook.ErrInvalidSubscription) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+
+		return err
+	}
+
+	audit.AddTargets(c.Request().Context(), subscription.ID.String())
+
+	if err = h.webhooksRepository.Add(c.Request().Context(), subscription); err != nil {
+		return err
+	}
+
+	// the secret is returned only once, the subscriber uses it to verify signatures
+	return c.JSON(http.StatusCreated, openapi.CreateWebhookResponse{
+		WebhookId: subscription.ID,
+		Secret:    subscription.Secret,
+	})
+}
+
+func (h Handler) GetWebhookDeliveries(c echo.Context, webhookID openapi.ID) error {
+	_, err := h.webhooksRepository.GetOne(c.Request().Context(), webhookID)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "webhook not found")
+		}
+
+		return err
+	}
+
+	deliveries, err := h.webhooksRepository.GetDeliveries(c.Request().Context(), webhookID)
+	if err != nil {
+		return fmt.Errorf("failed to find webhook deliveries: %w", err)
+	}
+
+	return c.JSON(http.StatusOK, deliveries)
+}
@@ -0,0 +1,153 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"tickets/http/openapi"
+
+	"github.com/deepmap/oapi-codegen/pkg/middleware"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/labstack/echo/v4"
+)
+
+// loadOpenAPISpec loads the specification embedded in the generated code.
+func loadOpenAPISpec() *openapi3.T {
+	spec, err := openapi.GetSwagger()
+	if err != nil {
+		panic(fmt.Errorf("failed to load the OpenAPI specification: %w", err))
+	}
+
+	// the API is served on any host
+	spec.Servers = nil
+
+	return spec
+}
+
+// newOpenAPIValidator validates the requests against the specification, it should run after the authentication.
+func newOpenAPIValidator(spec *openapi3.T) echo.MiddlewareFunc {
+	return middleware.OapiRequestValidatorWithOptions(spec, &middleware.Options{
+		Options: openapi3filter.Options{
+			MultiError: true,
+			// the credentials are checked by the auth middlewares
+			AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+		},
+		MultiErrorHandler: func(err openapi3.MultiError) *echo.HTTPError {
+			return &echo.HTTPError{Code: http.StatusBadRequest, Message: err.Error(), Internal: err}
+		},
+		ErrorHandler: func(c echo.Context, err *echo.HTTPError) error {
+			if err.Internal == nil {
+				return err
+			}
+
+			return openAPIValidationError(err.Internal)
+		},
+	})
+}
+
+// openAPIValidationError converts the validation error, invalid types get 400 and invalid values get 422.
+func openAPIValidationError(err error) *ValidationError {
+	validationErr := &ValidationError{
+		Status:  http.StatusUnprocessableEntity,
+		Message: "invalid request",
+	}
+
+	malformed := func(field FieldError) {
+		validationErr.Status = http.StatusBadRequest
+		validationErr.Message = "malformed request"
+		if field.Field != "" {
+			validationErr.Fields = append(validationErr.Fields, field)
+		}
+	}
+
+	for _, err := range unwrapMultiError(err) {
+		var requestErr *openapi3filter.RequestError
+		if !errors.As(err, &requestErr) {
+			malformed(FieldError{})
+			continue
+		}
+
+		schemaErrs := unwrapMultiError(requestErr.Err)
+
+		switch {
+		case requestErr.Parameter != nil:
+			for _, err := range schemaErrs {
+				malformed(FieldError{Field: requestErr.Parameter.Name, Message: schemaErrorMessage(err, requestErr.Reason)})
+			}
+		case requestErr.RequestBody != nil:
+			for _, err := range schemaErrs {
+				var schemaErr *openapi3.SchemaError
+				if !errors.As(err, &schemaErr) {
+					// the body isn't JSON or it's missing
+					malformed(FieldError{})
+					continue
+				}
+
+				field := FieldError{
+					Field:   jsonPath(schemaErr.JSONPointer()),
+					Message: schemaErrorMessage(schemaErr, ""),
+				}
+				if schemaErr.SchemaField == "type" {
+					malformed(field)
+				} else {
+					validationErr.Fields = append(validationErr.Fields, field)
+				}
+			}
+		default:
+			malformed(FieldError{})
+		}
+	}
+
+	return validationErr
+}
+
+// unwrapMultiError returns the errors of the multi error (and its nested multi errors).
+func unwrapMultiError(err error) []error {
+	multiErr, ok := err.(openapi3.MultiError)
+	if !ok {
+		return []error{err}
+	}
+
+	var errs []error
+	for _, err := range multiErr {
+		errs = append(errs, unwrapMultiError(err)...)
+	}
+
+	return errs
+}
+
+func schemaErrorMessage(err error, reason string) string {
+	var schemaErr *openapi3.SchemaError
+	if errors.As(err, &schemaErr) && schemaErr.SchemaField != "required" {
+		return schemaErr.Reason
+	}
+	if schemaErr != nil || errors.Is(err, openapi3filter.ErrInvalidRequired) {
+		return "is required"
+	}
+	if reason != "" || err == nil {
+		return reason
+	}
+
+	return err.Error()
+}
+
+// jsonPath formats the JSON pointer like the fields of FieldError, like "tickets[0].price".
+func jsonPath(pointer []string) string {
+	var path strings.Builder
+	for _, part := range pointer {
+		if _, err := strconv.Atoi(part); err == nil {
+			path.WriteString("[" + part + "]")
+			continue
+		}
+
+		if path.Len() > 0 {
+			path.WriteString(".")
+		}
+		path.WriteString(part)
+	}
+
+	return path.String()
+}
@@ -0,0 +1,4 @@
+// Package openapi contains the OpenAPI specification of the tickets API, and the code generated from it.
+package openapi
+
+//go:generate go run github.com/deepmap/oapi-codegen/cmd/oapi-codegen@v1.12.4 -package openapi -generate types,server,spec -o openapi.gen.go openapi.yaml
@@ -0,0 +1,1478 @@
+// Package openapi provides primitives to interact with the openapi HTTP API.
+//
+// Code generated by github.com/deepmap/oapi-codegen version v1.12.4 DO NOT EDIT.
+package openapi
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/deepmap/oapi-codegen/pkg/runtime"
+	openapi_types "github.com/deepmap/oapi-codegen/pkg/types"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	ApiKeyScopes = "apiKey.Scopes"
+	BearerScopes = "bearer.Scopes"
+)
+
+// Defines values for CreateWebhookRequestEventTypes.
+const (
+	BookingMade            CreateWebhookRequestEventTypes = "BookingMade"
+	CustomerDataErased     CreateWebhookRequestEventTypes = "CustomerDataErased"
+	TicketBookingCanceled  CreateWebhookRequestEventTypes = "TicketBookingCanceled"
+	TicketBookingConfirmed CreateWebhookRequestEventTypes = "TicketBookingConfirmed"
+	TicketPrinted          CreateWebhookRequestEventTypes = "TicketPrinted"
+	TicketRefunded         CreateWebhookRequestEventTypes = "TicketRefunded"
+)
+
+// Defines values for Role.
+const (
+	Admin    Role = "admin"
+	Gateway  Role = "gateway"
+	Promoter Role = "promoter"
+	Support  Role = "support"
+)
+
+// Defines values for TicketStatus.
+const (
+	TicketStatusCanceled  TicketStatus = "canceled"
+	TicketStatusCheckedIn TicketStatus = "checked-in"
+	TicketStatusConfirmed TicketStatus = "confirmed"
+	TicketStatusPrinted   TicketStatus = "printed"
+	TicketStatusRefunded  TicketStatus = "refunded"
+)
+
+// Defines values for TicketStatusUpdateStatus.
+const (
+	TicketStatusUpdateStatusCanceled  TicketStatusUpdateStatus = "canceled"
+	TicketStatusUpdateStatusConfirmed TicketStatusUpdateStatus = "confirmed"
+)
+
+// Defines values for ReportFormat.
+const (
+	ReportFormatCsv  ReportFormat = "csv"
+	ReportFormatJson ReportFormat = "json"
+)
+
+// Defines values for GetDailyReportsParamsFormat.
+const (
+	GetDailyReportsParamsFormatCsv  GetDailyReportsParamsFormat = "csv"
+	GetDailyReportsParamsFormatJson GetDailyReportsParamsFormat = "json"
+)
+
+// Defines values for GetShowReportParamsFormat.
+const (
+	GetShowReportParamsFormatCsv  GetShowReportParamsFormat = "csv"
+	GetShowReportParamsFormatJson GetShowReportParamsFormat = "json"
+)
+
+// Defines values for GetSheetReconciliationParamsSheet.
+const (
+	TicketsToPrint  GetSheetReconciliationParamsSheet = "tickets-to-print"
+	TicketsToRefund GetSheetReconciliationParamsSheet = "tickets-to-refund"
+)
+
+// Defines values for GetTicketsExportParamsFormat.
+const (
+	GetTicketsExportParamsFormatCsv    GetTicketsExportParamsFormat = "csv"
+	GetTicketsExportParamsFormatNdjson GetTicketsExportParamsFormat = "ndjson"
+)
+
+// APIKey defines model for APIKey.
+type APIKey struct {
+	CreatedAt time.Time          `json:"created_at"`
+	Id        openapi_types.UUID `json:"id"`
+	Name      string             `json:"name"`
+
+	// Prefix The beginning of the key, so the keys can be told apart.
+	Prefix    string     `json:"prefix"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	Role      Role       `json:"role"`
+
+	// TenantId The tenant the callers act for, `default` when it's not set.
+	TenantId TenantID `json:"tenant_id"`
+}
+
+// AuditRecord defines model for AuditRecord.
+type AuditRecord struct {
+	// Action The operation of the request, like `PostBookTickets`.
+	Action string `json:"action"`
+
+	// Actor The ID of the API key, or the operator from the JWT.
+	Actor         string             `json:"actor"`
+	CorrelationId string             `json:"correlation_id"`
+	Id            openapi_types.UUID `json:"id"`
+	OccurredAt    time.Time          `json:"occurred_at"`
+
+	// Summary The request body without the emails and secrets, truncated to 1000 characters.
+	Summary string `json:"summary"`
+
+	// TargetIds The IDs of the shows, bookings, tickets (or other resources) changed by the request.
+	TargetIds []string `json:"target_ids"`
+}
+
+// BookTicketsRequest defines model for BookTicketsRequest.
+type BookTicketsRequest struct {
+	CustomerEmail   string             `json:"customer_email"`
+	NumberOfTickets int                `json:"number_of_tickets"`
+	ShowId          openapi_types.UUID `json:"show_id"`
+}
+
+// BookTicketsResponse defines model for BookTicketsResponse.
+type BookTicketsResponse struct {
+	BookingId openapi_types.UUID `json:"booking_id"`
+}
+
+// Booking defines model for Booking.
+type Booking struct {
+	CustomerEmail   string             `json:"customer_email"`
+	Id              openapi_types.UUID `json:"id"`
+	NumberOfTickets int                `json:"number_of_tickets"`
+	ShowId          openapi_types.UUID `json:"show_id"`
+}
+
+// CreateAPIKeyRequest defines model for CreateAPIKeyRequest.
+type CreateAPIKeyRequest struct {
+	Name string `json:"name"`
+	Role Role   `json:"role"`
+}
+
+// CreateAPIKeyResponse defines model for CreateAPIKeyResponse.
+type CreateAPIKeyResponse struct {
+	CreatedAt time.Time          `json:"created_at"`
+	Id        openapi_types.UUID `json:"id"`
+
+	// Key The API key, it's returned only once.
+	Key  string `json:"key"`
+	Name string `json:"name"`
+
+	// Prefix The beginning of the key, so the keys can be told apart.
+	Prefix    string     `json:"prefix"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	Role      Role       `json:"role"`
+
+	// TenantId The tenant the callers act for, `default` when it's not set.
+	TenantId TenantID `json:"tenant_id"`
+}
+
+// CreateShowRequest defines model for CreateShowRequest.
+type CreateShowRequest struct {
+	DeadNationId    openapi_types.UUID `json:"dead_nation_id"`
+	NumberOfTickets int                `json:"number_of_tickets"`
+
+	// StartTime The start of the show, it must be in the future.
+	StartTime time.Time `json:"start_time"`
+	Title     string    `json:"title"`
+	Venue     string    `json:"venue"`
+}
+
+// CreateShowResponse defines model for CreateShowResponse.
+type CreateShowResponse struct {
+	ShowId openapi_types.UUID `json:"show_id"`
+}
+
+// CreateWebhookRequest defines model for CreateWebhookRequest.
+type CreateWebhookRequest struct {
+	EventTypes []CreateWebhookRequestEventTypes `json:"event_types"`
+
+	// Url The absolute http(s) URL the events are sent to.
+	Url string `json:"url"`
+}
+
+// CreateWebhookRequestEventTypes defines model for CreateWebhookRequest.EventTypes.
+type CreateWebhookRequestEventTypes string
+
+// CreateWebhookResponse defines model for CreateWebhookResponse.
+type CreateWebhookResponse struct {
+	// Secret The secret the deliveries are signed with, it's returned only once.
+	Secret    string             `json:"secret"`
+	WebhookId openapi_types.UUID `json:"webhook_id"`
+}
+
+// CustomerData defines model for CustomerData.
+type CustomerData struct {
+	Bookings        []Booking                `json:"bookings"`
+	Email           string                   `json:"email"`
+	SheetRows       []SheetRow               `json:"sheet_rows"`
+	Tickets         []TicketWithHistory      `json:"tickets"`
+	WebhookMessages []CustomerWebhookMessage `json:"webhook_messages"`
+}
+
+// CustomerErasure defines model for CustomerErasure.
+type CustomerErasure struct {
+	BookingIds []string `json:"booking_ids"`
+
+	// Pseudonym The address that replaced the customer's email.
+	Pseudonym string `json:"pseudonym"`
+
+	// SheetRows The number of the pseudonymised sheet rows.
+	SheetRows int      `json:"sheet_rows"`
+	TicketIds []string `json:"ticket_ids"`
+
+	// WebhookMessages The number of the pseudonymised webhook messages.
+	WebhookMessages int `json:"webhook_messages"`
+}
+
+// CustomerWebhookMessage defines model for CustomerWebhookMessage.
+type CustomerWebhookMessage struct {
+	// Body The payload sent to the webhook.
+	Body map[string]interface{} `json:"body"`
+
+	// CompletedAt Missing when the message wasn't delivered yet.
+	CompletedAt    *time.Time         `json:"completed_at,omitempty"`
+	EventId        string             `json:"event_id"`
+	EventType      string             `json:"event_type"`
+	SubscriptionId openapi_types.UUID `json:"subscription_id"`
+}
+
+// DailySalesReport defines model for DailySalesReport.
+type DailySalesReport struct {
+	Date openapi_types.Date `json:"date"`
+
+	// Revenue The revenue in each currency, the net revenue doesn't include the canceled tickets.
+	Revenue         []Revenue `json:"revenue"`
+	TicketsCanceled int       `json:"tickets_canceled"`
+	TicketsRefunded int       `json:"tickets_refunded"`
+	TicketsSold     int       `json:"tickets_sold"`
+}
+
+// Error defines model for Error.
+type Error struct {
+	Error  string        `json:"error"`
+	Fields *[]FieldError `json:"fields,omitempty"`
+}
+
+// FieldError defines model for FieldError.
+type FieldError struct {
+	// Field The JSON path of the field, like `tickets[0].price.currency`.
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// HandlerError defines model for HandlerError.
+type HandlerError struct {
+	Message    string    `json:"message"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// HandlerStatus defines model for HandlerStatus.
+type HandlerStatus struct {
+	ConsumerGroup string        `json:"consumer_group"`
+	LastError     *HandlerError `json:"last_error,omitempty"`
+	Name          string        `json:"name"`
+
+	// OldestPendingAgeSeconds How long ago the oldest pending message was published, missing when there are no pending messages.
+	OldestPendingAgeSeconds *float64 `json:"oldest_pending_age_seconds,omitempty"`
+	Paused                  bool     `json:"paused"`
+
+	// PendingMessages The messages delivered to the consumer group, but not acknowledged yet.
+	// Missing when the broker doesn't report them.
+	PendingMessages *int64 `json:"pending_messages,omitempty"`
+	Topic           string `json:"topic"`
+}
+
+// Money defines model for Money.
+type Money struct {
+	// Amount The decimal amount, with at most 2 decimal places.
+	Amount string `json:"amount"`
+
+	// Currency The ISO 4217 currency code.
+	Currency string `json:"currency"`
+}
+
+// Revenue defines model for Revenue.
+type Revenue struct {
+	Gross Money `json:"gross"`
+	Net   Money `json:"net"`
+}
+
+// Role defines model for Role.
+type Role string
+
+// SalesReport defines model for SalesReport.
+type SalesReport struct {
+	// Revenue The revenue in each currency, the net revenue doesn't include the canceled tickets.
+	Revenue         []Revenue `json:"revenue"`
+	TicketsCanceled int       `json:"tickets_canceled"`
+	TicketsRefunded int       `json:"tickets_refunded"`
+	TicketsSold     int       `json:"tickets_sold"`
+}
+
+// SheetReconciliation defines model for SheetReconciliation.
+type SheetReconciliation struct {
+	// Erased The rows of the erased customers' data that are still in the sheet with the customer's data, they have
+	// to be removed from the sheet. The rows are returned with the pseudonyms.
+	Erased [][]string `json:"erased"`
+
+	// InSheet The number of rows in the sheet.
+	InSheet int `json:"in_sheet"`
+
+	// Missing The recorded rows that are not in the sheet.
+	Missing [][]string `json:"missing"`
+
+	// Recorded The number of rows recorded as appended to the sheet.
+	Recorded int `json:"recorded"`
+
+	// Rejected The rows the sheet rejected, they are not expected in the sheet.
+	Rejected [][]string `json:"rejected"`
+
+	// SheetName The name of the sheet, without the tenant.
+	SheetName string `json:"sheet_name"`
+
+	// Unexpected The rows in the sheet that were not recorded.
+	Unexpected [][]string `json:"unexpected"`
+}
+
+// SheetRow defines model for SheetRow.
+type SheetRow struct {
+	AddedAt time.Time `json:"added_at"`
+
+	// AppendedAt Missing when the row wasn't appended to the sheet yet.
+	AppendedAt *time.Time `json:"appended_at,omitempty"`
+	Columns    []string   `json:"columns"`
+
+	// ErasedAt Set when the customer's data in the row was erased.
+	ErasedAt *time.Time `json:"erased_at,omitempty"`
+
+	// RejectedAt Set when the sheet rejected the row, it's not appended then.
+	RejectedAt *time.Time `json:"rejected_at,omitempty"`
+
+	// Rejection The reason the sheet rejected the row.
+	Rejection *string `json:"rejection,omitempty"`
+	SheetName string  `json:"sheet_name"`
+	TicketId  string  `json:"ticket_id"`
+}
+
+// ShowSalesReport defines model for ShowSalesReport.
+type ShowSalesReport struct {
+	// Revenue The revenue in each currency, the net revenue doesn't include the canceled tickets.
+	Revenue         []Revenue          `json:"revenue"`
+	ShowId          openapi_types.UUID `json:"show_id"`
+	TicketsCanceled int                `json:"tickets_canceled"`
+	TicketsRefunded int                `json:"tickets_refunded"`
+	TicketsSold     int                `json:"tickets_sold"`
+}
+
+// TenantID The tenant the callers act for, `default` when it's not set.
+type TenantID = string
+
+// Ticket defines model for Ticket.
+type Ticket struct {
+	BookingId     string       `json:"booking_id"`
+	CustomerEmail string       `json:"customer_email"`
+	DeletedAt     *time.Time   `json:"deleted_at,omitempty"`
+	Price         Money        `json:"price"`
+	Status        TicketStatus `json:"status"`
+	TicketId      string       `json:"ticket_id"`
+	UpdatedAt     time.Time    `json:"updated_at"`
+}
+
+// TicketStatus defines model for TicketStatus.
+type TicketStatus string
+
+// TicketStatusChange defines model for TicketStatusChange.
+type TicketStatusChange struct {
+	ChangedAt time.Time    `json:"changed_at"`
+	Status    TicketStatus `json:"status"`
+}
+
+// TicketStatusUpdate The details are required for the confirmed tickets, the canceled tickets can come without them
+// (or with empty details).
+type TicketStatusUpdate struct {
+	// BookingId The booking of the ticket (a UUID), it's empty for tickets not booked with the API.
+	BookingId     *string                  `json:"booking_id,omitempty"`
+	CustomerEmail *string                  `json:"customer_email,omitempty"`
+	Price         *Money                   `json:"price,omitempty"`
+	Status        TicketStatusUpdateStatus `json:"status"`
+	TicketId      openapi_types.UUID       `json:"ticket_id"`
+}
+
+// TicketStatusUpdateStatus defines model for TicketStatusUpdate.Status.
+type TicketStatusUpdateStatus string
+
+// TicketWithHistory defines model for TicketWithHistory.
+type TicketWithHistory struct {
+	BookingId     string               `json:"booking_id"`
+	CustomerEmail string               `json:"customer_email"`
+	DeletedAt     *time.Time           `json:"deleted_at,omitempty"`
+	Price         Money                `json:"price"`
+	Status        TicketStatus         `json:"status"`
+	StatusHistory []TicketStatusChange `json:"status_history"`
+	TicketId      string               `json:"ticket_id"`
+	UpdatedAt     time.Time            `json:"updated_at"`
+}
+
+// TicketsStatusRequest defines model for TicketsStatusRequest.
+type TicketsStatusRequest struct {
+	Tickets *[]TicketStatusUpdate `json:"tickets"`
+}
+
+// WebhookDelivery defines model for WebhookDelivery.
+type WebhookDelivery struct {
+	Attempt        int                `json:"attempt"`
+	DeliveredAt    time.Time          `json:"delivered_at"`
+	Error          *string            `json:"error,omitempty"`
+	EventId        string             `json:"event_id"`
+	EventType      string             `json:"event_type"`
+	Id             openapi_types.UUID `json:"id"`
+	StatusCode     int                `json:"status_code"`
+	SubscriptionId openapi_types.UUID `json:"subscription_id"`
+	Succeeded      bool               `json:"succeeded"`
+}
+
+// BookingIDFilter defines model for BookingIDFilter.
+type BookingIDFilter = string
+
+// CustomerEmail defines model for CustomerEmail.
+type CustomerEmail = string
+
+// EmailFilter defines model for EmailFilter.
+type EmailFilter = string
+
+// HandlerName defines model for HandlerName.
+type HandlerName = string
+
+// ID defines model for ID.
+type ID = openapi_types.UUID
+
+// IdempotencyKey defines model for IdempotencyKey.
+type IdempotencyKey = string
+
+// ReportFormat defines model for ReportFormat.
+type ReportFormat string
+
+// TicketStatusFilter defines model for TicketStatusFilter.
+type TicketStatusFilter = TicketStatus
+
+// TicketsSort defines model for TicketsSort.
+type TicketsSort = string
+
+// UpdatedAfterFilter defines model for UpdatedAfterFilter.
+type UpdatedAfterFilter = time.Time
+
+// UpdatedBeforeFilter defines model for UpdatedBeforeFilter.
+type UpdatedBeforeFilter = time.Time
+
+// BadRequest defines model for BadRequest.
+type BadRequest = Error
+
+// Conflict defines model for Conflict.
+type Conflict = Error
+
+// Forbidden defines model for Forbidden.
+type Forbidden = Error
+
+// NotFound defines model for NotFound.
+type NotFound = Error
+
+// Unauthorized defines model for Unauthorized.
+type Unauthorized = Error
+
+// UnprocessableEntity defines model for UnprocessableEntity.
+type UnprocessableEntity = Error
+
+// GetAuditRecordsParams defines parameters for GetAuditRecords.
+type GetAuditRecordsParams struct {
+	// Actor The ID of the API key, or the operator from the JWT.
+	Actor *string `form:"actor,omitempty" json:"actor,omitempty"`
+
+	// Action The operation of the request, like `PostBookTickets`.
+	Action *string `form:"action,omitempty" json:"action,omitempty"`
+
+	// TargetId The ID of a show, booking, ticket (or another resource) changed by the request.
+	TargetId *string    `form:"target_id,omitempty" json:"target_id,omitempty"`
+	From     *time.Time `form:"from,omitempty" json:"from,omitempty"`
+
+	// To The records before this time are returned.
+	To    *time.Time `form:"to,omitempty" json:"to,omitempty"`
+	Limit *int       `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// GetDailyReportsParams defines parameters for GetDailyReports.
+type GetDailyReportsParams struct {
+	// ShowId Limits the sales to the tickets of the show.
+	ShowId *openapi_types.UUID `form:"show_id,omitempty" json:"show_id,omitempty"`
+
+	// From The first day of the reports.
+	From *openapi_types.Date `form:"from,omitempty" json:"from,omitempty"`
+
+	// To The last day of the reports.
+	To     *openapi_types.Date          `form:"to,omitempty" json:"to,omitempty"`
+	Format *GetDailyReportsParamsFormat `form:"format,omitempty" json:"format,omitempty"`
+}
+
+// GetDailyReportsParamsFormat defines parameters for GetDailyReports.
+type GetDailyReportsParamsFormat string
+
+// GetShowReportParams defines parameters for GetShowReport.
+type GetShowReportParams struct {
+	Format *GetShowReportParamsFormat `form:"format,omitempty" json:"format,omitempty"`
+}
+
+// GetShowReportParamsFormat defines parameters for GetShowReport.
+type GetShowReportParamsFormat string
+
+// GetSheetReconciliationParamsSheet defines parameters for GetSheetReconciliation.
+type GetSheetReconciliationParamsSheet string
+
+// GetTicketsParams defines parameters for GetTickets.
+type GetTicketsParams struct {
+	Status        *TicketStatusFilter  `form:"status,omitempty" json:"status,omitempty"`
+	Email         *EmailFilter         `form:"email,omitempty" json:"email,omitempty"`
+	BookingId     *BookingIDFilter     `form:"booking_id,omitempty" json:"booking_id,omitempty"`
+	UpdatedAfter  *UpdatedAfterFilter  `form:"updated_after,omitempty" json:"updated_after,omitempty"`
+	UpdatedBefore *UpdatedBeforeFilter `form:"updated_before,omitempty" json:"updated_before,omitempty"`
+
+	// Sort The field to sort by, prefixed with "-" for the descending order.
+	Sort  *TicketsSort `form:"sort,omitempty" json:"sort,omitempty"`
+	Limit *int         `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Cursor The cursor from the `X-Next-Cursor` header of the previous page.
+	Cursor *string `form:"cursor,omitempty" json:"cursor,omitempty"`
+}
+
+// PostTicketsStatusParams defines parameters for PostTicketsStatus.
+type PostTicketsStatusParams struct {
+	// IdempotencyKey The key of the request, the tickets are updated only once for each key.
+	IdempotencyKey IdempotencyKey `json:"Idempotency-Key"`
+
+	// WebhookTimestamp The time of the request, as Unix seconds.
+	WebhookTimestamp string `json:"Webhook-Timestamp"`
+
+	// WebhookSignature The HMAC-SHA256 signatures of the request, separated by commas.
+	WebhookSignature string `json:"Webhook-Signature"`
+}
+
+// GetTicketsExportParams defines parameters for GetTicketsExport.
+type GetTicketsExportParams struct {
+	Status        *TicketStatusFilter  `form:"status,omitempty" json:"status,omitempty"`
+	Email         *EmailFilter         `form:"email,omitempty" json:"email,omitempty"`
+	BookingId     *BookingIDFilter     `form:"booking_id,omitempty" json:"booking_id,omitempty"`
+	UpdatedAfter  *UpdatedAfterFilter  `form:"updated_after,omitempty" json:"updated_after,omitempty"`
+	UpdatedBefore *UpdatedBeforeFilter `form:"updated_before,omitempty" json:"updated_before,omitempty"`
+
+	// Sort The field to sort by, prefixed with "-" for the descending order.
+	Sort   *TicketsSort                  `form:"sort,omitempty" json:"sort,omitempty"`
+	Format *GetTicketsExportParamsFormat `form:"format,omitempty" json:"format,omitempty"`
+}
+
+// GetTicketsExportParamsFormat defines parameters for GetTicketsExport.
+type GetTicketsExportParamsFormat string
+
+// PostAPIKeysJSONRequestBody defines body for PostAPIKeys for application/json ContentType.
+type PostAPIKeysJSONRequestBody = CreateAPIKeyRequest
+
+// PostBookTicketsJSONRequestBody defines body for PostBookTickets for application/json ContentType.
+type PostBookTicketsJSONRequestBody = BookTicketsRequest
+
+// PostShowsJSONRequestBody defines body for PostShows for application/json ContentType.
+type PostShowsJSONRequestBody = CreateShowRequest
+
+// PostTicketsStatusJSONRequestBody defines body for PostTicketsStatus for application/json ContentType.
+type PostTicketsStatusJSONRequestBody = TicketsStatusRequest
+
+// PostWebhooksJSONRequestBody defines body for PostWebhooks for application/json ContentType.
+type PostWebhooksJSONRequestBody = CreateWebhookRequest
+
+// ServerInterface represents all server handlers.
+type ServerInterface interface {
+	// List the message handlers
+	// (GET /admin/handlers)
+	GetAdminHandlers(ctx echo.Context) error
+	// Pause the message handler
+	// (POST /admin/handlers/{name}/pause)
+	PostPauseHandler(ctx echo.Context, name HandlerName) error
+	// Resume the message handler
+	// (POST /admin/handlers/{name}/resume)
+	PostResumeHandler(ctx echo.Context, name HandlerName) error
+	// List the API keys
+	// (GET /api-keys)
+	GetAPIKeys(ctx echo.Context) error
+	// Create an API key
+	// (POST /api-keys)
+	PostAPIKeys(ctx echo.Context) error
+	// Revoke the API key
+	// (DELETE /api-keys/{id})
+	DeleteAPIKey(ctx echo.Context, id ID) error
+	// List the audit records
+	// (GET /audit-records)
+	GetAuditRecords(ctx echo.Context, params GetAuditRecordsParams) error
+	// Book tickets for a show
+	// (POST /book-tickets)
+	PostBookTickets(ctx echo.Context) error
+	// Erase the customer's data
+	// (DELETE /customers/{email})
+	EraseCustomerData(ctx echo.Context, email CustomerEmail) error
+	// Export the customer's data
+	// (GET /customers/{email}/data)
+	GetCustomerData(ctx echo.Context, email CustomerEmail) error
+	// Get the daily sales reports
+	// (GET /reports/daily)
+	GetDailyReports(ctx echo.Context, params GetDailyReportsParams) error
+	// Get the sales report of the show
+	// (GET /reports/shows/{id})
+	GetShowReport(ctx echo.Context, id ID, params GetShowReportParams) error
+	// Reconcile the sheet
+	// (GET /sheets/{sheet}/reconciliation)
+	GetSheetReconciliation(ctx echo.Context, sheet GetSheetReconciliationParamsSheet) error
+	// Create a show
+	// (POST /shows)
+	PostShows(ctx echo.Context) error
+	// List the tickets
+	// (GET /tickets)
+	GetTickets(ctx echo.Context, params GetTicketsParams) error
+	// Update the status of the tickets
+	// (POST /tickets-status)
+	PostTicketsStatus(ctx echo.Context, params PostTicketsStatusParams) error
+	// Export the tickets
+	// (GET /tickets/export)
+	GetTicketsExport(ctx echo.Context, params GetTicketsExportParams) error
+	// Get the ticket with its status history
+	// (GET /tickets/{id})
+	GetTicket(ctx echo.Context, id ID) error
+	// Check in the ticket
+	// (POST /tickets/{id}/check-in)
+	PostTicketCheckIn(ctx echo.Context, id ID) error
+	// Subscribe a partner to the events
+	// (POST /webhooks)
+	PostWebhooks(ctx echo.Context) error
+	// List the deliveries of the webhook
+	// (GET /webhooks/{id}/deliveries)
+	GetWebhookDeliveries(ctx echo.Context, id ID) error
+}
+
+// ServerInterfaceWrapper converts echo contexts to parameters.
+type ServerInterfaceWrapper struct {
+	Handler ServerInterface
+}
+
+// GetAdminHandlers converts echo context to params.
+func (w *ServerInterfaceWrapper) GetAdminHandlers(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(ApiKeyScopes, []string{""})
+
+	ctx.Set(BearerScopes, []string{""})
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetAdminHandlers(ctx)
+	return err
+}
+
+// PostPauseHandler converts echo context to params.
+func (w *ServerInterfaceWrapper) PostPauseHandler(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "name" -------------
+	var name HandlerName
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "name", runtime.ParamLocationPath, ctx.Param("name"), &name)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter name: %s", err))
+	}
+
+	ctx.Set(ApiKeyScopes, []string{""})
+
+	ctx.Set(BearerScopes, []string{""})
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.PostPauseHandler(ctx, name)
+	return err
+}
+
+// PostResumeHandler converts echo context to params.
+func (w *ServerInterfaceWrapper) PostResumeHandler(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "name" -------------
+	var name HandlerName
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "name", runtime.ParamLocationPath, ctx.Param("name"), &name)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter name: %s", err))
+	}
+
+	ctx.Set(ApiKeyScopes, []string{""})
+
+	ctx.Set(BearerScopes, []string{""})
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.PostResumeHandler(ctx, name)
+	return err
+}
+
+// GetAPIKeys converts echo context to params.
+func (w *ServerInterfaceWrapper) GetAPIKeys(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(ApiKeyScopes, []string{""})
+
+	ctx.Set(BearerScopes, []string{""})
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetAPIKeys(ctx)
+	return err
+}
+
+// PostAPIKeys converts echo context to params.
+func (w *ServerInterfaceWrapper) PostAPIKeys(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(ApiKeyScopes, []string{""})
+
+	ctx.Set(BearerScopes, []string{""})
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.PostAPIKeys(ctx)
+	return err
+}
+
+// DeleteAPIKey converts echo context to params.
+func (w *ServerInterfaceWrapper) DeleteAPIKey(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id ID
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "id", runtime.ParamLocationPath, ctx.Param("id"), &id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(ApiKeyScopes, []string{""})
+
+	ctx.Set(BearerScopes, []string{""})
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.DeleteAPIKey(ctx, id)
+	return err
+}
+
+// GetAuditRecords converts echo context to params.
+func (w *ServerInterfaceWrapper) GetAuditRecords(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(ApiKeyScopes, []string{""})
+
+	ctx.Set(BearerScopes, []string{""})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetAuditRecordsParams
+	// ------------- Optional query parameter "actor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "actor", ctx.QueryParams(), &params.Actor)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter actor: %s", err))
+	}
+
+	// ------------- Optional query parameter "action" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "action", ctx.QueryParams(), &params.Action)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter action: %s", err))
+	}
+
+	// ------------- Optional query parameter "target_id" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "target_id", ctx.QueryParams(), &params.TargetId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter target_id: %s", err))
+	}
+
+	// ------------- Optional query parameter "from" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "from", ctx.QueryParams(), &params.From)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter from: %s", err))
+	}
+
+	// ------------- Optional query parameter "to" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "to", ctx.QueryParams(), &params.To)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter to: %s", err))
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", ctx.QueryParams(), &params.Limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter limit: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetAuditRecords(ctx, params)
+	return err
+}
+
+// PostBookTickets converts echo context to params.
+func (w *ServerInterfaceWrapper) PostBookTickets(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(ApiKeyScopes, []string{""})
+
+	ctx.Set(BearerScopes, []string{""})
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.PostBookTickets(ctx)
+	return err
+}
+
+// EraseCustomerData converts echo context to params.
+func (w *ServerInterfaceWrapper) EraseCustomerData(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "email" -------------
+	var email CustomerEmail
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "email", runtime.ParamLocationPath, ctx.Param("email"), &email)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter email: %s", err))
+	}
+
+	ctx.Set(ApiKeyScopes, []string{""})
+
+	ctx.Set(BearerScopes, []string{""})
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.EraseCustomerData(ctx, email)
+	return err
+}
+
+// GetCustomerData converts echo context to params.
+func (w *ServerInterfaceWrapper) GetCustomerData(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "email" -------------
+	var email CustomerEmail
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "email", runtime.ParamLocationPath, ctx.Param("email"), &email)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter email: %s", err))
+	}
+
+	ctx.Set(ApiKeyScopes, []string{""})
+
+	ctx.Set(BearerScopes, []string{""})
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetCustomerData(ctx, email)
+	return err
+}
+
+// GetDailyReports converts echo context to params.
+func (w *ServerInterfaceWrapper) GetDailyReports(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(ApiKeyScopes, []string{""})
+
+	ctx.Set(BearerScopes, []string{""})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetDailyReportsParams
+	// ------------- Optional query parameter "show_id" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "show_id", ctx.QueryParams(), &params.ShowId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter show_id: %s", err))
+	}
+
+	// ------------- Optional query parameter "from" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "from", ctx.QueryParams(), &params.From)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter from: %s", err))
+	}
+
+	// ------------- Optional query parameter "to" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "to", ctx.QueryParams(), &params.To)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter to: %s", err))
+	}
+
+	// ------------- Optional query parameter "format" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "format", ctx.QueryParams(), &params.Format)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter format: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetDailyReports(ctx, params)
+	return err
+}
+
+// GetShowReport converts echo context to params.
+func (w *ServerInterfaceWrapper) GetShowReport(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id ID
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "id", runtime.ParamLocationPath, ctx.Param("id"), &id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(ApiKeyScopes, []string{""})
+
+	ctx.Set(BearerScopes, []string{""})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetShowReportParams
+	// ------------- Optional query parameter "format" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "format", ctx.QueryParams(), &params.Format)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter format: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetShowReport(ctx, id, params)
+	return err
+}
+
+// GetSheetReconciliation converts echo context to params.
+func (w *ServerInterfaceWrapper) GetSheetReconciliation(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "sheet" -------------
+	var sheet GetSheetReconciliationParamsSheet
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "sheet", runtime.ParamLocationPath, ctx.Param("sheet"), &sheet)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter sheet: %s", err))
+	}
+
+	ctx.Set(ApiKeyScopes, []string{""})
+
+	ctx.Set(BearerScopes, []string{""})
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetSheetReconciliation(ctx, sheet)
+	return err
+}
+
+// PostShows converts echo context to params.
+func (w *ServerInterfaceWrapper) PostShows(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(ApiKeyScopes, []string{""})
+
+	ctx.Set(BearerScopes, []string{""})
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.PostShows(ctx)
+	return err
+}
+
+// GetTickets converts echo context to params.
+func (w *ServerInterfaceWrapper) GetTickets(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(ApiKeyScopes, []string{""})
+
+	ctx.Set(BearerScopes, []string{""})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetTicketsParams
+	// ------------- Optional query parameter "status" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "status", ctx.QueryParams(), &params.Status)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter status: %s", err))
+	}
+
+	// ------------- Optional query parameter "email" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "email", ctx.QueryParams(), &params.Email)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter email: %s", err))
+	}
+
+	// ------------- Optional query parameter "booking_id" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "booking_id", ctx.QueryParams(), &params.BookingId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter booking_id: %s", err))
+	}
+
+	// ------------- Optional query parameter "updated_after" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "updated_after", ctx.QueryParams(), &params.UpdatedAfter)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter updated_after: %s", err))
+	}
+
+	// ------------- Optional query parameter "updated_before" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "updated_before", ctx.QueryParams(), &params.UpdatedBefore)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter updated_before: %s", err))
+	}
+
+	// ------------- Optional query parameter "sort" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "sort", ctx.QueryParams(), &params.Sort)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter sort: %s", err))
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", ctx.QueryParams(), &params.Limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter limit: %s", err))
+	}
+
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cursor", ctx.QueryParams(), &params.Cursor)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter cursor: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetTickets(ctx, params)
+	return err
+}
+
+// PostTicketsStatus converts echo context to params.
+func (w *ServerInterfaceWrapper) PostTicketsStatus(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(ApiKeyScopes, []string{""})
+
+	ctx.Set(BearerScopes, []string{""})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params PostTicketsStatusParams
+
+	headers := ctx.Request().Header
+	// ------------- Required header parameter "Idempotency-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Idempotency-Key")]; found {
+		var IdempotencyKey IdempotencyKey
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for Idempotency-Key, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "Idempotency-Key", runtime.ParamLocationHeader, valueList[0], &IdempotencyKey)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter Idempotency-Key: %s", err))
+		}
+
+		params.IdempotencyKey = IdempotencyKey
+	} else {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Header parameter Idempotency-Key is required, but not found"))
+	}
+	// ------------- Required header parameter "Webhook-Timestamp" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Webhook-Timestamp")]; found {
+		var WebhookTimestamp string
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for Webhook-Timestamp, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "Webhook-Timestamp", runtime.ParamLocationHeader, valueList[0], &WebhookTimestamp)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter Webhook-Timestamp: %s", err))
+		}
+
+		params.WebhookTimestamp = WebhookTimestamp
+	} else {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Header parameter Webhook-Timestamp is required, but not found"))
+	}
+	// ------------- Required header parameter "Webhook-Signature" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Webhook-Signature")]; found {
+		var WebhookSignature string
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for Webhook-Signature, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "Webhook-Signature", runtime.ParamLocationHeader, valueList[0], &WebhookSignature)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter Webhook-Signature: %s", err))
+		}
+
+		params.WebhookSignature = WebhookSignature
+	} else {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Header parameter Webhook-Signature is required, but not found"))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.PostTicketsStatus(ctx, params)
+	return err
+}
+
+// GetTicketsExport converts echo context to params.
+func (w *ServerInterfaceWrapper) GetTicketsExport(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(ApiKeyScopes, []string{""})
+
+	ctx.Set(BearerScopes, []string{""})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetTicketsExportParams
+	// ------------- Optional query parameter "status" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "status", ctx.QueryParams(), &params.Status)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter status: %s", err))
+	}
+
+	// ------------- Optional query parameter "email" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "email", ctx.QueryParams(), &params.Email)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter email: %s", err))
+	}
+
+	// ------------- Optional query parameter "booking_id" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "booking_id", ctx.QueryParams(), &params.BookingId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter booking_id: %s", err))
+	}
+
+	// ------------- Optional query parameter "updated_after" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "updated_after", ctx.QueryParams(), &params.UpdatedAfter)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter updated_after: %s", err))
+	}
+
+	// ------------- Optional query parameter "updated_before" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "updated_before", ctx.QueryParams(), &params.UpdatedBefore)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter updated_before: %s", err))
+	}
+
+	// ------------- Optional query parameter "sort" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "sort", ctx.QueryParams(), &params.Sort)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter sort: %s", err))
+	}
+
+	// ------------- Optional query parameter "format" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "format", ctx.QueryParams(), &params.Format)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter format: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetTicketsExport(ctx, params)
+	return err
+}
+
+// GetTicket converts echo context to params.
+func (w *ServerInterfaceWrapper) GetTicket(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id ID
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "id", runtime.ParamLocationPath, ctx.Param("id"), &id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(ApiKeyScopes, []string{""})
+
+	ctx.Set(BearerScopes, []string{""})
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetTicket(ctx, id)
+	return err
+}
+
+// PostTicketCheckIn converts echo context to params.
+func (w *ServerInterfaceWrapper) PostTicketCheckIn(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id ID
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "id", runtime.ParamLocationPath, ctx.Param("id"), &id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(ApiKeyScopes, []string{""})
+
+	ctx.Set(BearerScopes, []string{""})
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.PostTicketCheckIn(ctx, id)
+	return err
+}
+
+// PostWebhooks converts echo context to params.
+func (w *ServerInterfaceWrapper) PostWebhooks(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(ApiKeyScopes, []string{""})
+
+	ctx.Set(BearerScopes, []string{""})
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.PostWebhooks(ctx)
+	return err
+}
+
+// GetWebhookDeliveries converts echo context to params.
+func (w *ServerInterfaceWrapper) GetWebhookDeliveries(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id ID
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "id", runtime.ParamLocationPath, ctx.Param("id"), &id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(ApiKeyScopes, []string{""})
+
+	ctx.Set(BearerScopes, []string{""})
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetWebhookDeliveries(ctx, id)
+	return err
+}
+
+// This is a simple interface which specifies echo.Route addition functions which
+// are present on both echo.Echo and echo.Group, since we want to allow using
+// either of them for path registration
+type EchoRouter interface {
+	CONNECT(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	DELETE(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	GET(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	HEAD(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	OPTIONS(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	PATCH(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	POST(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	PUT(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	TRACE(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+}
+
+// RegisterHandlers adds each server route to the EchoRouter.
+func RegisterHandlers(router EchoRouter, si ServerInterface) {
+	RegisterHandlersWithBaseURL(router, si, "")
+}
+
+// Registers handlers, and prepends BaseURL to the paths, so that the paths
+// can be served under a prefix.
+func RegisterHandlersWithBaseURL(router EchoRouter, si ServerInterface, baseURL string) {
+
+	wrapper := ServerInterfaceWrapper{
+		Handler: si,
+	}
+
+	router.GET(baseURL+"/admin/handlers", wrapper.GetAdminHandlers)
+	router.POST(baseURL+"/admin/handlers/:name/pause", wrapper.PostPauseHandler)
+	router.POST(baseURL+"/admin/handlers/:name/resume", wrapper.PostResumeHandler)
+	router.GET(baseURL+"/api-keys", wrapper.GetAPIKeys)
+	router.POST(baseURL+"/api-keys", wrapper.PostAPIKeys)
+	router.DELETE(baseURL+"/api-keys/:id", wrapper.DeleteAPIKey)
+	router.GET(baseURL+"/audit-records", wrapper.GetAuditRecords)
+	router.POST(baseURL+"/book-tickets", wrapper.PostBookTickets)
+	router.DELETE(baseURL+"/customers/:email", wrapper.EraseCustomerData)
+	router.GET(baseURL+"/customers/:email/data", wrapper.GetCustomerData)
+	router.GET(baseURL+"/reports/daily", wrapper.GetDailyReports)
+	router.GET(baseURL+"/reports/shows/:id", wrapper.GetShowReport)
+	router.GET(baseURL+"/sheets/:sheet/reconciliation", wrapper.GetSheetReconciliation)
+	router.POST(baseURL+"/shows", wrapper.PostShows)
+	router.GET(baseURL+"/tickets", wrapper.GetTickets)
+	router.POST(baseURL+"/tickets-status", wrapper.PostTicketsStatus)
+	router.GET(baseURL+"/tickets/export", wrapper.GetTicketsExport)
+	router.GET(baseURL+"/tickets/:id", wrapper.GetTicket)
+	router.POST(baseURL+"/tickets/:id/check-in", wrapper.PostTicketCheckIn)
+	router.POST(baseURL+"/webhooks", wrapper.PostWebhooks)
+	router.GET(baseURL+"/webhooks/:id/deliveries", wrapper.GetWebhookDeliveries)
+
+}
+
+// Base64 encoded, gzipped, json marshaled Swagger object
+var swaggerSpec = []string{
+
+	"H4sIAAAAAAAC/+x9e2/cNrb4VyH0W6A2ID+SZvtD/c+FG6eNe5s0sJPNAhlfmyOdmeFaIrUkZXs26+9+",
+	"wacoidJonMTZtPePIB6Jj8PD8+J5UB+TjJUVo0ClSI4+JhXmuAQJXP/6ibFrQpenJz+TQgJXj3BRsNsX",
+	"ZSXXf8NFDcmR5DWkCaHJUfLPGvg6SROKS0iOkrnpfUnyJE1EtoISqxHkulJvheSELpP7+zR5XgvJSuAv",
+	"SkwK1USPVmG5agYD/S5NOPyzJhxyN3Ez7oLxEsugaXuiNLnbW7K9/ux61gct0E00traXmOYF8Ne6R3Rl",
+	"+r+xhfUHPT0ZGIvkoyN5FNW1bhkZOYeyYhJotv5vWKs+OYiMk0oSpqZ7uwJ0DWvEFkiuAKmpQMhU/5Ak",
+	"uwYpEOaA6irHEnLEaLFGjGaAFowjwNlKdd9PLEJXgHPgDfzB9Htq/rHFlIT+BnQpV8nRk9hSzqBiXP5s",
+	"l/wxuoMWIeGwOSxwXSgk/UMwmqQJ0LpMjj64n5m4SS5i873Vyz+XWNbiQeQkdNcWMH/hsEiOkv930HDp",
+	"gXkrDsL5AgDEOeNyZOb+fi4IFDmSDAnGJZqvU1RxWJA7yNEtkSs0S/Zmid4/tcuqP9Cc0CViPAe+nwys",
+	"RoERR6whFCMXKiwlcNX/f/b+a8e/+bcloEss/51Z8XCp+W33L1HCfWfaHy8k8Ab7McD8yKppEmUP1WBP",
+	"khLGpvoJFozDtLnmuu32k92nCQdRMSrACGScnxmWU78yRiVQ/SeuqoJkWO3qgSbUo48TyegF54ybqfqk",
+	"YfkbEYFKXCiQId9PlMhmdFGQ7NHAEKzmGaAM0+8kmgPKVpguIUeEIiIFymrOgUqkWAg0gD8zPid5DvRx",
+	"IMxwUQD/TiDOCgememakJKstVK+Z/JnVNH9ktOUMhIII7oiQGpB3FNdyxTj5FzwSMBmHHKgkuDAaoiRC",
+	"GCGCCL3BBcktYBVnGQiB5wW8oJLI9eOS+goLBxC6UYJT7Guut4OoOY7fnFrlWHFWAZfEcGfGwcqsqQye",
+	"JiSfoJidPPnYf2FEdVxRz2FJKNVINur6GtYpEsz9LRShKm6SrMgRrjCX+7HJOdyw6y3Xpfhg036cqTb3",
+	"aSKBYqr1wSaVpxueniT396Fl8MHYPc6QUqOGg3okpeEONTqczf8BmVSAHNc5kWeQMZ73dxdnBrUxTKt2",
+	"miJ7hlFBrgFdvWFCKmPaquirKJpxJhmPj3964gY+fnNqttGqYzMz42jBWamf/Pr+bXT4jHEOhYbSovqh",
+	"xMgyLW+3IwhRlyXmAyalY745y9fa6GC11IvRKl8gTHMkIOMgRYokr2mmrUvJ0JPDw0OlDTjOJHARXbnE",
+	"fKmtCjGEXeHQK1bsVqTIHl3UZNao3WEcMbkC7qWq2PVaaL4ON11bRBJKEcWxfYA5x+s4HYfodVSROvJr",
+	"raa3qQ2aY+QdUGBgRXRkWMvYevC5Kk1oXc6BX7LFpUWhtdlJWZehxU6ohCVw1UUh/3ISDXbQ5jrGZk27",
+	"S9qIGWNv9VETnGe3hjDoOzS/6jZhOx6sQWL78Rn3QDf55I14rgW0UbCDNOqUYYnv3Anw6V//mo6fCLdR",
+	"SZ2lhYplM9AN+eCi+H2RHH0Yn9L0S+7T7jKvhw7gXgcQqSxOkDWn4Vl7f+NmqaH7K7nwazlfsdtB9OeA",
+	"80saKpKHEd8GYSAxl5dam0SRoN+HYluhA5W10OcDQvXzRS1rrvExTUlJIouHkNYN0Hr7fp1N6eA1zkMB",
+	"Why4bvph0jTbOSTXPlnsDk/8HuYrxq4HSQlugMpL1Vn/9FrT+VysXHyFc7VMI6HtM3UCJeo82nuBaQZF",
+	"8PwMFjXNgwdvuKKzPGk8jydY4hccC8gjvh29i6cGtCddBZ4mNS/iFIrnghW1BLSSstoRu+jd2W/GqFGr",
+	"NqcgAVQiyVoUWnOyEfVq0rSFvgmbMEgA2rIaYDP9znp+CnIDnIAFnSypdRFtI4nS5NbA8yCSC/qmDu7o",
+	"yoONHdTkbZobk9FOP9/3t39YMYsVgLzk7Hb6POeqyxm7jU0USM5JYxlaf0/k6iURkvF1bFCHzxKEwEuY",
+	"PrpDsCWuV6b/RgPX25BeoPm9aCEsAtnYNiverfmozdZe2gazPE0qAXXO6Loc4O085yAEkissEYeqwBnk",
+	"mkuckfOdMIeXKAu0SaM/vJH8Trt5WIiAHOm+SPUNhg4Up/ehbrniGClsB5kdAbkRYvB16KFBcwvwtLVx",
+	"n0IZHfqMEEg+YGVVeF0wnDsRrddrp95PIjMqJimg8f+0R3xl3V23KzCmiYUe3WLtmrPCFXK0BjndYDEa",
+	"YOA436iH6GtRzz2ED9P/nQECcFqTpwbLsW06waRYn+MChAnXtM3mjt2JJfT8DRuB1I0iU28QxAFI2i42",
+	"vsO+AeMe97CroyrT5enPqrmZZKMM1a1i2AwG6UGq4YlT+q/nv79GKpDo2Fq3db4rK6o/HF7sV5xksG+c",
+	"7dk67sUqG04b3xgDUNMhtiAbPR1Y0vBUD/JRdeBzo7fHGoHShuL6Z3hGRa2OvUvO6ioKbYGFvPS0NEYn",
+	"LYyMOYZZkYOQl5WJ1V3iJVwKyBiNOcFesltUMLpEeGnknOmMbOdQVqGqnhdErCBPUdmRaRy0YUhZt6No",
+	"CzRWz4sA/0alaKWLawGhLJszVgCm+p1dyLh6cm8DeWpFt9sFpHchRfNaIsokwtk1ZbcF5Esre2e0J6vn",
+	"nF0D92EUruWCelPuz2i4NELlD8/iWplVJNvMFNbVYFqnXdLxGIpR4StGY0EJXLKaDhj3OWSkxAUybVIT",
+	"7sUSlUxI9NS/1taN3kO4w0rJJUfJsx/3fzyMupmtdBhwtZ7/jp49ffL/kWuGMpZDe+gX7842MqddVTBd",
+	"DCVn4A/mbaQsORMbRbJBqOIxkBPbdqA005gBovBZj5Q77uK8JIqeKs5KZqPTdVWZSPoSS7jF6+gBtaNB",
+	"24vlDRZinnf9UjlMdHaGw6dJ6aAgfQtH/YRmRZ2DYSp71nZu8pbre9TPZmEaPudcurHjfkrXirvD/Wgr",
+	"wYpoi85+tZpHIIlMm3r0xjbYnOeU2M1IQbALIHVtCGylXmR72K2PTZh2/oAhvkM5ltgcQfSBXJKicJ4v",
+	"c0rQDN05lahOenfXaIVvYEYlUx4zDiW7gbyJI+kR9pGHQk3hT/h+YG/ACyMK/e5PP3h0fxN6qefedADR",
+	"UIXLjZ+HrJYaIv+M8RxyM5hHpVIM3ZE/w8rcbJNW5kHDAuFKqb9GmY0sl4MivlF6aujDNbb04JYOd5V+",
+	"/CVwYI5yzmyJYAGX0Lh1AWTaCgiasG7U8qypg3tk7S320Pt9C3bVDuGfZaE9V6lfdUAGAa03dNpaSLCf",
+	"qRMUw4KG3Ub0f55vGal1xDbtHMvZrTvDRql0u/Nsxoq6pFti3uAlCu45yAbUjhBEpLUCK1+nw+p2ZvPE",
+	"bWZzk1qvKWUh5lZAt4VgMCuBAxZsDIIR39TguaJJ3ttozLaIvunX7HLaUGecqNntRN/Al4hhbOkh8Mkp",
+	"0b0wcgtJnysmEM6kyqpM0ZVNjbwyFOOpQoBsW8Y4K6GdM/kB7/3rcO/HC/v/3sXHw/SH7+//EttY4xDe",
+	"FNeOGPQbQ9A5hI6vabSrfQmTTXDhz9bTU2LHaTVtEkEf6iFo5bHq5fSwlXay4F2KbzB3jPJbCwkOCFkQ",
+	"9Aqs0sAarXxsK1tBdg35HqHRI0M4xXOdxxLxXOjn2+3rQ3aqy5MOS8H8m7BkknKHjrnSZBFxQG4en8/s",
+	"UerOMGn0ZKMeoIyVEFoj5YyqvCD1BIHKtHZT7RpjeIzPIul65r0zfszEaAejd+9OT3attjDTaOAtYEpS",
+	"qK6hVX785jSeA/a5snseyr2jhHyRblA324n2kD0tBMNUFMbJJqdumK6R1A0z3eWqGXCLkF2LJzcalu2Z",
+	"4kkdrjJAtx0Mxj8swNhiP53qURQqd9fVG4xC76aM7YuN3JwYR17MsyWl4ob4yd/7/7YSXsPu/E8JtExM",
+	"krFbmbEc4mvaPmCj+mQZQB73qkZTuCYHdRz+25CHU3a2ob/L9zqIX3Mi1+eKquzGVsTmV0eLhP6+d/zm",
+	"1JYHOeoyPe7TZA6Ym3oM85er/0l+ff/W1V9oHJh2foSVlJVJDSd0wYbzrlrCWSAB/IZkcBQ8RAYb2syb",
+	"4+za+28qvC6BSmQdeemMqrRWXyAgUC2aXFKX1yvQzlBOamod0SY91oYmteqZ0efOxqzViUKSDEuw7l3q",
+	"8sfQjproyqPzChlM7yKn0zD1cKi04hnduTq2ZQPal3WEftJovNrdRy+U91AvBOnKI2GyQIRSmZwVYPWq",
+	"dnFabarqI9Q/s3wN9wvF6voNcGUgC63qsDOfdzByvlGkn2vX2+5R4BoYSpS+8gnhVygrMCln1Lb89f1b",
+	"tBPY4Fa/E7lrvF8b8Z965OudsDP7fddpwzNq5hfeyXJDBJkXYOawaCkxVXGWAH43CuF2fWmTvc8WCCMK",
+	"t27lamSb4T6jZgP14hzNGtcJsrFwtDKhJAORWGFuqE/tiIXVWDE2Kc5pEQWZzjnjwrDGk/3D/UMdcqqA",
+	"4ookR8n3+4f735uTykqz9IFe4IGbUj1axjx8Z9rBKFoRcg/nji6uudJDXe2m3twhvBPdEemMyrFoUDkY",
+	"/kmRZUrCkYrKIa0StN8I07Xjd0SokMpsMRjy6f+neXKU/ALyWIH40i22U8T19PBwq5KWScq4HYPsWw3R",
+	"mpcuhnUFzrPDJ0OT+WUctOqHdKfvN3dqarLuw4KA5DciZHTHdbb7UjShkQvVsUNLBx+VXrg/0KExbSUw",
+	"EXPISFYZurIdjafbEI4yuxUjeYKpqSSFy2pTlJV3qG9/Rt8GYxGB9PzaZao4qEsoQskoooQiIE6WK4mw",
+	"0gGNgBCanFVvtIBbZGO1dhoPl2LVAhbeO20jk8FhJmCDGHGqSpQ3ClJLMEnaqvkeMHibJgdhQfP9RY+0",
+	"n8VVZw9Pj0dqqsezzT18aV6bNjWuYsS5FW0aGhomzjP9XlgzQdORx9gAPXXpMbrTZtyvttWWdb6VvTbY",
+	"2mazK7KnNPEkddY1C3zJqLNtOtqNwi0IiRaEC9nfXaVkdOHA46gXX6QwSa+4pX5tfeLgCLfPbZk6F8eZ",
+	"0WRNtzZt2p4ZU041J7Fk6NT8SaRQRaarGSUCCck45EOCOtxhfWj/yWYrfpZy2FitzX37TCh5Dfc9+nry",
+	"hUAwk2wgKh2qsVaupa/DzaQSFM4/oix6+nTKNP2C5zY5GyQFB7c4OYcC6eAjye8NZRcQc4ue6ZLeB9C4",
+	"rrLxZfhaU2G6LpmpsmmT8Ime3IqObZXP6ckWOiekDVut/J9OG5+kp9QKw70boQhV0rxnIs7T9JRt68lB",
+	"+yHV0TSHloPZeSpcOKt1yhzXZjN6bKdRklLgG+e7Bn381w4kIRZ1gcpaYqmsc19Q7QL4WGlqjqkwhbEI",
+	"iwBcHUib0eCBl7UObGtQ2QQXu+be/RKMu8SYoZNeUzIu+jT+WSq5YxeLuKrgkVuCPled+sD0phh5y/nN",
+	"6rGt17MeldSHOhhHmLarrEeLrCOQ+eroTcDFOivMP+BGmOHEIoHMzS/m7Kf6t9KoBhfBHgRFbKiClGTg",
+	"Hp4nh4epKli0RZiH+udITWZEHH8JS7NhqKnmppZxDuP/0XI/bqa24A9FuXpu5bjilb0gQhM3XX/STsjQ",
+	"Qe09Azby5wSzzSvVCr2podW3nwBl9XKFBGApEL7BRMdz4gfMQFh8ISs1cmnBIxupscsBBijRYV3nlJmg",
+	"7J/DRlU4atGckfEBMaufzihxxCgOPuqw8wZT1eSfR6vrbJwCcUxzVjbZqM5M8K76xnvf1M+FcRPvYpvR",
+	"cUNG9TEmgYFowfgt1nmarJZzducHcirWzWwsDYcjNYx7o3XCNVRyRh272siCs078LVw+KdSuz4iOgi37",
+	"t6boeMr7FRjHtU54a7LcjOPxql//fGWqkxEJKj0asy+4KSW2kHRG7e1CTWzBhrgcmm14cQ7cBoFUJBLV",
+	"lW5k85lhja4BKrN4UXHAud60xj6bUWsV+OuMzH4Wqu26m4hYWqWrUBoYgIYOWsnZKWolQ89oOxva7oer",
+	"37azNXiSLHTKukCPBfWoybENc62NlSokB1wKB5vkpEQVK0hGlKfv6uzFyen55fnbsxfHr84v356dvlKk",
+	"qO9VVM5Bj23LppAHxSltoa13Odz2rQ9m7ds6P9komFJk7Mp7B6RulLYLImywzaTMkIXajJXOXcXe8fLt",
+	"SVq9gbF01kDWujeD8vYgt3Xxo6dBfRozKLNnJzx3IsYNOXrsO2pJiiZcN6M2Rm7TVtKWoEwbhu7IGi/9",
+	"nJg0XOljdo4zXbtGnvoAbawiwohLw9rqT3PdocwUS8MdzmSxtnEYO/4Qxw+VW6AMc74Ozq0x2WklmZYQ",
+	"M2rT8I3EEqD4T0KxjrH0LyC/NYbWcA5wc6xUZYybybfMzHeuknALbra2wUGuaqcnuXQELow9oku8crx2",
+	"fOqSKfQxgKJ3b5/vo7fBNb4Zq6m5xtfq57VWV2tj4KoyqbTJlNS+EpOIOkCmutrb5ExvdJb8pg6uIfiW",
+	"1RzvBDf/DF5B62+j2uIK5I/RG3K5kBpv3m1izLOBicf9CFNnLfBWk0q27ZQb5ELrAuXHOfz3rgOIpT9K",
+	"uJMH6hrm1viRq3NjXhmLwm/FR/CLu3tHIcYyAvcM5CSEe9KWD/rE5SMB04WEY6vvhGe2nriIBiTNNVO2",
+	"XHV7b/9jU+T4RTzt0pPPQnd/4JCEo9OQQkNaGiRWY8EdfNT/3x/wXq1slG6fs7LCHERjKW6slmyMs34d",
+	"YNcnNkDd/VreCQ7/Xj3jPjJJjtZi1vcNK312S70569t2oypKJ7cvZydNoWpwUHb+fJyV4LyGe5Lt6eqQ",
+	"K1up2/98gCtFHL503+Xvd8dM0vCRMQNi1+R/WZ7tb9AgP4atvlKaggMVmv1u+csAAi5htyNuX5exYJxu",
+	"2i5TnoQTwDl6rZc4RYIrf+65nuhL5huEV0t+lWyD1mWIA/Sh0finTTQYc90GIYhRiwKjSuVPdQ7wPUGL",
+	"3gduS+sUWOhPKthsGfVcRRvVRQU72psWOW7str494qNr+kRP4U4aWLT7VG9N6NLMai4YDzx9V3/few13",
+	"cu+5fuGS4o1QNn+rkWx9pjsZaVtdzTJw8mliJNvZRZGPikywk8JP2kxo3v3Mz4Qukc9tTO/V+nLGfToR",
+	"CebDJo8R50y3+HiKIR+0gXp8CQiHG8JqYUhl4CBnhhyNXz/KYcxXt00Kwnp2b26CMWvX87SwEs/hsZi0",
+	"mPJsuz+KiPtvLtjb3HzpxKt70hKwe03N5IDOV2k3PpjRqS1ygtb+dKmJQSApuL1VD+A6mjtVVSAHjF1y",
+	"Zevw9t6SEoTEZXWl/Zb++TlZUixrDo7YxVA6Y6sMcfsjYvvTVHGfiU6x6Oa2YIHeUXLnc+qHvj7VW+l2",
+	"n+WKwfPy1fHzvfOXx0//+gMSDlGiB6Fz8Or9zFhZ4s1QerxvBeXFl7HtohWmk8y7w8HrvWUtwIbScZZB",
+	"9aexw4yaDE0itthGdhyYwMGgjXZug4260st0MfEO5U439z8q1dw31xQnPT//mzK9Xp/oWyN3GHVgoQo4",
+	"KgiF3XEbyPi8/88S+lKW0Og37ZT7qvmknflFc83rn3xav9uj+UOkxqc617zF8a3YAkHUZypHT/biulTK",
+	"fpaXvrbPcXjs4r4Bhv1MmduHn1nXtC42HyOMP4HX1W66dQgK1I6uTyKuA31djLosZtjiVC2857S5N4Vx",
+	"ZG+dcYBgA5a5N3Kzc7WxDfUUp/TLFgs4bCn3zkrfkYMI/WNRierw4+YO/iuOHT+Qwko7g26QhlxixWbn",
+	"ZCQLrApT0SfUnSy1R9y5hUwyRidfzyaNma9W6PIUybTl3f2ERbREa+jk8t6t8kt6RjsfS/kqztHut0IG",
+	"JKvbxz+di/TcpS9qByeXFLiLMhlyDBjFs0abU4y4bShxklq3zdfIXrrijwRuJzZVA/8Csn2hjpr5a2j2",
+	"Sa6n7t0/E31QDVL/wErfe5ICYdYmhgEaDC7b0Zvtrtn5cKGOEu7inA8X9xf3/zsA/LTQchd+AAA=",
+}
+
+// GetSwagger returns the content of the embedded swagger specification file
+// or error if failed to decode
+func decodeSpec() ([]byte, error) {
+	zipped, err := base64.StdEncoding.DecodeString(strings.Join(swaggerSpec, ""))
+	if err != nil {
+		return nil, fmt.Errorf("error base64 decoding spec: %s", err)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(zipped))
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing spec: %s", err)
+	}
+	var buf bytes.Buffer
+	_, err = buf.ReadFrom(zr)
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing spec: %s", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+var rawSpec = decodeSpecCached()
+
+// a naive cached of a decoded swagger spec
+func decodeSpecCached() func() ([]byte, error) {
+	data, err := decodeSpec()
+	return func() ([]byte, error) {
+		return data, err
+	}
+}
+
+// Constructs a synthetic filesystem for resolving external references when loading openapi specifications.
+func PathToRawSpec(pathToFile string) map[string]func() ([]byte, error) {
+	var res = make(map[string]func() ([]byte, error))
+	if len(pathToFile) > 0 {
+		res[pathToFile] = rawSpec
+	}
+
+	return res
+}
+
+// GetSwagger returns the Swagger specification corresponding to the generated code
+// in this file. The external references of Swagger specification are resolved.
+// The logic of resolving external references is tightly connected to "import-mapping" feature.
+// Externally referenced files must be embedded in the corresponding golang packages.
+// Urls can be supported but this task was out of the scope.
+func GetSwagger() (swagger *openapi3.T, err error) {
+	var resolvePath = PathToRawSpec("")
+
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = true
+	loader.ReadFromURIFunc = func(loader *openapi3.Loader, url *url.URL) ([]byte, error) {
+		var pathToFile = url.String()
+		pathToFile = path.Clean(pathToFile)
+		getSpec, ok := resolvePath[pathToFile]
+		if !ok {
+			err1 := fmt.Errorf("path not found: %s", pathToFile)
+			return nil, err1
+		}
+		return getSpec()
+	}
+	var specData []byte
+	specData, err = rawSpec()
+	if err != nil {
+		return
+	}
+	swagger, err = loader.LoadFromData(specData)
+	if err != nil {
+		return
+	}
+	return
+}
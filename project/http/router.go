@@ -2,31 +2,86 @@ package http
 
 import (
 	"net/http"
+	"tickets/auth"
+	"tickets/entities"
+	"tickets/http/openapi"
+	"tickets/message/asyncapi"
 
 	libHttp "github.com/ThreeDotsLabs/go-event-driven/common/http"
-	"github.com/ThreeDotsLabs/watermill/components/cqrs"
 	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
-func NewHttpRouter(eventBus *cqrs.EventBus, spreadsheetsAPIClient SpreadsheetsAPI, ticketsRepository TicketsRepository, showsRepository ShowsRepository, bookingsRepository BookingsRepository) *echo.Echo {
+// maxBodySize is the maximum size of the request bodies.
+const maxBodySize = "1M"
+
+func NewHttpRouter(spreadsheetsAPIClient SpreadsheetsAPI, ticketsRepository TicketsRepository, showsRepository ShowsRepository, bookingsRepository BookingsRepository, webhooksRepository WebhooksRepository, sheetsReconciler SheetsReconciler, reportsRepository ReportsRepository, apiKeysRepository APIKeysRepository, messageHandlers MessageHandlers, auditRepository AuditRepository, customersRepository CustomersRepository, authenticator auth.Authenticator, asyncAPIDocument *asyncapi.Document) *echo.Echo {
 	e := libHttp.NewEcho()
+	e.HTTPErrorHandler = handleError
+	// before the other middlewares, as they read the whole body (like the validation and the audit)
+	e.Pre(middleware.BodyLimit(maxBodySize))
 
 	e.GET("/health", func(c echo.Context) error {
 		return c.String(http.StatusOK, "ok")
 	})
+	e.GET("/metrics", echo.WrapHandler(promhttp.Handler()))
 
 	handler := Handler{
 		spreadsheetsAPIClient: spreadsheetsAPIClient,
-		eventBus:              eventBus,
 		ticketsRepository:     ticketsRepository,
 		showsRepository:       showsRepository,
 		bookingsRepository:    bookingsRepository,
+		webhooksRepository:    webhooksRepository,
+		sheetsReconciler:      sheetsReconciler,
+		reportsRepository:     reportsRepository,
+		apiKeysRepository:     apiKeysRepository,
+		messageHandlers:       messageHandlers,
+		auditRepository:       auditRepository,
+		customersRepository:   customersRepository,
 	}
 
-	e.POST("/tickets-status", handler.PostTicketsStatus)
-	e.GET("/tickets", handler.GetAllTickets)
-	e.POST("/shows", handler.PostShows)
-	e.POST("/book-tickets", handler.PostBookTickets)
+	spec := loadOpenAPISpec()
+	e.GET("/openapi.json", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, spec)
+	})
+	e.GET("/asyncapi.json", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, asyncAPIDocument)
+	})
+
+	server := openapi.ServerInterfaceWrapper{Handler: handler}
+	// the requests are validated after they are authenticated
+	validate := newOpenAPIValidator(spec)
+	// the mutating routes are recorded in the audit log, the action is the operation ID
+	audited := newAuditMiddleware()
+
+	// admins can call all routes
+	admin := authenticator.Require(entities.RoleAdmin)
+	gateway := authenticator.Require(entities.RoleGateway)
+	promoter := authenticator.Require(entities.RolePromoter)
+	support := authenticator.Require(entities.RoleSupport)
+
+	e.POST("/tickets-status", server.PostTicketsStatus, authenticator.VerifySignature(), gateway, validate, audited("PostTicketsStatus"))
+	e.GET("/tickets", server.GetTickets, support, validate)
+	e.GET("/tickets/export", server.GetTicketsExport, support, validate)
+	e.GET("/tickets/:id", server.GetTicket, support, validate)
+	e.POST("/tickets/:id/check-in", server.PostTicketCheckIn, support, validate, audited("PostTicketCheckIn"))
+	e.POST("/shows", server.PostShows, promoter, validate, audited("PostShows"))
+	e.POST("/book-tickets", server.PostBookTickets, support, validate, audited("PostBookTickets"))
+	e.POST("/webhooks", server.PostWebhooks, admin, validate, audited("PostWebhooks"))
+	e.GET("/webhooks/:id/deliveries", server.GetWebhookDeliveries, admin, validate)
+	e.GET("/sheets/:sheet/reconciliation", server.GetSheetReconciliation, support, validate)
+	e.GET("/reports/shows/:id", server.GetShowReport, promoter, validate)
+	e.GET("/reports/daily", server.GetDailyReports, promoter, validate)
+	e.POST("/api-keys", server.PostAPIKeys, admin, validate, audited("PostAPIKeys"))
+	e.GET("/api-keys", server.GetAPIKeys, admin, validate)
+	e.DELETE("/api-keys/:id", server.DeleteAPIKey, admin, validate, audited("DeleteAPIKey"))
+	e.GET("/admin/handlers", server.GetAdminHandlers, admin, validate)
+	e.POST("/admin/handlers/:name/pause", server.PostPauseHandler, admin, validate, audited("PostPauseHandler"))
+	e.POST("/admin/handlers/:name/resume", server.PostResumeHandler, admin, validate, audited("PostResumeHandler"))
+	e.GET("/customers/:email/data", server.GetCustomerData, admin, validate)
+	e.DELETE("/customers/:email", server.EraseCustomerData, admin, validate, audited("EraseCustomerData"))
+	e.GET("/audit-records", server.GetAuditRecords, admin, validate)
 
 	return e
 }
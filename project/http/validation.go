@@ -0,0 +1,208 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"regexp"
+	"slices"
+	"strings"
+
+	libHttp "github.com/ThreeDotsLabs/go-event-driven/common/http"
+	"github.com/labstack/echo/v4"
+)
+
+// FieldError describes why the request field is invalid, Field is its JSON path, like "tickets[0].price.currency".
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError is returned with 400 for malformed requests, and with 422 for invalid values.
+type ValidationError struct {
+	Status  int
+	Message string
+	Fields  []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Fields) == 0 {
+		return e.Message
+	}
+
+	fields := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		fields = append(fields, field.Field+": "+field.Message)
+	}
+
+	return fmt.Sprintf("%s: %s", e.Message, strings.Join(fields, ", "))
+}
+
+type errorResponse struct {
+	Error  string       `json:"error"`
+	Fields []FieldError `json:"fields,omitempty"`
+}
+
+// handleError responds to validation errors with the field details, and to other errors like libHttp.HandleError.
+func handleError(err error, c echo.Context) {
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		libHttp.HandleError(err, c)
+		return
+	}
+
+	if c.Response().Committed {
+		return
+	}
+
+	jsonErr := c.JSON(validationErr.Status, errorResponse{
+		Error:  validationErr.Message,
+		Fields: validationErr.Fields,
+	})
+	if jsonErr != nil {
+		c.Logger().Error(jsonErr)
+	}
+}
+
+// bindAndValidate binds the request body and validates what the OpenAPI specification can't.
+func bindAndValidate[T any](c echo.Context, validate func(v *validator, request T)) (T, error) {
+	var request T
+	if err := c.Bind(&request); err != nil {
+		return request, bindError(err)
+	}
+
+	var v validator
+	validate(&v, request)
+
+	return request, v.err()
+}
+
+// bindError describes the body that couldn't be bound to the request.
+func bindError(err error) error {
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) && httpErr.Code != http.StatusBadRequest {
+		// like an unsupported media type
+		return err
+	}
+
+	validationErr := &ValidationError{
+		Status:  http.StatusBadRequest,
+		Message: "malformed request body",
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		validationErr.Fields = append(validationErr.Fields, FieldError{
+			Field:   typeErr.Field,
+			Message: "must be " + jsonType(typeErr.Type.Kind().String()),
+		})
+	} else if httpErr != nil && httpErr.Internal != nil {
+		// like invalid UUIDs or times
+		validationErr.Message += ": " + httpErr.Internal.Error()
+	}
+
+	return validationErr
+}
+
+func jsonType(kind string) string {
+	switch kind {
+	case "string":
+		return "a string"
+	case "bool":
+		return "a boolean"
+	case "slice", "array":
+		return "an array"
+	case "struct", "map":
+		return "an object"
+	default:
+		return "a number"
+	}
+}
+
+// validator collects the errors of all invalid fields, so the client can fix them at once.
+type validator struct {
+	fields []FieldError
+}
+
+// check adds the error of the field if ok is false.
+func (v *validator) check(ok bool, field string, message string) {
+	if !ok {
+		v.fields = append(v.fields, FieldError{Field: field, Message: message})
+	}
+}
+
+func (v *validator) err() error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+
+	return &ValidationError{
+		Status:  http.StatusUnprocessableEntity,
+		Message: "invalid request",
+		Fields:  v.fields,
+	}
+}
+
+func (v *validator) required(value string, field string) bool {
+	ok := strings.TrimSpace(value) != ""
+	v.check(ok, field, "is required")
+
+	return ok
+}
+
+// maxTextLength is the length of the VARCHAR(255) columns.
+const maxTextLength = 255
+
+func (v *validator) text(value string, field string) {
+	if v.required(value, field) {
+		v.check(len(value) <= maxTextLength, field, fmt.Sprintf("must be at most %d characters long", maxTextLength))
+	}
+}
+
+func (v *validator) email(value string, field string) {
+	if !v.required(value, field) {
+		return
+	}
+
+	// ParseAddress accepts names like "Name <email@example.com>", only the address is valid here
+	address, err := mail.ParseAddress(value)
+	v.check(err == nil && address.Address == value && len(value) <= maxTextLength, field, "must be a valid email address")
+}
+
+// amountPattern matches the amounts that fit the NUMERIC(10, 2) columns.
+var amountPattern = regexp.MustCompile(`^\d{1,8}(\.\d{1,2})?$`)
+
+func (v *validator) amount(value string, field string) {
+	if v.required(value, field) {
+		v.check(amountPattern.MatchString(value), field, "must be a non-negative decimal number with at most 2 decimal places")
+	}
+}
+
+func (v *validator) currency(value string, field string) {
+	if v.required(value, field) {
+		v.check(isCurrency(value), field, "must be an ISO 4217 currency code")
+	}
+}
+
+func (v *validator) oneOf(value string, allowed []string, field string) {
+	if v.required(value, field) {
+		v.check(slices.Contains(allowed, value), field, fmt.Sprintf("must be one of: %s", strings.Join(allowed, ", ")))
+	}
+}
+
+// currencies are the active ISO 4217 currency codes.
+var currencies = strings.Fields(`
+	AED AFN ALL AMD ANG AOA ARS AUD AWG AZN BAM BBD BDT BGN BHD BIF BMD BND BOB BRL BSD BTN BWP BYN BZD
+	CAD CDF CHF CLP CNY COP CRC CUP CVE CZK DJF DKK DOP DZD EGP ERN ETB EUR FJD FKP GBP GEL GHS GIP GMD
+	GNF GTQ GYD HKD HNL HTG HUF IDR ILS INR IQD IRR ISK JMD JOD JPY KES KGS KHR KMF KPW KRW KWD KYD KZT
+	LAK LBP LKR LRD LSL LYD MAD MDL MGA MKD MMK MNT MOP MRU MUR MVR MWK MXN MYR MZN NAD NGN NIO NOK NPR
+	NZD OMR PAB PEN PGK PHP PKR PLN PYG QAR RON RSD RUB RWF SAR SBD SCR SDG SEK SGD SHP SLE SOS SRD SSP
+	STN SVC SYP SZL THB TJS TMT TND TOP TRY TTD TWD TZS UAH UGX USD UYU UZS VES VND VUV WST XAF XCD XOF
+	XPF YER ZAR ZMW ZWL
+`)
+
+func isCurrency(code string) bool {
+	return slices.Contains(currencies, code)
+}
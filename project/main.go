@@ -2,12 +2,19 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
 	"tickets/api"
-	"tickets/message"
+	"tickets/auth"
+	"tickets/chaos"
+	"tickets/message/broker"
+	"tickets/message/event"
+	"tickets/message/outbox"
 	"tickets/service"
+	"tickets/sheetsync"
+	"time"
 
 	"github.com/ThreeDotsLabs/go-event-driven/common/clients"
 	"github.com/ThreeDotsLabs/go-event-driven/common/log"
@@ -30,27 +37,83 @@ func main() {
 		panic(err)
 	}
 
+	eventsFormat, err := event.ParseFormat(os.Getenv("EVENTS_FORMAT"))
+	if err != nil {
+		panic(err)
+	}
+	eventMarshaler := event.NewMarshaler(eventsFormat)
+
 	db, err := sqlx.Open("postgres", os.Getenv("POSTGRES_URL"))
 	if err != nil {
 		panic(err)
 	}
 	defer db.Close()
 
-	redisClient := message.NewRedisClient(os.Getenv("REDIS_ADDR"))
-	defer redisClient.Close()
+	brokerConfig, err := broker.ConfigFromEnv()
+	if err != nil {
+		panic(err)
+	}
+
+	watermillLogger := log.NewWatermill(log.FromContext(ctx))
 
-	spreadsheetsService := api.NewSpreadsheetsAPIClient(apiClients)
-	receiptsService := api.NewReceiptsServiceClient(apiClients)
-	fileService := api.NewFileAPIClient(apiClients)
-	deadNationAPI := api.NewDeadNationClient(apiClients)
+	messageBroker, err := broker.New(brokerConfig, watermillLogger)
+	if err != nil {
+		panic(err)
+	}
+	defer messageBroker.Close()
+
+	var spreadsheetsService sheetsync.SpreadsheetsAPI = api.NewSpreadsheetsAPIClient(apiClients)
+	var receiptsService event.ReceiptsService = api.NewReceiptsServiceClient(apiClients)
+	var fileService event.FileAPI = api.NewFileAPIClient(apiClients)
+	var deadNationAPI event.DeadNationAPI = api.NewDeadNationClient(apiClients)
+
+	chaosConfig, err := chaos.ConfigFromEnv()
+	if err != nil {
+		panic(err)
+	}
+
+	var chaosInjector *chaos.Chaos
+	if chaosConfig.Enabled() {
+		log.FromContext(ctx).Warnf("Chaos mode enabled: %+v", chaosConfig)
+
+		chaosInjector, err = chaos.New(chaosConfig)
+		if err != nil {
+			panic(err)
+		}
+
+		spreadsheetsService = chaos.NewSpreadsheetsAPI(chaosInjector, spreadsheetsService)
+		receiptsService = chaos.NewReceiptsService(chaosInjector, receiptsService)
+		fileService = chaos.NewFileAPI(chaosInjector, fileService)
+		deadNationAPI = chaos.NewDeadNationAPI(chaosInjector, deadNationAPI)
+	}
+
+	authConfig, err := auth.ConfigFromEnv()
+	if err != nil {
+		panic(err)
+	}
+
+	outboxConfig := outbox.Config{}
+	if os.Getenv("OUTBOX_LISTEN_NOTIFY") == "true" {
+		outboxConfig.Forwarder.ListenDSN = os.Getenv("POSTGRES_URL")
+	}
+	if maxAge := os.Getenv("OUTBOX_RETENTION_MAX_AGE"); maxAge != "" {
+		outboxConfig.Retention.MaxAge, err = time.ParseDuration(maxAge)
+		if err != nil {
+			panic(fmt.Errorf("invalid OUTBOX_RETENTION_MAX_AGE: %w", err))
+		}
+		outboxConfig.Retention.Archive = os.Getenv("OUTBOX_RETENTION_ARCHIVE") == "true"
+	}
 
 	err = service.New(
-		db,
-		redisClient,
+		service.NewPostgresRepositories(db, eventMarshaler, outboxConfig),
+		messageBroker,
+		eventMarshaler,
 		spreadsheetsService,
 		receiptsService,
 		fileService,
 		deadNationAPI,
+		chaosInjector,
+		authConfig,
 	).Run(ctx)
 	if err != nil {
 		panic(err)
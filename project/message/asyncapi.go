@@ -0,0 +1,84 @@
+package message
+
+import (
+	"fmt"
+	"tickets/message/asyncapi"
+	"tickets/message/event"
+	"tickets/message/outbox"
+	"tickets/tenant"
+)
+
+// NewAsyncAPIDocument describes the topics and payloads of the events, and the consumer groups of their handlers.
+func NewAsyncAPIDocument() *asyncapi.Document {
+	doc := asyncapi.NewDocument(asyncapi.Info{
+		Title:   "tickets events",
+		Version: "1.0.0",
+		Description: "Events published by the tickets service. " +
+			"Each event has its own Redis stream (topic), and each handler its own consumer group. " +
+			"Payloads are JSON by default, or protobuf (eventpb/events.proto) when the content_type header says so. " +
+			"The events are kept in the streams until the trim policies remove them, even when the customer's data " +
+			"in them was erased (see CustomerDataErased).",
+	}, event.ContentTypeJSON)
+
+	consumerGroups := make(map[string][]asyncapi.ConsumerGroup)
+	for _, handler := range registeredHandlers() {
+		consumerGroups[handler.EventName] = append(consumerGroups[handler.EventName], asyncapi.ConsumerGroup{
+			Name:    handler.ConsumerGroup,
+			Handler: handler.Name,
+		})
+	}
+
+	for _, eventName := range event.EventNames() {
+		e, _ := event.NewEvent(eventName)
+
+		messageRef := doc.AddMessage(asyncapi.Message{
+			Name:          eventName,
+			Title:         eventName,
+			Headers:       messageHeaders,
+			SchemaVersion: event.SchemaVersion(eventName),
+		}, e)
+
+		doc.Channels[event.Topic(eventName)] = asyncapi.Channel{
+			Subscribe: asyncapi.Operation{
+				OperationID: "on" + eventName,
+				Summary:     fmt.Sprintf("Subscribe to %s events.", eventName),
+				Message:     messageRef,
+			},
+			ConsumerGroups: consumerGroups[eventName],
+		}
+	}
+
+	return doc
+}
+
+// messageHeaders describes the metadata of the messages.
+var messageHeaders = &asyncapi.Schema{
+	Type: "object",
+	Properties: map[string]*asyncapi.Schema{
+		"name": {
+			Type:        "string",
+			Description: "Name of the event.",
+		},
+		event.ContentTypeMetadataKey: {
+			Type:        "string",
+			Description: fmt.Sprintf("Format of the payload, %s or %s. Messages without it are JSON.", event.ContentTypeJSON, event.ContentTypeProtobuf),
+		},
+		event.SchemaVersionMetadataKey: {
+			Type:        "string",
+			Description: "Version of the payload schema. Messages without it are at version 1.",
+		},
+		outbox.OrderingKeyMetadataKey: {
+			Type:        "string",
+			Description: "Events with the same key are handled in the order they were published.",
+		},
+		"correlation_id": {
+			Type:        "string",
+			Description: "ID correlating the event with the request that caused it.",
+		},
+		event.TenantIDMetadataKey: {
+			Type:        "string",
+			Description: fmt.Sprintf("Tenant the event belongs to, the handlers work with its data. Messages without it belong to the %s tenant.", tenant.Default),
+		},
+	},
+	Required: []string{"name"},
+}
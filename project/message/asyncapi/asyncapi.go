@@ -0,0 +1,196 @@
+// Package asyncapi contains the model of AsyncAPI documents, with JSON schemas generated from Go structs.
+package asyncapi
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const Version = "2.6.0"
+
+type Document struct {
+	AsyncAPI           string             `json:"asyncapi"`
+	Info               Info               `json:"info"`
+	DefaultContentType string             `json:"defaultContentType,omitempty"`
+	Channels           map[string]Channel `json:"channels"`
+	Components         Components         `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type Channel struct {
+	Description string    `json:"description,omitempty"`
+	Subscribe   Operation `json:"subscribe"`
+	// ConsumerGroups lists the groups reading the channel, every group receives every message.
+	ConsumerGroups []ConsumerGroup `json:"x-consumer-groups,omitempty"`
+}
+
+type Operation struct {
+	OperationID string    `json:"operationId"`
+	Summary     string    `json:"summary,omitempty"`
+	Message     Reference `json:"message"`
+}
+
+type ConsumerGroup struct {
+	Name    string `json:"name"`
+	Handler string `json:"handler"`
+}
+
+type Components struct {
+	Messages map[string]Message `json:"messages"`
+	Schemas  map[string]*Schema `json:"schemas"`
+}
+
+type Message struct {
+	Name        string  `json:"name"`
+	Title       string  `json:"title,omitempty"`
+	ContentType string  `json:"contentType,omitempty"`
+	Headers     *Schema `json:"headers,omitempty"`
+	Payload     *Schema `json:"payload"`
+	// SchemaVersion is the version of the payload, sent in the schema_version header.
+	SchemaVersion int `json:"x-schema-version,omitempty"`
+}
+
+type Reference struct {
+	Ref string `json:"$ref"`
+}
+
+// Schema is the subset of JSON schema used to describe the payloads.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+func NewDocument(info Info, defaultContentType string) *Document {
+	return &Document{
+		AsyncAPI:           Version,
+		Info:               info,
+		DefaultContentType: defaultContentType,
+		Channels:           make(map[string]Channel),
+		Components: Components{
+			Messages: make(map[string]Message),
+			Schemas:  make(map[string]*Schema),
+		},
+	}
+}
+
+// AddMessage adds the message with the payload schema generated from the struct, see SchemaOf.
+func (d *Document) AddMessage(message Message, payload any) Reference {
+	message.Payload = d.SchemaOf(payload)
+	d.Components.Messages[message.Name] = message
+
+	return Reference{Ref: "#/components/messages/" + message.Name}
+}
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	uuidType = reflect.TypeOf(uuid.UUID{})
+)
+
+// SchemaOf returns the JSON schema of the value, the named structs are referenced from the components.
+func (d *Document) SchemaOf(v any) *Schema {
+	return d.schemaOf(reflect.TypeOf(v))
+}
+
+func (d *Document) schemaOf(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case uuidType:
+		return &Schema{Type: "string", Format: "uuid"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// encoding/json sends bytes as base64
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: d.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: d.schemaOf(t.Elem())}
+	case reflect.Interface:
+		return &Schema{}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return d.structSchema(t)
+		}
+
+		if _, ok := d.Components.Schemas[t.Name()]; !ok {
+			// added before the fields, so recursive types don't loop
+			d.Components.Schemas[t.Name()] = &Schema{}
+			*d.Components.Schemas[t.Name()] = *d.structSchema(t)
+		}
+
+		return &Schema{Ref: "#/components/schemas/" + t.Name()}
+	default:
+		panic(fmt.Sprintf("unsupported type of the schema: %s", t))
+	}
+}
+
+func (d *Document) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{
+		Type:       "object",
+		Properties: make(map[string]*Schema),
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" && options == "" {
+			continue
+		}
+
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			// fields of embedded structs are marshaled like fields of the struct
+			embedded := d.structSchema(field.Type)
+			for property, propertySchema := range embedded.Properties {
+				schema.Properties[property] = propertySchema
+			}
+			schema.Required = append(schema.Required, embedded.Required...)
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		schema.Properties[name] = d.schemaOf(field.Type)
+		if !strings.Contains(options, "omitempty") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+
+	return schema
+}
@@ -0,0 +1,31 @@
+package message
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var updateAsyncAPI = flag.Bool("update-asyncapi", false, "update asyncapi.json with the generated document")
+
+const asyncAPIFile = "asyncapi.json"
+
+// Update asyncapi.json with: go test ./message -run TestAsyncAPIDocument -update-asyncapi
+func TestAsyncAPIDocument(t *testing.T) {
+	generated, err := json.MarshalIndent(NewAsyncAPIDocument(), "", "  ")
+	require.NoError(t, err)
+	generated = append(generated, '\n')
+
+	if *updateAsyncAPI {
+		require.NoError(t, os.WriteFile(asyncAPIFile, generated, 0644))
+	}
+
+	committed, err := os.ReadFile(asyncAPIFile)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, string(committed), string(generated), "%s is outdated, run the test with -update-asyncapi", asyncAPIFile)
+}
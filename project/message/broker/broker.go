@@ -0,0 +1,105 @@
+package broker
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+type Type string
+
+const (
+	TypeRedis     Type = "redis"
+	TypeKafka     Type = "kafka"
+	TypeNATS      Type = "nats"
+	TypeGoChannel Type = "gochannel"
+)
+
+// Broker builds the publisher and the subscribers of the consumer groups of the message broker.
+type Broker interface {
+	Publisher() message.Publisher
+	NewSubscriber(consumerGroup string) (message.Subscriber, error)
+	Close() error
+}
+
+// PendingMessages are the messages delivered to a consumer group, but not acknowledged yet.
+type PendingMessages struct {
+	Count int64
+	// OldestPublishedAt is zero when there are no pending messages.
+	OldestPublishedAt time.Time
+}
+
+type Config struct {
+	Type Type
+
+	RedisAddr    string
+	RedisJanitor RedisJanitorConfig
+	KafkaBrokers []string
+	NATSURL      string
+}
+
+func ConfigFromEnv() (Config, error) {
+	config := Config{
+		Type:      Type(os.Getenv("BROKER")),
+		RedisAddr: os.Getenv("REDIS_ADDR"),
+		NATSURL:   os.Getenv("NATS_URL"),
+	}
+	if config.Type == "" {
+		config.Type = TypeRedis
+	}
+	if trim := os.Getenv("REDIS_STREAMS_TRIM"); trim != "" {
+		var err error
+		config.RedisJanitor.TrimPolicies, config.RedisJanitor.DefaultTrimPolicy, err = ParseTrimPolicies(trim)
+		if err != nil {
+			return config, fmt.Errorf("invalid REDIS_STREAMS_TRIM: %w", err)
+		}
+	}
+	if brokers := os.Getenv("KAFKA_BROKERS"); brokers != "" {
+		config.KafkaBrokers = strings.Split(brokers, ",")
+	}
+
+	return config, config.Validate()
+}
+
+func (c Config) Validate() error {
+	switch c.Type {
+	case TypeRedis:
+		if c.RedisAddr == "" {
+			return fmt.Errorf("missing redis address")
+		}
+	case TypeKafka:
+		if len(c.KafkaBrokers) == 0 {
+			return fmt.Errorf("missing kafka brokers")
+		}
+	case TypeNATS:
+		if c.NATSURL == "" {
+			return fmt.Errorf("missing nats url")
+		}
+	case TypeGoChannel:
+	default:
+		return fmt.Errorf("unknown broker type: %s", c.Type)
+	}
+
+	return nil
+}
+
+func New(config Config, logger watermill.LoggerAdapter) (Broker, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	switch config.Type {
+	case TypeRedis:
+		return NewRedis(NewRedisClient(config.RedisAddr), config.RedisJanitor, logger)
+	case TypeKafka:
+		return NewKafka(config.KafkaBrokers, logger)
+	case TypeNATS:
+		return NewNATS(config.NATSURL, logger)
+	default:
+		return NewGoChannel(logger), nil
+	}
+}
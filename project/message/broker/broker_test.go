@@ -0,0 +1,95 @@
+package broker_test
+
+import (
+	"context"
+	"testing"
+	"tickets/message/broker"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	assert.NoError(t, broker.Config{Type: broker.TypeGoChannel}.Validate())
+	assert.NoError(t, broker.Config{Type: broker.TypeRedis, RedisAddr: "localhost:6379"}.Validate())
+	assert.NoError(t, broker.Config{Type: broker.TypeKafka, KafkaBrokers: []string{"localhost:9092"}}.Validate())
+	assert.NoError(t, broker.Config{Type: broker.TypeNATS, NATSURL: "nats://localhost:4222"}.Validate())
+
+	assert.Error(t, broker.Config{Type: broker.TypeRedis}.Validate())
+	assert.Error(t, broker.Config{Type: broker.TypeKafka}.Validate())
+	assert.Error(t, broker.Config{Type: broker.TypeNATS}.Validate())
+	assert.Error(t, broker.Config{Type: "rabbitmq"}.Validate())
+}
+
+func TestGoChannel_every_consumer_group_gets_all_messages(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b, err := broker.New(broker.Config{Type: broker.TypeGoChannel}, watermill.NopLogger{})
+	require.NoError(t, err)
+	defer b.Close()
+
+	var channels []<-chan *message.Message
+	for _, group := range []string{"svc-tickets.A", "svc-tickets.B"} {
+		sub, err := b.NewSubscriber(group)
+		require.NoError(t, err)
+
+		messages, err := sub.Subscribe(ctx, "topic")
+		require.NoError(t, err)
+		channels = append(channels, messages)
+
+		// router closes subscribers of the handlers, it shouldn't close the shared Pub/Sub
+		require.NoError(t, sub.Close())
+	}
+
+	msg := message.NewMessage(watermill.NewUUID(), []byte("payload"))
+	require.NoError(t, b.Publisher().Publish("topic", msg))
+
+	for _, messages := range channels {
+		select {
+		case received := <-messages:
+			assert.Equal(t, msg.UUID, received.UUID)
+			received.Ack()
+		case <-time.After(time.Second):
+			t.Fatal("message not received")
+		}
+	}
+}
+
+func TestGoChannel_subscriber_keeps_messages_between_subscriptions(t *testing.T) {
+	b, err := broker.New(broker.Config{Type: broker.TypeGoChannel}, watermill.NopLogger{})
+	require.NoError(t, err)
+	defer b.Close()
+
+	sub, err := b.NewSubscriber("svc-tickets.A")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	messages, err := sub.Subscribe(ctx, "topic")
+	require.NoError(t, err)
+
+	// the message published while nobody is subscribed is received by the next subscription
+	cancel()
+	_, open := <-messages
+	require.False(t, open)
+
+	msg := message.NewMessage(watermill.NewUUID(), []byte("payload"))
+	require.NoError(t, b.Publisher().Publish("topic", msg))
+
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+
+	messages, err = sub.Subscribe(ctx, "topic")
+	require.NoError(t, err)
+
+	select {
+	case received := <-messages:
+		assert.Equal(t, msg.UUID, received.UUID)
+		received.Ack()
+	case <-time.After(time.Second):
+		t.Fatal("message not received")
+	}
+}
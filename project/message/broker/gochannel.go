@@ -0,0 +1,115 @@
+package broker
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/ThreeDotsLabs/watermill/pubsub/gochannel"
+)
+
+// GoChannel is an in-process broker for tests and local runs, the messages are lost on restart.
+type GoChannel struct {
+	pubSub *gochannel.GoChannel
+}
+
+func NewGoChannel(logger watermill.LoggerAdapter) *GoChannel {
+	return &GoChannel{
+		pubSub: gochannel.NewGoChannel(gochannel.Config{
+			OutputChannelBuffer: 1024,
+		}, logger),
+	}
+}
+
+func (g *GoChannel) Publisher() message.Publisher {
+	return g.pubSub
+}
+
+// NewSubscriber returns a subscriber keeping the messages of its topics between its subscriptions, like a consumer group.
+func (g *GoChannel) NewSubscriber(consumerGroup string) (message.Subscriber, error) {
+	return &consumerGroupSubscriber{
+		pubSub:        g.pubSub,
+		subscriptions: make(map[string]*groupSubscription),
+	}, nil
+}
+
+func (g *GoChannel) Close() error {
+	return g.pubSub.Close()
+}
+
+// consumerGroupSubscriber subscribes to the topics once, closing it doesn't close the shared Pub/Sub.
+type consumerGroupSubscriber struct {
+	pubSub *gochannel.GoChannel
+
+	lock sync.Mutex
+	// topic -> subscription
+	subscriptions map[string]*groupSubscription
+}
+
+type groupSubscription struct {
+	// only one Subscribe call reads the messages at a time
+	lock     sync.Mutex
+	messages <-chan *message.Message
+}
+
+func (c *consumerGroupSubscriber) Subscribe(ctx context.Context, topic string) (<-chan *message.Message, error) {
+	subscription, err := c.subscription(topic)
+	if err != nil {
+		return nil, err
+	}
+
+	output := make(chan *message.Message)
+
+	go func() {
+		defer close(output)
+
+		subscription.lock.Lock()
+		defer subscription.lock.Unlock()
+
+		for {
+			select {
+			case msg, ok := <-subscription.messages:
+				if !ok {
+					return
+				}
+
+				select {
+				case output <- msg:
+				case <-ctx.Done():
+					// it's sent again to the next subscription
+					msg.Nack()
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return output, nil
+}
+
+func (c *consumerGroupSubscriber) subscription(topic string) (*groupSubscription, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if subscription, ok := c.subscriptions[topic]; ok {
+		return subscription, nil
+	}
+
+	// the subscription lasts until the Pub/Sub is closed, not until the ctx of the first Subscribe call is done
+	messages, err := c.pubSub.Subscribe(context.Background(), topic)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription := &groupSubscription{messages: messages}
+	c.subscriptions[topic] = subscription
+
+	return subscription, nil
+}
+
+func (c *consumerGroupSubscriber) Close() error {
+	return nil
+}
@@ -0,0 +1,53 @@
+package broker
+
+import (
+	"fmt"
+
+	"github.com/IBM/sarama"
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill-kafka/v3/pkg/kafka"
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+type Kafka struct {
+	brokers   []string
+	publisher *kafka.Publisher
+	logger    watermill.LoggerAdapter
+}
+
+func NewKafka(brokers []string, logger watermill.LoggerAdapter) (*Kafka, error) {
+	publisher, err := kafka.NewPublisher(kafka.PublisherConfig{
+		Brokers:   brokers,
+		Marshaler: kafka.DefaultMarshaler{},
+	}, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kafka publisher: %w", err)
+	}
+
+	return &Kafka{
+		brokers:   brokers,
+		publisher: publisher,
+		logger:    logger,
+	}, nil
+}
+
+func (k *Kafka) Publisher() message.Publisher {
+	return k.publisher
+}
+
+func (k *Kafka) NewSubscriber(consumerGroup string) (message.Subscriber, error) {
+	saramaConfig := kafka.DefaultSaramaSubscriberConfig()
+	// new consumer groups start from the beginning of the topic, as Redis consumer groups do
+	saramaConfig.Consumer.Offsets.Initial = sarama.OffsetOldest
+
+	return kafka.NewSubscriber(kafka.SubscriberConfig{
+		Brokers:               k.brokers,
+		Unmarshaler:           kafka.DefaultMarshaler{},
+		OverwriteSaramaConfig: saramaConfig,
+		ConsumerGroup:         consumerGroup,
+	}, k.logger)
+}
+
+func (k *Kafka) Close() error {
+	return k.publisher.Close()
+}
@@ -0,0 +1,71 @@
+package broker
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill-nats/v2/pkg/nats"
+	"github.com/ThreeDotsLabs/watermill/message"
+	natsgo "github.com/nats-io/nats.go"
+)
+
+type NATS struct {
+	url       string
+	publisher *nats.Publisher
+	logger    watermill.LoggerAdapter
+}
+
+func NewNATS(url string, logger watermill.LoggerAdapter) (*NATS, error) {
+	publisher, err := nats.NewPublisher(nats.PublisherConfig{
+		URL:       url,
+		Marshaler: &nats.NATSMarshaler{},
+		JetStream: nats.JetStreamConfig{
+			AutoProvision: true,
+			TrackMsgId:    true,
+		},
+	}, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create nats publisher: %w", err)
+	}
+
+	return &NATS{
+		url:       url,
+		publisher: publisher,
+		logger:    logger,
+	}, nil
+}
+
+func (n *NATS) Publisher() message.Publisher {
+	return n.publisher
+}
+
+// NewSubscriber creates a JetStream subscriber with a durable queue group per consumer group and topic.
+func (n *NATS) NewSubscriber(consumerGroup string) (message.Subscriber, error) {
+	return nats.NewSubscriber(nats.SubscriberConfig{
+		URL:              n.url,
+		QueueGroupPrefix: natsName(consumerGroup),
+		SubscribersCount: 1,
+		Unmarshaler:      &nats.NATSMarshaler{},
+		JetStream: nats.JetStreamConfig{
+			AutoProvision: true,
+			DurablePrefix: natsName(consumerGroup),
+			DurableCalculator: func(prefix string, topic string) string {
+				return natsName(prefix + "_" + topic)
+			},
+			SubscribeOptions: []natsgo.SubOpt{
+				natsgo.DeliverAll(),
+				natsgo.AckExplicit(),
+			},
+		},
+	}, n.logger)
+}
+
+func (n *NATS) Close() error {
+	return n.publisher.Close()
+}
+
+// natsName replaces characters which are not allowed in durable and queue group names.
+func natsName(name string) string {
+	return strings.NewReplacer(".", "_", "*", "_", ">", "_").Replace(name)
+}
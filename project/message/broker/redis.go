@@ -0,0 +1,142 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill-redisstream/pkg/redisstream"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/redis/go-redis/v9"
+)
+
+type Redis struct {
+	client        *redis.Client
+	publisher     message.Publisher
+	janitorConfig RedisJanitorConfig
+	logger        watermill.LoggerAdapter
+}
+
+func NewRedisClient(addr string) *redis.Client {
+	return redis.NewClient(&redis.Options{
+		Addr: addr,
+	})
+}
+
+func NewRedis(client *redis.Client, janitorConfig RedisJanitorConfig, logger watermill.LoggerAdapter) (*Redis, error) {
+	if client == nil {
+		return nil, fmt.Errorf("missing redis client")
+	}
+
+	return &Redis{
+		client:        client,
+		publisher:     newPipelinedPublisher(client),
+		janitorConfig: janitorConfig,
+		logger:        logger,
+	}, nil
+}
+
+func (r *Redis) Publisher() message.Publisher {
+	return r.publisher
+}
+
+func (r *Redis) NewSubscriber(consumerGroup string) (message.Subscriber, error) {
+	return redisstream.NewSubscriber(redisstream.SubscriberConfig{
+		Client:        r.client,
+		ConsumerGroup: consumerGroup,
+	}, r.logger)
+}
+
+// PendingMessages returns the messages of the topic delivered to the consumer group, but not acknowledged yet.
+func (r *Redis) PendingMessages(ctx context.Context, topic, consumerGroup string) (PendingMessages, error) {
+	pending, err := r.client.XPending(ctx, topic, consumerGroup).Result()
+	if redis.HasErrorPrefix(err, "NOGROUP") {
+		// the stream and the group are created by the first subscription, nothing was delivered before
+		return PendingMessages{}, nil
+	}
+	if err != nil {
+		return PendingMessages{}, fmt.Errorf("failed to read pending messages of %s in %s: %w", consumerGroup, topic, err)
+	}
+
+	messages := PendingMessages{Count: pending.Count}
+	if pending.Count > 0 {
+		oldest, err := parseStreamID(pending.Lower)
+		if err != nil {
+			return PendingMessages{}, err
+		}
+		messages.OldestPublishedAt = oldest.Time()
+	}
+
+	return messages, nil
+}
+
+// streamID is the ID of a stream entry, "<unix milliseconds>-<sequence>".
+type streamID struct {
+	ms  uint64
+	seq uint64
+}
+
+func parseStreamID(id string) (streamID, error) {
+	ms, seq, _ := strings.Cut(id, "-")
+
+	var parsed streamID
+	var err error
+	if parsed.ms, err = strconv.ParseUint(ms, 10, 64); err != nil {
+		return streamID{}, fmt.Errorf("invalid stream entry ID %q: %w", id, err)
+	}
+	if seq != "" {
+		if parsed.seq, err = strconv.ParseUint(seq, 10, 64); err != nil {
+			return streamID{}, fmt.Errorf("invalid stream entry ID %q: %w", id, err)
+		}
+	}
+
+	return parsed, nil
+}
+
+// streamIDAt returns the first ID of entries added at t or later.
+func streamIDAt(t time.Time) streamID {
+	return streamID{ms: uint64(t.UnixMilli())}
+}
+
+// Time returns the time the entry was added to the stream.
+func (id streamID) Time() time.Time {
+	return time.UnixMilli(int64(id.ms)).UTC()
+}
+
+// Next returns the smallest ID greater than id.
+func (id streamID) Next() streamID {
+	if id.seq == math.MaxUint64 {
+		return streamID{ms: id.ms + 1}
+	}
+
+	return streamID{ms: id.ms, seq: id.seq + 1}
+}
+
+func (id streamID) Less(other streamID) bool {
+	if id.ms != other.ms {
+		return id.ms < other.ms
+	}
+
+	return id.seq < other.seq
+}
+
+func (id streamID) String() string {
+	return fmt.Sprintf("%d-%d", id.ms, id.seq)
+}
+
+// NewJanitor returns the janitor of the topics' streams and of the consumer groups with groupPrefix, see RedisJanitor.
+func (r *Redis) NewJanitor(topics []string, groupPrefix string) *RedisJanitor {
+	return NewRedisJanitor(r.client, topics, groupPrefix, r.janitorConfig, r.logger)
+}
+
+func (r *Redis) Client() *redis.Client {
+	return r.client
+}
+
+func (r *Redis) Close() error {
+	return r.client.Close()
+}
@@ -0,0 +1,435 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	janitorTrimmedEntries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "broker",
+		Subsystem: "redis_janitor",
+		Name:      "trimmed_entries_total",
+		Help:      "Number of stream entries removed by the trim policies.",
+	}, []string{"stream"})
+	janitorClaimedMessages = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "broker",
+		Subsystem: "redis_janitor",
+		Name:      "claimed_messages_total",
+		Help:      "Number of pending messages of dead consumers claimed by live consumers.",
+	}, []string{"stream", "group"})
+	janitorRemovedConsumers = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "broker",
+		Subsystem: "redis_janitor",
+		Name:      "removed_consumers_total",
+		Help:      "Number of dead consumers removed from the consumer groups.",
+	}, []string{"stream", "group"})
+	janitorRuns = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "broker",
+		Subsystem: "redis_janitor",
+		Name:      "runs_total",
+		Help:      "Number of janitor runs.",
+	}, []string{"result"})
+)
+
+// TrimPolicy limits the entries kept in a stream, except the ones some consumer group didn't acknowledge yet.
+type TrimPolicy struct {
+	// MaxLen is the number of entries kept in the stream, 0 means no limit.
+	MaxLen int64
+	// MaxAge is how long the entries are kept in the stream, 0 means no limit.
+	MaxAge time.Duration
+}
+
+func (p TrimPolicy) Enabled() bool {
+	return p.MaxLen > 0 || p.MaxAge > 0
+}
+
+type RedisJanitorConfig struct {
+	// TrimPolicies by topic, the topics without one use DefaultTrimPolicy.
+	TrimPolicies      map[string]TrimPolicy
+	DefaultTrimPolicy TrimPolicy
+
+	// ConsumerTimeout is how long a consumer can be idle before its messages are claimed and it's removed.
+	ConsumerTimeout time.Duration
+
+	// Interval between the runs of the janitor.
+	Interval time.Duration
+	// BatchSize is the maximum number of messages claimed in one call, and entries trimmed by MaxLen in one run.
+	BatchSize int64
+}
+
+func (c *RedisJanitorConfig) setDefaults() {
+	if c.ConsumerTimeout == 0 {
+		// longer than the slowest retries of the handlers, so slow messages are not taken from live consumers
+		c.ConsumerTimeout = time.Minute * 10
+	}
+	if c.Interval == 0 {
+		c.Interval = time.Minute
+	}
+	if c.BatchSize == 0 {
+		c.BatchSize = 1000
+	}
+}
+
+func (c RedisJanitorConfig) trimPolicy(topic string) TrimPolicy {
+	if policy, ok := c.TrimPolicies[topic]; ok {
+		return policy
+	}
+
+	return c.DefaultTrimPolicy
+}
+
+// ParseTrimPolicies parses the policies by topic, like "*=maxlen:100000;dead_letters=maxage:720h,maxlen:1000".
+func ParseTrimPolicies(s string) (policies map[string]TrimPolicy, defaultPolicy TrimPolicy, err error) {
+	policies = make(map[string]TrimPolicy)
+
+	for _, entry := range strings.Split(s, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		topic, options, ok := strings.Cut(entry, "=")
+		if !ok || topic == "" {
+			return nil, TrimPolicy{}, fmt.Errorf("invalid trim policy %q, expected <topic>=<option>:<value>,...", entry)
+		}
+
+		var policy TrimPolicy
+		for _, option := range strings.Split(options, ",") {
+			key, value, _ := strings.Cut(strings.TrimSpace(option), ":")
+
+			switch key {
+			case "maxlen":
+				policy.MaxLen, err = strconv.ParseInt(value, 10, 64)
+				if err == nil && policy.MaxLen <= 0 {
+					err = errors.New("must be positive")
+				}
+			case "maxage":
+				policy.MaxAge, err = time.ParseDuration(value)
+				if err == nil && policy.MaxAge <= 0 {
+					err = errors.New("must be positive")
+				}
+			default:
+				err = errors.New("unknown option")
+			}
+			if err != nil {
+				return nil, TrimPolicy{}, fmt.Errorf("invalid trim policy option %q of %s: %w", option, topic, err)
+			}
+		}
+
+		if topic == "*" {
+			defaultPolicy = policy
+		} else {
+			policies[topic] = policy
+		}
+	}
+
+	return policies, defaultPolicy, nil
+}
+
+// RedisJanitor trims the streams without the unacknowledged entries, and claims the messages of the dead consumers.
+type RedisJanitor struct {
+	client      *redis.Client
+	topics      []string
+	groupPrefix string
+	config      RedisJanitorConfig
+	logger      watermill.LoggerAdapter
+}
+
+func NewRedisJanitor(client *redis.Client, topics []string, groupPrefix string, config RedisJanitorConfig, logger watermill.LoggerAdapter) *RedisJanitor {
+	if client == nil {
+		panic("missing redis client")
+	}
+	if logger == nil {
+		logger = watermill.NopLogger{}
+	}
+
+	config.setDefaults()
+
+	return &RedisJanitor{
+		client:      client,
+		topics:      topics,
+		groupPrefix: groupPrefix,
+		config:      config,
+		logger:      logger,
+	}
+}
+
+// Run maintains the streams every Interval until ctx is done.
+func (j *RedisJanitor) Run(ctx context.Context) error {
+	ticker := time.NewTicker(j.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := j.Maintain(ctx); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			janitorRuns.WithLabelValues("error").Inc()
+			j.logger.Error("Redis streams maintenance failed", err, nil)
+		} else {
+			janitorRuns.WithLabelValues("success").Inc()
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Maintain maintains the streams of all topics, it doesn't stop on errors of a single stream.
+func (j *RedisJanitor) Maintain(ctx context.Context) error {
+	var errs []error
+	for _, topic := range j.topics {
+		if err := j.maintainStream(ctx, topic); err != nil {
+			errs = append(errs, fmt.Errorf("stream %s: %w", topic, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (j *RedisJanitor) maintainStream(ctx context.Context, stream string) error {
+	groups, err := j.client.XInfoGroups(ctx, stream).Result()
+	if err != nil {
+		if isNoSuchKey(err) {
+			// nothing was published to the topic yet
+			return nil
+		}
+		return fmt.Errorf("failed to read consumer groups: %w", err)
+	}
+
+	for _, group := range groups {
+		if !strings.HasPrefix(group.Name, j.groupPrefix) {
+			continue
+		}
+		if err := j.maintainGroup(ctx, stream, group.Name); err != nil {
+			return fmt.Errorf("group %s: %w", group.Name, err)
+		}
+	}
+
+	return j.trim(ctx, stream)
+}
+
+func (j *RedisJanitor) maintainGroup(ctx context.Context, stream, group string) error {
+	consumers, err := j.client.XInfoConsumers(ctx, stream, group).Result()
+	if err != nil {
+		return fmt.Errorf("failed to read consumers: %w", err)
+	}
+
+	live, dead := splitDeadConsumers(consumers, j.config.ConsumerTimeout)
+	if len(dead) == 0 {
+		return nil
+	}
+
+	if live != "" {
+		if err := j.claimDeadConsumersMessages(ctx, stream, group, live); err != nil {
+			return err
+		}
+
+		// the pending counts changed with the claimed messages
+		consumers, err = j.client.XInfoConsumers(ctx, stream, group).Result()
+		if err != nil {
+			return fmt.Errorf("failed to read consumers: %w", err)
+		}
+		_, dead = splitDeadConsumers(consumers, j.config.ConsumerTimeout)
+	}
+
+	for _, consumer := range dead {
+		// removing a consumer removes its pending messages too, so they would never be handled
+		if consumer.Pending > 0 {
+			continue
+		}
+
+		if err := j.client.XGroupDelConsumer(ctx, stream, group, consumer.Name).Err(); err != nil {
+			return fmt.Errorf("failed to remove consumer %s: %w", consumer.Name, err)
+		}
+		janitorRemovedConsumers.WithLabelValues(stream, group).Inc()
+		j.logger.Info("Removed dead consumer", watermill.LogFields{"stream": stream, "group": group, "consumer": consumer.Name})
+	}
+
+	return nil
+}
+
+// claimDeadConsumersMessages moves the messages pending for longer than ConsumerTimeout to the live consumer.
+func (j *RedisJanitor) claimDeadConsumersMessages(ctx context.Context, stream, group, consumer string) error {
+	start := "0-0"
+	for {
+		claimed, next, err := j.client.XAutoClaimJustID(ctx, &redis.XAutoClaimArgs{
+			Stream:   stream,
+			Group:    group,
+			Consumer: consumer,
+			MinIdle:  j.config.ConsumerTimeout,
+			Start:    start,
+			Count:    j.config.BatchSize,
+		}).Result()
+		if err != nil {
+			return fmt.Errorf("failed to claim pending messages: %w", err)
+		}
+
+		if len(claimed) > 0 {
+			janitorClaimedMessages.WithLabelValues(stream, group).Add(float64(len(claimed)))
+			j.logger.Info("Claimed messages of dead consumers", watermill.LogFields{
+				"stream":   stream,
+				"group":    group,
+				"consumer": consumer,
+				"claimed":  len(claimed),
+			})
+		}
+
+		if next == "0-0" || next == "" {
+			return nil
+		}
+		start = next
+	}
+}
+
+// splitDeadConsumers returns the most recently active consumer (empty if all are dead), and the dead consumers.
+func splitDeadConsumers(consumers []redis.XInfoConsumer, timeout time.Duration) (live string, dead []redis.XInfoConsumer) {
+	var liveIdle time.Duration
+	for _, consumer := range consumers {
+		if consumer.Idle >= timeout {
+			dead = append(dead, consumer)
+			continue
+		}
+
+		if live == "" || consumer.Idle < liveIdle {
+			live = consumer.Name
+			liveIdle = consumer.Idle
+		}
+	}
+
+	return live, dead
+}
+
+func (j *RedisJanitor) trim(ctx context.Context, stream string) error {
+	policy := j.config.trimPolicy(stream)
+	if !policy.Enabled() {
+		return nil
+	}
+
+	cutoff, err := j.policyCutoff(ctx, stream, policy)
+	if err != nil {
+		return err
+	}
+	if cutoff == nil {
+		return nil
+	}
+
+	groups, err := j.client.XInfoGroups(ctx, stream).Result()
+	if err != nil {
+		return fmt.Errorf("failed to read consumer groups: %w", err)
+	}
+
+	positions := make([]groupPosition, 0, len(groups))
+	for _, group := range groups {
+		position := groupPosition{Pending: group.Pending}
+		if position.LastDelivered, err = parseStreamID(group.LastDeliveredID); err != nil {
+			return err
+		}
+
+		if group.Pending > 0 {
+			pending, err := j.client.XPending(ctx, stream, group.Name).Result()
+			if err != nil {
+				return fmt.Errorf("failed to read pending messages of %s: %w", group.Name, err)
+			}
+			if position.OldestPending, err = parseStreamID(pending.Lower); err != nil {
+				return err
+			}
+		}
+
+		positions = append(positions, position)
+	}
+
+	minID := trimID(*cutoff, positions)
+
+	trimmed, err := j.client.XTrimMinID(ctx, stream, minID.String()).Result()
+	if err != nil {
+		return fmt.Errorf("failed to trim: %w", err)
+	}
+
+	if trimmed > 0 {
+		janitorTrimmedEntries.WithLabelValues(stream).Add(float64(trimmed))
+		j.logger.Debug("Trimmed stream", watermill.LogFields{"stream": stream, "trimmed": trimmed, "min_id": minID.String()})
+	}
+
+	return nil
+}
+
+// policyCutoff returns the smallest ID the policy keeps, or nil when the policy keeps all entries.
+func (j *RedisJanitor) policyCutoff(ctx context.Context, stream string, policy TrimPolicy) (*streamID, error) {
+	var cutoff *streamID
+
+	if policy.MaxAge > 0 {
+		id := streamIDAt(time.Now().Add(-policy.MaxAge))
+		cutoff = &id
+	}
+
+	if policy.MaxLen > 0 {
+		length, err := j.client.XLen(ctx, stream).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read length: %w", err)
+		}
+
+		if excess := length - policy.MaxLen; excess > 0 {
+			// the oldest entries over the limit, the rest of them is trimmed by the next runs
+			entries, err := j.client.XRangeN(ctx, stream, "-", "+", min(excess, j.config.BatchSize)).Result()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read the oldest entries: %w", err)
+			}
+
+			if len(entries) > 0 {
+				last, err := parseStreamID(entries[len(entries)-1].ID)
+				if err != nil {
+					return nil, err
+				}
+
+				id := last.Next()
+				if cutoff == nil || cutoff.Less(id) {
+					cutoff = &id
+				}
+			}
+		}
+	}
+
+	return cutoff, nil
+}
+
+// groupPosition is how far a consumer group got in the stream.
+type groupPosition struct {
+	LastDelivered streamID
+	Pending       int64
+	// OldestPending is set when Pending > 0.
+	OldestPending streamID
+}
+
+// trimID returns the cutoff, or the oldest entry a consumer group didn't receive or acknowledge yet.
+func trimID(cutoff streamID, groups []groupPosition) streamID {
+	minID := cutoff
+	for _, group := range groups {
+		if next := group.LastDelivered.Next(); next.Less(minID) {
+			minID = next
+		}
+		if group.Pending > 0 && group.OldestPending.Less(minID) {
+			minID = group.OldestPending
+		}
+	}
+
+	return minID
+}
+
+func isNoSuchKey(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "no such key")
+}
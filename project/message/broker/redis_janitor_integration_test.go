@@ -0,0 +1,85 @@
+//go:build integration
+
+package broker_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"tickets/message/broker"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisJanitor(t *testing.T) {
+	ctx := context.Background()
+
+	client := broker.NewRedisClient(os.Getenv("REDIS_ADDR"))
+	defer client.Close()
+
+	stream := "janitor-test-" + watermill.NewShortUUID()
+	defer client.Del(ctx, stream)
+
+	var ids []string
+	for i := 0; i < 10; i++ {
+		id, err := client.XAdd(ctx, &redis.XAddArgs{Stream: stream, Values: map[string]any{"i": i}}).Result()
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+
+	const group = "svc-tickets.Test"
+	require.NoError(t, client.XGroupCreate(ctx, stream, group, "0").Err())
+	// the group of another service got all entries, it doesn't hold the trimming back
+	require.NoError(t, client.XGroupCreate(ctx, stream, "other-service.Test", "$").Err())
+
+	// the crashed consumer acknowledged only 4 of the 6 entries it got
+	readAndAck(t, client, stream, group, "crashed", 6, 4)
+
+	time.Sleep(time.Millisecond * 100)
+
+	// the live consumer handles the rest of the entries
+	readAndAck(t, client, stream, group, "live", 4, 4)
+
+	janitor := broker.NewRedisJanitor(client, []string{stream}, "svc-tickets.", broker.RedisJanitorConfig{
+		DefaultTrimPolicy: broker.TrimPolicy{MaxLen: 2},
+		ConsumerTimeout:   time.Millisecond * 100,
+	}, watermill.NopLogger{})
+	require.NoError(t, janitor.Maintain(ctx))
+
+	pending, err := client.XPending(ctx, stream, group).Result()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), pending.Count)
+	assert.Equal(t, map[string]int64{"live": 2}, pending.Consumers, "pending messages of the crashed consumer not claimed")
+
+	consumers, err := client.XInfoConsumers(ctx, stream, group).Result()
+	require.NoError(t, err)
+	require.Len(t, consumers, 1, "crashed consumer not removed")
+	assert.Equal(t, "live", consumers[0].Name)
+
+	// the policy keeps 2 entries, but the group didn't acknowledge the 5th and 6th entry yet
+	entries, err := client.XRange(ctx, stream, "-", "+").Result()
+	require.NoError(t, err)
+	require.Len(t, entries, 6)
+	assert.Equal(t, ids[4], entries[0].ID)
+}
+
+func readAndAck(t *testing.T, client *redis.Client, stream, group, consumer string, read int64, ack int) {
+	t.Helper()
+
+	streams, err := client.XReadGroup(context.Background(), &redis.XReadGroupArgs{
+		Group:    group,
+		Consumer: consumer,
+		Streams:  []string{stream, ">"},
+		Count:    read,
+	}).Result()
+	require.NoError(t, err)
+	require.Len(t, streams[0].Messages, int(read))
+
+	for _, msg := range streams[0].Messages[:ack] {
+		require.NoError(t, client.XAck(context.Background(), stream, group, msg.ID).Err())
+	}
+}
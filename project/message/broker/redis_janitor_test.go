@@ -0,0 +1,102 @@
+package broker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTrimPolicies(t *testing.T) {
+	policies, defaultPolicy, err := ParseTrimPolicies("*=maxlen:100000; dead_letters=maxage:720h,maxlen:1000")
+	require.NoError(t, err)
+
+	assert.Equal(t, TrimPolicy{MaxLen: 100000}, defaultPolicy)
+	assert.Equal(t, map[string]TrimPolicy{
+		"dead_letters": {MaxLen: 1000, MaxAge: time.Hour * 720},
+	}, policies)
+
+	for _, invalid := range []string{
+		"maxlen:100",
+		"=maxlen:100",
+		"TicketPrinted=maxlen:-1",
+		"TicketPrinted=maxage:forever",
+		"TicketPrinted=minid:0",
+	} {
+		_, _, err := ParseTrimPolicies(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestStreamID(t *testing.T) {
+	id, err := parseStreamID("1700000000000-5")
+	require.NoError(t, err)
+
+	assert.Equal(t, "1700000000000-5", id.String())
+	assert.Equal(t, "1700000000000-6", id.Next().String())
+	assert.Equal(t, time.UnixMilli(1700000000000).UTC(), id.Time())
+
+	assert.True(t, id.Less(id.Next()))
+	assert.True(t, id.Less(streamID{ms: 1700000000001}))
+	assert.False(t, id.Less(id))
+
+	_, err = parseStreamID("not-an-id")
+	assert.Error(t, err)
+}
+
+func TestTrimID(t *testing.T) {
+	cutoff := streamID{ms: 100}
+
+	testCases := []struct {
+		Name     string
+		Groups   []groupPosition
+		Expected streamID
+	}{
+		{
+			Name:     "no groups",
+			Expected: cutoff,
+		},
+		{
+			Name:     "all groups acknowledged the entries before the cutoff",
+			Groups:   []groupPosition{{LastDelivered: streamID{ms: 150}}, {LastDelivered: streamID{ms: 120, seq: 3}}},
+			Expected: cutoff,
+		},
+		{
+			Name:     "group didn't receive the entries yet",
+			Groups:   []groupPosition{{LastDelivered: streamID{ms: 150}}, {LastDelivered: streamID{ms: 50, seq: 3}}},
+			Expected: streamID{ms: 50, seq: 4},
+		},
+		{
+			Name: "group didn't acknowledge the entry",
+			Groups: []groupPosition{
+				{LastDelivered: streamID{ms: 150}, Pending: 2, OldestPending: streamID{ms: 20, seq: 1}},
+				{LastDelivered: streamID{ms: 50}},
+			},
+			Expected: streamID{ms: 20, seq: 1},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			assert.Equal(t, tc.Expected, trimID(cutoff, tc.Groups))
+		})
+	}
+}
+
+func TestSplitDeadConsumers(t *testing.T) {
+	live, dead := splitDeadConsumers([]redis.XInfoConsumer{
+		{Name: "crashed", Idle: time.Hour, Pending: 3},
+		{Name: "busy", Idle: time.Second * 30},
+		{Name: "active", Idle: time.Millisecond * 100},
+	}, time.Minute*10)
+
+	assert.Equal(t, "active", live)
+	require.Len(t, dead, 1)
+	assert.Equal(t, "crashed", dead[0].Name)
+
+	live, dead = splitDeadConsumers([]redis.XInfoConsumer{{Name: "crashed", Idle: time.Hour}}, time.Minute*10)
+	assert.Empty(t, live)
+	assert.Len(t, dead, 1)
+}
@@ -0,0 +1,62 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ThreeDotsLabs/watermill-redisstream/pkg/redisstream"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/redis/go-redis/v9"
+)
+
+// pipelinedPublisher publishes the messages of a Publish call to Redis streams in one pipeline.
+type pipelinedPublisher struct {
+	client     redis.UniversalClient
+	marshaller redisstream.Marshaller
+}
+
+func newPipelinedPublisher(client redis.UniversalClient) pipelinedPublisher {
+	return pipelinedPublisher{
+		client:     client,
+		marshaller: redisstream.DefaultMarshallerUnmarshaller{},
+	}
+}
+
+func (p pipelinedPublisher) Publish(topic string, msgs ...*message.Message) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	ctx := context.Background()
+
+	pipe := p.client.Pipeline()
+	for _, msg := range msgs {
+		values, err := p.marshaller.Marshal(topic, msg)
+		if err != nil {
+			return fmt.Errorf("cannot marshal message %s: %w", msg.UUID, err)
+		}
+
+		pipe.XAdd(ctx, &redis.XAddArgs{
+			Stream: topic,
+			Values: values,
+		})
+	}
+
+	cmds, err := pipe.Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot publish %d messages to %s: %w", len(msgs), topic, err)
+	}
+
+	for i, cmd := range cmds {
+		if err := cmd.Err(); err != nil {
+			return fmt.Errorf("cannot xadd message %s: %w", msgs[i].UUID, err)
+		}
+	}
+
+	return nil
+}
+
+// Close does nothing, the client is closed by Redis.Close.
+func (p pipelinedPublisher) Close() error {
+	return nil
+}
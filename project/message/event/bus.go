@@ -1,20 +1,43 @@
 package event
 
 import (
+	"tickets/message/outbox"
+	"tickets/tenant"
+
 	"github.com/ThreeDotsLabs/watermill/components/cqrs"
 	"github.com/ThreeDotsLabs/watermill/message"
 )
 
-func NewEventBus(publisher message.Publisher) (*cqrs.EventBus, error) {
+// orderedEvent is implemented by the events handled in order with the other events with the same key.
+type orderedEvent interface {
+	OrderingKey() string
+}
+
+// TenantIDMetadataKey is the metadata of the tenant the message was published for.
+const TenantIDMetadataKey = "tenant_id"
+
+// Topic returns the topic the event is published on, and the event handlers subscribe to.
+func Topic(eventName string) string {
+	return eventName
+}
+
+func NewEventBus(publisher message.Publisher, marshaler cqrs.CommandEventMarshaler) (*cqrs.EventBus, error) {
 	return cqrs.NewEventBusWithConfig(
 		publisher,
 		cqrs.EventBusConfig{
 			GeneratePublishTopic: func(params cqrs.GenerateEventPublishTopicParams) (string, error) {
-				return params.EventName, nil
+				return Topic(params.EventName), nil
 			},
-			Marshaler: cqrs.JSONMarshaler{
-				GenerateName: cqrs.StructName,
+			OnPublish: func(params cqrs.OnEventSendParams) error {
+				if event, ok := params.Event.(orderedEvent); ok {
+					params.Message.Metadata.Set(outbox.OrderingKeyMetadataKey, event.OrderingKey())
+				}
+
+				params.Message.Metadata.Set(TenantIDMetadataKey, tenant.FromContext(params.Message.Context()))
+
+				return nil
 			},
+			Marshaler: marshaler,
 		},
 	)
 }
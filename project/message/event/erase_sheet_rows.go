@@ -0,0 +1,19 @@
+package event
+
+import (
+	"context"
+	"tickets/entities"
+
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+)
+
+// EraseSheetRows marks the rows as erased, so the reconciliation reports the appended ones to be removed from the sheet.
+func (h Handler) EraseSheetRows(ctx context.Context, event *entities.CustomerDataErased) error {
+	log.FromContext(ctx).Info("Marking sheet rows of the erased customer data as erased")
+
+	if len(event.TicketIDs) == 0 {
+		return nil
+	}
+
+	return h.sheetRowsRepository.MarkErased(ctx, event.TicketIDs, event.Pseudonym)
+}
@@ -0,0 +1,4 @@
+// Package eventpb contains protobuf definitions of the events published on the bus.
+package eventpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative events.proto
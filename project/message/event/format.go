@@ -0,0 +1,143 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"tickets/entities"
+
+	"github.com/ThreeDotsLabs/watermill/components/cqrs"
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+type Format string
+
+const (
+	FormatJSON     Format = "json"
+	FormatProtobuf Format = "protobuf"
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch Format(s) {
+	case "", FormatJSON:
+		return FormatJSON, nil
+	case FormatProtobuf:
+		return FormatProtobuf, nil
+	default:
+		return "", fmt.Errorf("unknown events format: %s", s)
+	}
+}
+
+const ContentTypeMetadataKey = "content_type"
+
+const (
+	ContentTypeJSON     = "application/json"
+	ContentTypeProtobuf = "application/protobuf"
+)
+
+// Marshaler publishes the events in the configured format, and reads both formats by the content type.
+type Marshaler struct {
+	format   Format
+	json     VersionedMarshaler
+	protobuf ProtobufMarshaler
+}
+
+func NewMarshaler(format Format) Marshaler {
+	if format != FormatJSON && format != FormatProtobuf {
+		panic(fmt.Sprintf("unknown events format: %s", format))
+	}
+
+	return Marshaler{
+		format:   format,
+		json:     NewJSONMarshaler(),
+		protobuf: ProtobufMarshaler{},
+	}
+}
+
+func (m Marshaler) Marshal(v interface{}) (*message.Message, error) {
+	if m.format == FormatProtobuf {
+		msg, err := m.protobuf.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		msg.Metadata.Set(ContentTypeMetadataKey, ContentTypeProtobuf)
+
+		return msg, nil
+	}
+
+	msg, err := m.json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	msg.Metadata.Set(ContentTypeMetadataKey, ContentTypeJSON)
+
+	return msg, nil
+}
+
+func (m Marshaler) Unmarshal(msg *message.Message, v interface{}) error {
+	switch contentType := msg.Metadata.Get(ContentTypeMetadataKey); contentType {
+	// messages published before the content type was introduced are JSON
+	case "", ContentTypeJSON:
+		return m.json.Unmarshal(msg, v)
+	case ContentTypeProtobuf:
+		return m.protobuf.Unmarshal(msg, v)
+	default:
+		return fmt.Errorf("unsupported content type: %s", contentType)
+	}
+}
+
+func (m Marshaler) Name(v interface{}) string {
+	return cqrs.StructName(v)
+}
+
+func (m Marshaler) NameFromMessage(msg *message.Message) string {
+	return msg.Metadata.Get("name")
+}
+
+// JSONPayload returns the event as JSON of its current schema version, whatever format it was published in.
+func JSONPayload(msg *message.Message) ([]byte, error) {
+	eventName := cqrs.JSONMarshaler{}.NameFromMessage(msg)
+
+	newEvent, ok := events[eventName]
+	if !ok {
+		return nil, fmt.Errorf("unknown event: %s", eventName)
+	}
+
+	e := newEvent()
+	if err := NewMarshaler(FormatJSON).Unmarshal(msg, e); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(e)
+}
+
+// EventNames returns the names of all events published on the bus, sorted.
+func EventNames() []string {
+	names := make([]string, 0, len(events))
+	for name := range events {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// NewEvent returns a new event of the name, or false if no such event is published on the bus.
+func NewEvent(eventName string) (any, bool) {
+	newEvent, ok := events[eventName]
+	if !ok {
+		return nil, false
+	}
+
+	return newEvent(), true
+}
+
+// events lists all events published on the bus, by their name.
+var events = map[string]func() any{
+	"TicketBookingConfirmed": func() any { return &entities.TicketBookingConfirmed{} },
+	"TicketBookingCanceled":  func() any { return &entities.TicketBookingCanceled{} },
+	"TicketRefunded":         func() any { return &entities.TicketRefunded{} },
+	"TicketPrinted":          func() any { return &entities.TicketPrinted{} },
+	"BookingMade":            func() any { return &entities.BookingMade{} },
+	"CustomerDataErased":     func() any { return &entities.CustomerDataErased{} },
+}
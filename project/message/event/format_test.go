@@ -0,0 +1,146 @@
+package event_test
+
+import (
+	"testing"
+	"tickets/entities"
+	"tickets/message/event"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshaler_protobuf_round_trip(t *testing.T) {
+	marshaler := event.NewMarshaler(event.FormatProtobuf)
+
+	testCases := []struct {
+		event        any
+		unmarshalled any
+	}{
+		{
+			event: entities.TicketBookingConfirmed{
+				Header:        entities.NewEventHeader().WithTenant("acme"),
+				TicketID:      uuid.NewString(),
+				CustomerEmail: "email@example.com",
+				Price:         entities.Money{Amount: "50.30", Currency: "GBP"},
+				BookingID:     uuid.NewString(),
+			},
+			unmarshalled: &entities.TicketBookingConfirmed{},
+		},
+		{
+			event: entities.TicketBookingCanceled{
+				Header:        entities.NewEventHeader(),
+				TicketID:      uuid.NewString(),
+				CustomerEmail: "email@example.com",
+				Price:         entities.Money{Amount: "50.30", Currency: "GBP"},
+			},
+			unmarshalled: &entities.TicketBookingCanceled{},
+		},
+		{
+			event: entities.TicketRefunded{
+				Header:   entities.NewEventHeader(),
+				TicketID: uuid.NewString(),
+			},
+			unmarshalled: &entities.TicketRefunded{},
+		},
+		{
+			event: entities.TicketPrinted{
+				Header:   entities.NewEventHeader(),
+				TicketID: uuid.NewString(),
+				FileName: "ticket.html",
+			},
+			unmarshalled: &entities.TicketPrinted{},
+		},
+		{
+			event: entities.BookingMade{
+				Header:          entities.NewEventHeader(),
+				NumberOfTickets: 3,
+				BookingID:       uuid.New(),
+				CustomerEmail:   "email@example.com",
+				ShowId:          uuid.New(),
+			},
+			unmarshalled: &entities.BookingMade{},
+		},
+		{
+			event: entities.CustomerDataErased{
+				Header:     entities.NewEventHeader().WithTenant("acme"),
+				TicketIDs:  []string{uuid.NewString(), uuid.NewString()},
+				BookingIDs: []string{uuid.NewString()},
+				Pseudonym:  entities.NewCustomerPseudonym(),
+			},
+			unmarshalled: &entities.CustomerDataErased{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(marshaler.Name(tc.event), func(t *testing.T) {
+			msg, err := marshaler.Marshal(tc.event)
+			require.NoError(t, err)
+			assert.Equal(t, event.ContentTypeProtobuf, msg.Metadata.Get(event.ContentTypeMetadataKey))
+
+			require.NoError(t, marshaler.Unmarshal(msg, tc.unmarshalled))
+			assert.Equal(t, tc.event, derefTestEvent(tc.unmarshalled))
+		})
+	}
+}
+
+func TestMarshaler_reads_both_formats(t *testing.T) {
+	confirmed := entities.TicketBookingConfirmed{
+		Header:   entities.NewEventHeader(),
+		TicketID: uuid.NewString(),
+		Price:    entities.Money{Amount: "10", Currency: "EUR"},
+	}
+
+	jsonMsg, err := event.NewMarshaler(event.FormatJSON).Marshal(confirmed)
+	require.NoError(t, err)
+	assert.Equal(t, event.ContentTypeJSON, jsonMsg.Metadata.Get(event.ContentTypeMetadataKey))
+
+	protobufMsg, err := event.NewMarshaler(event.FormatProtobuf).Marshal(confirmed)
+	require.NoError(t, err)
+
+	for _, format := range []event.Format{event.FormatJSON, event.FormatProtobuf} {
+		marshaler := event.NewMarshaler(format)
+
+		var fromJSON, fromProtobuf entities.TicketBookingConfirmed
+		require.NoError(t, marshaler.Unmarshal(jsonMsg, &fromJSON))
+		require.NoError(t, marshaler.Unmarshal(protobufMsg, &fromProtobuf))
+
+		assert.Equal(t, confirmed, fromJSON)
+		assert.Equal(t, confirmed, fromProtobuf)
+	}
+
+	jsonPayload, err := event.JSONPayload(protobufMsg)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(jsonMsg.Payload), string(jsonPayload))
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := event.ParseFormat("")
+	require.NoError(t, err)
+	assert.Equal(t, event.FormatJSON, format)
+
+	format, err = event.ParseFormat("protobuf")
+	require.NoError(t, err)
+	assert.Equal(t, event.FormatProtobuf, format)
+
+	_, err = event.ParseFormat("avro")
+	assert.Error(t, err)
+}
+
+func derefTestEvent(v any) any {
+	switch e := v.(type) {
+	case *entities.TicketBookingConfirmed:
+		return *e
+	case *entities.TicketBookingCanceled:
+		return *e
+	case *entities.TicketRefunded:
+		return *e
+	case *entities.TicketPrinted:
+		return *e
+	case *entities.BookingMade:
+		return *e
+	case *entities.CustomerDataErased:
+		return *e
+	}
+	return v
+}
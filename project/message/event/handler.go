@@ -9,27 +9,26 @@ import (
 )
 
 type Handler struct {
-	spreadsheetsService SpreadsheetsAPI
 	receiptsService     ReceiptsService
 	ticketsRepository   TicketsRepository
 	fileService         FileAPI
 	deadNationAPI       DeadNationAPI
 	showRepository      ShowsRepository
+	sheetRowsRepository SheetRowsRepository
+	reportsRepository   ReportsRepository
 	eventBus            *cqrs.EventBus
 }
 
 func NewHandler(
-	spreadsheetsService SpreadsheetsAPI,
 	receiptsService ReceiptsService,
 	ticketsRepository TicketsRepository,
 	fileService FileAPI,
 	deadNationAPI DeadNationAPI,
 	showRepository ShowsRepository,
+	sheetRowsRepository SheetRowsRepository,
+	reportsRepository ReportsRepository,
 	eventBus *cqrs.EventBus,
 ) Handler {
-	if spreadsheetsService == nil {
-		panic("missing spreadsheetsService")
-	}
 	if receiptsService == nil {
 		panic("missing receiptsService")
 	}
@@ -45,32 +44,36 @@ func NewHandler(
 	if showRepository == nil {
 		panic("missing showRepository")
 	}
+	if sheetRowsRepository == nil {
+		panic("missing sheetRowsRepository")
+	}
+	if reportsRepository == nil {
+		panic("missing reportsRepository")
+	}
 	if eventBus == nil {
 		panic("missing eventBus")
 	}
 
 	return Handler{
-		spreadsheetsService: spreadsheetsService,
 		receiptsService:     receiptsService,
 		ticketsRepository:   ticketsRepository,
 		fileService:         fileService,
 		deadNationAPI:       deadNationAPI,
 		showRepository:      showRepository,
+		sheetRowsRepository: sheetRowsRepository,
+		reportsRepository:   reportsRepository,
 		eventBus:            eventBus,
 	}
 }
 
-type SpreadsheetsAPI interface {
-	AppendRow(ctx context.Context, sheetName string, row []string) error
-}
-
 type ReceiptsService interface {
 	IssueReceipt(ctx context.Context, request entities.IssueReceiptRequest) (entities.IssueReceiptResponse, error)
 }
 
 type TicketsRepository interface {
 	Add(ctx context.Context, ticket entities.Ticket) error
-	Remove(ctx context.Context, ticketId string) error
+	Remove(ctx context.Context, ticket entities.Ticket) error
+	UpdateStatus(ctx context.Context, ticketID string, status entities.TicketStatus) error
 }
 
 type FileAPI interface {
@@ -84,3 +87,13 @@ type DeadNationAPI interface {
 type ShowsRepository interface {
 	GetOne(ctx context.Context, showId uuid.UUID) (entities.Show, error)
 }
+
+type SheetRowsRepository interface {
+	Add(ctx context.Context, row entities.SheetRow) (bool, error)
+	MarkErased(ctx context.Context, ticketIDs []string, pseudonym string) error
+}
+
+type ReportsRepository interface {
+	SaveTicketSale(ctx context.Context, sale entities.TicketSale) error
+	AddBookedShow(ctx context.Context, bookingID string, showID uuid.UUID) error
+}
@@ -0,0 +1,145 @@
+package event
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/ThreeDotsLabs/watermill/components/cqrs"
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+const SchemaVersionMetadataKey = "schema_version"
+
+var ErrUnknownSchemaVersion = errors.New("unknown event schema version")
+
+// Upcaster migrates a payload from one schema version to the next one.
+type Upcaster func(payload map[string]any) (map[string]any, error)
+
+type SchemaRegistry struct {
+	// event name -> version the upcaster migrates from
+	upcasters map[string]map[int]Upcaster
+}
+
+func NewSchemaRegistry() *SchemaRegistry {
+	return &SchemaRegistry{
+		upcasters: make(map[string]map[int]Upcaster),
+	}
+}
+
+// Register adds an upcaster migrating eventName payloads from fromVersion to fromVersion+1.
+func (r *SchemaRegistry) Register(eventName string, fromVersion int, upcaster Upcaster) {
+	if fromVersion < 1 {
+		panic(fmt.Sprintf("invalid schema version %d for %s", fromVersion, eventName))
+	}
+
+	if r.upcasters[eventName] == nil {
+		r.upcasters[eventName] = make(map[int]Upcaster)
+	}
+	if _, ok := r.upcasters[eventName][fromVersion]; ok {
+		panic(fmt.Sprintf("upcaster for %s from version %d already registered", eventName, fromVersion))
+	}
+
+	r.upcasters[eventName][fromVersion] = upcaster
+}
+
+// CurrentVersion returns the version of the event struct in the code, 1 for the events without upcasters.
+func (r *SchemaRegistry) CurrentVersion(eventName string) int {
+	current := 1
+	for fromVersion := range r.upcasters[eventName] {
+		if fromVersion+1 > current {
+			current = fromVersion + 1
+		}
+	}
+
+	return current
+}
+
+// Upcast migrates the payload from version to the current version of the event.
+func (r *SchemaRegistry) Upcast(eventName string, version int, payload []byte) ([]byte, error) {
+	current := r.CurrentVersion(eventName)
+
+	if version < 1 || version > current {
+		return nil, fmt.Errorf("%w: %s version %d (current version is %d)", ErrUnknownSchemaVersion, eventName, version, current)
+	}
+	if version == current {
+		return payload, nil
+	}
+
+	var data map[string]any
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s version %d: %w", eventName, version, err)
+	}
+
+	for ; version < current; version++ {
+		upcaster, ok := r.upcasters[eventName][version]
+		if !ok {
+			return nil, fmt.Errorf("%w: missing upcaster for %s from version %d", ErrUnknownSchemaVersion, eventName, version)
+		}
+
+		var err error
+		data, err = upcaster(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to upcast %s from version %d: %w", eventName, version, err)
+		}
+	}
+
+	return json.Marshal(data)
+}
+
+// VersionedMarshaler records the schema version of the events, and upcasts the older payloads when unmarshaling.
+type VersionedMarshaler struct {
+	cqrs.JSONMarshaler
+
+	Registry *SchemaRegistry
+}
+
+func NewJSONMarshaler() VersionedMarshaler {
+	return VersionedMarshaler{
+		JSONMarshaler: cqrs.JSONMarshaler{
+			GenerateName: cqrs.StructName,
+		},
+		Registry: schemas,
+	}
+}
+
+func (m VersionedMarshaler) Marshal(v interface{}) (*message.Message, error) {
+	msg, err := m.JSONMarshaler.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	msg.Metadata.Set(SchemaVersionMetadataKey, strconv.Itoa(m.Registry.CurrentVersion(m.Name(v))))
+
+	return msg, nil
+}
+
+func (m VersionedMarshaler) Unmarshal(msg *message.Message, v interface{}) error {
+	version, err := SchemaVersionFromMessage(msg)
+	if err != nil {
+		return err
+	}
+
+	payload, err := m.Registry.Upcast(m.NameFromMessage(msg), version, msg.Payload)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(payload, v)
+}
+
+// SchemaVersionFromMessage returns the schema version of the message, 1 for the messages published without it.
+func SchemaVersionFromMessage(msg *message.Message) (int, error) {
+	rawVersion := msg.Metadata.Get(SchemaVersionMetadataKey)
+	if rawVersion == "" {
+		return 1, nil
+	}
+
+	version, err := strconv.Atoi(rawVersion)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrUnknownSchemaVersion, rawVersion)
+	}
+
+	return version, nil
+}
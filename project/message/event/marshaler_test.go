@@ -0,0 +1,86 @@
+package event_test
+
+import (
+	"testing"
+	"tickets/entities"
+	"tickets/message/event"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionedMarshaler_round_trip(t *testing.T) {
+	marshaler := event.NewJSONMarshaler()
+
+	confirmed := entities.TicketBookingConfirmed{
+		Header:        entities.NewEventHeader(),
+		TicketID:      "ticket-1",
+		CustomerEmail: "email@example.com",
+		Price:         entities.Money{Amount: "10", Currency: "EUR"},
+		BookingID:     "booking-1",
+	}
+
+	msg, err := marshaler.Marshal(confirmed)
+	require.NoError(t, err)
+	assert.Equal(t, "TicketBookingConfirmed", marshaler.NameFromMessage(msg))
+	assert.Equal(t, "2", msg.Metadata.Get(event.SchemaVersionMetadataKey))
+
+	var unmarshaled entities.TicketBookingConfirmed
+	require.NoError(t, marshaler.Unmarshal(msg, &unmarshaled))
+	assert.Equal(t, confirmed, unmarshaled)
+
+	msg, err = marshaler.Marshal(entities.TicketPrinted{TicketID: "ticket-1"})
+	require.NoError(t, err)
+	assert.Equal(t, "1", msg.Metadata.Get(event.SchemaVersionMetadataKey))
+}
+
+func TestVersionedMarshaler_upcasts_messages_without_version(t *testing.T) {
+	marshaler := event.NewJSONMarshaler()
+
+	// message published before schema versioning was introduced
+	msg := message.NewMessage("1", []byte(`{"ticket_id":"ticket-1","customer_email":"email@example.com","price":{"amount":"10","currency":"EUR"}}`))
+	msg.Metadata.Set("name", "TicketBookingConfirmed")
+
+	var unmarshaled entities.TicketBookingConfirmed
+	require.NoError(t, marshaler.Unmarshal(msg, &unmarshaled))
+	assert.Equal(t, "ticket-1", unmarshaled.TicketID)
+	assert.Equal(t, "", unmarshaled.BookingID)
+}
+
+func TestVersionedMarshaler_unknown_version(t *testing.T) {
+	marshaler := event.NewJSONMarshaler()
+
+	for _, version := range []string{"3", "0", "latest"} {
+		msg := message.NewMessage("1", []byte(`{"ticket_id":"ticket-1"}`))
+		msg.Metadata.Set("name", "TicketBookingConfirmed")
+		msg.Metadata.Set(event.SchemaVersionMetadataKey, version)
+
+		var unmarshaled entities.TicketBookingConfirmed
+		err := marshaler.Unmarshal(msg, &unmarshaled)
+		assert.ErrorIs(t, err, event.ErrUnknownSchemaVersion, "version %s", version)
+	}
+}
+
+func TestSchemaRegistry_Upcast_chain(t *testing.T) {
+	registry := event.NewSchemaRegistry()
+	registry.Register("ShowCreated", 1, func(payload map[string]any) (map[string]any, error) {
+		payload["venue"] = payload["place"]
+		delete(payload, "place")
+		return payload, nil
+	})
+	registry.Register("ShowCreated", 2, func(payload map[string]any) (map[string]any, error) {
+		payload["title"] = "untitled"
+		return payload, nil
+	})
+
+	assert.Equal(t, 3, registry.CurrentVersion("ShowCreated"))
+
+	payload, err := registry.Upcast("ShowCreated", 1, []byte(`{"place":"Arena"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"venue":"Arena","title":"untitled"}`, string(payload))
+
+	payload, err = registry.Upcast("ShowCreated", 2, []byte(`{"venue":"Arena"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"venue":"Arena","title":"untitled"}`, string(payload))
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"tickets/entities"
+	"tickets/tenant"
 
 	"github.com/ThreeDotsLabs/go-event-driven/common/log"
 )
@@ -11,7 +12,7 @@ import (
 func (h Handler) PrintTickets(ctx context.Context, event *entities.TicketBookingConfirmed) error {
 	log.FromContext(ctx).Info("Printing tickets")
 
-	fileName := event.TicketID + "-ticket.html"
+	fileName := tenant.Name(ctx, event.TicketID+"-ticket.html")
 	ticketIDHtml := "<div>Ticket ID: " + event.TicketID + "</div>"
 	priceHtml := "<div>Price: " + event.Price.Amount + event.Price.Currency + "</div>"
 	htmlBody := ticketIDHtml + priceHtml
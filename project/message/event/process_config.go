@@ -2,26 +2,31 @@ package event
 
 import (
 	"github.com/ThreeDotsLabs/watermill"
-	"github.com/ThreeDotsLabs/watermill-redisstream/pkg/redisstream"
 	"github.com/ThreeDotsLabs/watermill/components/cqrs"
 	"github.com/ThreeDotsLabs/watermill/message"
-	"github.com/redis/go-redis/v9"
 )
 
-func NewEventProcessConfig(redisClient *redis.Client, watermillLogger watermill.LoggerAdapter) cqrs.EventProcessorConfig {
+type SubscriberFactory interface {
+	NewSubscriber(consumerGroup string) (message.Subscriber, error)
+}
+
+// ConsumerGroupPrefix is the prefix of the consumer groups of the service's handlers.
+const ConsumerGroupPrefix = "svc-tickets."
+
+// ConsumerGroup returns the consumer group of the handler, so each handler receives all events it subscribes to.
+func ConsumerGroup(handlerName string) string {
+	return ConsumerGroupPrefix + handlerName
+}
+
+func NewEventProcessConfig(subscriberFactory SubscriberFactory, marshaler cqrs.CommandEventMarshaler, watermillLogger watermill.LoggerAdapter) cqrs.EventProcessorConfig {
 	return cqrs.EventProcessorConfig{
 		GenerateSubscribeTopic: func(params cqrs.EventProcessorGenerateSubscribeTopicParams) (string, error) {
-			return params.EventName, nil
+			return Topic(params.EventName), nil
 		},
 		SubscriberConstructor: func(params cqrs.EventProcessorSubscriberConstructorParams) (message.Subscriber, error) {
-			return redisstream.NewSubscriber(redisstream.SubscriberConfig{
-				Client:        redisClient,
-				ConsumerGroup: "svc-tickets." + params.HandlerName,
-			}, watermillLogger)
-		},
-		Marshaler: cqrs.JSONMarshaler{
-			GenerateName: cqrs.StructName,
+			return subscriberFactory.NewSubscriber(ConsumerGroup(params.HandlerName))
 		},
-		Logger: watermillLogger,
+		Marshaler: marshaler,
+		Logger:    watermillLogger,
 	}
 }
@@ -0,0 +1,262 @@
+package event
+
+import (
+	"fmt"
+	"tickets/entities"
+	"tickets/message/event/eventpb"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/components/cqrs"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ProtobufMarshaler marshals events using the definitions from eventpb/events.proto.
+type ProtobufMarshaler struct{}
+
+func (m ProtobufMarshaler) Marshal(v interface{}) (*message.Message, error) {
+	pb, err := toProto(v)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := proto.Marshal(pb)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %T to protobuf: %w", v, err)
+	}
+
+	msg := message.NewMessage(watermill.NewUUID(), payload)
+	msg.Metadata.Set("name", m.Name(v))
+
+	return msg, nil
+}
+
+func (m ProtobufMarshaler) Unmarshal(msg *message.Message, v interface{}) error {
+	pb, err := newProtoFor(v)
+	if err != nil {
+		return err
+	}
+
+	if err := proto.Unmarshal(msg.Payload, pb); err != nil {
+		return fmt.Errorf("failed to unmarshal %T from protobuf: %w", v, err)
+	}
+
+	return fromProto(pb, v)
+}
+
+func (m ProtobufMarshaler) Name(v interface{}) string {
+	return cqrs.StructName(v)
+}
+
+func (m ProtobufMarshaler) NameFromMessage(msg *message.Message) string {
+	return msg.Metadata.Get("name")
+}
+
+func toProto(v interface{}) (proto.Message, error) {
+	switch e := v.(type) {
+	case entities.TicketBookingConfirmed:
+		return &eventpb.TicketBookingConfirmed{
+			Header:        headerToProto(e.Header),
+			TicketId:      e.TicketID,
+			CustomerEmail: e.CustomerEmail,
+			Price:         moneyToProto(e.Price),
+			BookingId:     e.BookingID,
+			Version:       e.Version,
+		}, nil
+	case entities.TicketBookingCanceled:
+		return &eventpb.TicketBookingCanceled{
+			Header:        headerToProto(e.Header),
+			TicketId:      e.TicketID,
+			CustomerEmail: e.CustomerEmail,
+			Price:         moneyToProto(e.Price),
+			Version:       e.Version,
+		}, nil
+	case entities.TicketRefunded:
+		return &eventpb.TicketRefunded{
+			Header:   headerToProto(e.Header),
+			TicketId: e.TicketID,
+		}, nil
+	case entities.TicketPrinted:
+		return &eventpb.TicketPrinted{
+			Header:   headerToProto(e.Header),
+			TicketId: e.TicketID,
+			FileName: e.FileName,
+		}, nil
+	case entities.BookingMade:
+		return &eventpb.BookingMade{
+			Header:          headerToProto(e.Header),
+			NumberOfTickets: int64(e.NumberOfTickets),
+			BookingId:       e.BookingID.String(),
+			CustomerEmail:   e.CustomerEmail,
+			ShowId:          e.ShowId.String(),
+		}, nil
+	case entities.CustomerDataErased:
+		return &eventpb.CustomerDataErased{
+			Header:     headerToProto(e.Header),
+			TicketIds:  e.TicketIDs,
+			BookingIds: e.BookingIDs,
+			Pseudonym:  e.Pseudonym,
+		}, nil
+	case *entities.TicketBookingConfirmed, *entities.TicketBookingCanceled, *entities.TicketRefunded,
+		*entities.TicketPrinted, *entities.BookingMade, *entities.CustomerDataErased:
+		return toProto(derefEvent(v))
+	default:
+		return nil, fmt.Errorf("no protobuf definition for %T", v)
+	}
+}
+
+func newProtoFor(v interface{}) (proto.Message, error) {
+	switch v.(type) {
+	case *entities.TicketBookingConfirmed:
+		return &eventpb.TicketBookingConfirmed{}, nil
+	case *entities.TicketBookingCanceled:
+		return &eventpb.TicketBookingCanceled{}, nil
+	case *entities.TicketRefunded:
+		return &eventpb.TicketRefunded{}, nil
+	case *entities.TicketPrinted:
+		return &eventpb.TicketPrinted{}, nil
+	case *entities.BookingMade:
+		return &eventpb.BookingMade{}, nil
+	case *entities.CustomerDataErased:
+		return &eventpb.CustomerDataErased{}, nil
+	default:
+		return nil, fmt.Errorf("no protobuf definition for %T", v)
+	}
+}
+
+func fromProto(pb proto.Message, v interface{}) error {
+	switch e := v.(type) {
+	case *entities.TicketBookingConfirmed:
+		p := pb.(*eventpb.TicketBookingConfirmed)
+		*e = entities.TicketBookingConfirmed{
+			Header:        headerFromProto(p.Header),
+			TicketID:      p.TicketId,
+			CustomerEmail: p.CustomerEmail,
+			Price:         moneyFromProto(p.Price),
+			BookingID:     p.BookingId,
+			Version:       p.Version,
+		}
+	case *entities.TicketBookingCanceled:
+		p := pb.(*eventpb.TicketBookingCanceled)
+		*e = entities.TicketBookingCanceled{
+			Header:        headerFromProto(p.Header),
+			TicketID:      p.TicketId,
+			CustomerEmail: p.CustomerEmail,
+			Price:         moneyFromProto(p.Price),
+			Version:       p.Version,
+		}
+	case *entities.TicketRefunded:
+		p := pb.(*eventpb.TicketRefunded)
+		*e = entities.TicketRefunded{
+			Header:   headerFromProto(p.Header),
+			TicketID: p.TicketId,
+		}
+	case *entities.TicketPrinted:
+		p := pb.(*eventpb.TicketPrinted)
+		*e = entities.TicketPrinted{
+			Header:   headerFromProto(p.Header),
+			TicketID: p.TicketId,
+			FileName: p.FileName,
+		}
+	case *entities.BookingMade:
+		p := pb.(*eventpb.BookingMade)
+
+		bookingID, err := parseOptionalUUID(p.BookingId)
+		if err != nil {
+			return fmt.Errorf("invalid booking_id: %w", err)
+		}
+		showID, err := parseOptionalUUID(p.ShowId)
+		if err != nil {
+			return fmt.Errorf("invalid show_id: %w", err)
+		}
+
+		*e = entities.BookingMade{
+			Header:          headerFromProto(p.Header),
+			NumberOfTickets: int(p.NumberOfTickets),
+			BookingID:       bookingID,
+			CustomerEmail:   p.CustomerEmail,
+			ShowId:          showID,
+		}
+	case *entities.CustomerDataErased:
+		p := pb.(*eventpb.CustomerDataErased)
+		*e = entities.CustomerDataErased{
+			Header:     headerFromProto(p.Header),
+			TicketIDs:  p.TicketIds,
+			BookingIDs: p.BookingIds,
+			Pseudonym:  p.Pseudonym,
+		}
+	default:
+		return fmt.Errorf("no protobuf definition for %T", v)
+	}
+
+	return nil
+}
+
+func derefEvent(v interface{}) interface{} {
+	switch e := v.(type) {
+	case *entities.TicketBookingConfirmed:
+		return *e
+	case *entities.TicketBookingCanceled:
+		return *e
+	case *entities.TicketRefunded:
+		return *e
+	case *entities.TicketPrinted:
+		return *e
+	case *entities.BookingMade:
+		return *e
+	case *entities.CustomerDataErased:
+		return *e
+	default:
+		return v
+	}
+}
+
+func headerToProto(h entities.EventHeader) *eventpb.EventHeader {
+	return &eventpb.EventHeader{
+		Id:             h.ID,
+		PublishedAt:    timestamppb.New(h.PublishedAt),
+		IdempotencyKey: h.IdempotencyKey,
+		TenantId:       h.TenantID,
+	}
+}
+
+func headerFromProto(h *eventpb.EventHeader) entities.EventHeader {
+	if h == nil {
+		return entities.EventHeader{}
+	}
+
+	header := entities.EventHeader{
+		ID:             h.Id,
+		IdempotencyKey: h.IdempotencyKey,
+		TenantID:       h.TenantId,
+	}
+	if h.PublishedAt != nil {
+		header.PublishedAt = h.PublishedAt.AsTime()
+	}
+
+	return header
+}
+
+func moneyToProto(m entities.Money) *eventpb.Money {
+	return &eventpb.Money{
+		Amount:   m.Amount,
+		Currency: m.Currency,
+	}
+}
+
+func moneyFromProto(m *eventpb.Money) entities.Money {
+	return entities.Money{
+		Amount:   m.GetAmount(),
+		Currency: m.GetCurrency(),
+	}
+}
+
+func parseOptionalUUID(s string) (uuid.UUID, error) {
+	if s == "" {
+		return uuid.Nil, nil
+	}
+
+	return uuid.Parse(s)
+}
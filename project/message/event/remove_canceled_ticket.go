@@ -2,13 +2,28 @@ package event
 
 import (
 	"context"
+	"errors"
 	"tickets/entities"
 
 	"github.com/ThreeDotsLabs/go-event-driven/common/log"
 )
 
 func (h Handler) RemoveCanceledTicket(ctx context.Context, event *entities.TicketBookingCanceled) error {
-	log.FromContext(ctx).Info("Cancel tickets - deleting tickets from DB")
+	log.FromContext(ctx).Info("Cancel tickets - marking tickets as canceled in DB")
 
-	return h.ticketsRepository.Remove(ctx, event.TicketID)
+	ticket := entities.Ticket{
+		TicketID:      event.TicketID,
+		Price:         event.Price,
+		CustomerEmail: event.CustomerEmail,
+		Version:       ticketVersion(event.Version, event.Header),
+	}
+
+	err := h.ticketsRepository.Remove(ctx, ticket)
+	if errors.Is(err, entities.ErrStaleWrite) {
+		// the ticket was confirmed again after this cancellation
+		log.FromContext(ctx).WithError(err).Info("Skipping stale ticket cancellation")
+		return nil
+	}
+
+	return err
 }
@@ -0,0 +1,50 @@
+package event
+
+import (
+	"context"
+	"tickets/entities"
+
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+)
+
+// The sales are reported on the time the events were published, not when they were handled.
+
+func (h Handler) ReportTicketSold(ctx context.Context, event *entities.TicketBookingConfirmed) error {
+	log.FromContext(ctx).Info("Reporting ticket sale")
+
+	return h.reportsRepository.SaveTicketSale(ctx, entities.TicketSale{
+		TicketID:    event.TicketID,
+		BookingID:   event.BookingID,
+		Price:       event.Price,
+		ConfirmedAt: &event.Header.PublishedAt,
+		Status:      entities.TicketStatusConfirmed,
+		StatusAt:    &event.Header.PublishedAt,
+	})
+}
+
+func (h Handler) ReportTicketCanceled(ctx context.Context, event *entities.TicketBookingCanceled) error {
+	log.FromContext(ctx).Info("Reporting ticket cancellation")
+
+	return h.reportsRepository.SaveTicketSale(ctx, entities.TicketSale{
+		TicketID:   event.TicketID,
+		Price:      event.Price,
+		CanceledAt: &event.Header.PublishedAt,
+		Status:     entities.TicketStatusCanceled,
+		StatusAt:   &event.Header.PublishedAt,
+	})
+}
+
+func (h Handler) ReportTicketRefunded(ctx context.Context, event *entities.TicketRefunded) error {
+	log.FromContext(ctx).Info("Reporting ticket refund")
+
+	return h.reportsRepository.SaveTicketSale(ctx, entities.TicketSale{
+		TicketID:   event.TicketID,
+		RefundedAt: &event.Header.PublishedAt,
+	})
+}
+
+func (h Handler) ReportBookedShow(ctx context.Context, event *entities.BookingMade) error {
+	log.FromContext(ctx).Info("Reporting booked show")
+
+	return h.reportsRepository.AddBookedShow(ctx, event.BookingID.String(), event.ShowId)
+}
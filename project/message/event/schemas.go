@@ -0,0 +1,24 @@
+package event
+
+// schemas has the upcasters of the events, register one when old payloads can't be unmarshaled into the event anymore.
+var schemas = newSchemas()
+
+func newSchemas() *SchemaRegistry {
+	registry := NewSchemaRegistry()
+
+	// v2: booking_id was added
+	registry.Register("TicketBookingConfirmed", 1, func(payload map[string]any) (map[string]any, error) {
+		if _, ok := payload["booking_id"]; !ok {
+			payload["booking_id"] = ""
+		}
+
+		return payload, nil
+	})
+
+	return registry
+}
+
+// SchemaVersion returns the current schema version of the event, sent in the SchemaVersionMetadataKey metadata.
+func SchemaVersion(eventName string) int {
+	return schemas.CurrentVersion(eventName)
+}
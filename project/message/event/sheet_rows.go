@@ -0,0 +1,22 @@
+package event
+
+import (
+	"context"
+	"tickets/entities"
+
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+)
+
+// recordSheetRow records the row to be appended by the sheet sync, skipping the rows already recorded.
+func (h Handler) recordSheetRow(ctx context.Context, sheetRow entities.SheetRow) error {
+	added, err := h.sheetRowsRepository.Add(ctx, sheetRow)
+	if err != nil {
+		return err
+	}
+
+	if !added {
+		log.FromContext(ctx).Infof("Row for ticket %s already recorded for %s, skipping", sheetRow.TicketID, sheetRow.SheetName)
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package event
 
 import (
 	"context"
+	"errors"
 	"tickets/entities"
 
 	"github.com/ThreeDotsLabs/go-event-driven/common/log"
@@ -11,10 +12,28 @@ func (h Handler) StoreTickets(ctx context.Context, event *entities.TicketBooking
 	log.FromContext(ctx).Info("Saving tickets to DB")
 
 	ticket := entities.Ticket{
-		TicketID: event.TicketID,
-		Price: event.Price,
+		TicketID:      event.TicketID,
+		Price:         event.Price,
 		CustomerEmail: event.CustomerEmail,
+		BookingID:     event.BookingID,
+		Version:       ticketVersion(event.Version, event.Header),
 	}
 
-	return h.ticketsRepository.Add(ctx, ticket)
+	err := h.ticketsRepository.Add(ctx, ticket)
+	if errors.Is(err, entities.ErrStaleWrite) {
+		// the ticket was canceled after this confirmation, storing it would bring it back
+		log.FromContext(ctx).WithError(err).Info("Skipping stale ticket confirmation")
+		return nil
+	}
+
+	return err
+}
+
+// ticketVersion returns the version of the event, or its publish time if it was published without one.
+func ticketVersion(version int64, header entities.EventHeader) int64 {
+	if version != 0 {
+		return version
+	}
+
+	return header.PublishedAt.UnixNano()
 }
@@ -10,9 +10,10 @@ import (
 func (h Handler) TicketRefundToSheet(ctx context.Context, event *entities.TicketBookingCanceled) error {
 	log.FromContext(ctx).Info("Adding ticket refund to sheet")
 
-	return h.spreadsheetsService.AppendRow(
-		ctx,
-		"tickets-to-refund",
-		[]string{event.TicketID, event.CustomerEmail, event.Price.Amount, event.Price.Currency},
-	)
+	return h.recordSheetRow(ctx, entities.SheetRow{
+		SheetName:      entities.SheetTicketsToRefund,
+		TicketID:       event.TicketID,
+		IdempotencyKey: event.Header.IdempotencyKey,
+		Columns:        []string{event.TicketID, event.CustomerEmail, event.Price.Amount, event.Price.Currency},
+	})
 }
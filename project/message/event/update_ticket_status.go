@@ -0,0 +1,33 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"tickets/entities"
+
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+)
+
+func (h Handler) MarkTicketPrinted(ctx context.Context, event *entities.TicketPrinted) error {
+	log.FromContext(ctx).Info("Marking ticket as printed")
+
+	return h.updateTicketStatus(ctx, event.TicketID, entities.TicketStatusPrinted)
+}
+
+func (h Handler) MarkTicketRefunded(ctx context.Context, event *entities.TicketRefunded) error {
+	log.FromContext(ctx).Info("Marking ticket as refunded")
+
+	return h.updateTicketStatus(ctx, event.TicketID, entities.TicketStatusRefunded)
+}
+
+func (h Handler) updateTicketStatus(ctx context.Context, ticketID string, status entities.TicketStatus) error {
+	err := h.ticketsRepository.UpdateStatus(ctx, ticketID, status)
+	if errors.Is(err, entities.ErrInvalidStatusTransition) {
+		// for example, the ticket was canceled before it was printed
+		log.FromContext(ctx).WithError(err).Info("Skipping ticket status change")
+		return nil
+	}
+
+	// a ticket that's not stored yet is retried, the confirmation may be handled after the event
+	return err
+}
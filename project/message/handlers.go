@@ -0,0 +1,435 @@
+package message
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"tickets/entities"
+	"tickets/message/broker"
+	"tickets/message/event"
+	"tickets/webhook"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/components/cqrs"
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+var ErrUnknownHandler = errors.New("unknown handler")
+
+// PendingMessagesReader reads the messages delivered to the consumer groups, but not acknowledged yet.
+type PendingMessagesReader interface {
+	PendingMessages(ctx context.Context, topic, consumerGroup string) (broker.PendingMessages, error)
+}
+
+// HandlersRepository keeps the state of the handlers shared by the instances of the service.
+type HandlersRepository interface {
+	SetPaused(ctx context.Context, name string, paused bool) error
+	RecordError(ctx context.Context, name string, message string, occurredAt time.Time) error
+	GetStates(ctx context.Context) ([]entities.MessageHandlerState, error)
+}
+
+type HandlerStatus struct {
+	Name          string
+	Topic         string
+	ConsumerGroup string
+	Paused        bool
+	// PendingMessages is nil when the broker doesn't report them.
+	PendingMessages *broker.PendingMessages
+	// LastError is nil when the handler never failed.
+	LastError *HandlerError
+}
+
+type HandlerError struct {
+	Message    string
+	OccurredAt time.Time
+}
+
+// Handlers keeps which handlers are paused and their last errors in the repository, shared by all instances.
+type Handlers struct {
+	repository            HandlersRepository
+	pendingMessagesReader PendingMessagesReader
+	syncInterval          time.Duration
+	logger                watermill.LoggerAdapter
+
+	lock   sync.Mutex
+	paused map[string]bool
+	// name of the handler -> channel closed when it's paused or resumed
+	changed map[string]chan struct{}
+	// synced is closed once the paused handlers are read from the repository
+	synced     chan struct{}
+	syncedOnce sync.Once
+}
+
+// NewHandlers returns the state of the handlers, pendingMessagesReader can be nil.
+func NewHandlers(repository HandlersRepository, pendingMessagesReader PendingMessagesReader, logger watermill.LoggerAdapter) *Handlers {
+	if repository == nil {
+		panic("missing repository")
+	}
+	if logger == nil {
+		logger = watermill.NopLogger{}
+	}
+
+	return &Handlers{
+		repository:            repository,
+		pendingMessagesReader: pendingMessagesReader,
+		syncInterval:          defaultHandlersSyncInterval,
+		logger:                logger,
+		paused:                make(map[string]bool),
+		changed:               make(map[string]chan struct{}),
+		synced:                make(chan struct{}),
+	}
+}
+
+const defaultHandlersSyncInterval = time.Second * 5
+
+// resubscribeDelay is the wait before subscribing again after a failed subscription.
+const resubscribeDelay = time.Second
+
+func (h *Handlers) List(ctx context.Context) ([]HandlerStatus, error) {
+	states, err := h.repository.GetStates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statesByName := make(map[string]entities.MessageHandlerState, len(states))
+	for _, state := range states {
+		statesByName[state.Name] = state
+	}
+
+	var statuses []HandlerStatus
+	for _, handler := range registeredHandlers() {
+		state := statesByName[handler.Name]
+
+		status := HandlerStatus{
+			Name:          handler.Name,
+			Topic:         handler.Topic,
+			ConsumerGroup: handler.ConsumerGroup,
+			Paused:        state.Paused,
+		}
+
+		if state.LastErrorAt != nil {
+			status.LastError = &HandlerError{
+				Message:    state.LastError,
+				OccurredAt: *state.LastErrorAt,
+			}
+		}
+
+		if h.pendingMessagesReader != nil {
+			pending, err := h.pendingMessagesReader.PendingMessages(ctx, handler.Topic, handler.ConsumerGroup)
+			if err != nil {
+				return nil, err
+			}
+			status.PendingMessages = &pending
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// Pause stops the handler from consuming new messages in all instances until it's resumed.
+func (h *Handlers) Pause(ctx context.Context, name string) error {
+	return h.setPaused(ctx, name, true)
+}
+
+func (h *Handlers) Resume(ctx context.Context, name string) error {
+	return h.setPaused(ctx, name, false)
+}
+
+func (h *Handlers) setPaused(ctx context.Context, name string, paused bool) error {
+	if !isRegisteredHandler(name) {
+		return fmt.Errorf("%w: %s", ErrUnknownHandler, name)
+	}
+
+	if err := h.repository.SetPaused(ctx, name, paused); err != nil {
+		return err
+	}
+
+	// this instance doesn't wait for the next sync
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.updatePaused(name, paused)
+
+	return nil
+}
+
+// Run reads the handlers paused in the other instances every few seconds until ctx is done.
+func (h *Handlers) Run(ctx context.Context) error {
+	for {
+		if err := h.sync(ctx); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			h.logger.Error("Could not sync paused handlers", err, nil)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(h.syncInterval):
+		}
+	}
+}
+
+func (h *Handlers) sync(ctx context.Context) error {
+	states, err := h.repository.GetStates(ctx)
+	if err != nil {
+		return err
+	}
+
+	paused := make(map[string]bool)
+	for _, state := range states {
+		paused[state.Name] = state.Paused
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	for _, handler := range registeredHandlers() {
+		h.updatePaused(handler.Name, paused[handler.Name])
+	}
+
+	h.syncedOnce.Do(func() {
+		close(h.synced)
+	})
+
+	return nil
+}
+
+// updatePaused must be called with the lock held.
+func (h *Handlers) updatePaused(name string, paused bool) {
+	if h.paused[name] == paused {
+		return
+	}
+
+	h.paused[name] = paused
+	if changed, ok := h.changed[name]; ok {
+		close(changed)
+		delete(h.changed, name)
+	}
+}
+
+// state returns whether the handler is paused, and a channel closed when it's paused or resumed.
+func (h *Handlers) state(name string) (bool, <-chan struct{}) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	changed, ok := h.changed[name]
+	if !ok {
+		changed = make(chan struct{})
+		h.changed[name] = changed
+	}
+
+	return h.paused[name], changed
+}
+
+// Subscriber returns the subscriber of the handler, it consumes messages only while the handler is not paused.
+func (h *Handlers) Subscriber(handlerName string, subscriber message.Subscriber) message.Subscriber {
+	return pausableSubscriber{
+		Subscriber:  subscriber,
+		handlers:    h,
+		handlerName: handlerName,
+	}
+}
+
+type pausableSubscriber struct {
+	message.Subscriber
+	handlers    *Handlers
+	handlerName string
+}
+
+type subscription struct {
+	messages <-chan *message.Message
+	cancel   context.CancelFunc
+}
+
+var errSubscriptionClosed = errors.New("subscription closed")
+
+func (s pausableSubscriber) Subscribe(ctx context.Context, topic string) (<-chan *message.Message, error) {
+	select {
+	case <-s.handlers.synced:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	// unless the handler is paused, the first subscription is made right away, so the router fails if it's not possible
+	var sub *subscription
+	paused, changed := s.handlers.state(s.handlerName)
+	if !paused {
+		var err error
+		sub, err = s.subscribe(ctx, topic)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	output := make(chan *message.Message)
+
+	go func() {
+		defer close(output)
+		s.consume(ctx, topic, output, sub, changed)
+	}()
+
+	return output, nil
+}
+
+func (s pausableSubscriber) subscribe(ctx context.Context, topic string) (*subscription, error) {
+	subscriptionCtx, cancel := context.WithCancel(ctx)
+
+	messages, err := s.Subscriber.Subscribe(subscriptionCtx, topic)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	return &subscription{messages: messages, cancel: cancel}, nil
+}
+
+// close ends the subscription, the messages that are not forwarded yet are left for the consumer group.
+func (s *subscription) close() {
+	s.cancel()
+	for msg := range s.messages {
+		msg.Nack()
+	}
+}
+
+// consume forwards the messages to output while the handler is not paused, until ctx is done or the subscriber is closed.
+func (s pausableSubscriber) consume(ctx context.Context, topic string, output chan<- *message.Message, sub *subscription, changed <-chan struct{}) {
+	logFields := watermill.LogFields{"handler_name": s.handlerName, "topic": topic}
+
+	for {
+		if sub != nil {
+			err := forward(ctx, sub.messages, output, changed)
+			sub.close()
+			sub = nil
+
+			if errors.Is(err, errSubscriptionClosed) || ctx.Err() != nil {
+				return
+			}
+		}
+
+		var paused bool
+		paused, changed = s.handlers.state(s.handlerName)
+
+		if paused {
+			s.handlers.logger.Info("Handler paused", logFields)
+
+			select {
+			case <-changed:
+				continue
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		var err error
+		sub, err = s.subscribe(ctx, topic)
+		if err != nil {
+			s.handlers.logger.Error("Could not subscribe", err, logFields)
+
+			select {
+			case <-time.After(resubscribeDelay):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// forward forwards the messages to output, until the handler is paused (or resumed) or ctx is done.
+func forward(ctx context.Context, messages <-chan *message.Message, output chan<- *message.Message, changed <-chan struct{}) error {
+	for {
+		select {
+		case msg, ok := <-messages:
+			if !ok {
+				return errSubscriptionClosed
+			}
+
+			select {
+			case output <- msg:
+			case <-changed:
+				msg.Nack()
+				return nil
+			case <-ctx.Done():
+				msg.Nack()
+				return nil
+			}
+		case <-changed:
+			return nil
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// ErrorsMiddleware records the last error of each handler, it's used outside the retries.
+func (h *Handlers) ErrorsMiddleware(next message.HandlerFunc) message.HandlerFunc {
+	return func(msg *message.Message) ([]*message.Message, error) {
+		msgs, err := next(msg)
+		if err != nil {
+			h.recordError(msg.Context(), message.HandlerNameFromCtx(msg.Context()), err)
+		}
+
+		return msgs, err
+	}
+}
+
+// recordError saves the error of the handler, failing to save it doesn't fail the handler.
+func (h *Handlers) recordError(ctx context.Context, name string, handlerErr error) {
+	err := h.repository.RecordError(context.WithoutCancel(ctx), name, handlerErr.Error(), time.Now().UTC())
+	if err != nil {
+		h.logger.Error("Could not record handler error", err, watermill.LogFields{
+			"handler_name":  name,
+			"handler_error": handlerErr.Error(),
+		})
+	}
+}
+
+type registeredHandler struct {
+	Name          string
+	EventName     string
+	Topic         string
+	ConsumerGroup string
+}
+
+// registeredHandlers returns the handlers NewWatermillRouter registers, without the outbox forwarder.
+func registeredHandlers() []registeredHandler {
+	var handlers []registeredHandler
+
+	// the handlers are created only to read their names and events, they never handle anything
+	for _, handler := range eventHandlers(event.Handler{}) {
+		eventName := cqrs.StructName(handler.NewEvent())
+
+		handlers = append(handlers, registeredHandler{
+			Name:          handler.HandlerName(),
+			EventName:     eventName,
+			Topic:         event.Topic(eventName),
+			ConsumerGroup: event.ConsumerGroup(handler.HandlerName()),
+		})
+	}
+
+	for _, eventType := range webhook.EventTypes {
+		handlers = append(handlers, registeredHandler{
+			Name:          webhookHandlerName(eventType),
+			EventName:     eventType,
+			Topic:         eventType,
+			ConsumerGroup: event.ConsumerGroup(webhookHandlerName(eventType)),
+		})
+	}
+
+	return handlers
+}
+
+func isRegisteredHandler(name string) bool {
+	for _, handler := range registeredHandlers() {
+		if handler.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
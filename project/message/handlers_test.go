@@ -0,0 +1,167 @@
+package message
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"tickets/db/memory"
+	"tickets/message/broker"
+	"tickets/message/retry"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlers_paused_handler_doesnt_consume(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	handlers := NewHandlers(memory.NewMessageHandlersRepository(memory.NewDatabase()), nil, nil)
+	require.NoError(t, handlers.sync(ctx))
+
+	goChannel := broker.NewGoChannel(watermill.NopLogger{})
+	defer goChannel.Close()
+
+	subscriber, err := goChannel.NewSubscriber("svc-tickets.IssueReceipt")
+	require.NoError(t, err)
+
+	messages, err := handlers.Subscriber("IssueReceipt", subscriber).Subscribe(ctx, "TicketBookingConfirmed")
+	require.NoError(t, err)
+
+	publish := func() string {
+		msg := message.NewMessage(watermill.NewUUID(), []byte("{}"))
+		require.NoError(t, goChannel.Publisher().Publish("TicketBookingConfirmed", msg))
+		return msg.UUID
+	}
+
+	receive := func() string {
+		select {
+		case msg := <-messages:
+			msg.Ack()
+			return msg.UUID
+		case <-time.After(time.Second):
+			t.Fatal("message not received")
+			return ""
+		}
+	}
+
+	require.Equal(t, publish(), receive())
+
+	require.NoError(t, handlers.Pause(ctx, "IssueReceipt"))
+	pausedMsgID := publish()
+
+	select {
+	case <-messages:
+		t.Fatal("paused handler received a message")
+	case <-time.After(time.Millisecond * 100):
+	}
+
+	require.NoError(t, handlers.Resume(ctx, "IssueReceipt"))
+	assert.Equal(t, pausedMsgID, receive())
+
+	assert.ErrorIs(t, handlers.Pause(ctx, "Unknown"), ErrUnknownHandler)
+	assert.ErrorIs(t, handlers.Resume(ctx, "Unknown"), ErrUnknownHandler)
+}
+
+func TestHandlers_state_is_shared_by_instances(t *testing.T) {
+	ctx := context.Background()
+	repository := memory.NewMessageHandlersRepository(memory.NewDatabase())
+
+	instance1 := NewHandlers(repository, nil, nil)
+	instance2 := NewHandlers(repository, nil, nil)
+	require.NoError(t, instance2.sync(ctx))
+
+	require.NoError(t, instance1.Pause(ctx, "IssueReceipt"))
+	instance1.recordError(ctx, "IssueReceipt", errors.New("receipts service unavailable"))
+
+	paused, _ := instance2.state("IssueReceipt")
+	assert.False(t, paused, "paused before the sync")
+
+	require.NoError(t, instance2.sync(ctx))
+	paused, _ = instance2.state("IssueReceipt")
+	assert.True(t, paused)
+
+	statuses, err := instance2.List(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, statuses)
+
+	for _, status := range statuses {
+		assert.Nil(t, status.PendingMessages)
+
+		if status.Name != "IssueReceipt" {
+			assert.False(t, status.Paused, "%s is paused", status.Name)
+			assert.Nil(t, status.LastError, "unexpected error of %s", status.Name)
+			continue
+		}
+
+		assert.Equal(t, "TicketBookingConfirmed", status.Topic)
+		assert.Equal(t, "svc-tickets.IssueReceipt", status.ConsumerGroup)
+		assert.True(t, status.Paused)
+		require.NotNil(t, status.LastError)
+		assert.Equal(t, "receipts service unavailable", status.LastError.Message)
+		assert.WithinDuration(t, time.Now(), status.LastError.OccurredAt, time.Second)
+	}
+}
+
+func TestHandlers_error_is_recorded_when_message_is_given_up(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	repository := &countingHandlersRepository{HandlersRepository: memory.NewMessageHandlersRepository(memory.NewDatabase())}
+	handlers := NewHandlers(repository, nil, nil)
+
+	goChannel := broker.NewGoChannel(watermill.NopLogger{})
+	defer goChannel.Close()
+
+	router, err := message.NewRouter(message.RouterConfig{}, watermill.NopLogger{})
+	require.NoError(t, err)
+	useMiddlewares(router, goChannel.Publisher(), newLocalKeyLocks(), nil, handlers, watermill.NopLogger{})
+
+	subscriber, err := goChannel.NewSubscriber("svc-tickets.IssueReceipt")
+	require.NoError(t, err)
+
+	var attempts atomic.Int32
+	router.AddNoPublisherHandler("IssueReceipt", "TicketBookingConfirmed", subscriber, func(msg *message.Message) error {
+		if attempts.Add(1) < 3 {
+			return errors.New("receipts service unavailable")
+		}
+		return retry.Permanent(errors.New("invalid receipt"))
+	})
+
+	go func() {
+		_ = router.Run(ctx)
+	}()
+	<-router.Running()
+
+	require.NoError(t, goChannel.Publisher().Publish("TicketBookingConfirmed", message.NewMessage(watermill.NewUUID(), []byte("{}"))))
+
+	require.Eventually(t, func() bool {
+		return repository.recordedErrors.Load() > 0
+	}, time.Second*5, time.Millisecond*10)
+
+	assert.EqualValues(t, 3, attempts.Load())
+	assert.EqualValues(t, 1, repository.recordedErrors.Load(), "the retried errors should not be recorded")
+
+	statuses, err := handlers.List(ctx)
+	require.NoError(t, err)
+	for _, status := range statuses {
+		if status.Name == "IssueReceipt" {
+			require.NotNil(t, status.LastError)
+			assert.Equal(t, "invalid receipt", status.LastError.Message)
+		}
+	}
+}
+
+type countingHandlersRepository struct {
+	HandlersRepository
+	recordedErrors atomic.Int32
+}
+
+func (r *countingHandlersRepository) RecordError(ctx context.Context, name string, message string, occurredAt time.Time) error {
+	r.recordedErrors.Add(1)
+	return r.HandlersRepository.RecordError(ctx, name, message, occurredAt)
+}
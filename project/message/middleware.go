@@ -1,6 +1,11 @@
 package message
 
 import (
+	"tickets/chaos"
+	"tickets/message/event"
+	"tickets/message/outbox"
+	"tickets/message/retry"
+	"tickets/tenant"
 	"time"
 
 	"github.com/ThreeDotsLabs/go-event-driven/common/log"
@@ -11,16 +16,61 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-func useMiddlewares(router *message.Router, watermillLogger watermill.LoggerAdapter) {
+const DeadLetterTopic = "dead_letters"
+
+var retryPolicies = retry.Policies{
+	Default: retry.DefaultPolicy,
+	Handlers: map[string]retry.Policy{
+		// the forwarder only publishes to the broker, it should keep trying while the broker is unavailable
+		outbox.ForwarderHandlerName: {
+			MaxAttempts:     20,
+			InitialInterval: time.Millisecond * 100,
+			MaxInterval:     time.Second * 5,
+			Multiplier:      2,
+			Jitter:          0.2,
+		},
+		// bookings are sent in bursts when tickets go on sale, spread the retries so we don't add to the load
+		"BookPlaceInDeadNation": {
+			MaxAttempts:     8,
+			InitialInterval: time.Millisecond * 500,
+			MaxInterval:     time.Second * 10,
+			Multiplier:      2,
+			Jitter:          0.3,
+		},
+		"IssueReceipt": {
+			MaxAttempts:     11,
+			InitialInterval: time.Millisecond * 100,
+			MaxInterval:     time.Second * 2,
+			Multiplier:      2,
+			Jitter:          0.2,
+		},
+	},
+}
+
+func useMiddlewares(router *message.Router, deadLetterPublisher message.Publisher, keyLocker KeyLocker, chaosInjector *chaos.Chaos, handlers *Handlers, watermillLogger watermill.LoggerAdapter) {
 	router.AddMiddleware(middleware.Recoverer)
 
-	router.AddMiddleware(middleware.Retry{
-		MaxRetries:      10,
-		InitialInterval: time.Millisecond * 100,
-		MaxInterval:     time.Second,
-		Multiplier:      2,
-		Logger:          watermillLogger,
-	}.Middleware)
+	// permanent errors won't go away, so the message is moved to the dead letter topic instead of being redelivered
+	deadLetter, err := middleware.PoisonQueueWithFilter(deadLetterPublisher, DeadLetterTopic, retry.IsPermanent)
+	if err != nil {
+		panic(err)
+	}
+	router.AddMiddleware(deadLetter)
+
+	// before the retries, so the key is held until the message is handled or given up
+	router.AddMiddleware(newOrderedDispatch(keyLocker).Middleware)
+
+	// outside the retries, so the error is recorded once the message is given up, not on every attempt
+	router.AddMiddleware(handlers.ErrorsMiddleware)
+
+	policies := retryPolicies
+	policies.Logger = watermillLogger
+	router.AddMiddleware(policies.Middleware)
+
+	if chaosInjector != nil {
+		// after the retry middleware, so injected failures are retried like the real ones
+		router.AddMiddleware(chaosInjector.Middleware)
+	}
 
 	router.AddMiddleware(func(h message.HandlerFunc) message.HandlerFunc {
 		return func(msg *message.Message) (events []*message.Message, err error) {
@@ -34,6 +84,9 @@ func useMiddlewares(router *message.Router, watermillLogger watermill.LoggerAdap
 			ctx = log.ToContext(ctx, logrus.WithFields(logrus.Fields{"correlation_id": reqCorrelationID}))
 			ctx = log.ContextWithCorrelationID(ctx, reqCorrelationID)
 
+			// the messages published before the tenants were introduced belong to the default tenant
+			ctx = tenant.WithID(ctx, msg.Metadata.Get(event.TenantIDMetadataKey))
+
 			msg.SetContext(ctx)
 
 			return h(msg)
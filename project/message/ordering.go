@@ -0,0 +1,96 @@
+package message
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"tickets/message/outbox"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+// KeyLocker locks the ordering keys of the messages, see db.OrderingLocks.
+type KeyLocker interface {
+	// Lock waits until the key is locked and returns the function unlocking it.
+	Lock(ctx context.Context, key string) (unlock func(), err error)
+}
+
+// keyLockTimeout is how long a message waits for its key before it's redelivered, the holder may be waiting for it.
+const keyLockTimeout = time.Second * 10
+
+// orderedDispatch handles the messages with the same ordering key one at a time, in all handlers.
+type orderedDispatch struct {
+	locker KeyLocker
+}
+
+func newOrderedDispatch(locker KeyLocker) orderedDispatch {
+	return orderedDispatch{locker: locker}
+}
+
+func (o orderedDispatch) Middleware(h message.HandlerFunc) message.HandlerFunc {
+	return func(msg *message.Message) ([]*message.Message, error) {
+		key := msg.Metadata.Get(outbox.OrderingKeyMetadataKey)
+		if key == "" {
+			return h(msg)
+		}
+
+		ctx, cancel := context.WithTimeout(msg.Context(), keyLockTimeout)
+		unlock, err := o.locker.Lock(ctx, key)
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("could not lock ordering key %s: %w", key, err)
+		}
+		defer unlock()
+
+		return h(msg)
+	}
+}
+
+// localKeyLocks locks the keys within the process, for the service running without Postgres.
+type localKeyLocks struct {
+	lock sync.Mutex
+	keys map[string]*keyLock
+}
+
+type keyLock struct {
+	locked chan struct{}
+	// users is the number of messages handled or waiting for the key, the lock is removed when it drops to zero
+	users int
+}
+
+func newLocalKeyLocks() *localKeyLocks {
+	return &localKeyLocks{keys: make(map[string]*keyLock)}
+}
+
+func (o *localKeyLocks) Lock(ctx context.Context, key string) (func(), error) {
+	o.lock.Lock()
+	l, ok := o.keys[key]
+	if !ok {
+		l = &keyLock{locked: make(chan struct{}, 1)}
+		o.keys[key] = l
+	}
+	l.users++
+	o.lock.Unlock()
+
+	select {
+	case l.locked <- struct{}{}:
+		return func() {
+			<-l.locked
+			o.release(key, l)
+		}, nil
+	case <-ctx.Done():
+		o.release(key, l)
+		return nil, ctx.Err()
+	}
+}
+
+func (o *localKeyLocks) release(key string, l *keyLock) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	l.users--
+	if l.users == 0 {
+		delete(o.keys, key)
+	}
+}
@@ -0,0 +1,85 @@
+package message
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"tickets/message/outbox"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderedDispatch(t *testing.T) {
+	locks := newLocalKeyLocks()
+	dispatch := newOrderedDispatch(locks)
+
+	var running, maxRunning atomic.Int32
+	handler := dispatch.Middleware(func(msg *message.Message) ([]*message.Message, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+
+		for {
+			current := maxRunning.Load()
+			if n <= current || maxRunning.CompareAndSwap(current, n) {
+				break
+			}
+		}
+
+		time.Sleep(time.Millisecond * 20)
+		return nil, nil
+	})
+
+	handle := func(keys ...string) int32 {
+		running.Store(0)
+		maxRunning.Store(0)
+
+		wg := sync.WaitGroup{}
+		for _, key := range keys {
+			msg := message.NewMessage(watermill.NewUUID(), nil)
+			if key != "" {
+				msg.Metadata.Set(outbox.OrderingKeyMetadataKey, key)
+			}
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, _ = handler(msg)
+			}()
+		}
+		wg.Wait()
+
+		return maxRunning.Load()
+	}
+
+	assert.EqualValues(t, 1, handle("ticket-1", "ticket-1", "ticket-1"), "messages with the same key must not be handled concurrently")
+	assert.EqualValues(t, 3, handle("ticket-1", "ticket-2", "ticket-3"))
+	assert.EqualValues(t, 2, handle("", ""), "messages without a key are not ordered")
+
+	assert.Empty(t, locks.keys, "locks of handled keys should be removed")
+}
+
+func TestLocalKeyLocks_waiting_is_canceled(t *testing.T) {
+	locks := newLocalKeyLocks()
+
+	unlock, err := locks.Lock(context.Background(), "ticket-1")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+
+	_, err = locks.Lock(ctx, "ticket-1")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	unlock()
+
+	unlock, err = locks.Lock(context.Background(), "ticket-1")
+	require.NoError(t, err)
+	unlock()
+
+	assert.Empty(t, locks.keys, "locks of handled keys should be removed")
+}
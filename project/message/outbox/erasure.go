@@ -0,0 +1,41 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// RemoveForwarded removes the forwarded messages with the ordering keys from the outbox, its archive and its dead letters.
+func RemoveForwarded(ctx context.Context, tx *sqlx.Tx, orderingKeys []string) (int, error) {
+	if len(orderingKeys) == 0 {
+		return 0, nil
+	}
+
+	orderingKeyCondition := `payload->'metadata'->>'` + OrderingKeyMetadataKey + `' = ANY($1)`
+
+	// the dead letters are never forwarded
+	queries := map[string]string{
+		messagesTable:    `DELETE FROM ` + messagesTable + ` WHERE forwarded_at IS NOT NULL AND ` + orderingKeyCondition,
+		archiveTable:     `DELETE FROM ` + archiveTable + ` WHERE ` + orderingKeyCondition,
+		deadLettersTable: `DELETE FROM ` + deadLettersTable + ` WHERE ` + orderingKeyCondition,
+	}
+
+	removed := 0
+	for table, query := range queries {
+		res, err := tx.ExecContext(ctx, query, pq.StringArray(orderingKeys))
+		if err != nil {
+			return removed, fmt.Errorf("failed to remove forwarded messages from %s: %w", table, err)
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return removed, fmt.Errorf("failed to get removed messages: %w", err)
+		}
+		removed += int(affected)
+	}
+
+	return removed, nil
+}
@@ -9,13 +9,13 @@ import (
 )
 
 func AddForwarderHandler(
-	postgresSubscriber message.Subscriber,
+	outboxSubscriber message.Subscriber,
 	publisher message.Publisher,
 	router *message.Router,
 	logger watermill.LoggerAdapter,
 ) {
 	_, err := forwarder.NewForwarder(
-		postgresSubscriber,
+		outboxSubscriber,
 		publisher,
 		logger,
 		forwarder.Config{
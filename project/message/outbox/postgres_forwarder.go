@@ -0,0 +1,363 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"golang.org/x/sync/errgroup"
+)
+
+// OrderingKeyMetadataKey groups the messages forwarded in the order they were stored, like the events of one aggregate.
+const OrderingKeyMetadataKey = "ordering_key"
+
+const (
+	// shardsCount is part of the schema (see InitializeSchema), changing it requires a migration.
+	shardsCount = 16
+
+	// advisoryLockNamespace is the first key of the advisory locks of the shards.
+	advisoryLockNamespace = 7_404_101
+
+	notifyChannel = "events_to_forward"
+)
+
+// Config configures the jobs of the Postgres outbox.
+type Config struct {
+	Forwarder ForwarderConfig
+	Retention RetentionConfig
+}
+
+type Forwarder interface {
+	// Run forwards messages from the outbox until ctx is done.
+	Run(ctx context.Context) error
+}
+
+type ForwarderConfig struct {
+	// BatchSize is the maximum number of messages claimed and published at once.
+	BatchSize int
+	// PollInterval is how often the outbox is checked when it's empty (or a NOTIFY was lost, with ListenDSN).
+	PollInterval time.Duration
+	// Workers is the number of goroutines forwarding shards in parallel.
+	Workers int
+
+	// ListenDSN enables waking up on NOTIFY, LISTEN needs its own connection, so it's not taken from the pool.
+	ListenDSN string
+}
+
+func (c *ForwarderConfig) setDefaults() {
+	if c.BatchSize == 0 {
+		c.BatchSize = 100
+	}
+	if c.Workers == 0 {
+		c.Workers = 4
+	}
+	if c.PollInterval == 0 {
+		if c.ListenDSN != "" {
+			c.PollInterval = time.Second
+		} else {
+			c.PollInterval = time.Millisecond * 100
+		}
+	}
+}
+
+// PostgresForwarder forwards the shards of the outbox (by the ordering key) in batches, one worker per shard at a time.
+type PostgresForwarder struct {
+	db        *sqlx.DB
+	publisher message.Publisher
+	config    ForwarderConfig
+	logger    watermill.LoggerAdapter
+}
+
+func NewPostgresForwarder(
+	db *sqlx.DB,
+	publisher message.Publisher,
+	config ForwarderConfig,
+	logger watermill.LoggerAdapter,
+) *PostgresForwarder {
+	if db == nil {
+		panic("missing db")
+	}
+	if publisher == nil {
+		panic("missing publisher")
+	}
+	if logger == nil {
+		logger = watermill.NopLogger{}
+	}
+
+	config.setDefaults()
+
+	return &PostgresForwarder{
+		db:        db,
+		publisher: publisher,
+		config:    config,
+		logger:    logger,
+	}
+}
+
+func (f *PostgresForwarder) Run(ctx context.Context) error {
+	wakeUps := make([]chan struct{}, f.config.Workers)
+	for i := range wakeUps {
+		wakeUps[i] = make(chan struct{}, 1)
+	}
+
+	errgrp, ctx := errgroup.WithContext(ctx)
+
+	if f.config.ListenDSN != "" {
+		listener := pq.NewListener(f.config.ListenDSN, time.Millisecond*100, time.Minute, nil)
+		defer listener.Close()
+
+		if err := listener.Listen(notifyChannel); err != nil {
+			return fmt.Errorf("failed to listen for outbox notifications: %w", err)
+		}
+
+		errgrp.Go(func() error {
+			for {
+				select {
+				case <-ctx.Done():
+					return nil
+				// nil notifications are sent after reconnecting, wake up as well, as notifications may have been lost
+				case <-listener.Notify:
+					for _, wakeUp := range wakeUps {
+						select {
+						case wakeUp <- struct{}{}:
+						default:
+						}
+					}
+				}
+			}
+		})
+	}
+
+	for worker := 0; worker < f.config.Workers; worker++ {
+		worker := worker
+		errgrp.Go(func() error {
+			f.work(ctx, worker, wakeUps[worker])
+			return nil
+		})
+	}
+
+	return errgrp.Wait()
+}
+
+func (f *PostgresForwarder) work(ctx context.Context, worker int, wakeUp <-chan struct{}) {
+	for {
+		forwarded := 0
+
+		// workers start from different shards, so they don't compete for the same locks
+		for i := 0; i < shardsCount; i++ {
+			shard := (worker*shardsCount/f.config.Workers + i) % shardsCount
+
+			n, err := f.ForwardShard(ctx, shard)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+
+				f.logger.Error("Failed to forward outbox shard", err, watermill.LogFields{"shard": shard})
+				continue
+			}
+
+			forwarded += n
+		}
+
+		// there may be more messages waiting
+		if forwarded > 0 {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-wakeUp:
+		case <-time.After(f.config.PollInterval):
+		}
+	}
+}
+
+type outboxRow struct {
+	Offset  int64           `db:"offset"`
+	Payload json.RawMessage `db:"payload"`
+}
+
+// envelope is the message envelope of Watermill's forwarder (see NewPublisher).
+type envelope struct {
+	DestinationTopic string            `json:"destination_topic"`
+	UUID             string            `json:"uuid"`
+	Payload          []byte            `json:"payload"`
+	Metadata         map[string]string `json:"metadata"`
+}
+
+// ForwardShard forwards one batch of the shard, it returns 0 when the shard is empty or locked by another worker.
+func (f *PostgresForwarder) ForwardShard(ctx context.Context, shard int) (int, error) {
+	tx, err := f.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var locked bool
+	err = tx.GetContext(ctx, &locked, `SELECT pg_try_advisory_xact_lock($1, $2)`, advisoryLockNamespace, shard)
+	if err != nil {
+		return 0, fmt.Errorf("failed to lock shard %d: %w", shard, err)
+	}
+	if !locked {
+		return 0, nil
+	}
+
+	var rows []outboxRow
+	err = tx.SelectContext(
+		ctx,
+		&rows,
+		`
+		SELECT "offset", payload FROM `+messagesTable+`
+		WHERE
+			shard = $1
+			AND forwarded_at IS NULL
+			AND transaction_id < pg_snapshot_xmin(pg_current_snapshot())
+		ORDER BY transaction_id ASC, "offset" ASC
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
+		`,
+		shard,
+		f.config.BatchSize,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim messages of shard %d: %w", shard, err)
+	}
+
+	if len(rows) == 0 {
+		return 0, nil
+	}
+
+	messages, invalid := parseRows(rows)
+
+	if len(invalid) > 0 {
+		if err := f.deadLetter(ctx, tx, invalid); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := f.publish(messages); err != nil {
+		return 0, err
+	}
+
+	offsets := make(pq.Int64Array, 0, len(messages))
+	for _, msg := range messages {
+		offsets = append(offsets, msg.offset)
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		`UPDATE `+messagesTable+` SET forwarded_at = NOW() WHERE "offset" = ANY($1)`,
+		offsets,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark messages as forwarded: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit forwarded messages: %w", err)
+	}
+
+	return len(rows), nil
+}
+
+type outboxMessage struct {
+	offset int64
+	topic  string
+	msg    *message.Message
+}
+
+// parseRows returns the messages of the rows in order, and the errors of the rows that can't be forwarded.
+func parseRows(rows []outboxRow) ([]outboxMessage, map[int64]error) {
+	messages := make([]outboxMessage, 0, len(rows))
+	invalid := map[int64]error{}
+
+	for _, row := range rows {
+		var e envelope
+		if err := json.Unmarshal(row.Payload, &e); err != nil {
+			invalid[row.Offset] = fmt.Errorf("failed to unmarshal outbox message %d: %w", row.Offset, err)
+			continue
+		}
+		if e.DestinationTopic == "" {
+			invalid[row.Offset] = fmt.Errorf("outbox message %d has no destination topic", row.Offset)
+			continue
+		}
+
+		msg := message.NewMessage(e.UUID, e.Payload)
+		msg.Metadata = e.Metadata
+
+		messages = append(messages, outboxMessage{
+			offset: row.Offset,
+			topic:  e.DestinationTopic,
+			msg:    msg,
+		})
+	}
+
+	return messages, invalid
+}
+
+// deadLetter moves the invalid messages to the dead letters table in the transaction of the batch.
+func (f *PostgresForwarder) deadLetter(ctx context.Context, tx *sqlx.Tx, invalid map[int64]error) error {
+	for offset, invalidErr := range invalid {
+		f.logger.Error("Moving outbox message to dead letters", invalidErr, watermill.LogFields{"offset": offset})
+
+		_, err := tx.ExecContext(
+			ctx,
+			`
+			WITH removed AS (
+				DELETE FROM `+messagesTable+` WHERE "offset" = $1
+				RETURNING "offset", uuid, created_at, payload, metadata, transaction_id
+			)
+			INSERT INTO `+deadLettersTable+` ("offset", uuid, created_at, payload, metadata, transaction_id, error)
+			SELECT "offset", uuid, created_at, payload, metadata, transaction_id, $2 FROM removed
+			`,
+			offset,
+			invalidErr.Error(),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to move outbox message %d to dead letters: %w", offset, err)
+		}
+	}
+
+	return nil
+}
+
+// publish publishes the messages in order, consecutive messages to the same topic are published together.
+func (f *PostgresForwarder) publish(messages []outboxMessage) error {
+	var topic string
+	var batch []*message.Message
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		if err := f.publisher.Publish(topic, batch...); err != nil {
+			return fmt.Errorf("failed to publish %d messages to %s: %w", len(batch), topic, err)
+		}
+
+		batch = nil
+		return nil
+	}
+
+	for _, msg := range messages {
+		if msg.topic != topic {
+			if err := flush(); err != nil {
+				return err
+			}
+			topic = msg.topic
+		}
+
+		batch = append(batch, msg.msg)
+	}
+
+	return flush()
+}
@@ -0,0 +1,226 @@
+//go:build integration
+
+package outbox_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"tickets/message/outbox"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	_ "github.com/lib/pq"
+)
+
+// Run with: POSTGRES_URL=... go test -tags integration -bench . ./message/outbox/
+
+func TestPostgresForwarder_keeps_order_of_ordering_key_across_replicas(t *testing.T) {
+	db := connect(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runID := watermill.NewUUID()
+	publisher := newRecordingPublisher(runID)
+
+	// two replicas, one of them listening for notifications
+	for _, config := range []outbox.ForwarderConfig{
+		{BatchSize: 10},
+		{BatchSize: 10, ListenDSN: os.Getenv("POSTGRES_URL")},
+	} {
+		forwarder := outbox.NewPostgresForwarder(db, publisher, config, watermill.NopLogger{})
+		go func() {
+			assert.NoError(t, forwarder.Run(ctx))
+		}()
+	}
+
+	const aggregates = 10
+	const messagesPerAggregate = 20
+
+	for i := 0; i < messagesPerAggregate; i++ {
+		var msgs []*message.Message
+		for aggregate := 0; aggregate < aggregates; aggregate++ {
+			msgs = append(msgs, newMessage(runID, fmt.Sprintf("aggregate-%d", aggregate), i))
+		}
+		storeInOutbox(t, db, msgs...)
+	}
+
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		assert.Equal(t, aggregates*messagesPerAggregate, publisher.Count())
+	}, 30*time.Second, 50*time.Millisecond)
+
+	for aggregate, sequence := range publisher.Sequences() {
+		for i := range sequence {
+			assert.Equal(t, i, sequence[i], "messages of %s out of order: %v", aggregate, sequence)
+		}
+	}
+}
+
+func TestPostgresForwarder_moves_invalid_messages_to_dead_letters(t *testing.T) {
+	db := connect(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runID := watermill.NewUUID()
+	publisher := newRecordingPublisher(runID)
+
+	// stored without the destination topic, so it can't be forwarded
+	invalidUUID := watermill.NewUUID()
+	_, err := db.Exec(
+		`INSERT INTO watermill_events_to_forward (uuid, payload, metadata) VALUES ($1, $2, '{}')`,
+		invalidUUID,
+		fmt.Sprintf(`{"uuid": %q, "metadata": {"run_id": %q, %q: "aggregate"}}`, invalidUUID, runID, outbox.OrderingKeyMetadataKey),
+	)
+	require.NoError(t, err)
+
+	storeInOutbox(t, db, newMessage(runID, "aggregate", 0), newMessage(runID, "aggregate", 1))
+
+	forwarder := outbox.NewPostgresForwarder(db, publisher, outbox.ForwarderConfig{}, watermill.NopLogger{})
+	go func() {
+		assert.NoError(t, forwarder.Run(ctx))
+	}()
+
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		assert.Equal(t, 2, publisher.Count(), "the messages after the invalid one should be forwarded")
+	}, 10*time.Second, 50*time.Millisecond)
+
+	var deadLetterError string
+	err = db.Get(&deadLetterError, `SELECT error FROM watermill_events_to_forward_dead_letters WHERE uuid = $1`, invalidUUID)
+	require.NoError(t, err)
+	assert.Contains(t, deadLetterError, "no destination topic")
+}
+
+func BenchmarkPostgresForwarder(b *testing.B) {
+	db := connect(b)
+
+	runID := watermill.NewUUID()
+	publisher := newRecordingPublisher(runID)
+
+	const batch = 500
+	for stored := 0; stored < b.N; stored += batch {
+		var msgs []*message.Message
+		for i := stored; i < min(stored+batch, b.N); i++ {
+			msgs = append(msgs, newMessage(runID, fmt.Sprintf("aggregate-%d", i%100), i))
+		}
+		storeInOutbox(b, db, msgs...)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b.ResetTimer()
+
+	forwarder := outbox.NewPostgresForwarder(db, publisher, outbox.ForwarderConfig{}, watermill.NopLogger{})
+	go func() {
+		_ = forwarder.Run(ctx)
+	}()
+
+	for publisher.Count() < b.N {
+		time.Sleep(time.Millisecond)
+	}
+
+	b.StopTimer()
+	b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "msgs/s")
+}
+
+func connect(t testing.TB) *sqlx.DB {
+	db, err := sqlx.Open("postgres", os.Getenv("POSTGRES_URL"))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	require.NoError(t, outbox.InitializeSchema(db.DB))
+
+	return db
+}
+
+func newMessage(runID string, orderingKey string, sequence int) *message.Message {
+	msg := message.NewMessage(watermill.NewUUID(), []byte(strconv.Itoa(sequence)))
+	msg.Metadata.Set("run_id", runID)
+	msg.Metadata.Set(outbox.OrderingKeyMetadataKey, orderingKey)
+
+	return msg
+}
+
+func storeInOutbox(t testing.TB, db *sqlx.DB, msgs ...*message.Message) {
+	tx, err := db.Beginx()
+	require.NoError(t, err)
+
+	publisher, err := outbox.NewPublisherForDb(context.Background(), tx)
+	require.NoError(t, err)
+
+	require.NoError(t, publisher.Publish("forwarder-test", msgs...))
+	require.NoError(t, tx.Commit())
+}
+
+// recordingPublisher records messages of one test run, grouped by their ordering key.
+type recordingPublisher struct {
+	runID string
+
+	lock      sync.Mutex
+	count     int
+	sequences map[string][]int
+}
+
+func newRecordingPublisher(runID string) *recordingPublisher {
+	return &recordingPublisher{
+		runID:     runID,
+		sequences: make(map[string][]int),
+	}
+}
+
+func (p *recordingPublisher) Publish(topic string, msgs ...*message.Message) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for _, msg := range msgs {
+		if msg.Metadata.Get("run_id") != p.runID {
+			continue
+		}
+
+		sequence, err := strconv.Atoi(string(msg.Payload))
+		if err != nil {
+			return err
+		}
+
+		key := msg.Metadata.Get(outbox.OrderingKeyMetadataKey)
+		p.sequences[key] = append(p.sequences[key], sequence)
+		p.count++
+	}
+
+	return nil
+}
+
+func (p *recordingPublisher) Close() error {
+	return nil
+}
+
+func (p *recordingPublisher) Count() int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return p.count
+}
+
+func (p *recordingPublisher) Sequences() map[string][]int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	sequences := make(map[string][]int, len(p.sequences))
+	for key, sequence := range p.sequences {
+		sequences[key] = append([]int(nil), sequence...)
+	}
+
+	return sequences
+}
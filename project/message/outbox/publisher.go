@@ -26,6 +26,12 @@ func NewPublisherForDb(ctx context.Context, db *sqlx.Tx) (message.Publisher, err
 	if err != nil {
 		return nil, fmt.Errorf("failed to create outbox publisher: %w", err)
 	}
+
+	return NewPublisher(publisher), nil
+}
+
+// NewPublisher wraps the messages in the forwarder envelope, to be forwarded to their destination topic.
+func NewPublisher(publisher message.Publisher) message.Publisher {
 	publisher = log.CorrelationPublisherDecorator{Publisher: publisher}
 
 	publisher = forwarder.NewPublisher(publisher, forwarder.PublisherConfig{
@@ -33,5 +39,5 @@ func NewPublisherForDb(ctx context.Context, db *sqlx.Tx) (message.Publisher, err
 	})
 	publisher = log.CorrelationPublisherDecorator{Publisher: publisher}
 
-	return publisher, nil
-}
\ No newline at end of file
+	return publisher
+}
@@ -0,0 +1,192 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/jmoiron/sqlx"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	retentionRemovedMessages = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "outbox",
+		Subsystem: "retention",
+		Name:      "removed_messages_total",
+		Help:      "Number of forwarded outbox messages removed by the retention job.",
+	}, []string{"mode"})
+	retentionRuns = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "outbox",
+		Subsystem: "retention",
+		Name:      "runs_total",
+		Help:      "Number of retention job runs.",
+	}, []string{"result"})
+	retentionRunDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: "outbox",
+		Subsystem: "retention",
+		Name:      "run_duration_seconds",
+		Help:      "Duration of retention job runs.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 4, 8),
+	})
+	retentionLastSuccess = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "outbox",
+		Subsystem: "retention",
+		Name:      "last_success_timestamp_seconds",
+		Help:      "Time of the last successful retention job run.",
+	})
+)
+
+type RetentionConfig struct {
+	// MaxAge is how long forwarded messages are kept, zero disables the retention.
+	MaxAge time.Duration
+	// Archive moves the removed messages to the archive table instead of deleting them.
+	Archive bool
+
+	// Interval between the runs of the job.
+	Interval time.Duration
+	// BatchSize is the maximum number of messages removed in one transaction.
+	BatchSize int
+	// BatchPause is the pause between batches, so the job doesn't starve the forwarder.
+	BatchPause time.Duration
+}
+
+func (c RetentionConfig) Enabled() bool {
+	return c.MaxAge > 0
+}
+
+func (c *RetentionConfig) setDefaults() {
+	if c.Interval == 0 {
+		c.Interval = time.Hour
+	}
+	if c.BatchSize == 0 {
+		c.BatchSize = 1000
+	}
+	if c.BatchPause == 0 {
+		c.BatchPause = time.Millisecond * 100
+	}
+}
+
+// Retention removes the messages forwarded more than MaxAge ago, the messages not forwarded yet are never removed.
+type Retention struct {
+	db     *sqlx.DB
+	config RetentionConfig
+	logger watermill.LoggerAdapter
+}
+
+func NewRetention(db *sqlx.DB, config RetentionConfig, logger watermill.LoggerAdapter) *Retention {
+	if db == nil {
+		panic("missing db")
+	}
+	if !config.Enabled() {
+		panic("retention MaxAge is required")
+	}
+	if logger == nil {
+		logger = watermill.NopLogger{}
+	}
+
+	config.setDefaults()
+
+	return &Retention{
+		db:     db,
+		config: config,
+		logger: logger,
+	}
+}
+
+// Run removes the old messages every Interval until ctx is done.
+func (r *Retention) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		start := time.Now()
+
+		removed, err := r.Cleanup(ctx)
+		retentionRunDuration.Observe(time.Since(start).Seconds())
+
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			retentionRuns.WithLabelValues("error").Inc()
+			r.logger.Error("Outbox retention failed", err, watermill.LogFields{"removed": removed})
+		} else {
+			retentionRuns.WithLabelValues("success").Inc()
+			retentionLastSuccess.SetToCurrentTime()
+			r.logger.Info("Outbox retention finished", watermill.LogFields{"removed": removed})
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Cleanup removes the old messages in batches, and returns the number of removed messages.
+func (r *Retention) Cleanup(ctx context.Context) (int, error) {
+	cutoff := time.Now().Add(-r.config.MaxAge)
+
+	total := 0
+	for {
+		removed, err := r.removeBatch(ctx, cutoff)
+		total += removed
+		if err != nil {
+			return total, err
+		}
+
+		if removed < r.config.BatchSize {
+			return total, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return total, ctx.Err()
+		case <-time.After(r.config.BatchPause):
+		}
+	}
+}
+
+func (r *Retention) removeBatch(ctx context.Context, cutoff time.Time) (int, error) {
+	mode := "delete"
+
+	// SKIP LOCKED, so rows being marked as forwarded right now don't block the job
+	query := `
+		DELETE FROM ` + messagesTable + `
+		WHERE "offset" IN (
+			SELECT "offset" FROM ` + messagesTable + `
+			WHERE forwarded_at IS NOT NULL AND forwarded_at < $1
+			ORDER BY forwarded_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)`
+
+	if r.config.Archive {
+		mode = "archive"
+		query = `
+			WITH removed AS (` + query + `
+				RETURNING "offset", uuid, created_at, payload, metadata, transaction_id, forwarded_at
+			)
+			INSERT INTO ` + archiveTable + ` ("offset", uuid, created_at, payload, metadata, transaction_id, forwarded_at)
+			SELECT "offset", uuid, created_at, payload, metadata, transaction_id, forwarded_at FROM removed`
+	}
+
+	res, err := r.db.ExecContext(ctx, query, cutoff, r.config.BatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to %s outbox messages: %w", mode, err)
+	}
+
+	removed, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get removed outbox messages: %w", err)
+	}
+
+	retentionRemovedMessages.WithLabelValues(mode).Add(float64(removed))
+
+	return int(removed), nil
+}
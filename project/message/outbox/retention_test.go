@@ -0,0 +1,83 @@
+//go:build integration
+
+package outbox_test
+
+import (
+	"context"
+	"testing"
+	"tickets/message/outbox"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetention_removes_only_old_forwarded_messages(t *testing.T) {
+	for _, archive := range []bool{false, true} {
+		db := connect(t)
+		runID := watermill.NewUUID()
+
+		var msgs []*message.Message
+		for i := 0; i < 5; i++ {
+			msgs = append(msgs, newMessage(runID, watermill.NewUUID(), i))
+		}
+		storeInOutbox(t, db, msgs...)
+
+		// forwarded long ago
+		setForwardedAt(t, db, msgs[0].UUID, time.Now().Add(-time.Hour*48))
+		setForwardedAt(t, db, msgs[1].UUID, time.Now().Add(-time.Hour*48))
+		// forwarded recently
+		setForwardedAt(t, db, msgs[2].UUID, time.Now())
+		// msgs[3] and msgs[4] are not forwarded
+
+		retention := outbox.NewRetention(db, outbox.RetentionConfig{
+			MaxAge:    time.Hour * 24,
+			Archive:   archive,
+			BatchSize: 1,
+		}, watermill.NopLogger{})
+
+		_, err := retention.Cleanup(context.Background())
+		require.NoError(t, err)
+
+		assert.False(t, inOutbox(t, db, msgs[0].UUID))
+		assert.False(t, inOutbox(t, db, msgs[1].UUID))
+		assert.True(t, inOutbox(t, db, msgs[2].UUID))
+		assert.True(t, inOutbox(t, db, msgs[3].UUID))
+		assert.True(t, inOutbox(t, db, msgs[4].UUID))
+
+		var archived int
+		err = db.Get(
+			&archived,
+			`SELECT COUNT(*) FROM "watermill_events_to_forward_archive" WHERE payload->>'uuid' = ANY($1)`,
+			pq.StringArray{msgs[0].UUID, msgs[1].UUID},
+		)
+		require.NoError(t, err)
+
+		if archive {
+			assert.Equal(t, 2, archived)
+		} else {
+			assert.Equal(t, 0, archived)
+		}
+	}
+}
+
+func setForwardedAt(t *testing.T, db *sqlx.DB, messageUUID string, forwardedAt time.Time) {
+	_, err := db.Exec(
+		`UPDATE "watermill_events_to_forward" SET forwarded_at = $1 WHERE payload->>'uuid' = $2`,
+		forwardedAt,
+		messageUUID,
+	)
+	require.NoError(t, err)
+}
+
+func inOutbox(t *testing.T, db *sqlx.DB, messageUUID string) bool {
+	var exists bool
+	err := db.Get(&exists, `SELECT EXISTS (SELECT 1 FROM "watermill_events_to_forward" WHERE payload->>'uuid' = $1)`, messageUUID)
+	require.NoError(t, err)
+
+	return exists
+}
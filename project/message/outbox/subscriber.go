@@ -4,11 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/ThreeDotsLabs/go-event-driven/common/log"
 	"github.com/ThreeDotsLabs/watermill"
 	watermillSQL "github.com/ThreeDotsLabs/watermill-sql/v2/pkg/sql"
+	"github.com/ThreeDotsLabs/watermill/pubsub/gochannel"
 )
 
 func NewPostgresSubscriber(db *sql.DB, logger watermill.LoggerAdapter) *watermillSQL.Subscriber {
@@ -29,7 +31,102 @@ func NewPostgresSubscriber(db *sql.DB, logger watermill.LoggerAdapter) *watermil
 	return sub
 }
 
+// InitializeSchema creates the outbox table, and extends it for the PostgresForwarder.
 func InitializeSchema(db *sql.DB) error {
 	sqlSub := NewPostgresSubscriber(db, log.NewWatermill(log.FromContext(context.Background())))
-	return sqlSub.SubscribeInitialize(outboxTopic)
+	if err := sqlSub.SubscribeInitialize(outboxTopic); err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var forwardedAtExists bool
+	err = tx.QueryRow(
+		`SELECT EXISTS (
+			SELECT 1 FROM information_schema.columns WHERE table_name = $1 AND column_name = 'forwarded_at'
+		)`,
+		strings.Trim(messagesTable, `"`),
+	).Scan(&forwardedAtExists)
+	if err != nil {
+		return fmt.Errorf("failed to check outbox schema: %w", err)
+	}
+
+	if !forwardedAtExists {
+		_, err = tx.Exec(`ALTER TABLE ` + messagesTable + ` ADD COLUMN forwarded_at timestamptz`)
+		if err != nil {
+			return fmt.Errorf("failed to add forwarded_at to outbox: %w", err)
+		}
+
+		// messages acked by the subscriber used before the PostgresForwarder must not be forwarded again
+		_, err = tx.Exec(`
+			UPDATE ` + messagesTable + ` SET forwarded_at = NOW()
+			WHERE (transaction_id, "offset") <= (
+				SELECT last_processed_transaction_id, offset_acked FROM ` + offsetsTable + ` WHERE consumer_group = ''
+			)
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to mark forwarded outbox messages: %w", err)
+		}
+	}
+
+	_, err = tx.Exec(fmt.Sprintf(`
+		ALTER TABLE %[1]s ADD COLUMN IF NOT EXISTS shard INTEGER GENERATED ALWAYS AS (
+			(hashtext(COALESCE(payload->'metadata'->>'%[2]s', uuid)) & 2147483647) %% %[3]d
+		) STORED;
+
+		CREATE INDEX IF NOT EXISTS events_to_forward_not_forwarded_idx
+			ON %[1]s (shard, transaction_id, "offset") WHERE forwarded_at IS NULL;
+		CREATE INDEX IF NOT EXISTS events_to_forward_forwarded_at_idx
+			ON %[1]s (forwarded_at) WHERE forwarded_at IS NOT NULL;
+
+		CREATE TABLE IF NOT EXISTS %[5]s (
+			"offset" BIGINT NOT NULL,
+			uuid VARCHAR(36) NOT NULL,
+			created_at TIMESTAMP NOT NULL,
+			payload JSON DEFAULT NULL,
+			metadata JSON DEFAULT NULL,
+			transaction_id xid8 NOT NULL,
+			forwarded_at timestamptz NOT NULL,
+			archived_at timestamptz NOT NULL DEFAULT NOW()
+		);
+
+		CREATE TABLE IF NOT EXISTS %[6]s (
+			"offset" BIGINT NOT NULL,
+			uuid VARCHAR(36) NOT NULL,
+			created_at TIMESTAMP NOT NULL,
+			payload JSON DEFAULT NULL,
+			metadata JSON DEFAULT NULL,
+			transaction_id xid8 NOT NULL,
+			error TEXT NOT NULL,
+			dead_lettered_at timestamptz NOT NULL DEFAULT NOW()
+		);
+
+		CREATE OR REPLACE FUNCTION notify_events_to_forward() RETURNS trigger AS $$
+		BEGIN
+			PERFORM pg_notify('%[4]s', '');
+			RETURN NULL;
+		END;
+		$$ LANGUAGE plpgsql;
+
+		CREATE OR REPLACE TRIGGER events_to_forward_notify AFTER INSERT ON %[1]s
+			FOR EACH STATEMENT EXECUTE FUNCTION notify_events_to_forward();
+	`, messagesTable, OrderingKeyMetadataKey, shardsCount, notifyChannel, archiveTable, deadLettersTable))
+	if err != nil {
+		return fmt.Errorf("failed to extend outbox schema: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// NewGoChannel creates an in-memory outbox, for running the service without Postgres.
+func NewGoChannel(logger watermill.LoggerAdapter) *gochannel.GoChannel {
+	return gochannel.NewGoChannel(gochannel.Config{
+		OutputChannelBuffer: 1024,
+	}, logger)
 }
@@ -1,3 +1,16 @@
 package outbox
 
 const outboxTopic = "events_to_forward"
+
+// tables created by watermill-sql for the outbox topic
+const (
+	messagesTable = `"watermill_events_to_forward"`
+	offsetsTable  = `"watermill_offsets_events_to_forward"`
+	archiveTable  = `"watermill_events_to_forward_archive"`
+)
+
+// deadLettersTable keeps the messages that can't be forwarded, so they don't stop their shard.
+const deadLettersTable = `"watermill_events_to_forward_dead_letters"`
+
+// ForwarderHandlerName is the name of the router handler forwarding messages from the outbox (set by Watermill's forwarder).
+const ForwarderHandlerName = "events_forwarder"
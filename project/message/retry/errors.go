@@ -0,0 +1,80 @@
+// Package retry classifies handler errors and retries the failed messages with per-handler policies.
+package retry
+
+import (
+	"errors"
+	"time"
+)
+
+type Kind int
+
+const (
+	// KindTransient errors are retried with backoff, like all the errors that are not classified.
+	KindTransient Kind = iota
+	// KindPermanent errors won't go away on retry, the message goes straight to the dead letter topic.
+	KindPermanent
+	// KindRateLimited errors are retried after the delay requested by the other side.
+	KindRateLimited
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindPermanent:
+		return "permanent"
+	case KindRateLimited:
+		return "rate_limited"
+	default:
+		return "transient"
+	}
+}
+
+type Error struct {
+	Kind Kind
+	Err  error
+
+	// RetryAfter is the delay requested for KindRateLimited errors, it may be 0 when it's unknown.
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func Permanent(err error) error {
+	return &Error{Kind: KindPermanent, Err: err}
+}
+
+func Transient(err error) error {
+	return &Error{Kind: KindTransient, Err: err}
+}
+
+func RateLimited(err error, retryAfter time.Duration) error {
+	return &Error{Kind: KindRateLimited, Err: err, RetryAfter: retryAfter}
+}
+
+// KindOf returns the kind of the outermost classified error in the chain.
+func KindOf(err error) Kind {
+	var retryErr *Error
+	if errors.As(err, &retryErr) {
+		return retryErr.Kind
+	}
+
+	return KindTransient
+}
+
+func IsPermanent(err error) bool {
+	return KindOf(err) == KindPermanent
+}
+
+func retryAfter(err error) time.Duration {
+	var retryErr *Error
+	if errors.As(err, &retryErr) {
+		return retryErr.RetryAfter
+	}
+
+	return 0
+}
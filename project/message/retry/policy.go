@@ -0,0 +1,140 @@
+package retry
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+type Policy struct {
+	// MaxAttempts includes the first attempt, then the message is nacked and redelivered later.
+	MaxAttempts int
+
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	Multiplier      float64
+	// Jitter randomizes the intervals by up to the given fraction (0.1 is ±10%).
+	Jitter float64
+
+	// MaxRateLimitedWait is the longest wait for the rate limits before the message is nacked, zero means defaultMaxRateLimitedWait.
+	MaxRateLimitedWait time.Duration
+}
+
+// defaultMaxRateLimitedWait is below the time after which the Redis subscribers claim the pending messages.
+const defaultMaxRateLimitedWait = time.Second * 30
+
+var DefaultPolicy = Policy{
+	MaxAttempts:     11,
+	InitialInterval: time.Millisecond * 100,
+	MaxInterval:     time.Second,
+	Multiplier:      2,
+}
+
+// interval returns how long to wait after the given failed attempt.
+func (p Policy) interval(attempt int) time.Duration {
+	interval := float64(p.InitialInterval)
+	for i := 1; i < attempt; i++ {
+		interval *= p.Multiplier
+		if interval > float64(p.MaxInterval) {
+			interval = float64(p.MaxInterval)
+			break
+		}
+	}
+
+	if p.Jitter > 0 {
+		interval += interval * p.Jitter * (2*rand.Float64() - 1)
+	}
+
+	return time.Duration(interval)
+}
+
+// Policies are the retry policies of the router handlers, handlers without their own policy use Default.
+type Policies struct {
+	Default  Policy
+	Handlers map[string]Policy
+
+	Logger watermill.LoggerAdapter
+}
+
+func (p Policies) For(handlerName string) Policy {
+	if policy, ok := p.Handlers[handlerName]; ok {
+		return policy
+	}
+
+	return p.Default
+}
+
+// Middleware retries the message according to the policy of the handler that consumed it.
+func (p Policies) Middleware(h message.HandlerFunc) message.HandlerFunc {
+	return func(msg *message.Message) ([]*message.Message, error) {
+		handlerName := message.HandlerNameFromCtx(msg.Context())
+
+		return p.For(handlerName).handle(h, msg, p.Logger)
+	}
+}
+
+// Middleware retries the message, except for permanent errors, rate-limited errors don't use up the attempts.
+func (p Policy) Middleware(h message.HandlerFunc) message.HandlerFunc {
+	return func(msg *message.Message) ([]*message.Message, error) {
+		return p.handle(h, msg, nil)
+	}
+}
+
+func (p Policy) handle(h message.HandlerFunc, msg *message.Message, logger watermill.LoggerAdapter) ([]*message.Message, error) {
+	if logger == nil {
+		logger = watermill.NopLogger{}
+	}
+
+	ctx := msg.Context()
+
+	maxRateLimitedWait := p.MaxRateLimitedWait
+	if maxRateLimitedWait == 0 {
+		maxRateLimitedWait = defaultMaxRateLimitedWait
+	}
+
+	attempt := 1
+	var rateLimitedWait time.Duration
+	for {
+		events, err := h(msg)
+		if err == nil {
+			return events, nil
+		}
+
+		kind := KindOf(err)
+
+		var wait time.Duration
+		switch kind {
+		case KindPermanent:
+			return nil, err
+		case KindRateLimited:
+			wait = max(retryAfter(err), p.interval(attempt))
+			if rateLimitedWait+wait > maxRateLimitedWait {
+				return nil, err
+			}
+			rateLimitedWait += wait
+		default:
+			if attempt >= p.MaxAttempts {
+				return nil, err
+			}
+			wait = p.interval(attempt)
+			attempt++
+		}
+
+		logger.Error("Error occurred, retrying", err, watermill.LogFields{
+			"handler":      message.HandlerNameFromCtx(ctx),
+			"message_uuid": msg.UUID,
+			"error_kind":   kind.String(),
+			"attempt":      attempt,
+			"max_attempts": p.MaxAttempts,
+			"wait_time":    wait,
+		})
+
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(wait):
+		}
+	}
+}
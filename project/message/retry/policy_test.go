@@ -0,0 +1,132 @@
+package retry_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"tickets/message/retry"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKindOf(t *testing.T) {
+	err := errors.New("failed")
+
+	assert.Equal(t, retry.KindTransient, retry.KindOf(err))
+	assert.Equal(t, retry.KindTransient, retry.KindOf(retry.Transient(err)))
+	assert.Equal(t, retry.KindPermanent, retry.KindOf(fmt.Errorf("wrapped: %w", retry.Permanent(err))))
+	assert.Equal(t, retry.KindRateLimited, retry.KindOf(retry.RateLimited(err, time.Second)))
+
+	assert.True(t, errors.Is(retry.Permanent(err), err))
+}
+
+func TestPolicies_Middleware(t *testing.T) {
+	policies := retry.Policies{
+		Default: retry.Policy{
+			MaxAttempts:     3,
+			InitialInterval: time.Millisecond,
+			MaxInterval:     time.Millisecond,
+			Multiplier:      2,
+		},
+		Handlers: map[string]retry.Policy{
+			"patient": {
+				MaxAttempts:     5,
+				InitialInterval: time.Millisecond,
+				MaxInterval:     time.Millisecond * 10,
+				Multiplier:      2,
+				Jitter:          0.5,
+			},
+		},
+	}
+
+	testCases := []struct {
+		Name             string
+		HandlerName      string
+		Err              error
+		ExpectedAttempts int
+	}{
+		{
+			Name:             "transient_error_uses_default_policy",
+			HandlerName:      "default",
+			Err:              errors.New("failed"),
+			ExpectedAttempts: 3,
+		},
+		{
+			Name:             "transient_error_uses_handler_policy",
+			HandlerName:      "patient",
+			Err:              retry.Transient(errors.New("failed")),
+			ExpectedAttempts: 5,
+		},
+		{
+			Name:             "permanent_error_is_not_retried",
+			HandlerName:      "patient",
+			Err:              fmt.Errorf("wrapped: %w", retry.Permanent(errors.New("bad request"))),
+			ExpectedAttempts: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			attempts := 0
+			handler := policies.For(tc.HandlerName).Middleware(func(msg *message.Message) ([]*message.Message, error) {
+				attempts++
+				return nil, tc.Err
+			})
+
+			_, err := handler(message.NewMessage(watermill.NewUUID(), nil))
+			require.Error(t, err)
+			assert.Equal(t, tc.ExpectedAttempts, attempts)
+		})
+	}
+}
+
+func TestPolicy_Middleware_rate_limited_errors_dont_use_up_attempts(t *testing.T) {
+	policy := retry.Policy{
+		MaxAttempts:     2,
+		InitialInterval: time.Millisecond,
+		MaxInterval:     time.Millisecond,
+		Multiplier:      1,
+	}
+
+	attempts := 0
+	handler := policy.Middleware(func(msg *message.Message) ([]*message.Message, error) {
+		attempts++
+		if attempts <= 3 {
+			return nil, retry.RateLimited(errors.New("too many requests"), time.Millisecond*5)
+		}
+		return nil, nil
+	})
+
+	start := time.Now()
+	_, err := handler(message.NewMessage(watermill.NewUUID(), nil))
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, attempts)
+	assert.GreaterOrEqual(t, time.Since(start), time.Millisecond*15, "retry after should be respected")
+}
+
+func TestPolicy_Middleware_rate_limited_errors_wait_up_to_max(t *testing.T) {
+	policy := retry.Policy{
+		MaxAttempts:        2,
+		InitialInterval:    time.Millisecond,
+		MaxInterval:        time.Millisecond,
+		Multiplier:         1,
+		MaxRateLimitedWait: time.Millisecond * 20,
+	}
+
+	attempts := 0
+	handler := policy.Middleware(func(msg *message.Message) ([]*message.Message, error) {
+		attempts++
+		return nil, retry.RateLimited(errors.New("too many requests"), time.Millisecond*5)
+	})
+
+	_, err := handler(message.NewMessage(watermill.NewUUID(), nil))
+	require.Error(t, err)
+	assert.False(t, retry.IsPermanent(err), "the message should be nacked, not moved to the dead letter topic")
+
+	assert.Equal(t, 5, attempts)
+}
@@ -2,23 +2,44 @@ package message
 
 import (
 	"fmt"
+	"tickets/chaos"
 	"tickets/message/event"
 	"tickets/message/outbox"
+	"tickets/webhook"
 
 	"github.com/ThreeDotsLabs/watermill"
 	"github.com/ThreeDotsLabs/watermill/components/cqrs"
 	"github.com/ThreeDotsLabs/watermill/message"
 )
 
-func NewWatermillRouter(postgresSubscriber message.Subscriber, publisher message.Publisher, eventProcessorConfig cqrs.EventProcessorConfig, eventHandler event.Handler, watermillLogger watermill.LoggerAdapter) *message.Router {
+// NewWatermillRouter creates the router of the handlers, keyLocker may be nil if the service runs in a single process.
+func NewWatermillRouter(outboxSubscriber message.Subscriber, publisher message.Publisher, eventProcessorConfig cqrs.EventProcessorConfig, eventHandler event.Handler, webhookDispatcher webhook.Dispatcher, keyLocker KeyLocker, chaosInjector *chaos.Chaos, handlers *Handlers, watermillLogger watermill.LoggerAdapter) *message.Router {
 	router, err := message.NewRouter(message.RouterConfig{}, watermillLogger)
 	if err != nil {
 		panic(err)
 	}
 
-	useMiddlewares(router, watermillLogger)
+	if keyLocker == nil {
+		keyLocker = newLocalKeyLocks()
+	}
+
+	useMiddlewares(router, publisher, keyLocker, chaosInjector, handlers, watermillLogger)
 
-	outbox.AddForwarderHandler(postgresSubscriber, publisher, router, watermillLogger)
+	// the subscriptions of the paused handlers are closed, see Handlers.Subscriber
+	subscriberConstructor := eventProcessorConfig.SubscriberConstructor
+	eventProcessorConfig.SubscriberConstructor = func(params cqrs.EventProcessorSubscriberConstructorParams) (message.Subscriber, error) {
+		subscriber, err := subscriberConstructor(params)
+		if err != nil {
+			return nil, err
+		}
+
+		return handlers.Subscriber(params.HandlerName, subscriber), nil
+	}
+
+	// without the subscriber, the outbox is forwarded outside of the router
+	if outboxSubscriber != nil {
+		outbox.AddForwarderHandler(outboxSubscriber, publisher, router, watermillLogger)
+	}
 
 	ep, err := cqrs.NewEventProcessorWithConfig(
 		router,
@@ -29,7 +50,33 @@ func NewWatermillRouter(postgresSubscriber message.Subscriber, publisher message
 		panic(err)
 	}
 
-	ep.AddHandlers(
+	ep.AddHandlers(eventHandlers(eventHandler)...)
+
+	// webhooks get raw events, so every event type has its own handler (and consumer group)
+	for _, eventType := range webhook.EventTypes {
+		handlerName := webhookHandlerName(eventType)
+
+		subscriber, err := eventProcessorConfig.SubscriberConstructor(cqrs.EventProcessorSubscriberConstructorParams{
+			HandlerName: handlerName,
+		})
+		if err != nil {
+			panic(err)
+		}
+
+		router.AddNoPublisherHandler(
+			handlerName,
+			eventType,
+			subscriber,
+			webhookDispatcher.Handle,
+		)
+	}
+
+	return router
+}
+
+// eventHandlers returns the event handlers, renaming one changes its consumer group (see event.ConsumerGroup).
+func eventHandlers(eventHandler event.Handler) []cqrs.EventHandler {
+	return []cqrs.EventHandler{
 		cqrs.NewEventHandler(
 			"AppendToTracker",
 			eventHandler.AppendToTracker,
@@ -54,11 +101,51 @@ func NewWatermillRouter(postgresSubscriber message.Subscriber, publisher message
 			"PrintTicketHandler",
 			eventHandler.PrintTickets,
 		),
+		cqrs.NewEventHandler(
+			"MarkTicketPrinted",
+			eventHandler.MarkTicketPrinted,
+		),
+		cqrs.NewEventHandler(
+			"MarkTicketRefunded",
+			eventHandler.MarkTicketRefunded,
+		),
 		cqrs.NewEventHandler(
 			"BookPlaceInDeadNation",
 			eventHandler.BookPlaceInDeadNation,
 		),
-	)
+		cqrs.NewEventHandler(
+			"ReportTicketSold",
+			eventHandler.ReportTicketSold,
+		),
+		cqrs.NewEventHandler(
+			"ReportTicketCanceled",
+			eventHandler.ReportTicketCanceled,
+		),
+		cqrs.NewEventHandler(
+			"ReportTicketRefunded",
+			eventHandler.ReportTicketRefunded,
+		),
+		cqrs.NewEventHandler(
+			"ReportBookedShow",
+			eventHandler.ReportBookedShow,
+		),
+		cqrs.NewEventHandler(
+			"EraseSheetRows",
+			eventHandler.EraseSheetRows,
+		),
+	}
+}
 
-	return router
+func webhookHandlerName(eventType string) string {
+	return "Webhooks" + eventType
+}
+
+// Topics returns the topics the service publishes to: the topics of the events and the dead letter topic.
+func Topics() []string {
+	var topics []string
+	for _, eventName := range event.EventNames() {
+		topics = append(topics, event.Topic(eventName))
+	}
+
+	return append(topics, DeadLetterTopic)
 }
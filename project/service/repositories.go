@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"tickets/auth"
+	"tickets/db"
+	"tickets/db/memory"
+	ticketsHttp "tickets/http"
+	"tickets/message"
+	"tickets/message/event"
+	"tickets/message/outbox"
+	"tickets/sheetsync"
+	"tickets/webhook"
+
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/components/cqrs"
+	watermillMessage "github.com/ThreeDotsLabs/watermill/message"
+	"github.com/jmoiron/sqlx"
+)
+
+type TicketsRepository interface {
+	event.TicketsRepository
+	ticketsHttp.TicketsRepository
+}
+
+type ShowsRepository interface {
+	event.ShowsRepository
+	ticketsHttp.ShowsRepository
+}
+
+type BookingsRepository interface {
+	ticketsHttp.BookingsRepository
+}
+
+type WebhooksRepository interface {
+	webhook.Repository
+	ticketsHttp.WebhooksRepository
+}
+
+type SheetRowsRepository interface {
+	event.SheetRowsRepository
+	sheetsync.Repository
+}
+
+type ReportsRepository interface {
+	event.ReportsRepository
+	ticketsHttp.ReportsRepository
+}
+
+type APIKeysRepository interface {
+	auth.APIKeysRepository
+	ticketsHttp.APIKeysRepository
+}
+
+type Job interface {
+	Run(ctx context.Context) error
+}
+
+type Repositories struct {
+	Tickets   TicketsRepository
+	Shows     ShowsRepository
+	Bookings  BookingsRepository
+	Webhooks  WebhooksRepository
+	SheetRows SheetRowsRepository
+	Reports   ReportsRepository
+	APIKeys   APIKeysRepository
+	Audit     ticketsHttp.AuditRepository
+	Customers ticketsHttp.CustomersRepository
+
+	MessageHandlers message.HandlersRepository
+	// OrderingLocks is nil when the messages are handled by a single instance.
+	OrderingLocks message.KeyLocker
+
+	// The outbox is forwarded either by a router handler reading from OutboxSubscriber,
+	// or by the forwarder created with NewOutboxForwarder.
+	OutboxSubscriber   watermillMessage.Subscriber
+	NewOutboxForwarder func(publisher watermillMessage.Publisher, logger watermill.LoggerAdapter) outbox.Forwarder
+
+	// Jobs run in the background while the service is running.
+	Jobs []Job
+
+	// InitializeSchema is called before the service starts, it's nil when the repositories don't need a schema.
+	InitializeSchema func() error
+}
+
+func NewPostgresRepositories(
+	dbConn *sqlx.DB,
+	eventMarshaler cqrs.CommandEventMarshaler,
+	outboxConfig outbox.Config,
+) Repositories {
+	var jobs []Job
+	if outboxConfig.Retention.Enabled() {
+		jobs = append(jobs, outbox.NewRetention(dbConn, outboxConfig.Retention, log.NewWatermill(log.FromContext(context.Background()))))
+	}
+
+	return Repositories{
+		Tickets:   db.NewTicketsRepository(dbConn),
+		Shows:     db.NewShowsRepository(dbConn),
+		Bookings:  db.NewBookingsRepository(dbConn, eventMarshaler),
+		Webhooks:  db.NewWebhooksRepository(dbConn),
+		SheetRows: db.NewSheetRowsRepository(dbConn),
+		Reports:   db.NewReportsRepository(dbConn),
+		APIKeys:   db.NewAPIKeysRepository(dbConn),
+		Audit:     db.NewAuditRepository(dbConn),
+		Customers: db.NewCustomersRepository(dbConn, eventMarshaler),
+
+		MessageHandlers: db.NewMessageHandlersRepository(dbConn),
+		OrderingLocks:   db.NewOrderingLocks(dbConn),
+		NewOutboxForwarder: func(publisher watermillMessage.Publisher, logger watermill.LoggerAdapter) outbox.Forwarder {
+			return outbox.NewPostgresForwarder(dbConn, publisher, outboxConfig.Forwarder, logger)
+		},
+		Jobs: jobs,
+		InitializeSchema: func() error {
+			return db.InitializeDatabaseSchema(dbConn)
+		},
+	}
+}
+
+// NewInMemoryRepositories creates repositories keeping the data (and the outbox) in memory.
+func NewInMemoryRepositories(eventMarshaler cqrs.CommandEventMarshaler) Repositories {
+	database := memory.NewDatabase()
+	outboxPubSub := outbox.NewGoChannel(watermill.NopLogger{})
+
+	return Repositories{
+		Tickets:   memory.NewTicketsRepository(database),
+		Shows:     memory.NewShowsRepository(database),
+		Bookings:  memory.NewBookingsRepository(database, outboxPubSub, eventMarshaler),
+		Webhooks:  memory.NewWebhooksRepository(database),
+		SheetRows: memory.NewSheetRowsRepository(database),
+		Reports:   memory.NewReportsRepository(database),
+		APIKeys:   memory.NewAPIKeysRepository(database),
+		Audit:     memory.NewAuditRepository(database),
+		Customers: memory.NewCustomersRepository(database, outboxPubSub, eventMarshaler),
+
+		MessageHandlers: memory.NewMessageHandlersRepository(database),
+
+		OutboxSubscriber: outboxPubSub,
+	}
+}
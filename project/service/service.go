@@ -4,17 +4,20 @@ import (
 	"context"
 	"fmt"
 	stdHTTP "net/http"
-	"tickets/db"
+	"tickets/auth"
+	"tickets/chaos"
 	ticketsHttp "tickets/http"
 	"tickets/message"
+	"tickets/message/broker"
 	"tickets/message/event"
 	"tickets/message/outbox"
+	"tickets/sheetsync"
+	"tickets/webhook"
 
 	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+	"github.com/ThreeDotsLabs/watermill/components/cqrs"
 	watermillMessage "github.com/ThreeDotsLabs/watermill/message"
-	"github.com/jmoiron/sqlx"
 	"github.com/labstack/echo/v4"
-	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/sync/errgroup"
 )
@@ -24,66 +27,105 @@ func init() {
 }
 
 type Service struct {
-	db              *sqlx.DB
-	watermillRouter *watermillMessage.Router
-	echoRouter      *echo.Echo
+	initializeSchema func() error
+	watermillRouter  *watermillMessage.Router
+	outboxForwarder  outbox.Forwarder
+	jobs             []Job
+	echoRouter       *echo.Echo
 }
 
 func New(
-	dbConn *sqlx.DB,
-	redisClient *redis.Client,
-	spreadsheetsService event.SpreadsheetsAPI,
+	repositories Repositories,
+	messageBroker broker.Broker,
+	eventMarshaler cqrs.CommandEventMarshaler,
+	spreadsheetsService sheetsync.SpreadsheetsAPI,
 	receiptsService event.ReceiptsService,
 	fileService event.FileAPI,
 	deadNationAPI event.DeadNationAPI,
+	chaosInjector *chaos.Chaos,
+	authConfig auth.Config,
 ) Service {
-	ticketsRepo := db.NewTicketsRepository(dbConn)
-	showsRepo := db.NewShowsRepository(dbConn)
-	bookingsRepo := db.NewBookingsRepository(dbConn)
 	watermillLogger := log.NewWatermill(log.FromContext(context.Background()))
 
-	var redisPublisher watermillMessage.Publisher
-	redisPublisher = message.NewRedisPublisher(redisClient, watermillLogger)
-	redisPublisher = log.CorrelationPublisherDecorator{Publisher: redisPublisher}
+	var publisher watermillMessage.Publisher
+	publisher = messageBroker.Publisher()
+	if chaosInjector != nil {
+		publisher = chaos.NewPublisher(chaosInjector, publisher, watermillLogger)
+	}
+	publisher = log.CorrelationPublisherDecorator{Publisher: publisher}
 
-	eventBus, err := event.NewEventBus(redisPublisher)
+	eventBus, err := event.NewEventBus(publisher, eventMarshaler)
 	if err != nil {
 		fmt.Println("Error creating Event Bus:", err.Error())
 		panic(err)
 	}
 
 	eventsHandler := event.NewHandler(
-		spreadsheetsService,
 		receiptsService,
-		ticketsRepo,
+		repositories.Tickets,
 		fileService,
 		deadNationAPI,
-		showsRepo,
+		repositories.Shows,
+		repositories.SheetRows,
+		repositories.Reports,
 		eventBus,
 	)
 
-	postgresSubscriber := outbox.NewPostgresSubscriber(dbConn.DB, watermillLogger)
-	eventProcessConfig := event.NewEventProcessConfig(redisClient, watermillLogger)
+	sheetSyncer := sheetsync.NewSyncer(repositories.SheetRows, spreadsheetsService, sheetsync.Config{}, watermillLogger)
+
+	webhookDispatcher := webhook.NewDispatcher(repositories.Webhooks, nil, webhook.Config{})
+
+	pendingMessagesReader, _ := messageBroker.(message.PendingMessagesReader)
+	handlers := message.NewHandlers(repositories.MessageHandlers, pendingMessagesReader, watermillLogger)
+
+	eventProcessConfig := event.NewEventProcessConfig(messageBroker, eventMarshaler, watermillLogger)
 
 	watermillRouter := message.NewWatermillRouter(
-		postgresSubscriber,
-		redisPublisher,
+		repositories.OutboxSubscriber,
+		publisher,
 		eventProcessConfig,
 		eventsHandler,
+		webhookDispatcher,
+		repositories.OrderingLocks,
+		chaosInjector,
+		handlers,
 		watermillLogger,
 	)
 
 	echoRouter := ticketsHttp.NewHttpRouter(
-		eventBus,
 		spreadsheetsService,
-		ticketsRepo,
-		showsRepo,
-		bookingsRepo,
+		repositories.Tickets,
+		repositories.Shows,
+		repositories.Bookings,
+		repositories.Webhooks,
+		sheetSyncer,
+		repositories.Reports,
+		repositories.APIKeys,
+		handlers,
+		repositories.Audit,
+		repositories.Customers,
+		auth.NewAuthenticator(repositories.APIKeys, authConfig),
+		message.NewAsyncAPIDocument(),
 	)
 
+	var outboxForwarder outbox.Forwarder
+	if repositories.NewOutboxForwarder != nil {
+		outboxForwarder = repositories.NewOutboxForwarder(publisher, watermillLogger)
+	}
+
+	// the rows and webhook messages recorded by the handlers are appended (and delivered) by the jobs,
+	// the handlers job keeps the paused handlers in sync with the other instances
+	jobs := append([]Job{sheetSyncer, webhookDispatcher, handlers}, repositories.Jobs...)
+
+	if redisBroker, ok := messageBroker.(*broker.Redis); ok {
+		jobs = append(jobs, redisBroker.NewJanitor(message.Topics(), event.ConsumerGroupPrefix))
+	}
+
 	return Service{
-		dbConn,
+		repositories.InitializeSchema,
 		watermillRouter,
+		outboxForwarder,
+		jobs,
 		echoRouter,
 	}
 }
@@ -91,8 +133,10 @@ func New(
 func (s Service) Run(
 	ctx context.Context,
 ) error {
-	if err := db.InitializeDatabaseSchema(s.db); err != nil {
-		return fmt.Errorf("failed to initialize database schema: %w", err)
+	if s.initializeSchema != nil {
+		if err := s.initializeSchema(); err != nil {
+			return fmt.Errorf("failed to initialize database schema: %w", err)
+		}
 	}
 
 	errgrp, ctx := errgroup.WithContext(ctx)
@@ -101,6 +145,21 @@ func (s Service) Run(
 		return s.watermillRouter.Run(ctx)
 	})
 
+	if s.outboxForwarder != nil {
+		errgrp.Go(func() error {
+			<-s.watermillRouter.Running()
+
+			return s.outboxForwarder.Run(ctx)
+		})
+	}
+
+	for _, job := range s.jobs {
+		job := job
+		errgrp.Go(func() error {
+			return job.Run(ctx)
+		})
+	}
+
 	errgrp.Go(func() error {
 		// we don't want to start HTTP server before Watermill router (so service won't be healthy before it's ready)
 		<-s.watermillRouter.Running()
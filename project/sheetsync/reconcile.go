@@ -0,0 +1,113 @@
+package sheetsync
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"tickets/entities"
+	"tickets/tenant"
+)
+
+// Reconcile compares the rows of the tenant from the context recorded as appended to the sheet with the rows
+// the tenant's sheet reports.
+// Rows are compared by their columns, as the sheet doesn't know the ticket IDs or idempotency keys of the rows.
+// Pending rows are not compared, as they are not expected in the sheet yet, and neither are the rows rejected by the API.
+//
+// The rows whose customer's data was erased are not expected in the sheet either. They may be there with the
+// pseudonym (when they were appended after the erasure), or with the customer's data, which the sheet can't
+// change: those are reported as Erased, so they are removed from the sheet.
+func (s *Syncer) Reconcile(ctx context.Context, sheetName string) (entities.SheetReconciliation, error) {
+	recorded, err := s.repository.GetAppended(ctx, sheetName)
+	if err != nil {
+		return entities.SheetReconciliation{}, fmt.Errorf("failed to get recorded rows: %w", err)
+	}
+
+	rejected, err := s.repository.GetRejected(ctx, sheetName)
+	if err != nil {
+		return entities.SheetReconciliation{}, fmt.Errorf("failed to get rejected rows: %w", err)
+	}
+
+	tenantSheetName := tenant.Name(ctx, sheetName)
+	inSheet, err := s.api.GetRows(ctx, tenantSheetName)
+	if err != nil {
+		return entities.SheetReconciliation{}, fmt.Errorf("failed to get rows of %s: %w", tenantSheetName, err)
+	}
+
+	reconciliation := entities.SheetReconciliation{
+		SheetName:  sheetName,
+		Recorded:   len(recorded),
+		InSheet:    len(inSheet),
+		Missing:    [][]string{},
+		Unexpected: [][]string{},
+		Rejected:   make([][]string, 0, len(rejected)),
+		Erased:     [][]string{},
+	}
+
+	for _, row := range rejected {
+		reconciliation.Rejected = append(reconciliation.Rejected, row.Columns)
+	}
+
+	// the number of times each row is in the sheet, which are matched with the recorded rows one by one
+	sheetRows := make(map[string]int, len(inSheet))
+	for _, row := range inSheet {
+		sheetRows[rowKey(row)]++
+	}
+
+	var erased []entities.SheetRow
+	for _, row := range recorded {
+		key := rowKey(row.Columns)
+		if sheetRows[key] > 0 {
+			sheetRows[key]--
+			continue
+		}
+
+		if row.ErasedAt != nil {
+			// matched with the rows still having the customer's data below
+			erased = append(erased, row)
+			continue
+		}
+
+		reconciliation.Missing = append(reconciliation.Missing, row.Columns)
+	}
+
+	for _, row := range inSheet {
+		key := rowKey(row)
+		if sheetRows[key] == 0 {
+			continue
+		}
+		sheetRows[key]--
+
+		if i := slices.IndexFunc(erased, func(erasedRow entities.SheetRow) bool {
+			return sameExceptPseudonym(erasedRow, row)
+		}); i != -1 {
+			reconciliation.Erased = append(reconciliation.Erased, erased[i].Columns)
+			erased = slices.Delete(erased, i, i+1)
+			continue
+		}
+
+		reconciliation.Unexpected = append(reconciliation.Unexpected, row)
+	}
+
+	return reconciliation, nil
+}
+
+// sameExceptPseudonym returns true if the sheet row has the columns of the erased row,
+// except the columns with the pseudonym, which had the customer's data before the erasure.
+func sameExceptPseudonym(erased entities.SheetRow, sheetRow []string) bool {
+	if len(erased.Columns) != len(sheetRow) {
+		return false
+	}
+
+	for i, column := range erased.Columns {
+		if column != erased.ErasedPseudonym && column != sheetRow[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func rowKey(columns []string) string {
+	return strings.Join(columns, "\x1f")
+}
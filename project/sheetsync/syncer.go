@@ -0,0 +1,218 @@
+// Package sheetsync appends the rows recorded by the event handlers to the sheets.
+// The handlers only record the rows (deduplicated by sheet, ticket and idempotency key),
+// and the Syncer appends the pending rows in batches, so bursts of events don't hit the API rate limits.
+package sheetsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"tickets/entities"
+	"tickets/message/retry"
+	"tickets/tenant"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	appendedRows = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "sheetsync",
+		Name:      "appended_rows_total",
+		Help:      "Number of rows appended to the sheets.",
+	}, []string{"sheet"})
+	flushErrors = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "sheetsync",
+		Name:      "flush_errors_total",
+		Help:      "Number of flushes stopped by an error.",
+	})
+)
+
+type SpreadsheetsAPI interface {
+	AppendRow(ctx context.Context, sheetName string, row []string) error
+	GetRows(ctx context.Context, sheetName string) ([][]string, error)
+}
+
+type Repository interface {
+	// ClaimPending returns up to limit pending rows, from the oldest, and claims them for claimFor,
+	// so other replicas don't append them in the meantime. The claim is committed right away.
+	ClaimPending(ctx context.Context, limit int, claimFor time.Duration) ([]entities.SheetRow, error)
+	MarkAppended(ctx context.Context, row entities.SheetRow) error
+	MarkRejected(ctx context.Context, row entities.SheetRow, rejection string) error
+	// ReleaseClaimed makes the claimed rows pending again, so they are appended by the next flush.
+	ReleaseClaimed(ctx context.Context, rows []entities.SheetRow) error
+	GetAppended(ctx context.Context, sheetName string) ([]entities.SheetRow, error)
+	GetRejected(ctx context.Context, sheetName string) ([]entities.SheetRow, error)
+}
+
+type Config struct {
+	// FlushInterval is the time between flushes of the pending rows.
+	FlushInterval time.Duration
+	// BatchSize is the maximum number of rows appended in one batch.
+	BatchSize int
+	// RowPause is the pause between appended rows, it keeps the rate of the API calls below the limits.
+	RowPause time.Duration
+}
+
+func (c *Config) setDefaults() {
+	if c.FlushInterval == 0 {
+		c.FlushInterval = time.Millisecond * 500
+	}
+	if c.BatchSize == 0 {
+		c.BatchSize = 100
+	}
+	if c.RowPause == 0 {
+		// up to 5 appends per second, well below the limits of the API
+		c.RowPause = time.Millisecond * 200
+	}
+}
+
+// claimFor is how long the claimed rows are not claimed again, it's enough to append all of them.
+func (c Config) claimFor() time.Duration {
+	return c.RowPause*time.Duration(c.BatchSize) + time.Minute
+}
+
+type Syncer struct {
+	repository Repository
+	api        SpreadsheetsAPI
+	config     Config
+	logger     watermill.LoggerAdapter
+}
+
+func NewSyncer(repository Repository, api SpreadsheetsAPI, config Config, logger watermill.LoggerAdapter) *Syncer {
+	if repository == nil {
+		panic("missing repository")
+	}
+	if api == nil {
+		panic("missing api")
+	}
+	if logger == nil {
+		logger = watermill.NopLogger{}
+	}
+
+	config.setDefaults()
+
+	return &Syncer{
+		repository: repository,
+		api:        api,
+		config:     config,
+		logger:     logger,
+	}
+}
+
+// Run flushes the pending rows every FlushInterval until ctx is done.
+func (s *Syncer) Run(ctx context.Context) error {
+	for {
+		wait := s.config.FlushInterval
+
+		flushed, err := s.Flush(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			flushErrors.Inc()
+			s.logger.Error("Sheet rows flush failed", err, watermill.LogFields{"flushed": flushed})
+
+			var retryErr *retry.Error
+			if errors.As(err, &retryErr) && retryErr.RetryAfter > wait {
+				wait = retryErr.RetryAfter
+			}
+		} else if flushed > 0 {
+			s.logger.Debug("Sheet rows flushed", watermill.LogFields{"flushed": flushed})
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(wait):
+		}
+	}
+}
+
+// Flush appends the pending rows in batches, until there are no pending rows or an append fails.
+// It returns the number of appended rows.
+//
+// The rows are claimed before they are appended, and marked as appended one by one, so no transaction is kept open
+// while the API is called. If the process dies after an append but before the row is marked, the row is appended
+// again once its claim expires (Reconcile reports it as unexpected).
+func (s *Syncer) Flush(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		rows, err := s.repository.ClaimPending(ctx, s.config.BatchSize, s.config.claimFor())
+		if err != nil {
+			return total, fmt.Errorf("failed to claim pending rows: %w", err)
+		}
+
+		appended, err := s.appendRows(ctx, rows)
+		total += appended
+		if err != nil {
+			return total, err
+		}
+
+		if len(rows) < s.config.BatchSize {
+			return total, nil
+		}
+	}
+}
+
+// appendRows appends the rows in order, and stops at the first error. The rows after it are released.
+func (s *Syncer) appendRows(ctx context.Context, rows []entities.SheetRow) (int, error) {
+	appended := 0
+	for i, row := range rows {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return appended, s.release(ctx, rows[i:], ctx.Err())
+			case <-time.After(s.config.RowPause):
+			}
+		}
+
+		// every tenant has its own sheets
+		sheetName := tenant.NameFor(row.TenantID, row.SheetName)
+
+		err := s.api.AppendRow(ctx, sheetName, row.Columns)
+		if retry.IsPermanent(err) {
+			// retrying won't help, and the row would block the rows after it: it's reported as rejected by Reconcile
+			s.logger.Error("Skipping sheet row rejected by the API", err, watermill.LogFields{
+				"sheet":     sheetName,
+				"ticket_id": row.TicketID,
+			})
+
+			if err := s.repository.MarkRejected(ctx, row, err.Error()); err != nil {
+				return appended, s.release(ctx, rows[i+1:], err)
+			}
+			continue
+		}
+		if err != nil {
+			err = fmt.Errorf("failed to append row for ticket %s to %s: %w", row.TicketID, sheetName, err)
+			return appended, s.release(ctx, rows[i:], err)
+		}
+
+		if err := s.repository.MarkAppended(ctx, row); err != nil {
+			return appended, s.release(ctx, rows[i+1:], err)
+		}
+
+		appendedRows.WithLabelValues(row.SheetName).Inc()
+		appended++
+	}
+
+	return appended, nil
+}
+
+// release releases the rows that were not appended because of err, and returns err.
+func (s *Syncer) release(ctx context.Context, rows []entities.SheetRow, err error) error {
+	if len(rows) == 0 {
+		return err
+	}
+
+	// the context may be done already, the rows are released anyway
+	releaseErr := s.repository.ReleaseClaimed(context.WithoutCancel(ctx), rows)
+	if releaseErr != nil {
+		releaseErr = fmt.Errorf("failed to release claimed rows: %w", releaseErr)
+	}
+
+	return errors.Join(err, releaseErr)
+}
@@ -0,0 +1,259 @@
+package sheetsync_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"tickets/db/memory"
+	"tickets/entities"
+	"tickets/message/event"
+	"tickets/message/retry"
+	"tickets/sheetsync"
+	"tickets/tenant"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/pubsub/gochannel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncer_Flush(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewSheetRowsRepository(memory.NewDatabase())
+	api := &spreadsheetsAPIStub{}
+
+	syncer := sheetsync.NewSyncer(repo, api, sheetsync.Config{BatchSize: 2, RowPause: time.Millisecond}, nil)
+
+	for _, ticketID := range []string{"1", "2", "3"} {
+		added, err := repo.Add(ctx, sheetRow(ticketID))
+		require.NoError(t, err)
+		require.True(t, added)
+	}
+
+	// a redelivered event records the same row
+	added, err := repo.Add(ctx, sheetRow("1"))
+	require.NoError(t, err)
+	assert.False(t, added)
+
+	api.failAfter(1, errors.New("unavailable"))
+
+	flushed, err := syncer.Flush(ctx)
+	require.Error(t, err)
+	assert.Equal(t, 1, flushed)
+
+	// the rows appended before the error are not appended again
+	api.failAfter(0, nil)
+
+	flushed, err = syncer.Flush(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, flushed)
+
+	assert.Equal(t, [][]string{{"1", "email"}, {"2", "email"}, {"3", "email"}}, api.rows["sheet"])
+
+	flushed, err = syncer.Flush(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, flushed)
+}
+
+func TestSyncer_Flush_skips_rows_rejected_by_the_api(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewSheetRowsRepository(memory.NewDatabase())
+	api := &spreadsheetsAPIStub{}
+
+	syncer := sheetsync.NewSyncer(repo, api, sheetsync.Config{RowPause: time.Millisecond}, nil)
+
+	for _, ticketID := range []string{"1", "2"} {
+		_, err := repo.Add(ctx, sheetRow(ticketID))
+		require.NoError(t, err)
+	}
+
+	api.failAfter(0, retry.Permanent(errors.New("bad request")))
+	api.failOnce = true
+
+	flushed, err := syncer.Flush(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, flushed)
+	assert.Equal(t, [][]string{{"2", "email"}}, api.rows["sheet"])
+
+	// the rejected row is not appended again
+	flushed, err = syncer.Flush(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, flushed)
+
+	reconciliation, err := syncer.Reconcile(ctx, "sheet")
+	require.NoError(t, err)
+	assert.True(t, reconciliation.Consistent(), "the rejected row is not expected in the sheet")
+	assert.Equal(t, 1, reconciliation.Recorded)
+	assert.Equal(t, [][]string{{"1", "email"}}, reconciliation.Rejected)
+}
+
+func TestSyncer_Reconcile(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewSheetRowsRepository(memory.NewDatabase())
+	api := &spreadsheetsAPIStub{}
+
+	syncer := sheetsync.NewSyncer(repo, api, sheetsync.Config{RowPause: time.Millisecond}, nil)
+
+	for _, ticketID := range []string{"1", "2"} {
+		_, err := repo.Add(ctx, sheetRow(ticketID))
+		require.NoError(t, err)
+	}
+
+	_, err := syncer.Flush(ctx)
+	require.NoError(t, err)
+
+	reconciliation, err := syncer.Reconcile(ctx, "sheet")
+	require.NoError(t, err)
+	assert.True(t, reconciliation.Consistent())
+	assert.Equal(t, 2, reconciliation.Recorded)
+
+	// the row was appended twice (for example, the process died before marking it as appended),
+	// and someone removed another one from the sheet
+	api.rows["sheet"] = [][]string{{"1", "email"}, {"1", "email"}}
+
+	// pending rows are not expected in the sheet yet
+	_, err = repo.Add(ctx, sheetRow("3"))
+	require.NoError(t, err)
+
+	reconciliation, err = syncer.Reconcile(ctx, "sheet")
+	require.NoError(t, err)
+	assert.False(t, reconciliation.Consistent())
+	assert.Equal(t, [][]string{{"2", "email"}}, reconciliation.Missing)
+	assert.Equal(t, [][]string{{"1", "email"}}, reconciliation.Unexpected)
+}
+
+func TestSyncer_Reconcile_sheets_of_the_tenant(t *testing.T) {
+	ctx := context.Background()
+	acmeCtx := tenant.WithID(ctx, "acme")
+	repo := memory.NewSheetRowsRepository(memory.NewDatabase())
+	api := &spreadsheetsAPIStub{}
+
+	syncer := sheetsync.NewSyncer(repo, api, sheetsync.Config{RowPause: time.Millisecond}, nil)
+
+	_, err := repo.Add(ctx, sheetRow("1"))
+	require.NoError(t, err)
+	_, err = repo.Add(acmeCtx, sheetRow("2"))
+	require.NoError(t, err)
+
+	// the rows of all tenants are appended, every tenant to its own sheet
+	flushed, err := syncer.Flush(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, flushed)
+
+	assert.Equal(t, [][]string{{"1", "email"}}, api.rows["sheet"])
+	assert.Equal(t, [][]string{{"2", "email"}}, api.rows["acme-sheet"])
+
+	reconciliation, err := syncer.Reconcile(acmeCtx, "sheet")
+	require.NoError(t, err)
+	assert.True(t, reconciliation.Consistent())
+	assert.Equal(t, "sheet", reconciliation.SheetName)
+	assert.Equal(t, 1, reconciliation.Recorded)
+	assert.Equal(t, 1, reconciliation.InSheet)
+
+	reconciliation, err = syncer.Reconcile(ctx, "sheet")
+	require.NoError(t, err)
+	assert.True(t, reconciliation.Consistent())
+	assert.Equal(t, 1, reconciliation.Recorded)
+}
+
+func TestSyncer_Reconcile_erased_rows(t *testing.T) {
+	ctx := context.Background()
+	database := memory.NewDatabase()
+	repo := memory.NewSheetRowsRepository(database)
+	customers := memory.NewCustomersRepository(
+		database,
+		gochannel.NewGoChannel(gochannel.Config{}, watermill.NopLogger{}),
+		event.NewMarshaler(event.FormatJSON),
+	)
+	api := &spreadsheetsAPIStub{}
+
+	syncer := sheetsync.NewSyncer(repo, api, sheetsync.Config{RowPause: time.Millisecond}, nil)
+
+	for _, ticketID := range []string{"1", "2", "3"} {
+		err := memory.NewTicketsRepository(database).Add(ctx, entities.Ticket{TicketID: ticketID, CustomerEmail: "email"})
+		require.NoError(t, err)
+		_, err = repo.Add(ctx, sheetRow(ticketID))
+		require.NoError(t, err)
+	}
+
+	// the first two rows are appended before the erasure, and the last one after it
+	api.failAfter(2, errors.New("unavailable"))
+	_, err := syncer.Flush(ctx)
+	require.Error(t, err)
+	api.failAfter(0, nil)
+
+	erasure, err := customers.Erase(ctx, "email", "pseudonym")
+	require.NoError(t, err)
+	require.NoError(t, repo.MarkErased(ctx, erasure.TicketIDs, erasure.Pseudonym))
+
+	_, err = syncer.Flush(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"1", "email"}, {"2", "email"}, {"3", "pseudonym"}}, api.rows["sheet"])
+
+	// someone removed the first row from the sheet already
+	api.rows["sheet"] = api.rows["sheet"][1:]
+
+	reconciliation, err := syncer.Reconcile(ctx, "sheet")
+	require.NoError(t, err)
+	assert.True(t, reconciliation.Consistent())
+	assert.Equal(t, [][]string{{"2", "pseudonym"}}, reconciliation.Erased)
+}
+
+func sheetRow(ticketID string) entities.SheetRow {
+	return entities.SheetRow{
+		SheetName:      "sheet",
+		TicketID:       ticketID,
+		IdempotencyKey: "key",
+		Columns:        []string{ticketID, "email"},
+	}
+}
+
+type spreadsheetsAPIStub struct {
+	lock sync.Mutex
+	rows map[string][][]string
+
+	// when err is set, appends fail with it after the number of successful appends
+	successes int
+	err       error
+	failOnce  bool
+}
+
+func (s *spreadsheetsAPIStub) failAfter(successes int, err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.successes = successes
+	s.err = err
+}
+
+func (s *spreadsheetsAPIStub) AppendRow(ctx context.Context, sheetName string, row []string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.err != nil {
+		if s.successes == 0 {
+			err := s.err
+			if s.failOnce {
+				s.err = nil
+			}
+			return err
+		}
+		s.successes--
+	}
+
+	if s.rows == nil {
+		s.rows = make(map[string][][]string)
+	}
+	s.rows[sheetName] = append(s.rows[sheetName], row)
+
+	return nil
+}
+
+func (s *spreadsheetsAPIStub) GetRows(ctx context.Context, sheetName string) ([][]string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return append([][]string(nil), s.rows[sheetName]...), nil
+}
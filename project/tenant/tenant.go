@@ -0,0 +1,67 @@
+// Package tenant identifies the promoters (or venues) sharing the service. Their shows, bookings and tickets
+// are isolated: the repositories read and write only the data of the tenant from the context.
+//
+// HTTP requests get the tenant of the authenticated caller, and the messages the tenant of the request
+// (or message) they were published in, see event.TenantIDMetadataKey.
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// Default is the tenant of the callers and messages without a tenant, and of the data stored before
+// the tenants were introduced.
+const Default = "default"
+
+var ErrInvalidID = errors.New("invalid tenant ID")
+
+// the IDs are used in the names of sheets and files, so they are kept simple
+var idPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,63}$`)
+
+// Validate checks that the ID has only lowercase letters, digits and dashes (up to 64 characters).
+func Validate(id string) error {
+	if !idPattern.MatchString(id) {
+		return fmt.Errorf("%w: %q must have only lowercase letters, digits and dashes", ErrInvalidID, id)
+	}
+
+	return nil
+}
+
+type contextKey struct{}
+
+// WithID returns the context of the tenant. Empty ID means the Default tenant.
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the tenant of the context, or Default if there is none.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	if id == "" {
+		return Default
+	}
+
+	return id
+}
+
+// Name returns the name of the sheet or file of the tenant from the context, see NameFor.
+func Name(ctx context.Context, name string) string {
+	return NameFor(FromContext(ctx), name)
+}
+
+// NameFor returns the name of the tenant's sheet or file, prefixed with the tenant, like "acme-tickets-to-print".
+// The sheets and files of the Default tenant keep their names. The file names are file IDs in the files API,
+// so they are prefixed the same way instead of being put in the tenant's directory.
+//
+// The prefixed names are meant for the external APIs only: the IDs may contain dashes, so the names can't be
+// split back into the tenant and the name.
+func NameFor(id string, name string) string {
+	if id == Default {
+		return name
+	}
+
+	return id + "-" + name
+}
@@ -0,0 +1,99 @@
+package tests_test
+
+import (
+	"net/http"
+	"testing"
+	"tickets/api"
+	"tickets/entities"
+	"tickets/message/broker"
+	"tickets/message/event"
+	"tickets/service"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type handlerStatus struct {
+	Name            string `json:"name"`
+	Topic           string `json:"topic"`
+	ConsumerGroup   string `json:"consumer_group"`
+	Paused          bool   `json:"paused"`
+	PendingMessages *int64 `json:"pending_messages"`
+}
+
+func TestComponent_admin_handlers(t *testing.T) {
+	eventMarshaler := event.NewMarshaler(event.FormatJSON)
+
+	messageBroker := broker.NewGoChannel(watermill.NopLogger{})
+	defer messageBroker.Close()
+
+	receiptsService := &api.ReceiptsServiceMock{IssuedReceipts: map[string]entities.IssueReceiptRequest{}}
+
+	runService(t, service.New(
+		service.NewInMemoryRepositories(eventMarshaler),
+		messageBroker,
+		eventMarshaler,
+		&api.SpreadsheetsAPIMock{},
+		receiptsService,
+		&api.FileServiceMock{},
+		&api.DeadNationMock{},
+		nil,
+		testAuthConfig,
+	))
+
+	issueReceipt := func(t *testing.T) handlerStatus {
+		var handlers []handlerStatus
+		require.Equal(t, http.StatusOK, getJSON(t, "/admin/handlers", &handlers))
+
+		handler, ok := lo.Find(handlers, func(h handlerStatus) bool {
+			return h.Name == "IssueReceipt"
+		})
+		require.True(t, ok, "IssueReceipt handler not listed")
+
+		return handler
+	}
+
+	t.Run("list", func(t *testing.T) {
+		handler := issueReceipt(t)
+		assert.Equal(t, "TicketBookingConfirmed", handler.Topic)
+		assert.Equal(t, "svc-tickets.IssueReceipt", handler.ConsumerGroup)
+		assert.False(t, handler.Paused)
+		// Go channels don't keep the pending messages
+		assert.Nil(t, handler.PendingMessages)
+
+		supportToken := signToken(operatorsKey, "support@example.com", time.Hour, entities.RoleSupport)
+		assert.Equal(t, http.StatusForbidden, request(t, http.MethodGet, "/admin/handlers", echo.HeaderAuthorization, "Bearer "+supportToken, nil))
+	})
+
+	t.Run("unknown handler", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, request(t, http.MethodPost, "/admin/handlers/Unknown/pause", echo.HeaderAuthorization, "Bearer "+adminToken, nil))
+	})
+
+	t.Run("pause and resume", func(t *testing.T) {
+		require.Equal(t, http.StatusNoContent, request(t, http.MethodPost, "/admin/handlers/IssueReceipt/pause", echo.HeaderAuthorization, "Bearer "+adminToken, nil))
+		assert.True(t, issueReceipt(t).Paused)
+
+		ticket := TicketStatus{
+			TicketID:  uuid.NewString(),
+			Status:    "confirmed",
+			Price:     Money{Amount: "50.30", Currency: "GBP"},
+			Email:     "email@example.com",
+			BookingID: uuid.NewString(),
+		}
+		sendTicketsStatus(t, TicketsStatusRequest{Tickets: []TicketStatus{ticket}}, uuid.NewString())
+
+		assert.Never(t, func() bool {
+			return len(receiptsService.Issued()) > 0
+		}, time.Millisecond*500, time.Millisecond*50, "receipt issued by the paused handler")
+
+		require.Equal(t, http.StatusNoContent, request(t, http.MethodPost, "/admin/handlers/IssueReceipt/resume", echo.HeaderAuthorization, "Bearer "+adminToken, nil))
+		assert.False(t, issueReceipt(t).Paused)
+
+		assertReceiptForTicketIssued(t, receiptsService, ticket)
+	})
+}
@@ -0,0 +1,154 @@
+package tests_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"tickets/api"
+	"tickets/auth"
+	"tickets/entities"
+	"tickets/message/broker"
+	"tickets/message/event"
+	"tickets/service"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComponent_audit(t *testing.T) {
+	eventMarshaler := event.NewMarshaler(event.FormatJSON)
+
+	messageBroker := broker.NewGoChannel(watermill.NopLogger{})
+	defer messageBroker.Close()
+
+	runService(t, service.New(
+		service.NewInMemoryRepositories(eventMarshaler),
+		messageBroker,
+		eventMarshaler,
+		&api.SpreadsheetsAPIMock{},
+		&api.ReceiptsServiceMock{IssuedReceipts: map[string]entities.IssueReceiptRequest{}},
+		&api.FileServiceMock{},
+		&api.DeadNationMock{},
+		nil,
+		testAuthConfig,
+	))
+
+	admin := "Bearer " + signTenantToken("auditors", entities.RoleAdmin)
+
+	var show struct {
+		ShowID uuid.UUID `json:"show_id"`
+	}
+	require.Equal(t, http.StatusCreated, requestJSON(t, http.MethodPost, "/shows", admin, map[string]any{
+		"dead_nation_id":    uuid.New(),
+		"number_of_tickets": 2,
+		"start_time":        time.Now().Add(time.Hour * 24),
+		"title":             "Audited show",
+		"venue":             "Audited venue",
+	}, &show))
+
+	booking := map[string]any{
+		"show_id":           show.ShowID,
+		"number_of_tickets": 2,
+		"customer_email":    "audited@example.com",
+	}
+	var bookingResp struct {
+		BookingID uuid.UUID `json:"booking_id"`
+	}
+	require.Equal(t, http.StatusCreated, requestJSON(t, http.MethodPost, "/book-tickets", admin, booking, &bookingResp))
+	// the failed requests change nothing, so they are not recorded
+	require.Equal(t, http.StatusBadRequest, requestJSON(t, http.MethodPost, "/book-tickets", admin, booking, nil))
+
+	t.Run("records of the show", func(t *testing.T) {
+		var records []entities.AuditRecord
+		require.Equal(t, http.StatusOK, requestJSON(t, http.MethodGet, "/audit-records?target_id="+show.ShowID.String(), admin, nil, &records))
+		require.Len(t, records, 2)
+
+		assert.Equal(t, "PostBookTickets", records[0].Action)
+		assert.Equal(t, "operator@auditors", records[0].Actor)
+		assert.ElementsMatch(t, []string{bookingResp.BookingID.String(), show.ShowID.String()}, records[0].TargetIDs)
+		assert.NotEmpty(t, records[0].CorrelationID)
+		assert.Contains(t, records[0].Summary, `"customer_email":"[redacted]"`)
+		assert.NotContains(t, records[0].Summary, "audited@example.com")
+
+		assert.Equal(t, "PostShows", records[1].Action)
+		assert.Contains(t, records[1].Summary, "Audited show")
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		var records []entities.AuditRecord
+		require.Equal(t, http.StatusOK, requestJSON(t, http.MethodGet, "/audit-records?action=PostShows", admin, nil, &records))
+		assert.Len(t, records, 1)
+
+		require.Equal(t, http.StatusOK, requestJSON(t, http.MethodGet, "/audit-records?actor=someone-else", admin, nil, &records))
+		assert.Empty(t, records)
+
+		from := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+		require.Equal(t, http.StatusOK, requestJSON(t, http.MethodGet, "/audit-records?from="+from, admin, nil, &records))
+		assert.Empty(t, records)
+
+		require.Equal(t, http.StatusOK, requestJSON(t, http.MethodGet, "/audit-records?limit=1", admin, nil, &records))
+		assert.Len(t, records, 1)
+
+		// the records of the other tenants are not visible
+		require.Equal(t, http.StatusOK, requestJSON(t, http.MethodGet, "/audit-records?target_id="+show.ShowID.String(), "Bearer "+adminToken, nil, &records))
+		assert.Empty(t, records)
+	})
+
+	t.Run("too large body", func(t *testing.T) {
+		var records []entities.AuditRecord
+		require.Equal(t, http.StatusOK, requestJSON(t, http.MethodGet, "/audit-records?action=PostShows", admin, nil, &records))
+		require.Len(t, records, 1)
+
+		require.Equal(t, http.StatusRequestEntityTooLarge, requestJSON(t, http.MethodPost, "/shows", admin, map[string]any{
+			"dead_nation_id":    uuid.New(),
+			"number_of_tickets": 2,
+			"start_time":        time.Now().Add(time.Hour * 24),
+			"title":             strings.Repeat("a", 2<<20),
+			"venue":             "Audited venue",
+		}, nil))
+
+		require.Equal(t, http.StatusOK, requestJSON(t, http.MethodGet, "/audit-records?action=PostShows", admin, nil, &records))
+		assert.Len(t, records, 1)
+	})
+
+	t.Run("published changes", func(t *testing.T) {
+		var gatewayKey struct {
+			ID  string `json:"id"`
+			Key string `json:"key"`
+		}
+		require.Equal(t, http.StatusCreated, requestJSON(t, http.MethodPost, "/api-keys", admin, map[string]any{
+			"name": "auditors gateway",
+			"role": "gateway",
+		}, &gatewayKey))
+
+		ticket := TicketStatus{
+			TicketID:  uuid.NewString(),
+			Status:    "confirmed",
+			Price:     Money{Amount: "30.00", Currency: "EUR"},
+			Email:     "audited@example.com",
+			BookingID: bookingResp.BookingID.String(),
+		}
+		status := request(t, http.MethodPost, "/tickets-status", auth.APIKeyHeader, gatewayKey.Key, TicketsStatusRequest{
+			Tickets: []TicketStatus{ticket},
+		})
+		require.Equal(t, http.StatusOK, status)
+
+		var records []entities.AuditRecord
+		require.Equal(t, http.StatusOK, requestJSON(t, http.MethodGet, "/audit-records?target_id="+ticket.TicketID, admin, nil, &records))
+		require.Len(t, records, 1)
+		assert.Equal(t, "PostTicketsStatus", records[0].Action)
+		assert.Equal(t, gatewayKey.ID, records[0].Actor)
+		assert.NotContains(t, records[0].Summary, "audited@example.com")
+
+		// the key was created by the tenant's admin
+		require.Equal(t, http.StatusOK, requestJSON(t, http.MethodGet, "/audit-records?action=PostAPIKeys&target_id="+gatewayKey.ID, admin, nil, &records))
+		assert.Len(t, records, 1)
+
+		require.Equal(t, http.StatusNoContent, requestJSON(t, http.MethodDelete, "/api-keys/"+gatewayKey.ID, admin, nil, nil))
+		require.Equal(t, http.StatusOK, requestJSON(t, http.MethodGet, "/audit-records?action=DeleteAPIKey&target_id="+gatewayKey.ID, admin, nil, &records))
+		assert.Len(t, records, 1)
+	})
+}
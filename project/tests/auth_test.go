@@ -0,0 +1,290 @@
+package tests_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"tickets/api"
+	"tickets/auth"
+	"tickets/entities"
+	"tickets/message/broker"
+	"tickets/message/event"
+	"tickets/service"
+	"tickets/webhook"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// operatorsKey signs the operators' tokens in the tests, it's generated for each test run.
+var operatorsKey = generateKey()
+
+var testAuthConfig = auth.Config{
+	JWTPublicKey: &operatorsKey.PublicKey,
+	// the previous secret is still accepted while it's rotated
+	GatewaySecrets: []string{gatewaySecret, "previous-gateway-secret"},
+}
+
+const gatewaySecret = "gateway-secret"
+
+var adminToken = signToken(operatorsKey, "admin@example.com", time.Hour, entities.RoleAdmin)
+
+func generateKey() *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+
+	return key
+}
+
+// signRequest signs the request like the gateway does.
+func signRequest(req *http.Request, secret string, timestamp time.Time, body []byte) {
+	unixTime := strconv.FormatInt(timestamp.Unix(), 10)
+
+	req.Header.Set(webhook.HeaderTimestamp, unixTime)
+	req.Header.Set(webhook.HeaderSignature, webhook.Sign(secret, unixTime, body))
+}
+
+func signToken(key *rsa.PrivateKey, subject string, expiresIn time.Duration, roles ...entities.Role) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"sub":   subject,
+		"exp":   time.Now().Add(expiresIn).Unix(),
+		"roles": roles,
+	}).SignedString(key)
+	if err != nil {
+		panic(err)
+	}
+
+	return token
+}
+
+func TestComponent_auth(t *testing.T) {
+	eventMarshaler := event.NewMarshaler(event.FormatJSON)
+
+	messageBroker := broker.NewGoChannel(watermill.NopLogger{})
+	defer messageBroker.Close()
+
+	runService(t, service.New(
+		service.NewInMemoryRepositories(eventMarshaler),
+		messageBroker,
+		eventMarshaler,
+		&api.SpreadsheetsAPIMock{},
+		&api.ReceiptsServiceMock{IssuedReceipts: map[string]entities.IssueReceiptRequest{}},
+		&api.FileServiceMock{},
+		&api.DeadNationMock{},
+		nil,
+		testAuthConfig,
+	))
+
+	promoterToken := signToken(operatorsKey, "promoter@example.com", time.Hour, entities.RolePromoter)
+	supportToken := signToken(operatorsKey, "support@example.com", time.Hour, entities.RoleSupport)
+
+	t.Run("operators", func(t *testing.T) {
+		testCases := []struct {
+			Name           string
+			Header         string
+			Value          string
+			Path           string
+			ExpectedStatus int
+		}{
+			{"no credentials", "", "", "/tickets", http.StatusUnauthorized},
+			{"support", echo.HeaderAuthorization, "Bearer " + supportToken, "/tickets", http.StatusOK},
+			{"missing role", echo.HeaderAuthorization, "Bearer " + promoterToken, "/tickets", http.StatusForbidden},
+			{"promoter", echo.HeaderAuthorization, "Bearer " + promoterToken, "/reports/daily", http.StatusOK},
+			{"admin", echo.HeaderAuthorization, "Bearer " + adminToken, "/api-keys", http.StatusOK},
+			{
+				"expired token",
+				echo.HeaderAuthorization,
+				"Bearer " + signToken(operatorsKey, "support@example.com", -time.Minute, entities.RoleSupport),
+				"/tickets",
+				http.StatusUnauthorized,
+			},
+			{
+				"token signed with another key",
+				echo.HeaderAuthorization,
+				"Bearer " + signToken(generateKey(), "support@example.com", time.Hour, entities.RoleSupport),
+				"/tickets",
+				http.StatusUnauthorized,
+			},
+			{"unknown API key", auth.APIKeyHeader, "tk_unknown", "/tickets", http.StatusUnauthorized},
+			{"health", "", "", "/health", http.StatusOK},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.Name, func(t *testing.T) {
+				assert.Equal(t, tc.ExpectedStatus, request(t, http.MethodGet, tc.Path, tc.Header, tc.Value, nil))
+			})
+		}
+	})
+
+	t.Run("api keys", func(t *testing.T) {
+		var created struct {
+			ID  uuid.UUID `json:"id"`
+			Key string    `json:"key"`
+		}
+		postJSON(t, "/api-keys", map[string]any{"name": "gateway", "role": "gateway"}, &created)
+		require.NotEmpty(t, created.Key)
+
+		status := request(t, http.MethodPost, "/tickets-status", auth.APIKeyHeader, created.Key, TicketsStatusRequest{
+			Tickets: []TicketStatus{{
+				TicketID: uuid.NewString(),
+				Status:   "confirmed",
+				Price:    Money{Amount: "10.00", Currency: "EUR"},
+				Email:    "email@example.com",
+			}},
+		})
+		assert.Equal(t, http.StatusOK, status)
+
+		assert.Equal(t, http.StatusForbidden, request(t, http.MethodGet, "/tickets", auth.APIKeyHeader, created.Key, nil))
+
+		var apiKeys []map[string]any
+		require.Equal(t, http.StatusOK, getJSON(t, "/api-keys", &apiKeys))
+		require.Len(t, apiKeys, 1)
+		assert.Equal(t, created.ID.String(), apiKeys[0]["id"])
+		assert.NotContains(t, apiKeys[0], "key")
+		assert.NotContains(t, apiKeys[0], "key_hash")
+
+		assert.Equal(t, http.StatusForbidden, request(t, http.MethodDelete, "/api-keys/"+created.ID.String(), echo.HeaderAuthorization, "Bearer "+supportToken, nil))
+		assert.Equal(t, http.StatusNoContent, request(t, http.MethodDelete, "/api-keys/"+created.ID.String(), echo.HeaderAuthorization, "Bearer "+adminToken, nil))
+
+		assert.Equal(t, http.StatusUnauthorized, request(t, http.MethodPost, "/tickets-status", auth.APIKeyHeader, created.Key, TicketsStatusRequest{}))
+	})
+
+	t.Run("gateway signatures", func(t *testing.T) {
+		payload, err := json.Marshal(TicketsStatusRequest{})
+		require.NoError(t, err)
+
+		testCases := []struct {
+			Name           string
+			Sign           func(req *http.Request)
+			ExpectedStatus int
+		}{
+			{
+				Name: "valid",
+				Sign: func(req *http.Request) {
+					signRequest(req, gatewaySecret, time.Now(), payload)
+				},
+				ExpectedStatus: http.StatusOK,
+			},
+			{
+				Name: "previous secret",
+				Sign: func(req *http.Request) {
+					signRequest(req, "previous-gateway-secret", time.Now(), payload)
+				},
+				ExpectedStatus: http.StatusOK,
+			},
+			{
+				Name: "signed with the old and the new secret",
+				Sign: func(req *http.Request) {
+					signRequest(req, "old-gateway-secret", time.Now(), payload)
+					unixTime := req.Header.Get(webhook.HeaderTimestamp)
+					req.Header.Set(
+						webhook.HeaderSignature,
+						req.Header.Get(webhook.HeaderSignature)+", "+webhook.Sign(gatewaySecret, unixTime, payload),
+					)
+				},
+				ExpectedStatus: http.StatusOK,
+			},
+			{
+				Name:           "missing",
+				Sign:           func(req *http.Request) {},
+				ExpectedStatus: http.StatusUnauthorized,
+			},
+			{
+				Name: "unknown secret",
+				Sign: func(req *http.Request) {
+					signRequest(req, "unknown-secret", time.Now(), payload)
+				},
+				ExpectedStatus: http.StatusUnauthorized,
+			},
+			{
+				Name: "replayed",
+				Sign: func(req *http.Request) {
+					signRequest(req, gatewaySecret, time.Now().Add(-time.Hour), payload)
+				},
+				ExpectedStatus: http.StatusUnauthorized,
+			},
+			{
+				Name: "different body",
+				Sign: func(req *http.Request) {
+					signRequest(req, gatewaySecret, time.Now(), []byte(`{"tickets":[]}`))
+				},
+				ExpectedStatus: http.StatusUnauthorized,
+			},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.Name, func(t *testing.T) {
+				req, err := http.NewRequest(http.MethodPost, "http://localhost:8080/tickets-status", bytes.NewBuffer(payload))
+				require.NoError(t, err)
+
+				req.Header.Set(echo.HeaderContentType, "application/json")
+				req.Header.Set(echo.HeaderAuthorization, "Bearer "+adminToken)
+				req.Header.Set("Idempotency-Key", uuid.NewString())
+				tc.Sign(req)
+
+				resp, err := http.DefaultClient.Do(req)
+				require.NoError(t, err)
+				defer resp.Body.Close()
+
+				assert.Equal(t, tc.ExpectedStatus, resp.StatusCode)
+			})
+		}
+
+		resp, err := http.Get("http://localhost:8080/metrics")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		metrics, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		for _, result := range []string{"valid", "missing", "invalid", "expired"} {
+			assert.True(t, strings.Contains(string(metrics), `auth_signature_verifications_total{result="`+result+`"}`), "missing metric of %s signatures", result)
+		}
+	})
+
+	t.Run("invalid api key role", func(t *testing.T) {
+		status := request(t, http.MethodPost, "/api-keys", echo.HeaderAuthorization, "Bearer "+adminToken, map[string]any{"name": "gateway", "role": "root"})
+		assert.Equal(t, http.StatusUnprocessableEntity, status)
+	})
+}
+
+// request sends the request with the credentials header, and returns the response status code.
+func request(t *testing.T, method, path, header, value string, body any) int {
+	t.Helper()
+
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		require.NoError(t, err)
+	}
+
+	httpReq, err := http.NewRequest(method, "http://localhost:8080"+path, bytes.NewBuffer(payload))
+	require.NoError(t, err)
+
+	httpReq.Header.Set(echo.HeaderContentType, "application/json")
+	httpReq.Header.Set("Idempotency-Key", uuid.NewString())
+	signRequest(httpReq, gatewaySecret, time.Now(), payload)
+	if header != "" {
+		httpReq.Header.Set(header, value)
+	}
+
+	resp, err := http.DefaultClient.Do(httpReq)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	return resp.StatusCode
+}
@@ -0,0 +1,150 @@
+package tests_test
+
+import (
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"testing"
+	"tickets/api"
+	"tickets/api/fakegateway"
+	"tickets/chaos"
+	"tickets/message/broker"
+	"tickets/message/event"
+	"tickets/service"
+	"time"
+
+	"github.com/ThreeDotsLabs/go-event-driven/common/clients"
+	"github.com/ThreeDotsLabs/go-event-driven/common/clients/dead_nation"
+	"github.com/ThreeDotsLabs/go-event-driven/common/clients/receipts"
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestComponent_Chaos runs the ticket flow with failed, delayed, duplicated and reordered messages and API calls,
+// and checks that every ticket still ends up with exactly one receipt, one file and one sheet row.
+// Set CHAOS_SEED to reproduce a failed run.
+func TestComponent_Chaos(t *testing.T) {
+	seed := time.Now().UnixNano()
+	if envSeed := os.Getenv("CHAOS_SEED"); envSeed != "" {
+		var err error
+		seed, err = strconv.ParseInt(envSeed, 10, 64)
+		require.NoError(t, err)
+	}
+	t.Logf("chaos seed: %d", seed)
+
+	chaosInjector, err := chaos.New(chaos.Config{
+		Seed:          seed,
+		FailureRate:   0.1,
+		LostAckRate:   0.1,
+		DelayRate:     0.2,
+		MaxDelay:      time.Millisecond * 20,
+		DuplicateRate: 0.2,
+		ReorderRate:   0.2,
+	})
+	require.NoError(t, err)
+
+	// the spreadsheets API is not idempotent: duplicated calls and lost responses would add duplicated rows,
+	// so it only gets failures that happen before the row is appended
+	spreadsheetsChaos, err := chaos.New(chaos.Config{
+		Seed:        seed,
+		FailureRate: 0.1,
+		DelayRate:   0.2,
+		MaxDelay:    time.Millisecond * 20,
+	})
+	require.NoError(t, err)
+
+	gateway := fakegateway.New()
+	defer gateway.Close()
+
+	apiClients, err := clients.NewClients(gateway.URL(), nil)
+	require.NoError(t, err)
+
+	eventMarshaler := event.NewMarshaler(event.FormatJSON)
+
+	messageBroker := broker.NewGoChannel(watermill.NopLogger{})
+	defer messageBroker.Close()
+
+	runService(t, service.New(
+		service.NewInMemoryRepositories(eventMarshaler),
+		messageBroker,
+		eventMarshaler,
+		chaos.NewSpreadsheetsAPI(spreadsheetsChaos, api.NewSpreadsheetsAPIClient(apiClients)),
+		chaos.NewReceiptsService(chaosInjector, api.NewReceiptsServiceClient(apiClients)),
+		chaos.NewFileAPI(chaosInjector, api.NewFileAPIClient(apiClients)),
+		chaos.NewDeadNationAPI(chaosInjector, api.NewDeadNationClient(apiClients)),
+		chaosInjector,
+		testAuthConfig,
+	))
+
+	var tickets []TicketStatus
+	for i := 0; i < 2; i++ {
+		request := TicketsStatusRequest{}
+		for j := 0; j < 3; j++ {
+			request.Tickets = append(request.Tickets, TicketStatus{
+				TicketID: uuid.NewString(),
+				Status:   "confirmed",
+				Price: Money{
+					Amount:   strconv.Itoa(10*i + j + 1),
+					Currency: "EUR",
+				},
+				Email:     "email@example.com",
+				BookingID: uuid.NewString(),
+			})
+		}
+		tickets = append(tickets, request.Tickets...)
+
+		// publishing fails under chaos as well, so the request is retried like a real client would do,
+		// and it's sent once more after it succeeded
+		idempotencyKey := uuid.NewString()
+		require.Eventually(t, func() bool {
+			return trySendTicketsStatus(t, request, idempotencyKey) == http.StatusOK
+		}, 10*time.Second, 10*time.Millisecond)
+		trySendTicketsStatus(t, request, idempotencyKey)
+	}
+
+	assert.EventuallyWithT(t, func(t *assert.CollectT) {
+		issuedReceipts := gateway.Receipts()
+		rows := gateway.SheetRows("tickets-to-print")
+
+		for _, ticket := range tickets {
+			receiptsCount := countFunc(issuedReceipts, func(r receipts.Receipt) bool {
+				return r.TicketId == ticket.TicketID
+			})
+			assert.Equal(t, 1, receiptsCount, "receipts of ticket %s", ticket.TicketID)
+
+			_, ok := gateway.File(ticket.TicketID + "-ticket.html")
+			assert.True(t, ok, "file of ticket %s not found", ticket.TicketID)
+
+			rowsCount := countFunc(rows, func(row []string) bool {
+				return slices.Contains(row, ticket.TicketID)
+			})
+			assert.Equal(t, 1, rowsCount, "rows of ticket %s", ticket.TicketID)
+		}
+
+		assert.Len(t, issuedReceipts, len(tickets))
+		assert.Len(t, rows, len(tickets))
+	}, time.Minute, 100*time.Millisecond)
+
+	showID := createShow(t, uuid.New())
+	bookingID := bookTickets(t, showID, 2)
+
+	assert.EventuallyWithT(t, func(t *assert.CollectT) {
+		assert.True(t, slices.ContainsFunc(gateway.DeadNationBookings(), func(b dead_nation.PostTicketBookingRequest) bool {
+			return b.BookingId == bookingID
+		}), "booking not found in dead nation")
+	}, time.Minute, 100*time.Millisecond)
+}
+
+func countFunc[T any](s []T, f func(T) bool) int {
+	count := 0
+	for _, v := range s {
+		if f(v) {
+			count++
+		}
+	}
+
+	return count
+}
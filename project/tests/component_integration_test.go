@@ -0,0 +1,45 @@
+//go:build integration
+
+package tests_test
+
+import (
+	"os"
+	"testing"
+	"tickets/message/broker"
+	"tickets/message/event"
+	"tickets/message/outbox"
+	"tickets/service"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+
+	_ "github.com/lib/pq"
+)
+
+// TestComponent_Integration runs the component tests against Postgres and Redis:
+// POSTGRES_URL=... REDIS_ADDR=... go test -tags integration ./...
+func TestComponent_Integration(t *testing.T) {
+	db, err := sqlx.Open("postgres", os.Getenv("POSTGRES_URL"))
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	redisBroker, err := broker.NewRedis(broker.NewRedisClient(os.Getenv("REDIS_ADDR")), broker.RedisJanitorConfig{}, watermill.NopLogger{})
+	require.NoError(t, err)
+	defer redisBroker.Close()
+
+	eventMarshaler := event.NewMarshaler(event.FormatJSON)
+
+	testComponent(
+		t,
+		service.NewPostgresRepositories(db, eventMarshaler, outbox.Config{
+			Forwarder: outbox.ForwarderConfig{
+				ListenDSN: os.Getenv("POSTGRES_URL"),
+			},
+		}),
+		redisBroker,
+		eventMarshaler,
+	)
+}
@@ -5,57 +5,60 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
-	"os"
 	"testing"
 	"tickets/api"
-	dbAdapters "tickets/db"
 	"tickets/entities"
-	"tickets/message"
+	"tickets/message/broker"
+	"tickets/message/event"
 	"tickets/service"
 	"time"
 
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/components/cqrs"
 	"github.com/google/uuid"
-	"github.com/jmoiron/sqlx"
+	"github.com/labstack/echo/v4"
 	"github.com/lithammer/shortuuid/v3"
 	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-
-	_ "github.com/lib/pq"
 )
 
 func TestComponent(t *testing.T) {
-	db, err := sqlx.Open("postgres", os.Getenv("POSTGRES_URL"))
-	if err != nil {
-		panic(err)
-	}
-	defer db.Close()
+	eventMarshaler := event.NewMarshaler(event.FormatJSON)
 
-	redisClient := message.NewRedisClient(os.Getenv("REDIS_ADDR"))
-	defer redisClient.Close()
+	messageBroker := broker.NewGoChannel(watermill.NopLogger{})
+	defer messageBroker.Close()
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	testComponent(
+		t,
+		service.NewInMemoryRepositories(eventMarshaler),
+		messageBroker,
+		eventMarshaler,
+	)
+}
 
+func testComponent(
+	t *testing.T,
+	repositories service.Repositories,
+	messageBroker broker.Broker,
+	eventMarshaler cqrs.CommandEventMarshaler,
+) {
 	spreadsheetsService := &api.SpreadsheetsAPIMock{}
 	receiptsService := &api.ReceiptsServiceMock{IssuedReceipts: map[string]entities.IssueReceiptRequest{}}
 	fileService := &api.FileServiceMock{}
 	bookingService := &api.DeadNationMock{}
 
-	go func() {
-		svc := service.New(
-			db,
-			redisClient,
-			spreadsheetsService,
-			receiptsService,
-			fileService,
-			bookingService,
-		)
-
-		assert.NoError(t, svc.Run(ctx))
-	}()
-
-	waitForHttpServer(t)
+	runService(t, service.New(
+		repositories,
+		messageBroker,
+		eventMarshaler,
+		spreadsheetsService,
+		receiptsService,
+		fileService,
+		bookingService,
+		nil,
+		testAuthConfig,
+	))
 
 	ticket := TicketStatus{
 		TicketID: uuid.NewString(),
@@ -78,7 +81,7 @@ func TestComponent(t *testing.T) {
 	assertReceiptForTicketIssued(t, receiptsService, ticket)
 	assertTicketsPrinted(t, fileService, ticket)
 	assertRowToSheetAdded(t, spreadsheetsService, ticket, "tickets-to-print")
-	assertTicketStoredInRepository(t, db, ticket)
+	assertTicketStoredInRepository(t, repositories.Tickets, ticket)
 
 	// Ticket Cancelled tests
 	sendTicketsStatus(t, TicketsStatusRequest{Tickets: []TicketStatus{
@@ -90,32 +93,107 @@ func TestComponent(t *testing.T) {
 	}}, uuid.NewString())
 
 	assertRowToSheetAdded(t, spreadsheetsService, ticket, "tickets-to-refund")
+	assertTicketCanceled(t, ticket.TicketID)
+
+	// Booking tickets goes through the outbox
+	showID := createShow(t, uuid.New())
+	bookingID := bookTickets(t, showID, 2)
+
+	assertBookedInDeadNation(t, bookingService, bookingID)
+}
+
+func assertBookedInDeadNation(t *testing.T, deadNationService *api.DeadNationMock, bookingID uuid.UUID) {
+	assert.EventuallyWithT(
+		t,
+		func(t *assert.CollectT) {
+			_, ok := lo.Find(deadNationService.Bookings(), func(b entities.DeadNationBooking) bool {
+				return b.BookingID == bookingID
+			})
+			assert.True(t, ok, "booking %s not found in Dead Nation", bookingID)
+		},
+		10*time.Second,
+		100*time.Millisecond,
+	)
 }
 
-func assertTicketStoredInRepository(t *testing.T, db *sqlx.DB, ticket TicketStatus) {
-	ticketsRepo := dbAdapters.NewTicketsRepository(db)
+func assertTicketStoredInRepository(t *testing.T, ticketsRepo service.TicketsRepository, ticket TicketStatus) {
+	assert.EventuallyWithT(
+		t,
+		func(t *assert.CollectT) {
+			stored, err := ticketsRepo.GetOne(context.Background(), ticket.TicketID)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			assert.Equal(t, ticket.BookingID, stored.BookingID)
+			assert.Nil(t, stored.DeletedAt)
+		},
+		10*time.Second,
+		100*time.Millisecond,
+	)
+}
 
-	assert.Eventually(
+func assertTicketCanceled(t *testing.T, ticketID string) {
+	assert.EventuallyWithT(
 		t,
-		func() bool {
-			tickets, err := ticketsRepo.GetAll(context.Background())
-			if err != nil {
-				return false
+		func(t *assert.CollectT) {
+			var resp struct {
+				Status        string `json:"status"`
+				DeletedAt     string `json:"deleted_at"`
+				StatusHistory []struct {
+					Status string `json:"status"`
+				} `json:"status_history"`
+			}
+			if !assert.Equal(t, http.StatusOK, getJSON(t, "/tickets/"+ticketID, &resp)) {
+				return
+			}
+
+			assert.Equal(t, "canceled", resp.Status)
+			assert.NotEmpty(t, resp.DeletedAt)
+			if assert.NotEmpty(t, resp.StatusHistory) {
+				assert.Equal(t, "confirmed", resp.StatusHistory[0].Status)
+				assert.Equal(t, "canceled", resp.StatusHistory[len(resp.StatusHistory)-1].Status)
 			}
 
-			for _, t := range tickets {
-				if t.TicketID == ticket.TicketID {
-					return true
+			var active []entities.Ticket
+			if assert.Equal(t, http.StatusOK, getJSON(t, "/tickets?email=email@example.com", &active)) {
+				for _, ticket := range active {
+					assert.NotEqual(t, ticketID, ticket.TicketID, "canceled ticket listed as active")
 				}
 			}
 
-			return false
+			var canceled []entities.Ticket
+			if assert.Equal(t, http.StatusOK, getJSON(t, "/tickets?status=canceled&limit=1000", &canceled)) {
+				assert.True(t, lo.ContainsBy(canceled, func(ticket entities.Ticket) bool {
+					return ticket.TicketID == ticketID
+				}), "canceled ticket not found by status")
+			}
 		},
 		10*time.Second,
 		100*time.Millisecond,
 	)
 }
 
+// runService starts the service and waits until it's ready, the service is stopped when the test ends.
+func runService(t *testing.T, svc service.Service) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		assert.NoError(t, svc.Run(ctx))
+	}()
+
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	waitForHttpServer(t)
+}
+
 func waitForHttpServer(t *testing.T) {
 	t.Helper()
 
@@ -141,8 +219,8 @@ func assertRowToSheetAdded(t *testing.T, spreadSheetService *api.SpreadsheetsAPI
 	return assert.EventuallyWithT(
 		t,
 		func(t *assert.CollectT) {
-			rows, ok := spreadSheetService.Rows[sheetName]
-			if !assert.True(t, ok, "sheet %s not found", sheetName) {
+			rows, err := spreadSheetService.GetRows(context.Background(), sheetName)
+			if !assert.NoError(t, err) || !assert.NotEmpty(t, rows, "sheet %s not found", sheetName) {
 				return
 			}
 
@@ -180,7 +258,7 @@ func assertReceiptForTicketIssued(t *testing.T, receiptsService *api.ReceiptsSer
 	assert.EventuallyWithT(
 		t,
 		func(collectT *assert.CollectT) {
-			issuedReceipts := len(receiptsService.IssuedReceipts)
+			issuedReceipts := len(receiptsService.Issued())
 
 			assert.Equal(collectT, 1, issuedReceipts, "receipt for ticket %s not found", ticket.TicketID)
 		},
@@ -188,7 +266,7 @@ func assertReceiptForTicketIssued(t *testing.T, receiptsService *api.ReceiptsSer
 		100*time.Millisecond,
 	)
 
-	receipt, ok := lo.Find(lo.Values(receiptsService.IssuedReceipts), func(r entities.IssueReceiptRequest) bool {
+	receipt, ok := lo.Find(receiptsService.Issued(), func(r entities.IssueReceiptRequest) bool {
 		return r.TicketID == ticket.TicketID
 	})
 	require.Truef(t, ok, "receipt for ticket %s not found", ticket.TicketID)
@@ -206,7 +284,7 @@ type TicketStatus struct {
 	TicketID  string `json:"ticket_id"`
 	Status    string `json:"status"`
 	Price     Money  `json:"price"`
-	Email     string `json:"email"`
+	Email     string `json:"customer_email"`
 	BookingID string `json:"booking_id"`
 }
 
@@ -218,6 +296,13 @@ type Money struct {
 func sendTicketsStatus(t *testing.T, req TicketsStatusRequest, idempotencyKey string) {
 	t.Helper()
 
+	require.Equal(t, http.StatusOK, trySendTicketsStatus(t, req, idempotencyKey))
+}
+
+// trySendTicketsStatus sends the tickets status and returns the response status code.
+func trySendTicketsStatus(t *testing.T, req TicketsStatusRequest, idempotencyKey string) int {
+	t.Helper()
+
 	payload, err := json.Marshal(req)
 	require.NoError(t, err)
 
@@ -237,9 +322,90 @@ func sendTicketsStatus(t *testing.T, req TicketsStatusRequest, idempotencyKey st
 
 	httpReq.Header.Set("Correlation-ID", correlationID)
 	httpReq.Header.Set("Idempotency-Key", idempotencyKey)
+	httpReq.Header.Set(echo.HeaderAuthorization, "Bearer "+adminToken)
 	httpReq.Header.Set("Content-Type", "application/json")
+	signRequest(httpReq, gatewaySecret, time.Now(), payload)
 
 	resp, err := http.DefaultClient.Do(httpReq)
 	require.NoError(t, err)
-	require.Equal(t, http.StatusOK, resp.StatusCode)
+	defer resp.Body.Close()
+
+	return resp.StatusCode
+}
+
+func createShow(t *testing.T, deadNationID uuid.UUID) uuid.UUID {
+	t.Helper()
+
+	var resp struct {
+		ShowID uuid.UUID `json:"show_id"`
+	}
+	postJSON(t, "/shows", map[string]any{
+		"dead_nation_id":    deadNationID,
+		"number_of_tickets": 10,
+		"start_time":        time.Now().Add(time.Hour * 24),
+		"title":             "Example show",
+		"venue":             "Example venue",
+	}, &resp)
+
+	return resp.ShowID
+}
+
+func bookTickets(t *testing.T, showID uuid.UUID, numberOfTickets int) uuid.UUID {
+	t.Helper()
+
+	var resp struct {
+		BookingID uuid.UUID `json:"booking_id"`
+	}
+	postJSON(t, "/book-tickets", map[string]any{
+		"show_id":           showID,
+		"number_of_tickets": numberOfTickets,
+		"customer_email":    "email@example.com",
+	}, &resp)
+
+	return resp.BookingID
+}
+
+func postJSON(t *testing.T, path string, req any, resp any) {
+	t.Helper()
+
+	payload, err := json.Marshal(req)
+	require.NoError(t, err)
+
+	httpReq, err := http.NewRequest(http.MethodPost, "http://localhost:8080"+path, bytes.NewBuffer(payload))
+	require.NoError(t, err)
+
+	httpReq.Header.Set(echo.HeaderContentType, "application/json")
+	httpReq.Header.Set(echo.HeaderAuthorization, "Bearer "+adminToken)
+
+	httpResp, err := http.DefaultClient.Do(httpReq)
+	require.NoError(t, err)
+	defer httpResp.Body.Close()
+
+	require.Equal(t, http.StatusCreated, httpResp.StatusCode)
+	require.NoError(t, json.NewDecoder(httpResp.Body).Decode(resp))
+}
+
+// getJSON decodes the response if it's successful, and returns the status code.
+func getJSON(t require.TestingT, path string, resp any) int {
+	httpResp, err := httpGet(path)
+	require.NoError(t, err)
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode == http.StatusOK {
+		require.NoError(t, json.NewDecoder(httpResp.Body).Decode(resp))
+	}
+
+	return httpResp.StatusCode
+}
+
+// httpGet sends the GET request to the service as an admin.
+func httpGet(path string) (*http.Response, error) {
+	httpReq, err := http.NewRequest(http.MethodGet, "http://localhost:8080"+path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq.Header.Set(echo.HeaderAuthorization, "Bearer "+adminToken)
+
+	return http.DefaultClient.Do(httpReq)
 }
@@ -0,0 +1,217 @@
+package tests_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"tickets/api"
+	"tickets/auth"
+	"tickets/entities"
+	"tickets/message/broker"
+	"tickets/message/event"
+	"tickets/service"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComponent_customer_data(t *testing.T) {
+	eventMarshaler := event.NewMarshaler(event.FormatJSON)
+
+	messageBroker := broker.NewGoChannel(watermill.NopLogger{})
+	defer messageBroker.Close()
+
+	erasedSubscriber, err := messageBroker.NewSubscriber("customer-data-erased-test")
+	require.NoError(t, err)
+	erasedEvents, err := erasedSubscriber.Subscribe(context.Background(), event.Topic("CustomerDataErased"))
+	require.NoError(t, err)
+
+	runService(t, service.New(
+		service.NewInMemoryRepositories(eventMarshaler),
+		messageBroker,
+		eventMarshaler,
+		&api.SpreadsheetsAPIMock{},
+		&api.ReceiptsServiceMock{IssuedReceipts: map[string]entities.IssueReceiptRequest{}},
+		&api.FileServiceMock{},
+		&api.DeadNationMock{},
+		nil,
+		testAuthConfig,
+	))
+
+	admin := "Bearer " + adminToken
+	email := "erase-me@example.com"
+	otherEmail := "keep-me@example.com"
+
+	var gatewayKey struct {
+		Key string `json:"key"`
+	}
+	postJSON(t, "/api-keys", map[string]any{"name": "gateway", "role": "gateway"}, &gatewayKey)
+
+	webhookServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer webhookServer.Close()
+
+	require.Equal(t, http.StatusCreated, requestJSON(t, http.MethodPost, "/webhooks", admin, map[string]any{
+		"url":         webhookServer.URL,
+		"event_types": []string{"BookingMade", "TicketBookingConfirmed"},
+	}, nil))
+
+	var show struct {
+		ShowID uuid.UUID `json:"show_id"`
+	}
+	require.Equal(t, http.StatusCreated, requestJSON(t, http.MethodPost, "/shows", admin, map[string]any{
+		"dead_nation_id":    uuid.New(),
+		"number_of_tickets": 10,
+		"start_time":        time.Now().Add(time.Hour * 24),
+		"title":             "Show",
+		"venue":             "Venue",
+	}, &show))
+
+	var booking struct {
+		BookingID uuid.UUID `json:"booking_id"`
+	}
+	require.Equal(t, http.StatusCreated, requestJSON(t, http.MethodPost, "/book-tickets", admin, map[string]any{
+		"show_id":           show.ShowID,
+		"number_of_tickets": 1,
+		"customer_email":    email,
+	}, &booking))
+
+	ticket := TicketStatus{
+		TicketID:  uuid.NewString(),
+		Status:    "confirmed",
+		Price:     Money{Amount: "30.00", Currency: "EUR"},
+		Email:     email,
+		BookingID: booking.BookingID.String(),
+	}
+	otherTicket := TicketStatus{
+		TicketID:  uuid.NewString(),
+		Status:    "confirmed",
+		Price:     Money{Amount: "30.00", Currency: "EUR"},
+		Email:     otherEmail,
+		BookingID: uuid.NewString(),
+	}
+	status := request(t, http.MethodPost, "/tickets-status", auth.APIKeyHeader, gatewayKey.Key, TicketsStatusRequest{
+		Tickets: []TicketStatus{ticket, otherTicket},
+	})
+	require.Equal(t, http.StatusOK, status)
+
+	var data entities.CustomerData
+	assert.EventuallyWithT(t, func(t *assert.CollectT) {
+		data = entities.CustomerData{}
+		if !assert.Equal(t, http.StatusOK, requestJSON(t, http.MethodGet, "/customers/"+email+"/data", admin, nil, &data)) {
+			return
+		}
+		assert.Len(t, data.Tickets, 1)
+		if assert.Len(t, data.SheetRows, 1) {
+			assert.NotNil(t, data.SheetRows[0].AppendedAt)
+		}
+		assert.Len(t, data.WebhookMessages, 2)
+	}, time.Second*10, time.Millisecond*50)
+
+	t.Run("export", func(t *testing.T) {
+		assert.Equal(t, email, data.Email)
+
+		require.Len(t, data.Tickets, 1)
+		assert.Equal(t, ticket.TicketID, data.Tickets[0].TicketID)
+		assert.NotEmpty(t, data.Tickets[0].StatusHistory)
+
+		require.Len(t, data.Bookings, 1)
+		assert.Equal(t, booking.BookingID, data.Bookings[0].ID)
+
+		require.Len(t, data.SheetRows, 1)
+		assert.Equal(t, "tickets-to-print", data.SheetRows[0].SheetName)
+		assert.Contains(t, data.SheetRows[0].Columns, email)
+
+		require.Len(t, data.WebhookMessages, 2)
+		for _, message := range data.WebhookMessages {
+			assert.Contains(t, string(message.Body), email)
+		}
+	})
+
+	var erasure entities.CustomerErasure
+	require.Equal(t, http.StatusOK, requestJSON(t, http.MethodDelete, "/customers/"+email, admin, nil, &erasure))
+
+	t.Run("erasure", func(t *testing.T) {
+		assert.Equal(t, []string{ticket.TicketID}, erasure.TicketIDs)
+		assert.Equal(t, []string{booking.BookingID.String()}, erasure.BookingIDs)
+		assert.Equal(t, 1, erasure.SheetRows)
+		assert.Equal(t, 2, erasure.WebhookMessages)
+		require.NotEmpty(t, erasure.Pseudonym)
+
+		var erased entities.CustomerData
+		require.Equal(t, http.StatusOK, requestJSON(t, http.MethodGet, "/customers/"+email+"/data", admin, nil, &erased))
+		assert.Empty(t, erased.Tickets)
+		assert.Empty(t, erased.Bookings)
+		assert.Empty(t, erased.SheetRows)
+		assert.Empty(t, erased.WebhookMessages)
+
+		var pseudonymised entities.CustomerData
+		require.Equal(t, http.StatusOK, requestJSON(t, http.MethodGet, "/customers/"+erasure.Pseudonym+"/data", admin, nil, &pseudonymised))
+		require.Len(t, pseudonymised.Tickets, 1)
+		assert.Equal(t, ticket.TicketID, pseudonymised.Tickets[0].TicketID)
+		require.Len(t, pseudonymised.SheetRows, 1)
+		assert.Contains(t, pseudonymised.SheetRows[0].Columns, erasure.Pseudonym)
+		assert.NotContains(t, pseudonymised.SheetRows[0].Columns, email)
+		require.Len(t, pseudonymised.WebhookMessages, 2)
+		for _, message := range pseudonymised.WebhookMessages {
+			assert.NotContains(t, string(message.Body), email)
+		}
+
+		var other entities.CustomerData
+		require.Equal(t, http.StatusOK, requestJSON(t, http.MethodGet, "/customers/"+otherEmail+"/data", admin, nil, &other))
+		assert.Len(t, other.Tickets, 1, "the data of other customers should be kept")
+	})
+
+	t.Run("event", func(t *testing.T) {
+		select {
+		case msg := <-erasedEvents:
+			msg.Ack()
+
+			var erased entities.CustomerDataErased
+			require.NoError(t, eventMarshaler.Unmarshal(msg, &erased))
+			assert.Equal(t, erasure.TicketIDs, erased.TicketIDs)
+			assert.Equal(t, erasure.BookingIDs, erased.BookingIDs)
+			assert.Equal(t, erasure.Pseudonym, erased.Pseudonym)
+			assert.NotContains(t, string(msg.Payload), email)
+		case <-time.After(time.Second * 10):
+			t.Fatal("CustomerDataErased was not published")
+		}
+	})
+
+	t.Run("sheet", func(t *testing.T) {
+		// the row was appended with the email before the erasure, so it has to be removed from the sheet
+		assert.EventuallyWithT(t, func(t *assert.CollectT) {
+			var reconciliation entities.SheetReconciliation
+			if !assert.Equal(t, http.StatusOK, requestJSON(t, http.MethodGet, "/sheets/tickets-to-print/reconciliation", admin, nil, &reconciliation)) {
+				return
+			}
+			assert.True(t, reconciliation.Consistent())
+			if assert.Len(t, reconciliation.Erased, 1) {
+				assert.Contains(t, reconciliation.Erased[0], erasure.Pseudonym)
+			}
+		}, time.Second*10, time.Millisecond*50)
+	})
+
+	t.Run("audit", func(t *testing.T) {
+		var records []entities.AuditRecord
+		require.Equal(t, http.StatusOK, requestJSON(t, http.MethodGet, "/audit-records?action=EraseCustomerData", admin, nil, &records))
+		require.Len(t, records, 1)
+		assert.Contains(t, records[0].TargetIDs, erasure.Pseudonym)
+		assert.Contains(t, records[0].TargetIDs, ticket.TicketID)
+
+		payload, err := json.Marshal(records[0])
+		require.NoError(t, err)
+		assert.NotContains(t, string(payload), email)
+	})
+
+	t.Run("invalid email", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnprocessableEntity, requestJSON(t, http.MethodGet, "/customers/not-an-email/data", admin, nil, nil))
+		assert.Equal(t, http.StatusUnprocessableEntity, requestJSON(t, http.MethodDelete, "/customers/not-an-email", admin, nil, nil))
+	})
+}
@@ -0,0 +1,185 @@
+package tests_test
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+	"tickets/api"
+	"tickets/api/fakegateway"
+	ticketsMessage "tickets/message"
+	"tickets/message/broker"
+	"tickets/message/event"
+	"tickets/service"
+	"time"
+
+	"github.com/ThreeDotsLabs/go-event-driven/common/clients"
+	"github.com/ThreeDotsLabs/go-event-driven/common/clients/dead_nation"
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message/router/middleware"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestComponent_FakeGateway runs the service with the real API clients against the fake gateway,
+// with failures injected into every API.
+func TestComponent_FakeGateway(t *testing.T) {
+	gateway := fakegateway.New()
+	defer gateway.Close()
+
+	apiClients, err := clients.NewClients(gateway.URL(), nil)
+	require.NoError(t, err)
+
+	eventMarshaler := event.NewMarshaler(event.FormatJSON)
+
+	messageBroker := broker.NewGoChannel(watermill.NopLogger{})
+	defer messageBroker.Close()
+
+	gateway.InjectFailure(fakegateway.Failure{
+		PathPrefix: "/receipts-api/",
+		StatusCode: http.StatusServiceUnavailable,
+		Times:      2,
+	})
+	gateway.InjectFailure(fakegateway.Failure{
+		PathPrefix: "/spreadsheets-api/",
+		StatusCode: http.StatusInternalServerError,
+		Times:      1,
+	})
+	gateway.InjectFailure(fakegateway.Failure{
+		PathPrefix: "/files-api/",
+		Latency:    time.Millisecond * 100,
+		Times:      1,
+	})
+	gateway.InjectFailure(fakegateway.Failure{
+		PathPrefix: "/dead-nation-api/",
+		StatusCode: http.StatusBadGateway,
+		Times:      1,
+	})
+
+	runService(t, service.New(
+		service.NewInMemoryRepositories(eventMarshaler),
+		messageBroker,
+		eventMarshaler,
+		api.NewSpreadsheetsAPIClient(apiClients),
+		api.NewReceiptsServiceClient(apiClients),
+		api.NewFileAPIClient(apiClients),
+		api.NewDeadNationClient(apiClients),
+		nil,
+		testAuthConfig,
+	))
+
+	ticket := TicketStatus{
+		TicketID: uuid.NewString(),
+		Status:   "confirmed",
+		Price: Money{
+			Amount:   "50.30",
+			Currency: "GBP",
+		},
+		Email:     "email@example.com",
+		BookingID: uuid.NewString(),
+	}
+
+	idempotencyKey := uuid.NewString()
+	for i := 0; i < 2; i++ {
+		sendTicketsStatus(t, TicketsStatusRequest{Tickets: []TicketStatus{ticket}}, idempotencyKey)
+	}
+
+	assert.EventuallyWithT(t, func(t *assert.CollectT) {
+		receipts := gateway.Receipts()
+		if assert.Len(t, receipts, 1) {
+			assert.Equal(t, ticket.TicketID, receipts[0].TicketId)
+			assert.Equal(t, ticket.Price.Amount, receipts[0].Price.MoneyAmount)
+		}
+
+		content, ok := gateway.File(ticket.TicketID + "-ticket.html")
+		if assert.True(t, ok, "ticket file not uploaded") {
+			assert.Contains(t, content, ticket.TicketID)
+		}
+
+		assert.True(t, slices.ContainsFunc(gateway.SheetRows("tickets-to-print"), func(row []string) bool {
+			return slices.Contains(row, ticket.TicketID)
+		}), "ticket not found in sheet")
+
+		// duplicated messages hit the "already exists" responses
+		assert.Contains(t, statusCodes(gateway, http.MethodPut, "/receipts-api/"), http.StatusOK)
+		assert.Contains(t, statusCodes(gateway, http.MethodPut, "/files-api/"), http.StatusConflict)
+	}, 10*time.Second, 100*time.Millisecond)
+
+	assert.Equal(t, []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable}, statusCodes(gateway, http.MethodPut, "/receipts-api/")[:2])
+	assert.Contains(t, statusCodes(gateway, http.MethodPost, "/spreadsheets-api/"), http.StatusInternalServerError)
+
+	showID := createShow(t, uuid.New())
+	bookingID := bookTickets(t, showID, 2)
+
+	assert.EventuallyWithT(t, func(t *assert.CollectT) {
+		assert.True(t, slices.ContainsFunc(gateway.DeadNationBookings(), func(b dead_nation.PostTicketBookingRequest) bool {
+			return b.BookingId == bookingID
+		}), "booking not found in dead nation")
+	}, 10*time.Second, 100*time.Millisecond)
+
+	assert.Equal(t, http.StatusBadGateway, statusCodes(gateway, http.MethodPost, "/dead-nation-api/")[0])
+}
+
+func statusCodes(gateway *fakegateway.Gateway, method string, pathPrefix string) []int {
+	var codes []int
+	for _, req := range gateway.Requests() {
+		if req.Method == method && len(req.Path) >= len(pathPrefix) && req.Path[:len(pathPrefix)] == pathPrefix {
+			codes = append(codes, req.StatusCode)
+		}
+	}
+
+	return codes
+}
+
+func TestComponent_FakeGateway_permanent_errors_go_to_dead_letter(t *testing.T) {
+	gateway := fakegateway.New()
+	defer gateway.Close()
+
+	apiClients, err := clients.NewClients(gateway.URL(), nil)
+	require.NoError(t, err)
+
+	eventMarshaler := event.NewMarshaler(event.FormatJSON)
+
+	messageBroker := broker.NewGoChannel(watermill.NopLogger{})
+	defer messageBroker.Close()
+
+	deadLetterSubscriber, err := messageBroker.NewSubscriber("dead-letters-test")
+	require.NoError(t, err)
+
+	deadLetters, err := deadLetterSubscriber.Subscribe(context.Background(), ticketsMessage.DeadLetterTopic)
+	require.NoError(t, err)
+
+	gateway.InjectFailure(fakegateway.Failure{
+		PathPrefix: "/dead-nation-api/",
+		StatusCode: http.StatusBadRequest,
+	})
+
+	runService(t, service.New(
+		service.NewInMemoryRepositories(eventMarshaler),
+		messageBroker,
+		eventMarshaler,
+		api.NewSpreadsheetsAPIClient(apiClients),
+		api.NewReceiptsServiceClient(apiClients),
+		api.NewFileAPIClient(apiClients),
+		api.NewDeadNationClient(apiClients),
+		nil,
+		testAuthConfig,
+	))
+
+	showID := createShow(t, uuid.New())
+	bookTickets(t, showID, 2)
+
+	select {
+	case msg := <-deadLetters:
+		msg.Ack()
+
+		assert.Equal(t, "BookPlaceInDeadNation", msg.Metadata.Get(middleware.PoisonedHandlerKey))
+		assert.Contains(t, msg.Metadata.Get(middleware.ReasonForPoisonedKey), "400")
+	case <-time.After(10 * time.Second):
+		t.Fatal("message not moved to the dead letter topic")
+	}
+
+	// 400 is not retried
+	assert.Len(t, statusCodes(gateway, http.MethodPost, "/dead-nation-api/"), 1)
+}
@@ -0,0 +1,114 @@
+package tests_test
+
+import (
+	"encoding/csv"
+	"net/http"
+	"testing"
+	"tickets/api"
+	"tickets/entities"
+	"tickets/message/broker"
+	"tickets/message/event"
+	"tickets/service"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComponent_sales_reports(t *testing.T) {
+	eventMarshaler := event.NewMarshaler(event.FormatJSON)
+
+	messageBroker := broker.NewGoChannel(watermill.NopLogger{})
+	defer messageBroker.Close()
+
+	runService(t, service.New(
+		service.NewInMemoryRepositories(eventMarshaler),
+		messageBroker,
+		eventMarshaler,
+		&api.SpreadsheetsAPIMock{},
+		&api.ReceiptsServiceMock{IssuedReceipts: map[string]entities.IssueReceiptRequest{}},
+		&api.FileServiceMock{},
+		&api.DeadNationMock{},
+		nil,
+		testAuthConfig,
+	))
+
+	showID := createShow(t, uuid.New())
+	bookingID := bookTickets(t, showID, 3)
+
+	tickets := []TicketStatus{
+		{TicketID: uuid.NewString(), Status: "confirmed", Price: Money{Amount: "50.30", Currency: "GBP"}},
+		{TicketID: uuid.NewString(), Status: "confirmed", Price: Money{Amount: "20.00", Currency: "GBP"}},
+		{TicketID: uuid.NewString(), Status: "confirmed", Price: Money{Amount: "15.50", Currency: "EUR"}},
+	}
+	for i := range tickets {
+		tickets[i].Email = "email@example.com"
+		tickets[i].BookingID = bookingID.String()
+	}
+	sendTicketsStatus(t, TicketsStatusRequest{Tickets: tickets}, uuid.NewString())
+
+	canceled := tickets[1]
+	canceled.Status = "canceled"
+	canceled.BookingID = ""
+	sendTicketsStatus(t, TicketsStatusRequest{Tickets: []TicketStatus{canceled}}, uuid.NewString())
+
+	expected := entities.SalesReport{
+		TicketsSold:     3,
+		TicketsCanceled: 1,
+		Revenue: []entities.Revenue{
+			{
+				Gross: entities.Money{Amount: "15.50", Currency: "EUR"},
+				Net:   entities.Money{Amount: "15.50", Currency: "EUR"},
+			},
+			{
+				Gross: entities.Money{Amount: "70.30", Currency: "GBP"},
+				Net:   entities.Money{Amount: "50.30", Currency: "GBP"},
+			},
+		},
+	}
+
+	assert.EventuallyWithT(t, func(t *assert.CollectT) {
+		var report entities.ShowSalesReport
+		if assert.Equal(t, http.StatusOK, getJSON(t, "/reports/shows/"+showID.String(), &report)) {
+			assert.Equal(t, showID, report.ShowID)
+			assert.Equal(t, expected, report.SalesReport)
+		}
+	}, 10*time.Second, 100*time.Millisecond)
+
+	var daily []entities.DailySalesReport
+	require.Equal(t, http.StatusOK, getJSON(t, "/reports/daily?show_id="+showID.String(), &daily))
+	require.Len(t, daily, 1)
+	assert.Equal(t, entities.SaleDay(time.Now()), daily[0].Date)
+	assert.Equal(t, expected, daily[0].SalesReport)
+
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format(time.DateOnly)
+	require.Equal(t, http.StatusOK, getJSON(t, "/reports/daily?show_id="+showID.String()+"&from="+tomorrow, &daily))
+	assert.Empty(t, daily)
+
+	t.Run("csv", func(t *testing.T) {
+		resp, err := httpGet("/reports/shows/" + showID.String() + "?format=csv")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		rows, err := csv.NewReader(resp.Body).ReadAll()
+		require.NoError(t, err)
+
+		assert.Equal(t, [][]string{
+			{"show_id", "tickets_sold", "tickets_canceled", "tickets_refunded", "gross_EUR", "net_EUR", "gross_GBP", "net_GBP"},
+			{showID.String(), "3", "1", "0", "15.50", "15.50", "70.30", "50.30"},
+		}, rows)
+	})
+
+	t.Run("unknown show", func(t *testing.T) {
+		var report entities.ShowSalesReport
+		assert.Equal(t, http.StatusNotFound, getJSON(t, "/reports/shows/"+uuid.NewString(), &report))
+	})
+
+	t.Run("invalid date", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, getJSON(t, "/reports/daily?from=yesterday", &daily))
+	})
+}
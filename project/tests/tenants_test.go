@@ -0,0 +1,181 @@
+package tests_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"tickets/api"
+	"tickets/auth"
+	"tickets/entities"
+	"tickets/message/broker"
+	"tickets/message/event"
+	"tickets/service"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComponent_tenants(t *testing.T) {
+	eventMarshaler := event.NewMarshaler(event.FormatJSON)
+
+	messageBroker := broker.NewGoChannel(watermill.NopLogger{})
+	defer messageBroker.Close()
+
+	spreadsheetsService := &api.SpreadsheetsAPIMock{}
+	fileService := &api.FileServiceMock{}
+
+	runService(t, service.New(
+		service.NewInMemoryRepositories(eventMarshaler),
+		messageBroker,
+		eventMarshaler,
+		spreadsheetsService,
+		&api.ReceiptsServiceMock{IssuedReceipts: map[string]entities.IssueReceiptRequest{}},
+		fileService,
+		&api.DeadNationMock{},
+		nil,
+		testAuthConfig,
+	))
+
+	acmeAdmin := "Bearer " + signTenantToken("acme", entities.RoleAdmin)
+	defaultAdmin := "Bearer " + adminToken
+
+	var gatewayKey struct {
+		ID       uuid.UUID `json:"id"`
+		Key      string    `json:"key"`
+		TenantID string    `json:"tenant_id"`
+	}
+	require.Equal(t, http.StatusCreated, requestJSON(t, http.MethodPost, "/api-keys", acmeAdmin, map[string]any{
+		"name": "acme gateway",
+		"role": "gateway",
+		// the keys are created for the caller's tenant
+		"tenant_id": "default",
+	}, &gatewayKey))
+	require.Equal(t, "acme", gatewayKey.TenantID)
+
+	t.Run("shows and bookings", func(t *testing.T) {
+		var show, defaultShow struct {
+			ShowID uuid.UUID `json:"show_id"`
+		}
+		newShow := map[string]any{
+			"dead_nation_id":    uuid.New(),
+			"number_of_tickets": 10,
+			"start_time":        time.Now().Add(time.Hour * 24),
+			"title":             "Acme show",
+			"venue":             "Acme venue",
+		}
+		require.Equal(t, http.StatusCreated, requestJSON(t, http.MethodPost, "/shows", acmeAdmin, newShow, &show))
+
+		booking := map[string]any{
+			"show_id":           show.ShowID,
+			"number_of_tickets": 2,
+			"customer_email":    "email@example.com",
+		}
+		assert.Equal(t, http.StatusUnprocessableEntity, requestJSON(t, http.MethodPost, "/book-tickets", defaultAdmin, booking, nil))
+		assert.Equal(t, http.StatusCreated, requestJSON(t, http.MethodPost, "/book-tickets", acmeAdmin, booking, nil))
+
+		// the Dead Nation IDs are unique within the tenant only
+		require.Equal(t, http.StatusCreated, requestJSON(t, http.MethodPost, "/shows", defaultAdmin, newShow, &defaultShow))
+		booking["show_id"] = defaultShow.ShowID
+		assert.Equal(t, http.StatusCreated, requestJSON(t, http.MethodPost, "/book-tickets", defaultAdmin, booking, nil))
+	})
+
+	t.Run("tickets", func(t *testing.T) {
+		ticket := TicketStatus{
+			TicketID:  uuid.NewString(),
+			Status:    "confirmed",
+			Price:     Money{Amount: "30.00", Currency: "EUR"},
+			Email:     "email@example.com",
+			BookingID: uuid.NewString(),
+		}
+		status := request(t, http.MethodPost, "/tickets-status", auth.APIKeyHeader, gatewayKey.Key, TicketsStatusRequest{
+			Tickets: []TicketStatus{ticket},
+		})
+		require.Equal(t, http.StatusOK, status)
+
+		assert.EventuallyWithT(t, func(t *assert.CollectT) {
+			assert.Equal(t, http.StatusOK, requestJSON(t, http.MethodGet, "/tickets/"+ticket.TicketID, acmeAdmin, nil, nil))
+		}, time.Second*10, time.Millisecond*50)
+
+		assert.Equal(t, http.StatusNotFound, requestJSON(t, http.MethodGet, "/tickets/"+ticket.TicketID, defaultAdmin, nil, nil))
+
+		var defaultTickets []entities.Ticket
+		require.Equal(t, http.StatusOK, requestJSON(t, http.MethodGet, "/tickets?booking_id="+ticket.BookingID, defaultAdmin, nil, &defaultTickets))
+		assert.Empty(t, defaultTickets)
+
+		assertRowToSheetAdded(t, spreadsheetsService, ticket, "acme-tickets-to-print")
+		assert.EventuallyWithT(t, func(t *assert.CollectT) {
+			content, err := fileService.DownloadFile(context.Background(), "acme-"+ticket.TicketID+"-ticket.html")
+			if assert.NoError(t, err) {
+				assert.Contains(t, content, ticket.TicketID)
+			}
+		}, time.Second*10, time.Millisecond*50)
+	})
+
+	t.Run("api keys", func(t *testing.T) {
+		var apiKeys []entities.APIKey
+		require.Equal(t, http.StatusOK, requestJSON(t, http.MethodGet, "/api-keys", acmeAdmin, nil, &apiKeys))
+		require.Len(t, apiKeys, 1)
+		assert.Equal(t, gatewayKey.ID, apiKeys[0].ID)
+
+		require.Equal(t, http.StatusOK, requestJSON(t, http.MethodGet, "/api-keys", defaultAdmin, nil, &apiKeys))
+		for _, apiKey := range apiKeys {
+			assert.NotEqual(t, gatewayKey.ID, apiKey.ID)
+		}
+
+		assert.Equal(t, http.StatusNotFound, requestJSON(t, http.MethodDelete, "/api-keys/"+gatewayKey.ID.String(), defaultAdmin, nil, nil))
+	})
+
+	t.Run("invalid tenant", func(t *testing.T) {
+		status := request(t, http.MethodGet, "/tickets", echo.HeaderAuthorization, "Bearer "+signTenantToken("Acme Inc.", entities.RoleSupport), nil)
+		assert.Equal(t, http.StatusUnauthorized, status)
+	})
+}
+
+// signTenantToken signs the token of an operator of the tenant.
+func signTenantToken(tenantID string, roles ...entities.Role) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"sub":       "operator@" + tenantID,
+		"exp":       time.Now().Add(time.Hour).Unix(),
+		"roles":     roles,
+		"tenant_id": tenantID,
+	}).SignedString(operatorsKey)
+	if err != nil {
+		panic(err)
+	}
+
+	return token
+}
+
+// requestJSON sends the request with the Authorization header, decodes the response if it's successful,
+// and returns the status code.
+func requestJSON(t require.TestingT, method, path, authorization string, body any, resp any) int {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		require.NoError(t, err)
+	}
+
+	httpReq, err := http.NewRequest(method, "http://localhost:8080"+path, bytes.NewBuffer(payload))
+	require.NoError(t, err)
+
+	httpReq.Header.Set(echo.HeaderContentType, "application/json")
+	httpReq.Header.Set(echo.HeaderAuthorization, authorization)
+
+	httpResp, err := http.DefaultClient.Do(httpReq)
+	require.NoError(t, err)
+	defer httpResp.Body.Close()
+
+	if resp != nil && httpResp.StatusCode < 300 {
+		require.NoError(t, json.NewDecoder(httpResp.Body).Decode(resp))
+	}
+
+	return httpResp.StatusCode
+}
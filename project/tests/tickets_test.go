@@ -0,0 +1,158 @@
+package tests_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"slices"
+	"testing"
+	"tickets/api"
+	"tickets/entities"
+	"tickets/message/broker"
+	"tickets/message/event"
+	"tickets/service"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComponent_tickets_pagination_and_export(t *testing.T) {
+	eventMarshaler := event.NewMarshaler(event.FormatJSON)
+
+	messageBroker := broker.NewGoChannel(watermill.NopLogger{})
+	defer messageBroker.Close()
+
+	repositories := service.NewInMemoryRepositories(eventMarshaler)
+
+	runService(t, service.New(
+		repositories,
+		messageBroker,
+		eventMarshaler,
+		&api.SpreadsheetsAPIMock{},
+		&api.ReceiptsServiceMock{IssuedReceipts: map[string]entities.IssueReceiptRequest{}},
+		&api.FileServiceMock{},
+		&api.DeadNationMock{},
+		nil,
+		testAuthConfig,
+	))
+
+	email := uuid.NewString() + "@example.com"
+
+	var ticketIDs []string
+	var req TicketsStatusRequest
+	for i := 0; i < 5; i++ {
+		ticketID := uuid.NewString()
+		ticketIDs = append(ticketIDs, ticketID)
+
+		req.Tickets = append(req.Tickets, TicketStatus{
+			TicketID: ticketID,
+			Status:   "confirmed",
+			Price: Money{
+				Amount:   "10.00",
+				Currency: "EUR",
+			},
+			Email:     email,
+			BookingID: uuid.NewString(),
+		})
+	}
+	sendTicketsStatus(t, req, uuid.NewString())
+
+	assert.EventuallyWithT(t, func(t *assert.CollectT) {
+		tickets, err := repositories.Tickets.Find(context.Background(), entities.TicketsFilter{CustomerEmail: email})
+		if assert.NoError(t, err) {
+			assert.Len(t, tickets, len(ticketIDs))
+		}
+	}, 10*time.Second, 100*time.Millisecond)
+
+	slices.Sort(ticketIDs)
+
+	t.Run("pages", func(t *testing.T) {
+		var pagedIDs []string
+		cursor := ""
+		for {
+			query := url.Values{"email": {email}, "limit": {"2"}, "cursor": {cursor}}
+
+			resp, err := httpGet("/tickets?" + query.Encode())
+			require.NoError(t, err)
+
+			var page []entities.Ticket
+			err = json.NewDecoder(resp.Body).Decode(&page)
+			resp.Body.Close()
+			require.NoError(t, err)
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+
+			for _, ticket := range page {
+				pagedIDs = append(pagedIDs, ticket.TicketID)
+			}
+
+			cursor = resp.Header.Get("X-Next-Cursor")
+			if cursor == "" {
+				break
+			}
+		}
+
+		assert.Equal(t, ticketIDs, pagedIDs)
+	})
+
+	t.Run("descending", func(t *testing.T) {
+		var page []entities.Ticket
+		require.Equal(t, http.StatusOK, getJSON(t, "/tickets?sort=-ticket_id&limit=3&email="+url.QueryEscape(email), &page))
+
+		require.Len(t, page, 3)
+		assert.Equal(t, ticketIDs[4], page[0].TicketID)
+		assert.Equal(t, ticketIDs[2], page[2].TicketID)
+	})
+
+	t.Run("export_ndjson", func(t *testing.T) {
+		resp, err := httpGet("/tickets/export?format=ndjson&email=" + url.QueryEscape(email))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+
+		var exportedIDs []string
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			var ticket entities.Ticket
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &ticket))
+			exportedIDs = append(exportedIDs, ticket.TicketID)
+		}
+		require.NoError(t, scanner.Err())
+
+		assert.Equal(t, ticketIDs, exportedIDs)
+	})
+
+	t.Run("export_csv", func(t *testing.T) {
+		resp, err := httpGet("/tickets/export?format=csv&email=" + url.QueryEscape(email))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		rows, err := csv.NewReader(resp.Body).ReadAll()
+		require.NoError(t, err)
+
+		require.Len(t, rows, len(ticketIDs)+1)
+		assert.Equal(t, "ticket_id", rows[0][0])
+		for i, row := range rows[1:] {
+			assert.Equal(t, ticketIDs[i], row[0])
+			assert.Contains(t, []string{"confirmed", "printed"}, row[1])
+			assert.Equal(t, email, row[4])
+		}
+	})
+
+	t.Run("invalid_cursor", func(t *testing.T) {
+		resp, err := httpGet("/tickets?cursor=invalid")
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
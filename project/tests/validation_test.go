@@ -0,0 +1,250 @@
+package tests_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"tickets/api"
+	"tickets/entities"
+	"tickets/message/broker"
+	"tickets/message/event"
+	"tickets/service"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type errorResponse struct {
+	Error  string       `json:"error"`
+	Fields []fieldError `json:"fields"`
+}
+
+func TestComponent_request_validation(t *testing.T) {
+	eventMarshaler := event.NewMarshaler(event.FormatJSON)
+
+	messageBroker := broker.NewGoChannel(watermill.NopLogger{})
+	defer messageBroker.Close()
+
+	runService(t, service.New(
+		service.NewInMemoryRepositories(eventMarshaler),
+		messageBroker,
+		eventMarshaler,
+		&api.SpreadsheetsAPIMock{},
+		&api.ReceiptsServiceMock{IssuedReceipts: map[string]entities.IssueReceiptRequest{}},
+		&api.FileServiceMock{},
+		&api.DeadNationMock{},
+		nil,
+		testAuthConfig,
+	))
+
+	showID := createShow(t, uuid.New())
+
+	testCases := []struct {
+		Name           string
+		Path           string
+		Body           string
+		ExpectedStatus int
+		ExpectedFields []string
+	}{
+		{
+			Name:           "malformed_json",
+			Path:           "/book-tickets",
+			Body:           `{"show_id":`,
+			ExpectedStatus: http.StatusBadRequest,
+		},
+		{
+			Name:           "wrong_field_type",
+			Path:           "/book-tickets",
+			Body:           `{"show_id":"` + showID.String() + `","number_of_tickets":"two","customer_email":"email@example.com"}`,
+			ExpectedStatus: http.StatusBadRequest,
+			ExpectedFields: []string{"number_of_tickets"},
+		},
+		{
+			Name:           "invalid_booking",
+			Path:           "/book-tickets",
+			Body:           `{"show_id":"` + showID.String() + `","number_of_tickets":0,"customer_email":"not-an-email"}`,
+			ExpectedStatus: http.StatusUnprocessableEntity,
+			// the spec is checked before the rest of the rules
+			ExpectedFields: []string{"number_of_tickets"},
+		},
+		{
+			Name:           "invalid_booking_email",
+			Path:           "/book-tickets",
+			Body:           `{"show_id":"` + showID.String() + `","number_of_tickets":1,"customer_email":"not-an-email"}`,
+			ExpectedStatus: http.StatusUnprocessableEntity,
+			ExpectedFields: []string{"customer_email"},
+		},
+		{
+			Name: "invalid_show",
+			Path: "/shows",
+			Body: mustMarshal(t, map[string]any{
+				"dead_nation_id":    uuid.New(),
+				"number_of_tickets": -1,
+				"start_time":        time.Now().Add(time.Hour),
+				"title":             "",
+				"venue":             "Example venue",
+			}),
+			ExpectedStatus: http.StatusUnprocessableEntity,
+			ExpectedFields: []string{"number_of_tickets", "title"},
+		},
+		{
+			Name: "show_in_the_past",
+			Path: "/shows",
+			Body: mustMarshal(t, map[string]any{
+				"dead_nation_id":    uuid.Nil,
+				"number_of_tickets": 10,
+				"start_time":        time.Now().Add(-time.Hour),
+				"title":             "Example title",
+				"venue":             "Example venue",
+			}),
+			ExpectedStatus: http.StatusUnprocessableEntity,
+			ExpectedFields: []string{"dead_nation_id", "start_time"},
+		},
+		{
+			Name: "invalid_tickets_status",
+			Path: "/tickets-status",
+			Body: mustMarshal(t, TicketsStatusRequest{Tickets: []TicketStatus{
+				{
+					TicketID:  uuid.NewString(),
+					Status:    "sold",
+					Price:     Money{Amount: "10.00", Currency: "EUR"},
+					Email:     "email@example.com",
+					BookingID: uuid.NewString(),
+				},
+			}}),
+			ExpectedStatus: http.StatusUnprocessableEntity,
+			ExpectedFields: []string{"tickets[0].status"},
+		},
+		{
+			Name: "invalid_ticket_price",
+			Path: "/tickets-status",
+			Body: mustMarshal(t, TicketsStatusRequest{Tickets: []TicketStatus{
+				{
+					TicketID: uuid.NewString(),
+					Status:   "confirmed",
+					Price:    Money{Amount: "-10", Currency: "XYZ"},
+					Email:    "email@example.com",
+				},
+			}}),
+			ExpectedStatus: http.StatusUnprocessableEntity,
+			ExpectedFields: []string{"tickets[0].price.amount", "tickets[0].price.currency"},
+		},
+		{
+			Name:           "invalid_webhook",
+			Path:           "/webhooks",
+			Body:           `{"url":"https://example.com/hook","event_types":["TicketSold_v9"]}`,
+			ExpectedStatus: http.StatusUnprocessableEntity,
+			ExpectedFields: []string{"event_types[0]"},
+		},
+		{
+			Name:           "invalid_webhook_url",
+			Path:           "/webhooks",
+			Body:           `{"url":"example.com/hook","event_types":["TicketBookingConfirmed"]}`,
+			ExpectedStatus: http.StatusUnprocessableEntity,
+			ExpectedFields: []string{"url"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			status, resp := postRaw(t, tc.Path, []byte(tc.Body))
+			assert.Equal(t, tc.ExpectedStatus, status)
+			assert.NotEmpty(t, resp.Error)
+
+			fields := make([]string, 0, len(resp.Fields))
+			for _, field := range resp.Fields {
+				assert.NotEmpty(t, field.Message, "missing message of %s", field.Field)
+				fields = append(fields, field.Field)
+			}
+			assert.ElementsMatch(t, tc.ExpectedFields, fields)
+		})
+	}
+
+	t.Run("unknown_show", func(t *testing.T) {
+		status, resp := postRaw(t, "/book-tickets", []byte(mustMarshal(t, map[string]any{
+			"show_id":           uuid.New(),
+			"number_of_tickets": 1,
+			"customer_email":    "email@example.com",
+		})))
+		assert.Equal(t, http.StatusUnprocessableEntity, status)
+		assert.Equal(t, []fieldError{{Field: "show_id", Message: "show not found"}}, resp.Fields)
+	})
+
+	t.Run("invalid_query_parameter", func(t *testing.T) {
+		resp, err := httpGet("/tickets?limit=0&sort=price")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var errResp errorResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&errResp))
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		fields := make([]string, 0, len(errResp.Fields))
+		for _, field := range errResp.Fields {
+			fields = append(fields, field.Field)
+		}
+		assert.ElementsMatch(t, []string{"limit", "sort"}, fields)
+	})
+
+	t.Run("specifications", func(t *testing.T) {
+		var spec struct {
+			OpenAPI string         `json:"openapi"`
+			Paths   map[string]any `json:"paths"`
+		}
+		require.Equal(t, http.StatusOK, getJSON(t, "/openapi.json", &spec))
+
+		assert.NotEmpty(t, spec.OpenAPI)
+		assert.Contains(t, spec.Paths, "/tickets-status")
+		assert.Contains(t, spec.Paths, "/book-tickets")
+
+		var asyncAPI struct {
+			AsyncAPI string         `json:"asyncapi"`
+			Channels map[string]any `json:"channels"`
+		}
+		require.Equal(t, http.StatusOK, getJSON(t, "/asyncapi.json", &asyncAPI))
+
+		assert.NotEmpty(t, asyncAPI.AsyncAPI)
+		assert.Contains(t, asyncAPI.Channels, "TicketBookingConfirmed")
+	})
+}
+
+// postRaw sends the body as an admin, and returns the response status code with the decoded error.
+func postRaw(t *testing.T, path string, payload []byte) (int, errorResponse) {
+	t.Helper()
+
+	httpReq, err := http.NewRequest(http.MethodPost, "http://localhost:8080"+path, bytes.NewBuffer(payload))
+	require.NoError(t, err)
+
+	httpReq.Header.Set(echo.HeaderContentType, "application/json")
+	httpReq.Header.Set(echo.HeaderAuthorization, "Bearer "+adminToken)
+	httpReq.Header.Set("Idempotency-Key", uuid.NewString())
+	signRequest(httpReq, gatewaySecret, time.Now(), payload)
+
+	resp, err := http.DefaultClient.Do(httpReq)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var errResp errorResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&errResp))
+
+	return resp.StatusCode, errResp
+}
+
+func mustMarshal(t *testing.T, v any) string {
+	t.Helper()
+
+	payload, err := json.Marshal(v)
+	require.NoError(t, err)
+
+	return string(payload)
+}
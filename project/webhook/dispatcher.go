@@ -0,0 +1,323 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"tickets/entities"
+	"tickets/message/event"
+	"time"
+
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+	"github.com/ThreeDotsLabs/watermill/components/cqrs"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+)
+
+type Repository interface {
+	FindActiveForEventType(ctx context.Context, eventType string) ([]entities.WebhookSubscription, error)
+	// AddMessages records the messages to deliver, skipping the ones already recorded (of a redelivered event).
+	AddMessages(ctx context.Context, messages []entities.WebhookMessage) error
+	// ClaimDueMessages returns up to limit messages due to be delivered to the active subscriptions,
+	// and postpones their next attempt by claimFor, so they are not claimed by other replicas in the meantime.
+	ClaimDueMessages(ctx context.Context, limit int, claimFor time.Duration) ([]entities.WebhookMessage, error)
+	// UpdateMessage saves the attempts, the next attempt and the completion of the message.
+	UpdateMessage(ctx context.Context, message entities.WebhookMessage) error
+	AddDelivery(ctx context.Context, delivery entities.WebhookDelivery) error
+	MarkDeliverySucceeded(ctx context.Context, subscriptionID uuid.UUID) error
+	MarkDeliveryFailed(ctx context.Context, subscriptionID uuid.UUID, disableAfter int) (bool, error)
+}
+
+type Config struct {
+	MaxAttempts          int
+	InitialInterval      time.Duration
+	MaxInterval          time.Duration
+	Multiplier           float64
+	DisableAfterFailures int
+	Timeout              time.Duration
+
+	// PollInterval is the time between the checks for the messages due to be delivered.
+	PollInterval time.Duration
+	// BatchSize is the maximum number of messages claimed at once.
+	BatchSize int
+	// Concurrency is the maximum number of messages delivered at the same time,
+	// so a slow endpoint doesn't hold the deliveries to the other ones.
+	Concurrency int
+}
+
+func (c *Config) setDefaults() {
+	if c.MaxAttempts == 0 {
+		c.MaxAttempts = 5
+	}
+	if c.InitialInterval == 0 {
+		c.InitialInterval = time.Millisecond * 500
+	}
+	if c.MaxInterval == 0 {
+		c.MaxInterval = time.Second * 10
+	}
+	if c.Multiplier == 0 {
+		c.Multiplier = 2
+	}
+	if c.DisableAfterFailures == 0 {
+		c.DisableAfterFailures = 5
+	}
+	if c.Timeout == 0 {
+		c.Timeout = time.Second * 5
+	}
+	if c.PollInterval == 0 {
+		c.PollInterval = time.Millisecond * 500
+	}
+	if c.BatchSize == 0 {
+		c.BatchSize = 20
+	}
+	if c.Concurrency == 0 {
+		c.Concurrency = 10
+	}
+}
+
+// claimFor is how long the claimed messages are not claimed again. All of them are attempted by then,
+// as every attempt takes at most Timeout.
+func (c Config) claimFor() time.Duration {
+	rounds := (c.BatchSize + c.Concurrency - 1) / c.Concurrency
+
+	return c.Timeout*time.Duration(rounds) + time.Second*30
+}
+
+type Dispatcher struct {
+	repository Repository
+	client     *http.Client
+	config     Config
+}
+
+func NewDispatcher(repository Repository, client *http.Client, config Config) Dispatcher {
+	if repository == nil {
+		panic("missing repository")
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	config.setDefaults()
+
+	return Dispatcher{
+		repository: repository,
+		client:     client,
+		config:     config,
+	}
+}
+
+type payload struct {
+	ID   string          `json:"id"`
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Handle is a Watermill handler recording the events published by the event bus for the subscribed webhooks.
+func (d Dispatcher) Handle(msg *message.Message) error {
+	eventType := cqrs.JSONMarshaler{}.NameFromMessage(msg)
+
+	data, err := event.JSONPayload(msg)
+	if err != nil {
+		return fmt.Errorf("failed to read %s payload: %w", eventType, err)
+	}
+
+	return d.Dispatch(msg.Context(), msg.UUID, eventType, data)
+}
+
+// Dispatch records the event as a message for every active subscription of its type, they are delivered by Run.
+// A redelivered event is not delivered again to the subscriptions that already got it.
+// Receivers should still deduplicate on the Webhook-ID header, as a message may be delivered more than once.
+func (d Dispatcher) Dispatch(ctx context.Context, eventID string, eventType string, data []byte) error {
+	subscriptions, err := d.repository.FindActiveForEventType(ctx, eventType)
+	if err != nil {
+		return err
+	}
+
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(payload{
+		ID:   eventID,
+		Type: eventType,
+		Data: data,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	now := time.Now().UTC()
+
+	messages := make([]entities.WebhookMessage, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		messages = append(messages, entities.WebhookMessage{
+			Subscription:  subscription,
+			EventID:       eventID,
+			EventType:     eventType,
+			Body:          body,
+			CorrelationID: log.CorrelationIDFromContext(ctx),
+			NextAttemptAt: now,
+		})
+	}
+
+	return d.repository.AddMessages(ctx, messages)
+}
+
+// Run delivers the due messages every PollInterval until ctx is done.
+func (d Dispatcher) Run(ctx context.Context) error {
+	logger := log.FromContext(ctx)
+
+	for {
+		delivered, err := d.DeliverDue(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			logger.WithError(err).Error("Webhooks delivery failed")
+		}
+
+		// there may be more due messages
+		if err == nil && delivered == d.config.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(d.config.PollInterval):
+		}
+	}
+}
+
+// DeliverDue makes one attempt to deliver every claimed message, and returns the number of claimed messages.
+// Failed messages are retried with a backoff by the next calls, until MaxAttempts.
+func (d Dispatcher) DeliverDue(ctx context.Context) (int, error) {
+	messages, err := d.repository.ClaimDueMessages(ctx, d.config.BatchSize, d.config.claimFor())
+	if err != nil {
+		return 0, err
+	}
+
+	errgrp, groupCtx := errgroup.WithContext(ctx)
+	errgrp.SetLimit(d.config.Concurrency)
+
+	for _, msg := range messages {
+		msg := msg
+		errgrp.Go(func() error {
+			return d.deliver(groupCtx, msg)
+		})
+	}
+
+	return len(messages), errgrp.Wait()
+}
+
+func (d Dispatcher) deliver(ctx context.Context, msg entities.WebhookMessage) error {
+	logger := log.FromContext(ctx).WithFields(logrus.Fields{
+		"webhook_id": msg.Subscription.ID,
+		"event_id":   msg.EventID,
+		"event_type": msg.EventType,
+	})
+
+	msg.Attempts++
+
+	statusCode, sendErr := d.send(ctx, msg)
+
+	now := time.Now().UTC()
+
+	delivery := entities.WebhookDelivery{
+		ID:             uuid.New(),
+		SubscriptionID: msg.Subscription.ID,
+		EventID:        msg.EventID,
+		EventType:      msg.EventType,
+		Attempt:        msg.Attempts,
+		StatusCode:     statusCode,
+		Succeeded:      sendErr == nil,
+		DeliveredAt:    now,
+	}
+	if sendErr != nil {
+		delivery.Error = sendErr.Error()
+	}
+
+	if err := d.repository.AddDelivery(ctx, delivery); err != nil {
+		return err
+	}
+
+	if sendErr == nil {
+		msg.CompletedAt = &now
+		if err := d.repository.UpdateMessage(ctx, msg); err != nil {
+			return err
+		}
+
+		return d.repository.MarkDeliverySucceeded(ctx, msg.Subscription.ID)
+	}
+
+	logger.WithError(sendErr).WithField("attempt", msg.Attempts).Warn("Webhook delivery failed")
+
+	if msg.Attempts < d.config.MaxAttempts {
+		msg.NextAttemptAt = now.Add(d.backoff(msg.Attempts))
+		return d.repository.UpdateMessage(ctx, msg)
+	}
+
+	msg.CompletedAt = &now
+	if err := d.repository.UpdateMessage(ctx, msg); err != nil {
+		return err
+	}
+
+	disabled, err := d.repository.MarkDeliveryFailed(ctx, msg.Subscription.ID, d.config.DisableAfterFailures)
+	if err != nil {
+		return err
+	}
+	if disabled {
+		logger.Warn("Webhook disabled after too many failed deliveries")
+	}
+
+	return nil
+}
+
+// backoff returns the time to wait after the failed attempt before the next one.
+func (d Dispatcher) backoff(attempt int) time.Duration {
+	interval := d.config.InitialInterval
+	for i := 1; i < attempt; i++ {
+		interval = time.Duration(float64(interval) * d.config.Multiplier)
+		if interval >= d.config.MaxInterval {
+			return d.config.MaxInterval
+		}
+	}
+
+	return interval
+}
+
+func (d Dispatcher) send(ctx context.Context, msg entities.WebhookMessage) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.config.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, msg.Subscription.URL, bytes.NewReader(msg.Body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Correlation-ID", msg.CorrelationID)
+	req.Header.Set(HeaderID, msg.EventID)
+	req.Header.Set(HeaderEvent, msg.EventType)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(msg.Subscription.Secret, timestamp, msg.Body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
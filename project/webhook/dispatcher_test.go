@@ -0,0 +1,245 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"tickets/db"
+	"tickets/db/memory"
+	"tickets/tenant"
+	"tickets/webhook"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDispatcher_Dispatch_signs_payload(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewWebhooksRepository(memory.NewDatabase())
+
+	subscription, err := webhook.NewSubscription("http://placeholder", []string{"TicketBookingConfirmed"})
+	require.NoError(t, err)
+
+	var received atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		assert.True(t, webhook.VerifySignature(
+			subscription.Secret,
+			r.Header.Get(webhook.HeaderTimestamp),
+			body,
+			r.Header.Get(webhook.HeaderSignature),
+		))
+		assert.Equal(t, "event-1", r.Header.Get(webhook.HeaderID))
+		assert.Equal(t, "TicketBookingConfirmed", r.Header.Get(webhook.HeaderEvent))
+
+		var payload map[string]any
+		require.NoError(t, json.Unmarshal(body, &payload))
+		assert.Equal(t, "TicketBookingConfirmed", payload["type"])
+		assert.Equal(t, map[string]any{"ticket_id": "123"}, payload["data"])
+
+		received.Add(1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	subscription.URL = server.URL
+	require.NoError(t, repo.Add(ctx, subscription))
+
+	dispatcher := webhook.NewDispatcher(repo, server.Client(), testConfig())
+
+	err = dispatcher.Dispatch(ctx, "event-1", "TicketBookingConfirmed", []byte(`{"ticket_id":"123"}`))
+	require.NoError(t, err)
+	assert.EqualValues(t, 0, received.Load(), "the event should be delivered outside of the handler")
+
+	deliverAll(t, dispatcher)
+
+	assert.EqualValues(t, 1, received.Load())
+	deliveries, err := repo.GetDeliveries(ctx, subscription.ID)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.True(t, deliveries[0].Succeeded)
+	assert.Equal(t, http.StatusNoContent, deliveries[0].StatusCode)
+
+	// the event is redelivered to the handler
+	err = dispatcher.Dispatch(ctx, "event-1", "TicketBookingConfirmed", []byte(`{"ticket_id":"123"}`))
+	require.NoError(t, err)
+	deliverAll(t, dispatcher)
+	assert.EqualValues(t, 1, received.Load(), "delivered event should not be delivered again")
+
+	err = dispatcher.Dispatch(ctx, "event-2", "BookingMade", []byte(`{}`))
+	require.NoError(t, err)
+	deliverAll(t, dispatcher)
+	assert.EqualValues(t, 1, received.Load(), "event type not subscribed should not be delivered")
+}
+
+func TestDispatcher_Dispatch_retries(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewWebhooksRepository(memory.NewDatabase())
+
+	var failingCalls, calls atomic.Int32
+
+	failingServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failingCalls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer failingServer.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	failingSubscription, err := webhook.NewSubscription(failingServer.URL, []string{"BookingMade"})
+	require.NoError(t, err)
+	require.NoError(t, repo.Add(ctx, failingSubscription))
+
+	subscription, err := webhook.NewSubscription(server.URL, []string{"BookingMade"})
+	require.NoError(t, err)
+	require.NoError(t, repo.Add(ctx, subscription))
+
+	dispatcher := webhook.NewDispatcher(repo, http.DefaultClient, testConfig())
+
+	err = dispatcher.Dispatch(ctx, uuid.NewString(), "BookingMade", []byte(`{}`))
+	require.NoError(t, err)
+
+	deliverAll(t, dispatcher)
+
+	assert.EqualValues(t, 3, failingCalls.Load())
+	assert.EqualValues(t, 1, calls.Load(), "the retries should not deliver the event to the other subscriptions again")
+
+	deliveries, err := repo.GetDeliveries(ctx, failingSubscription.ID)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 3)
+
+	var succeeded []bool
+	for _, delivery := range deliveries {
+		succeeded = append(succeeded, delivery.Succeeded)
+	}
+	assert.ElementsMatch(t, []bool{false, false, true}, succeeded)
+
+	failingSubscription, err = repo.GetOne(ctx, failingSubscription.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, failingSubscription.ConsecutiveFailures)
+}
+
+func TestDispatcher_Dispatch_disables_failing_endpoint(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewWebhooksRepository(memory.NewDatabase())
+
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	subscription, err := webhook.NewSubscription(server.URL, []string{"BookingMade"})
+	require.NoError(t, err)
+	require.NoError(t, repo.Add(ctx, subscription))
+
+	config := testConfig()
+	config.DisableAfterFailures = 2
+
+	dispatcher := webhook.NewDispatcher(repo, server.Client(), config)
+
+	for i := 0; i < 3; i++ {
+		err = dispatcher.Dispatch(ctx, uuid.NewString(), "BookingMade", []byte(`{}`))
+		require.NoError(t, err)
+		deliverAll(t, dispatcher)
+	}
+
+	assert.EqualValues(t, 2*config.MaxAttempts, calls.Load(), "disabled webhook should not be called")
+
+	subscription, err = repo.GetOne(ctx, subscription.ID)
+	require.NoError(t, err)
+	assert.True(t, subscription.Disabled)
+}
+
+func TestDispatcher_Dispatch_delivers_to_subscriptions_of_the_event_tenant(t *testing.T) {
+	repo := memory.NewWebhooksRepository(memory.NewDatabase())
+	acme := tenant.WithID(context.Background(), "acme")
+	other := tenant.WithID(context.Background(), "other")
+
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	subscription, err := webhook.NewSubscription(server.URL, []string{"BookingMade"})
+	require.NoError(t, err)
+	require.NoError(t, repo.Add(acme, subscription))
+
+	dispatcher := webhook.NewDispatcher(repo, http.DefaultClient, testConfig())
+
+	require.NoError(t, dispatcher.Dispatch(other, uuid.NewString(), "BookingMade", []byte(`{}`)))
+	deliverAll(t, dispatcher)
+	assert.EqualValues(t, 0, calls.Load(), "the event of another tenant should not be delivered")
+
+	require.NoError(t, dispatcher.Dispatch(acme, uuid.NewString(), "BookingMade", []byte(`{}`)))
+	deliverAll(t, dispatcher)
+	assert.EqualValues(t, 1, calls.Load())
+
+	_, err = repo.GetOne(other, subscription.ID)
+	assert.ErrorIs(t, err, db.ErrNotFound)
+	deliveries, err := repo.GetDeliveries(other, subscription.ID)
+	require.NoError(t, err)
+	assert.Empty(t, deliveries)
+}
+
+func TestNewSubscription_validation(t *testing.T) {
+	_, err := webhook.NewSubscription("not-an-url", []string{"BookingMade"})
+	assert.ErrorIs(t, err, webhook.ErrInvalidSubscription)
+
+	_, err = webhook.NewSubscription("https://example.com", nil)
+	assert.ErrorIs(t, err, webhook.ErrInvalidSubscription)
+
+	_, err = webhook.NewSubscription("https://example.com", []string{"UnknownEvent"})
+	assert.ErrorIs(t, err, webhook.ErrInvalidSubscription)
+}
+
+func testConfig() webhook.Config {
+	return webhook.Config{
+		MaxAttempts:     3,
+		InitialInterval: time.Millisecond,
+		MaxInterval:     time.Millisecond * 5,
+	}
+}
+
+// deliverAll delivers the messages until none of them is due after the longest backoff.
+func deliverAll(t *testing.T, dispatcher webhook.Dispatcher) {
+	t.Helper()
+
+	idle := false
+	for {
+		delivered, err := dispatcher.DeliverDue(context.Background())
+		require.NoError(t, err)
+
+		if delivered > 0 {
+			idle = false
+			continue
+		}
+		if idle {
+			return
+		}
+
+		idle = true
+		time.Sleep(time.Millisecond * 20)
+	}
+}
@@ -0,0 +1,55 @@
+package webhook
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"tickets/entities"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventTypes lists the events that partners can subscribe to.
+var EventTypes = []string{
+	"BookingMade",
+	"TicketBookingConfirmed",
+	"TicketBookingCanceled",
+	"TicketRefunded",
+	"TicketPrinted",
+	"CustomerDataErased",
+}
+
+var ErrInvalidSubscription = errors.New("invalid webhook subscription")
+
+func NewSubscription(targetURL string, eventTypes []string) (entities.WebhookSubscription, error) {
+	parsedURL, err := url.Parse(targetURL)
+	if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") || parsedURL.Host == "" {
+		return entities.WebhookSubscription{}, fmt.Errorf("%w: url must be an absolute http(s) url", ErrInvalidSubscription)
+	}
+
+	if len(eventTypes) == 0 {
+		return entities.WebhookSubscription{}, fmt.Errorf("%w: at least one event type is required", ErrInvalidSubscription)
+	}
+	for _, eventType := range eventTypes {
+		if !slices.Contains(EventTypes, eventType) {
+			return entities.WebhookSubscription{}, fmt.Errorf("%w: unknown event type %s", ErrInvalidSubscription, eventType)
+		}
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return entities.WebhookSubscription{}, fmt.Errorf("could not generate webhook secret: %w", err)
+	}
+
+	return entities.WebhookSubscription{
+		ID:         uuid.New(),
+		URL:        targetURL,
+		EventTypes: eventTypes,
+		Secret:     hex.EncodeToString(secret),
+		CreatedAt:  time.Now().UTC(),
+	}, nil
+}